@@ -0,0 +1,185 @@
+package s3bytes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// DefaultAnomalyWindow is the number of the trailing day-over-day changes
+	// that a change is compared with by default.
+	DefaultAnomalyWindow = 14
+
+	// DefaultAnomalyZScore is the absolute z-score from which a change is anomalous by default.
+	DefaultAnomalyZScore = 3.0
+
+	// minStdDev is the floor of the standard deviation of the trailing changes, so that
+	// a change after a perfectly steady series yields a finite z-score.
+	minStdDev = 1.0
+
+	// minStdDevRatio is the floor of the standard deviation of the trailing changes relative to
+	// the previous value, so that a change of a few bytes or objects in a large steady bucket
+	// is not scored as anomalous.
+	minStdDevRatio = 0.001
+)
+
+// anomalyHeader is the columns appended to the header of the anomaly report.
+var anomalyHeader = []string{
+	"AnomalyDate",
+	"Change",
+	"ChangePercent",
+	"ZScore",
+}
+
+// Anomaly represents the most anomalous day-over-day change in the daily series of a bucket.
+// AnomalyDate is the date of the datapoint after the change, Change is the difference from the
+// previous datapoint, ChangePercent is the change relative to the previous value, and ZScore is
+// the change standardized by the mean and the standard deviation of the trailing changes.
+// All fields are zero if no change is anomalous.
+type Anomaly struct {
+	AnomalyDate   time.Time `json:",omitzero"`
+	Change        float64   `json:",omitzero"`
+	ChangePercent float64   `json:",omitzero"`
+	ZScore        float64   `json:",omitzero"`
+}
+
+// IsAnomalous reports whether an anomalous change is found.
+func (a Anomaly) IsAnomalous() bool {
+	return !a.AnomalyDate.IsZero()
+}
+
+// AnomalyDetector flags the day-over-day changes of a daily series that deviate from the trailing changes.
+// A change is anomalous if the absolute z-score reaches the threshold and the absolute change
+// in percent reaches the minimum.
+type AnomalyDetector struct {
+	window     int
+	zScore     float64
+	minChange  float64
+	latestOnly bool
+}
+
+// AnomalyOption is a functional option for the anomaly detector.
+type AnomalyOption func(*AnomalyDetector)
+
+// WithAnomalyWindow sets the number of the trailing changes that a change is compared with.
+func WithAnomalyWindow(window int) AnomalyOption {
+	return func(d *AnomalyDetector) {
+		d.window = window
+	}
+}
+
+// WithZScore sets the absolute z-score from which a change is anomalous.
+func WithZScore(zScore float64) AnomalyOption {
+	return func(d *AnomalyDetector) {
+		d.zScore = zScore
+	}
+}
+
+// WithMinChange sets the minimum absolute change in percent of an anomalous change,
+// which suppresses the anomalies of the buckets that are too steady to be worth noting.
+func WithMinChange(percent float64) AnomalyOption {
+	return func(d *AnomalyDetector) {
+		d.minChange = percent
+	}
+}
+
+// WithLatestOnly sets whether to examine only the latest change instead of all changes in the lookback,
+// which suits a check condition that should fail only while the anomaly is the current state.
+func WithLatestOnly(latestOnly bool) AnomalyOption {
+	return func(d *AnomalyDetector) {
+		d.latestOnly = latestOnly
+	}
+}
+
+// NewAnomalyDetector creates a new anomaly detector with DefaultAnomalyWindow and DefaultAnomalyZScore.
+func NewAnomalyDetector(opts ...AnomalyOption) (*AnomalyDetector, error) {
+	d := &AnomalyDetector{
+		window: DefaultAnomalyWindow,
+		zScore: DefaultAnomalyZScore,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.window < 2 {
+		return nil, fmt.Errorf("invalid anomaly window: %d", d.window)
+	}
+	if d.zScore <= 0 {
+		return nil, fmt.Errorf("invalid z-score: %v", d.zScore)
+	}
+	if d.minChange < 0 {
+		return nil, fmt.Errorf("invalid min change: %v", d.minChange)
+	}
+	return d, nil
+}
+
+// detect returns the anomalous change with the largest absolute z-score in the datapoints,
+// or only the latest change if it is anomalous when the detector examines the latest only.
+// A change is examined only when it is preceded by a full window of changes.
+func (d *AnomalyDetector) detect(points []point) Anomaly {
+	var ret Anomaly
+	if len(points) < d.window+2 {
+		return ret
+	}
+	changes := make([]float64, len(points)-1)
+	for i := range changes {
+		changes[i] = points[i+1].value - points[i].value
+	}
+	first := d.window
+	if d.latestOnly {
+		first = len(changes) - 1
+	}
+	for i := first; i < len(changes); i++ {
+		mean, stddev := meanStdDev(changes[i-d.window : i])
+		floor := math.Max(minStdDev, math.Abs(points[i].value)*minStdDevRatio)
+		z := (changes[i] - mean) / math.Max(stddev, floor)
+		if math.Abs(z) < d.zScore || math.Abs(z) <= math.Abs(ret.ZScore) {
+			continue
+		}
+		percent := math.Copysign(100, changes[i])
+		if prev := points[i].value; prev != 0 {
+			percent = changes[i] / prev * 100
+		}
+		if math.Abs(percent) < d.minChange {
+			continue
+		}
+		ret = Anomaly{
+			AnomalyDate:   points[i+1].timestamp,
+			Change:        changes[i],
+			ChangePercent: math.Round(percent*100) / 100,
+			ZScore:        math.Round(z*100) / 100,
+		}
+	}
+	return ret
+}
+
+// meanStdDev returns the mean and the population standard deviation of the values.
+func meanStdDev(values []float64) (float64, float64) {
+	var sum, sq float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(values)))
+}
+
+// ListAnomalies examines the daily series of each bucket over the last lookback days with the
+// detector, and annotates the metrics with the most anomalous change. The series are fetched from
+// CloudWatch with the same queries as List, and each row holds the latest datapoint as the value.
+// The lookback must cover the window of the detector and the change to examine.
+func (man *Manager) ListAnomalies(ctx context.Context, lookback int, detector *AnomalyDetector) (*MetricData, error) {
+	if detector == nil {
+		return nil, errors.New("no anomaly detector")
+	}
+	if lookback < detector.window+2 {
+		return nil, fmt.Errorf("invalid lookback: %d days, must be at least %d for the anomaly window", lookback, detector.window+2)
+	}
+	return man.listSeries(ctx, lookback, anomalyHeader, func(metric *Metric, s *series) {
+		metric.Anomaly = detector.detect(s.points)
+	})
+}
@@ -0,0 +1,494 @@
+package s3bytes
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"golang.org/x/sync/semaphore"
+)
+
+// dropValue grows by 100 a day from 1000 and drops to 0 on the 21st day.
+func dropValue(i int, t time.Time) float64 {
+	if i == 20 {
+		return 0
+	}
+	return linearValue(i, t)
+}
+
+func TestNewAnomalyDetector(t *testing.T) {
+	type args struct {
+		opts []AnomalyOption
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *AnomalyDetector
+		wantErr bool
+	}{
+		{
+			name: "default",
+			args: args{},
+			want: &AnomalyDetector{
+				window: DefaultAnomalyWindow,
+				zScore: DefaultAnomalyZScore,
+			},
+			wantErr: false,
+		},
+		{
+			name: "options",
+			args: args{
+				opts: []AnomalyOption{WithAnomalyWindow(7), WithZScore(2.5), WithMinChange(10), WithLatestOnly(true)},
+			},
+			want: &AnomalyDetector{
+				window:     7,
+				zScore:     2.5,
+				minChange:  10,
+				latestOnly: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid window",
+			args: args{
+				opts: []AnomalyOption{WithAnomalyWindow(1)},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "invalid z-score",
+			args: args{
+				opts: []AnomalyOption{WithZScore(0)},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "invalid min change",
+			args: args{
+				opts: []AnomalyOption{WithMinChange(-1)},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewAnomalyDetector(tt.args.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewAnomalyDetector() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewAnomalyDetector() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnomalyDetector_detect(t *testing.T) {
+	type fields struct {
+		window     int
+		zScore     float64
+		minChange  float64
+		latestOnly bool
+	}
+	type args struct {
+		points []point
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   Anomaly
+	}{
+		{
+			name: "drop",
+			fields: fields{
+				window: 14,
+				zScore: 3,
+			},
+			args: args{
+				points: newTestPoints(22, dropValue),
+			},
+			want: Anomaly{
+				AnomalyDate:   testSeriesOrigin.Add(20 * day),
+				Change:        -2900,
+				ChangePercent: -100,
+				ZScore:        -1034.48,
+			},
+		},
+		{
+			name: "latest only",
+			fields: fields{
+				window:     14,
+				zScore:     3,
+				latestOnly: true,
+			},
+			args: args{
+				points: newTestPoints(21, dropValue),
+			},
+			want: Anomaly{
+				AnomalyDate:   testSeriesOrigin.Add(20 * day),
+				Change:        -2900,
+				ChangePercent: -100,
+				ZScore:        -1034.48,
+			},
+		},
+		{
+			name: "latest only after anomaly",
+			fields: fields{
+				window:     14,
+				zScore:     3,
+				latestOnly: true,
+			},
+			args: args{
+				points: newTestPoints(25, dropValue),
+			},
+			want: Anomaly{},
+		},
+		{
+			name: "steady",
+			fields: fields{
+				window: 14,
+				zScore: 3,
+			},
+			args: args{
+				points: newTestPoints(30, linearValue),
+			},
+			want: Anomaly{},
+		},
+		{
+			name: "largest z-score",
+			fields: fields{
+				window: 7,
+				zScore: 3,
+			},
+			args: args{
+				points: newTestPoints(30, func(i int, t time.Time) float64 {
+					switch {
+					case i >= 25:
+						return linearValue(i, t) + 5000
+					case i >= 10:
+						return linearValue(i, t) + 500
+					}
+					return linearValue(i, t)
+				}),
+			},
+			want: Anomaly{
+				AnomalyDate:   testSeriesOrigin.Add(25 * day),
+				Change:        4600,
+				ChangePercent: 117.95,
+				ZScore:        1153.85,
+			},
+		},
+		{
+			name: "small change in large steady bucket",
+			fields: fields{
+				window: 14,
+				zScore: 3,
+			},
+			args: args{
+				points: newTestPoints(22, func(i int, _ time.Time) float64 {
+					if i >= 20 {
+						return 100005
+					}
+					return 100000
+				}),
+			},
+			want: Anomaly{},
+		},
+		{
+			name: "suppressed by min change",
+			fields: fields{
+				window:    14,
+				zScore:    3,
+				minChange: 10,
+			},
+			args: args{
+				points: newTestPoints(22, func(i int, t time.Time) float64 {
+					if i == 20 {
+						return linearValue(i, t) + 50
+					}
+					return linearValue(i, t)
+				}),
+			},
+			want: Anomaly{},
+		},
+		{
+			name: "growth from zero",
+			fields: fields{
+				window: 2,
+				zScore: 3,
+			},
+			args: args{
+				points: []point{
+					{timestamp: testSeriesOrigin, value: 0},
+					{timestamp: testSeriesOrigin.Add(day), value: 0},
+					{timestamp: testSeriesOrigin.Add(2 * day), value: 0},
+					{timestamp: testSeriesOrigin.Add(3 * day), value: 1024},
+				},
+			},
+			want: Anomaly{
+				AnomalyDate:   testSeriesOrigin.Add(3 * day),
+				Change:        1024,
+				ChangePercent: 100,
+				ZScore:        1024,
+			},
+		},
+		{
+			name: "too few datapoints",
+			fields: fields{
+				window: 14,
+				zScore: 3,
+			},
+			args: args{
+				points: newTestPoints(15, dropValue),
+			},
+			want: Anomaly{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &AnomalyDetector{
+				window:     tt.fields.window,
+				zScore:     tt.fields.zScore,
+				minChange:  tt.fields.minChange,
+				latestOnly: tt.fields.latestOnly,
+			}
+			got := d.detect(tt.args.points)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AnomalyDetector.detect() = %v, want %v", got, tt.want)
+			}
+			if got.IsAnomalous() != !tt.want.AnomalyDate.IsZero() {
+				t.Errorf("Anomaly.IsAnomalous() = %v, want %v", got.IsAnomalous(), !tt.want.AnomalyDate.IsZero())
+			}
+		})
+	}
+}
+
+func Test_meanStdDev(t *testing.T) {
+	type args struct {
+		values []float64
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantMean   float64
+		wantStdDev float64
+	}{
+		{
+			name: "constant",
+			args: args{
+				values: []float64{100, 100, 100},
+			},
+			wantMean:   100,
+			wantStdDev: 0,
+		},
+		{
+			name: "varying",
+			args: args{
+				values: []float64{2, 4, 4, 4, 5, 5, 7, 9},
+			},
+			wantMean:   5,
+			wantStdDev: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mean, stddev := meanStdDev(tt.args.values)
+			if math.Abs(mean-tt.wantMean) > 1e-9 {
+				t.Errorf("meanStdDev() mean = %v, want %v", mean, tt.wantMean)
+			}
+			if math.Abs(stddev-tt.wantStdDev) > 1e-9 {
+				t.Errorf("meanStdDev() stddev = %v, want %v", stddev, tt.wantStdDev)
+			}
+		})
+	}
+}
+
+func TestManager_ListAnomalies(t *testing.T) {
+	listBuckets := func(_ context.Context, _ *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+		return &s3.ListBucketsOutput{
+			Buckets: []s3types.Bucket{
+				{Name: aws.String("bucket0")},
+				{Name: aws.String("bucket1")},
+			},
+		}, nil
+	}
+	points := newTestPoints(22, dropValue)
+	detector, err := NewAnomalyDetector()
+	if err != nil {
+		t.Fatal(err)
+	}
+	type fields struct {
+		client     *Client
+		noDataMode NoDataMode
+	}
+	type args struct {
+		lookback int
+		detector *AnomalyDetector
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *MetricData
+		wantErr bool
+	}{
+		{
+			name: "anomaly",
+			fields: fields{
+				client: newMockClient(
+					&mockS3{
+						ListDirectoryBucketsFunc: listNoDirectoryBuckets,
+						ListBucketsFunc:          listBuckets,
+					},
+					newMockSeriesCloudWatch(points),
+				),
+			},
+			args: args{
+				lookback: 30,
+				detector: detector,
+			},
+			want: &MetricData{
+				Header: append(slices.Clone(header), anomalyHeader...),
+				Metrics: []*Metric{
+					{
+						BucketName:  "bucket0",
+						Region:      "ap-northeast-1",
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeStandardStorage,
+						Value:       3100,
+						Status:      DataStatusOK,
+						Timestamp:   points[21].timestamp,
+						Source:      SourceTypeCloudWatch,
+						BucketType:  BucketTypeGeneralPurpose,
+						Anomaly: Anomaly{
+							AnomalyDate:   points[20].timestamp,
+							Change:        -2900,
+							ChangePercent: -100,
+							ZScore:        -1034.48,
+						},
+					},
+					{
+						BucketName:  "bucket1",
+						Region:      "ap-northeast-1",
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeStandardStorage,
+						Status:      DataStatusNoData,
+						Source:      SourceTypeCloudWatch,
+						BucketType:  BucketTypeGeneralPurpose,
+					},
+				},
+				Total: 3100,
+			},
+			wantErr: false,
+		},
+		{
+			name: "hide no data",
+			fields: fields{
+				client: newMockClient(
+					&mockS3{
+						ListDirectoryBucketsFunc: listNoDirectoryBuckets,
+						ListBucketsFunc:          listBuckets,
+					},
+					newMockSeriesCloudWatch(points[:20]),
+				),
+				noDataMode: NoDataModeHide,
+			},
+			args: args{
+				lookback: 30,
+				detector: detector,
+			},
+			want: &MetricData{
+				Header: append(slices.Clone(header), anomalyHeader...),
+				Metrics: []*Metric{
+					{
+						BucketName:  "bucket0",
+						Region:      "ap-northeast-1",
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeStandardStorage,
+						Value:       2900,
+						Status:      DataStatusOK,
+						Timestamp:   points[19].timestamp,
+						Source:      SourceTypeCloudWatch,
+						BucketType:  BucketTypeGeneralPurpose,
+					},
+				},
+				Total: 2900,
+			},
+			wantErr: false,
+		},
+		{
+			name: "metric error",
+			fields: fields{
+				client: newMockClient(
+					&mockS3{
+						ListDirectoryBucketsFunc: listNoDirectoryBuckets,
+						ListBucketsFunc:          listBuckets,
+					},
+					&mockCloudWatch{
+						GetMetricDataFunc: func(_ context.Context, _ *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+							return nil, errors.New("error")
+						},
+					},
+				),
+			},
+			args: args{
+				lookback: 30,
+				detector: detector,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:   "lookback shorter than window",
+			fields: fields{},
+			args: args{
+				lookback: DefaultAnomalyWindow + 1,
+				detector: detector,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:   "no detector",
+			fields: fields{},
+			args: args{
+				lookback: 30,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			man := &Manager{
+				client:      tt.fields.client,
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+				regions:     []string{"ap-northeast-1"},
+				noDataMode:  tt.fields.noDataMode,
+				sem:         semaphore.NewWeighted(int64(DefaultConcurrency)),
+			}
+			got, err := man.ListAnomalies(context.Background(), tt.args.lookback, tt.args.detector)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Manager.ListAnomalies() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Manager.ListAnomalies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -26,6 +26,7 @@ func BenchmarkList(b *testing.B) {
 	man := &Manager{
 		client: newMockClient(
 			&mockS3{
+				ListDirectoryBucketsFunc: listNoDirectoryBuckets,
 				ListBucketsFunc: func(_ context.Context, _ *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
 					for i := 0; i < n; i++ {
 						buckets[i] = s3types.Bucket{
@@ -57,7 +58,7 @@ func BenchmarkList(b *testing.B) {
 		regions:     []string{"us-east-1"},
 		metricName:  MetricNameBucketSizeBytes,
 		storageType: StorageTypeStandardStorage,
-		sem:         semaphore.NewWeighted(NumWorker),
+		sem:         semaphore.NewWeighted(int64(DefaultConcurrency)),
 	}
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -4,40 +4,105 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"strings"
 
+	"github.com/dustin/go-humanize"
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/components"
 	"github.com/go-echarts/go-echarts/v2/opts"
-	"github.com/pkg/browser"
 )
 
-func getPieItems(data *MetricData) (string, []opts.PieData) {
+// chartFilePrefix is the prefix of the chart files written to the current directory by default.
+const chartFilePrefix = "s3bytes"
+
+func getChartTitle(metricName MetricName) string {
+	switch metricName {
+	case MetricNameBucketSizeBytes:
+		return "Bucket Size Bytes"
+	case MetricNameNumberOfObjects:
+		return "Number Of Objects"
+	default:
+		if metricName.isRequest() {
+			return metricName.String()
+		}
+		return ""
+	}
+}
+
+// getChartLabel returns the label of the metric, which is the bucket name followed by the prefix if any
+// such as "bucket/logs/" and "bucket/" for the root,
+// or the group if the metric is aggregated by GroupMetrics.
+func getChartLabel(metric *Metric) string {
+	if metric.Group != "" {
+		return metric.Group
+	}
+	switch metric.Prefix {
+	case "":
+		return metric.BucketName
+	case rootPrefix:
+		return metric.BucketName + rootPrefix
+	default:
+		return metric.BucketName + prefixDelimiter + metric.Prefix
+	}
+}
+
+// formatValue formats the value in bytes with the SI unit for the metrics in bytes,
+// and with thousands separators otherwise, keeping 2 decimals for the latencies.
+func formatValue(data *MetricData, value float64) string {
+	if len(data.Metrics) > 0 && data.Metrics[0].MetricName.IsBytes() {
+		return humanize.Bytes(uint64(value))
+	}
+	if len(data.Metrics) > 0 && data.Metrics[0].MetricName.isLatency() {
+		return humanize.FormatFloat("#,###.##", value)
+	}
+	return humanize.Comma(int64(value))
+}
+
+// isTopLevel reports whether the metric is not nested in another prefix,
+// so that the values of nested prefixes are not counted twice.
+func isTopLevel(metric *Metric) bool {
+	return ParentPrefix(metric.Prefix) == ""
+}
+
+// getPieItems returns the largest buckets in the order of the data for the pie chart.
+// Buckets without values are excluded first, and if the rest exceed topN, the largest topN-1 are
+// named and the remainder is grouped into "others" so that the chart has at most topN slices.
+// Buckets whose share is below minPercent are also grouped into "others" if minPercent is positive.
+func getPieItems(data *MetricData, topN int, minPercent float64) (string, []opts.PieData) {
 	var (
-		othersTotal = 0.0
-		items       = make([]opts.PieData, 0, MaxChartItems)
-		title       = ""
+		title   = ""
+		metrics = make([]*Metric, 0, len(data.Metrics))
+		total   = 0.0
 	)
-	for i, metric := range data.Metrics {
-		if metric.Value == 0 {
+	for _, metric := range data.Metrics {
+		if metric.Value == 0 || !isTopLevel(metric) {
 			continue
 		}
 		if title == "" {
-			switch metric.MetricName {
-			case MetricNameBucketSizeBytes:
-				title = "Bucket Size Bytes"
-			case MetricNameNumberOfObjects:
-				title = "Number Of Objects"
-			}
+			title = getChartTitle(metric.MetricName)
 		}
-		if i < MaxChartItems-1 {
-			item := opts.PieData{
-				Name:  metric.BucketName,
-				Value: metric.Value,
-			}
-			items = append(items, item)
-		} else {
+		metrics = append(metrics, metric)
+		total += metric.Value
+	}
+	named := len(metrics)
+	if named > topN {
+		named = max(topN-1, 0)
+	}
+	var (
+		othersTotal = 0.0
+		items       = make([]opts.PieData, 0, named+1)
+	)
+	for i, metric := range metrics {
+		if i >= named || metric.Value/total*100 < minPercent {
 			othersTotal += metric.Value
+			continue
+		}
+		item := opts.PieData{
+			Name:  getChartLabel(metric),
+			Value: metric.Value,
 		}
+		items = append(items, item)
 	}
 	if othersTotal > 0 {
 		item := opts.PieData{
@@ -49,6 +114,19 @@ func getPieItems(data *MetricData) (string, []opts.PieData) {
 	return title, items
 }
 
+// withPieValues appends the humanized values to the names of the items,
+// so that the legend and the tooltip show the absolute values along with the shares.
+func withPieValues(data *MetricData, items []opts.PieData) []opts.PieData {
+	ret := make([]opts.PieData, len(items))
+	for i, item := range items {
+		ret[i] = item
+		if v, ok := item.Value.(float64); ok {
+			ret[i].Name = item.Name + " (" + formatValue(data, v) + ")"
+		}
+	}
+	return ret
+}
+
 func newPie(title string, items []opts.PieData) *charts.Pie {
 	if len(items) == 0 {
 		return nil
@@ -69,6 +147,11 @@ func newPie(title string, items []opts.PieData) *charts.Pie {
 			X:      "right",
 			Y:      "bottom",
 		}),
+		charts.WithTooltipOpts(opts.Tooltip{
+			Show:      opts.Bool(true),
+			Trigger:   "item",
+			Formatter: "{b}: {d}%",
+		}),
 	)
 	pie.AddSeries("", items)
 	pie.SetSeriesOptions(
@@ -81,33 +164,301 @@ func newPie(title string, items []opts.PieData) *charts.Pie {
 	return pie
 }
 
-func render(pie *charts.Pie) error {
-	if pie == nil {
+// getBarItems returns the largest buckets up to topN in ascending order of value,
+// since the horizontal bar chart draws the first category at the bottom.
+func getBarItems(data *MetricData, topN int) (string, []string, []opts.BarData) {
+	var (
+		title = ""
+		names = make([]string, 0, topN)
+		items = make([]opts.BarData, 0, topN)
+	)
+	for _, metric := range data.Metrics {
+		if metric.Value == 0 {
+			continue
+		}
+		if len(items) == topN {
+			break
+		}
+		if title == "" {
+			title = getChartTitle(metric.MetricName)
+		}
+		names = append(names, getChartLabel(metric))
+		items = append(items, opts.BarData{
+			Name:  getChartLabel(metric),
+			Value: metric.Value,
+		})
+	}
+	slices.Reverse(names)
+	slices.Reverse(items)
+	return title, names, items
+}
+
+func newBar(title string, names []string, items []opts.BarData) *charts.Bar {
+	if len(items) == 0 {
 		return nil
 	}
-	title := "s3bytes"
-	page := components.NewPage()
-	page.SetPageTitle(title)
-	page.AddCharts(pie)
-	fname := title + ".html"
-	i := 1
-	for {
-		if _, err := os.Stat(fname); err != nil {
-			if os.IsNotExist(err) {
+	bar := charts.NewBar()
+	bar.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{
+			Theme:  "light",
+			Width:  "1280px",
+			Height: "720px",
+		}),
+		charts.WithTitleOpts(opts.Title{
+			Title: title,
+			Left:  "center",
+		}),
+		charts.WithLegendOpts(opts.Legend{
+			Show: opts.Bool(false),
+		}),
+		charts.WithGridOpts(opts.Grid{
+			ContainLabel: opts.Bool(true),
+		}),
+	)
+	bar.SetXAxis(names).AddSeries(title, items)
+	bar.SetSeriesOptions(
+		charts.WithLabelOpts(opts.Label{
+			Show:     opts.Bool(true),
+			Position: "right",
+		}),
+	)
+	bar.XYReversal()
+	return bar
+}
+
+// getRegionTreeMapNodes builds the tree of regions and their buckets.
+func getRegionTreeMapNodes(data *MetricData) (string, []opts.TreeMapNode) {
+	var (
+		title   = ""
+		regions = make([]*treeMapNode, 0)
+		nodes   = make(map[string]*treeMapNode)
+	)
+	for _, metric := range data.Metrics {
+		if metric.Value == 0 || !isTopLevel(metric) {
+			continue
+		}
+		if title == "" {
+			title = getChartTitle(metric.MetricName)
+		}
+		region, ok := nodes[metric.Region]
+		if !ok {
+			region = &treeMapNode{name: metric.Region}
+			nodes[metric.Region] = region
+			regions = append(regions, region)
+		}
+		region.value += metric.Value
+		region.children = append(region.children, &treeMapNode{
+			name:  getChartLabel(metric),
+			value: metric.Value,
+		})
+	}
+	items := make([]opts.TreeMapNode, len(regions))
+	for i, region := range regions {
+		items[i] = region.toTreeMapNode()
+	}
+	return title, items
+}
+
+// treeMapNode is a mutable node used to build the tree of buckets and prefixes.
+type treeMapNode struct {
+	name     string
+	prefix   string
+	value    float64
+	children []*treeMapNode
+}
+
+func (n *treeMapNode) toTreeMapNode() opts.TreeMapNode {
+	node := opts.TreeMapNode{
+		Name:  n.name,
+		Value: int(n.value),
+	}
+	for _, child := range n.children {
+		node.Children = append(node.Children, child.toTreeMapNode())
+	}
+	return node
+}
+
+// getTreeMapNodes builds the tree of buckets and their prefixes.
+// Each prefix is placed under the nearest ancestor prefix present in the data,
+// and the value of each bucket is the sum of its top-level nodes.
+func getTreeMapNodes(data *MetricData) (string, []opts.TreeMapNode) {
+	var (
+		title   = ""
+		buckets = make([]*treeMapNode, 0)
+		nodes   = make(map[string]*treeMapNode)
+	)
+	for _, metric := range data.Metrics {
+		if metric.Value == 0 {
+			continue
+		}
+		if title == "" {
+			title = getChartTitle(metric.MetricName)
+		}
+		bucket, ok := nodes[metric.BucketName]
+		if !ok {
+			bucket = &treeMapNode{name: metric.BucketName}
+			nodes[metric.BucketName] = bucket
+			buckets = append(buckets, bucket)
+		}
+		if metric.Prefix == "" {
+			bucket.value += metric.Value
+			continue
+		}
+		parent := bucket
+		for p := ParentPrefix(metric.Prefix); p != ""; p = ParentPrefix(p) {
+			if node, ok := nodes[metric.BucketName+"/"+p]; ok {
+				parent = node
 				break
 			}
-			return err
 		}
-		fname = fmt.Sprintf("%s%d.html", title, i)
-		i++
+		node := &treeMapNode{
+			name:   strings.TrimPrefix(metric.Prefix, parent.prefix),
+			prefix: metric.Prefix,
+			value:  metric.Value,
+		}
+		nodes[metric.BucketName+"/"+metric.Prefix] = node
+		parent.children = append(parent.children, node)
+		if parent == bucket {
+			bucket.value += metric.Value
+		}
+	}
+	items := make([]opts.TreeMapNode, len(buckets))
+	for i, bucket := range buckets {
+		items[i] = bucket.toTreeMapNode()
+	}
+	return title, items
+}
+
+func newTreeMap(title string, items []opts.TreeMapNode) *charts.TreeMap {
+	if len(items) == 0 {
+		return nil
+	}
+	treemap := charts.NewTreeMap()
+	treemap.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{
+			Theme:  "light",
+			Width:  "1280px",
+			Height: "720px",
+		}),
+		charts.WithTitleOpts(opts.Title{
+			Title: title,
+			Left:  "center",
+		}),
+		charts.WithLegendOpts(opts.Legend{
+			Show: opts.Bool(false),
+		}),
+	)
+	treemap.AddSeries(title, items)
+	treemap.SetSeriesOptions(
+		charts.WithLabelOpts(opts.Label{
+			Show:     opts.Bool(true),
+			Position: "inside",
+		}),
+	)
+	return treemap
+}
+
+// sunburstAccountName is the name of the root node of the sunburst chart.
+const sunburstAccountName = "account"
+
+// getSunburstItems builds the hierarchy of the account, regions, storage types, and buckets.
+func getSunburstItems(data *MetricData) (string, []opts.SunBurstData) {
+	var (
+		title = ""
+		root  = &opts.SunBurstData{Name: sunburstAccountName}
+		nodes = make(map[string]*opts.SunBurstData)
+	)
+	child := func(parent *opts.SunBurstData, key, name string) *opts.SunBurstData {
+		if node, ok := nodes[key]; ok {
+			return node
+		}
+		node := &opts.SunBurstData{Name: name}
+		nodes[key] = node
+		parent.Children = append(parent.Children, node)
+		return node
 	}
-	f, err := os.Create(fname)
+	for _, metric := range data.Metrics {
+		if metric.Value == 0 || !isTopLevel(metric) {
+			continue
+		}
+		if title == "" {
+			title = getChartTitle(metric.MetricName)
+		}
+		region := child(root, metric.Region, metric.Region)
+		storageType := child(region, metric.Region+"/"+metric.StorageType.String(), metric.StorageType.String())
+		storageType.Children = append(storageType.Children, &opts.SunBurstData{
+			Name:  getChartLabel(metric),
+			Value: metric.Value,
+		})
+		region.Value += metric.Value
+		storageType.Value += metric.Value
+		root.Value += metric.Value
+	}
+	if len(root.Children) == 0 {
+		return title, nil
+	}
+	return title, []opts.SunBurstData{*root}
+}
+
+func newSunburst(title string, items []opts.SunBurstData) *charts.Sunburst {
+	if len(items) == 0 {
+		return nil
+	}
+	sunburst := charts.NewSunburst()
+	sunburst.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{
+			Theme:  "light",
+			Width:  "1280px",
+			Height: "720px",
+		}),
+		charts.WithTitleOpts(opts.Title{
+			Title: title,
+			Left:  "center",
+		}),
+	)
+	sunburst.AddSeries(title, items)
+	sunburst.SetSeriesOptions(
+		charts.WithLabelOpts(opts.Label{
+			Show: opts.Bool(true),
+		}),
+	)
+	return sunburst
+}
+
+// renderPage renders the chart as an HTML page to the writer.
+func renderPage(w io.Writer, chart components.Charter) error {
+	page := components.NewPage()
+	page.SetPageTitle(chartFilePrefix)
+	page.AddCharts(chart)
+	return page.Render(w)
+}
+
+// writeChart writes the chart as an HTML page to the file, overwriting it if it exists.
+func writeChart(path string, chart components.Charter) error {
+	f, err := os.Create(path)
 	if err != nil {
 		return err
 	}
-	if err := page.Render(io.MultiWriter(f)); err != nil {
+	if err := renderPage(f, chart); err != nil {
+		_ = f.Close()
 		return err
 	}
-	_ = browser.OpenFile(fname)
-	return nil
+	return f.Close()
+}
+
+// nextChartFile returns the first file name of s3bytes.html, s3bytes1.html, ...
+// that does not exist in the current directory.
+func nextChartFile() (string, error) {
+	fname := chartFilePrefix + ".html"
+	i := 1
+	for {
+		if _, err := os.Stat(fname); err != nil {
+			if os.IsNotExist(err) {
+				return fname, nil
+			}
+			return "", err
+		}
+		fname = fmt.Sprintf("%s%d.html", chartFilePrefix, i)
+		i++
+	}
 }
@@ -1,7 +1,10 @@
 package s3bytes
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/go-echarts/go-echarts/v2/charts"
@@ -10,7 +13,9 @@ import (
 
 func Test_getPieItems(t *testing.T) {
 	type args struct {
-		data *MetricData
+		data       *MetricData
+		topN       int
+		minPercent float64
 	}
 	type want struct {
 		title string
@@ -43,6 +48,7 @@ func Test_getPieItems(t *testing.T) {
 						},
 					},
 				},
+				topN: 3,
 			},
 			want: want{
 				title: "Bucket Size Bytes",
@@ -80,6 +86,7 @@ func Test_getPieItems(t *testing.T) {
 						},
 					},
 				},
+				topN: 3,
 			},
 			want: want{
 				title: "Number Of Objects",
@@ -117,6 +124,7 @@ func Test_getPieItems(t *testing.T) {
 						},
 					},
 				},
+				topN: 3,
 			},
 			want: want{
 				title: "Bucket Size Bytes",
@@ -164,6 +172,7 @@ func Test_getPieItems(t *testing.T) {
 						},
 					},
 				},
+				topN: 3,
 			},
 			want: want{
 				title: "Bucket Size Bytes",
@@ -183,10 +192,206 @@ func Test_getPieItems(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "zero before others",
+			args: args{
+				data: &MetricData{
+					Header: header,
+					Metrics: []*Metric{
+						{
+							BucketName:  "bucket0",
+							Region:      "ap-northeast-1",
+							MetricName:  MetricNameBucketSizeBytes,
+							StorageType: StorageTypeStandardStorage,
+							Value:       0,
+						},
+						{
+							BucketName:  "bucket1",
+							Region:      "ap-northeast-2",
+							MetricName:  MetricNameBucketSizeBytes,
+							StorageType: StorageTypeGlacierStorage,
+							Value:       4096,
+						},
+						{
+							BucketName:  "bucket2",
+							Region:      "us-east-1",
+							MetricName:  MetricNameBucketSizeBytes,
+							StorageType: StorageTypeGlacierStorage,
+							Value:       1024,
+						},
+						{
+							BucketName:  "bucket3",
+							Region:      "us-east-1",
+							MetricName:  MetricNameBucketSizeBytes,
+							StorageType: StorageTypeGlacierStorage,
+							Value:       512,
+						},
+						{
+							BucketName:  "bucket4",
+							Region:      "us-east-1",
+							MetricName:  MetricNameBucketSizeBytes,
+							StorageType: StorageTypeGlacierStorage,
+							Value:       256,
+						},
+					},
+				},
+				topN: 3,
+			},
+			want: want{
+				title: "Bucket Size Bytes",
+				items: []opts.PieData{
+					{
+						Name:  "bucket1",
+						Value: float64(4096),
+					},
+					{
+						Name:  "bucket2",
+						Value: float64(1024),
+					},
+					{
+						Name:  "others",
+						Value: float64(768),
+					},
+				},
+			},
+		},
+		{
+			name: "just top n",
+			args: args{
+				data: &MetricData{
+					Header: header,
+					Metrics: []*Metric{
+						{
+							BucketName:  "bucket0",
+							Region:      "ap-northeast-1",
+							MetricName:  MetricNameBucketSizeBytes,
+							StorageType: StorageTypeStandardStorage,
+							Value:       4096,
+						},
+						{
+							BucketName:  "bucket1",
+							Region:      "ap-northeast-2",
+							MetricName:  MetricNameBucketSizeBytes,
+							StorageType: StorageTypeGlacierStorage,
+							Value:       1024,
+						},
+						{
+							BucketName:  "bucket2",
+							Region:      "us-east-1",
+							MetricName:  MetricNameBucketSizeBytes,
+							StorageType: StorageTypeGlacierStorage,
+							Value:       512,
+						},
+					},
+				},
+				topN: 3,
+			},
+			want: want{
+				title: "Bucket Size Bytes",
+				items: []opts.PieData{
+					{
+						Name:  "bucket0",
+						Value: float64(4096),
+					},
+					{
+						Name:  "bucket1",
+						Value: float64(1024),
+					},
+					{
+						Name:  "bucket2",
+						Value: float64(512),
+					},
+				},
+			},
+		},
+		{
+			name: "min percent",
+			args: args{
+				data: &MetricData{
+					Header: header,
+					Metrics: []*Metric{
+						{
+							BucketName:  "bucket0",
+							Region:      "ap-northeast-1",
+							MetricName:  MetricNameBucketSizeBytes,
+							StorageType: StorageTypeStandardStorage,
+							Value:       900,
+						},
+						{
+							BucketName:  "bucket1",
+							Region:      "ap-northeast-2",
+							MetricName:  MetricNameBucketSizeBytes,
+							StorageType: StorageTypeGlacierStorage,
+							Value:       60,
+						},
+						{
+							BucketName:  "bucket2",
+							Region:      "us-east-1",
+							MetricName:  MetricNameBucketSizeBytes,
+							StorageType: StorageTypeGlacierStorage,
+							Value:       40,
+						},
+					},
+				},
+				topN:       10,
+				minPercent: 5,
+			},
+			want: want{
+				title: "Bucket Size Bytes",
+				items: []opts.PieData{
+					{
+						Name:  "bucket0",
+						Value: float64(900),
+					},
+					{
+						Name:  "bucket1",
+						Value: float64(60),
+					},
+					{
+						Name:  "others",
+						Value: float64(40),
+					},
+				},
+			},
+		},
+		{
+			name: "top 1",
+			args: args{
+				data: &MetricData{
+					Header: header,
+					Metrics: []*Metric{
+						{
+							BucketName:  "bucket0",
+							Region:      "ap-northeast-1",
+							MetricName:  MetricNameBucketSizeBytes,
+							StorageType: StorageTypeStandardStorage,
+							Value:       900,
+						},
+						{
+							BucketName:  "bucket1",
+							Region:      "ap-northeast-2",
+							MetricName:  MetricNameBucketSizeBytes,
+							StorageType: StorageTypeGlacierStorage,
+							Value:       100,
+						},
+					},
+				},
+				topN: 1,
+			},
+			want: want{
+				title: "Bucket Size Bytes",
+				items: []opts.PieData{
+					{
+						Name:  "others",
+						Value: float64(1000),
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			title, items := getPieItems(tt.args.data)
+			title, items := getPieItems(tt.args.data, tt.args.topN, tt.args.minPercent)
 			if title != tt.want.title {
 				t.Errorf("getPieItems() title = %v, want %v", title, tt.want)
 			}
@@ -197,9 +402,518 @@ func Test_getPieItems(t *testing.T) {
 	}
 }
 
-func Test_render(t *testing.T) {
+func Test_withPieValues(t *testing.T) {
 	type args struct {
-		pie *charts.Pie
+		data  *MetricData
+		items []opts.PieData
+	}
+	tests := []struct {
+		name string
+		args args
+		want []opts.PieData
+	}{
+		{
+			name: "size",
+			args: args{
+				data: testSizeMetricData,
+				items: []opts.PieData{
+					{
+						Name:  "bucket0",
+						Value: float64(1024),
+					},
+					{
+						Name:  "others",
+						Value: float64(1234567),
+					},
+				},
+			},
+			want: []opts.PieData{
+				{
+					Name:  "bucket0 (1.0 kB)",
+					Value: float64(1024),
+				},
+				{
+					Name:  "others (1.2 MB)",
+					Value: float64(1234567),
+				},
+			},
+		},
+		{
+			name: "objects",
+			args: args{
+				data: testObjectMetricData,
+				items: []opts.PieData{
+					{
+						Name:  "bucket0",
+						Value: float64(1234567),
+					},
+				},
+			},
+			want: []opts.PieData{
+				{
+					Name:  "bucket0 (1,234,567)",
+					Value: float64(1234567),
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withPieValues(tt.args.data, tt.args.items); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("withPieValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getBarItems(t *testing.T) {
+	type args struct {
+		data *MetricData
+		topN int
+	}
+	type want struct {
+		title string
+		names []string
+		items []opts.BarData
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "top",
+			args: args{
+				data: &MetricData{
+					Header: header,
+					Metrics: []*Metric{
+						{
+							BucketName:  "bucket0",
+							Region:      "ap-northeast-1",
+							MetricName:  MetricNameBucketSizeBytes,
+							StorageType: StorageTypeStandardStorage,
+							Value:       4096,
+						},
+						{
+							BucketName:  "bucket1",
+							Region:      "ap-northeast-1",
+							MetricName:  MetricNameBucketSizeBytes,
+							StorageType: StorageTypeStandardStorage,
+							Value:       0,
+						},
+						{
+							BucketName:  "bucket2",
+							Region:      "us-east-1",
+							MetricName:  MetricNameBucketSizeBytes,
+							StorageType: StorageTypeStandardStorage,
+							Value:       1024,
+						},
+						{
+							BucketName:  "bucket3",
+							Region:      "us-east-1",
+							MetricName:  MetricNameBucketSizeBytes,
+							StorageType: StorageTypeStandardStorage,
+							Value:       512,
+						},
+						{
+							BucketName:  "bucket4",
+							Region:      "us-east-1",
+							MetricName:  MetricNameBucketSizeBytes,
+							StorageType: StorageTypeStandardStorage,
+							Value:       256,
+						},
+					},
+				},
+				topN: 3,
+			},
+			want: want{
+				title: "Bucket Size Bytes",
+				names: []string{"bucket3", "bucket2", "bucket0"},
+				items: []opts.BarData{
+					{
+						Name:  "bucket3",
+						Value: float64(512),
+					},
+					{
+						Name:  "bucket2",
+						Value: float64(1024),
+					},
+					{
+						Name:  "bucket0",
+						Value: float64(4096),
+					},
+				},
+			},
+		},
+		{
+			name: "prefix",
+			args: args{
+				data: &MetricData{
+					Header: prefixHeader,
+					Metrics: []*Metric{
+						{
+							BucketName:  "bucket0",
+							Region:      "ap-northeast-1",
+							Prefix:      "logs/",
+							MetricName:  MetricNameBucketSizeBytes,
+							StorageType: StorageTypeStandardStorage,
+							Value:       1024,
+						},
+						{
+							BucketName:  "bucket0",
+							Region:      "ap-northeast-1",
+							Prefix:      "/",
+							MetricName:  MetricNameBucketSizeBytes,
+							StorageType: StorageTypeStandardStorage,
+							Value:       512,
+						},
+					},
+				},
+				topN: 3,
+			},
+			want: want{
+				title: "Bucket Size Bytes",
+				names: []string{"bucket0/", "bucket0/logs/"},
+				items: []opts.BarData{
+					{
+						Name:  "bucket0/",
+						Value: float64(512),
+					},
+					{
+						Name:  "bucket0/logs/",
+						Value: float64(1024),
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, names, items := getBarItems(tt.args.data, tt.args.topN)
+			if title != tt.want.title {
+				t.Errorf("getBarItems() title = %v, want %v", title, tt.want.title)
+			}
+			if !reflect.DeepEqual(names, tt.want.names) {
+				t.Errorf("getBarItems() names = %v, want %v", names, tt.want.names)
+			}
+			if !reflect.DeepEqual(items, tt.want.items) {
+				t.Errorf("getBarItems() items = %v, want %v", items, tt.want.items)
+			}
+		})
+	}
+}
+
+func Test_getRegionTreeMapNodes(t *testing.T) {
+	type args struct {
+		data *MetricData
+	}
+	type want struct {
+		title string
+		items []opts.TreeMapNode
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "regions",
+			args: args{
+				data: testSunburstMetricData,
+			},
+			want: want{
+				title: "Bucket Size Bytes",
+				items: []opts.TreeMapNode{
+					{
+						Name:  "ap-northeast-1",
+						Value: 3072,
+						Children: []opts.TreeMapNode{
+							{
+								Name:  "bucket0",
+								Value: 1024,
+							},
+							{
+								Name:  "bucket1",
+								Value: 2048,
+							},
+						},
+					},
+					{
+						Name:  "us-east-1",
+						Value: 4096,
+						Children: []opts.TreeMapNode{
+							{
+								Name:  "bucket2",
+								Value: 4096,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, items := getRegionTreeMapNodes(tt.args.data)
+			if title != tt.want.title {
+				t.Errorf("getRegionTreeMapNodes() title = %v, want %v", title, tt.want.title)
+			}
+			if !reflect.DeepEqual(items, tt.want.items) {
+				t.Errorf("getRegionTreeMapNodes() items = %v, want %v", items, tt.want.items)
+			}
+		})
+	}
+}
+
+var testSunburstMetricData = &MetricData{
+	Header: header,
+	Metrics: []*Metric{
+		{
+			BucketName:  "bucket0",
+			Region:      "ap-northeast-1",
+			MetricName:  MetricNameBucketSizeBytes,
+			StorageType: StorageTypeStandardStorage,
+			Value:       1024,
+		},
+		{
+			BucketName:  "bucket1",
+			Region:      "ap-northeast-1",
+			MetricName:  MetricNameBucketSizeBytes,
+			StorageType: StorageTypeStandardStorage,
+			Value:       2048,
+		},
+		{
+			BucketName:  "bucket2",
+			Region:      "us-east-1",
+			MetricName:  MetricNameBucketSizeBytes,
+			StorageType: StorageTypeStandardStorage,
+			Value:       4096,
+		},
+		{
+			BucketName:  "bucket3",
+			Region:      "us-east-1",
+			MetricName:  MetricNameBucketSizeBytes,
+			StorageType: StorageTypeStandardStorage,
+			Value:       0,
+		},
+	},
+}
+
+func Test_getSunburstItems(t *testing.T) {
+	type args struct {
+		data *MetricData
+	}
+	type want struct {
+		title string
+		items []opts.SunBurstData
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "hierarchy",
+			args: args{
+				data: testSunburstMetricData,
+			},
+			want: want{
+				title: "Bucket Size Bytes",
+				items: []opts.SunBurstData{
+					{
+						Name:  "account",
+						Value: 7168,
+						Children: []*opts.SunBurstData{
+							{
+								Name:  "ap-northeast-1",
+								Value: 3072,
+								Children: []*opts.SunBurstData{
+									{
+										Name:  "StandardStorage",
+										Value: 3072,
+										Children: []*opts.SunBurstData{
+											{
+												Name:  "bucket0",
+												Value: 1024,
+											},
+											{
+												Name:  "bucket1",
+												Value: 2048,
+											},
+										},
+									},
+								},
+							},
+							{
+								Name:  "us-east-1",
+								Value: 4096,
+								Children: []*opts.SunBurstData{
+									{
+										Name:  "StandardStorage",
+										Value: 4096,
+										Children: []*opts.SunBurstData{
+											{
+												Name:  "bucket2",
+												Value: 4096,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "empty",
+			args: args{
+				data: &MetricData{
+					Header: header,
+				},
+			},
+			want: want{
+				title: "",
+				items: nil,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, items := getSunburstItems(tt.args.data)
+			if title != tt.want.title {
+				t.Errorf("getSunburstItems() title = %v, want %v", title, tt.want.title)
+			}
+			if !reflect.DeepEqual(items, tt.want.items) {
+				t.Errorf("getSunburstItems() items = %v, want %v", items, tt.want.items)
+			}
+		})
+	}
+}
+
+func Test_getTreeMapNodes(t *testing.T) {
+	type args struct {
+		data *MetricData
+	}
+	type want struct {
+		title string
+		items []opts.TreeMapNode
+	}
+	newMetric := func(bucket, prefix string, value float64) *Metric {
+		return &Metric{
+			BucketName:  bucket,
+			Region:      "ap-northeast-1",
+			Prefix:      prefix,
+			MetricName:  MetricNameBucketSizeBytes,
+			StorageType: StorageTypeStandardStorage,
+			Value:       value,
+		}
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "nested",
+			args: args{
+				data: &MetricData{
+					Header: prefixHeader,
+					Metrics: []*Metric{
+						newMetric("bucket0", "logs/", 300),
+						newMetric("bucket0", "logs/2025/", 100),
+						newMetric("bucket0", "logs/2026/", 200),
+						newMetric("bucket0", "data/", 1024),
+						newMetric("bucket1", "tmp/", 0),
+						newMetric("bucket1", "assets/", 512),
+					},
+				},
+			},
+			want: want{
+				title: "Bucket Size Bytes",
+				items: []opts.TreeMapNode{
+					{
+						Name:  "bucket0",
+						Value: 1324,
+						Children: []opts.TreeMapNode{
+							{
+								Name:  "logs/",
+								Value: 300,
+								Children: []opts.TreeMapNode{
+									{
+										Name:  "2025/",
+										Value: 100,
+									},
+									{
+										Name:  "2026/",
+										Value: 200,
+									},
+								},
+							},
+							{
+								Name:  "data/",
+								Value: 1024,
+							},
+						},
+					},
+					{
+						Name:  "bucket1",
+						Value: 512,
+						Children: []opts.TreeMapNode{
+							{
+								Name:  "assets/",
+								Value: 512,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "missing ancestor",
+			args: args{
+				data: &MetricData{
+					Header: prefixHeader,
+					Metrics: []*Metric{
+						newMetric("bucket0", "logs/2026/01/", 100),
+					},
+				},
+			},
+			want: want{
+				title: "Bucket Size Bytes",
+				items: []opts.TreeMapNode{
+					{
+						Name:  "bucket0",
+						Value: 100,
+						Children: []opts.TreeMapNode{
+							{
+								Name:  "logs/2026/01/",
+								Value: 100,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, items := getTreeMapNodes(tt.args.data)
+			if title != tt.want.title {
+				t.Errorf("getTreeMapNodes() title = %v, want %v", title, tt.want.title)
+			}
+			if !reflect.DeepEqual(items, tt.want.items) {
+				t.Errorf("getTreeMapNodes() items = %v, want %v", items, tt.want.items)
+			}
+		})
+	}
+}
+
+func Test_writeChart(t *testing.T) {
+	dir := t.TempDir()
+	type args struct {
+		path  string
+		chart *charts.Pie
 	}
 	tests := []struct {
 		name    string
@@ -207,9 +921,10 @@ func Test_render(t *testing.T) {
 		wantErr bool
 	}{
 		{
-			name: "render",
+			name: "write",
 			args: args{
-				pie: newPie("Bucket Size Bytes", []opts.PieData{
+				path: filepath.Join(dir, "chart.html"),
+				chart: newPie("Bucket Size Bytes", []opts.PieData{
 					{
 						Name:  "bucket1",
 						Value: float64(4096),
@@ -218,20 +933,56 @@ func Test_render(t *testing.T) {
 						Name:  "bucket0",
 						Value: float64(1024),
 					},
+				}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "directory not found",
+			args: args{
+				path: filepath.Join(dir, "unknown", "chart.html"),
+				chart: newPie("Bucket Size Bytes", []opts.PieData{
 					{
-						Name:  "others",
-						Value: float64(768),
+						Name:  "bucket0",
+						Value: float64(1024),
 					},
 				}),
 			},
-			wantErr: false,
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := render(tt.args.pie); (err != nil) != tt.wantErr {
-				t.Errorf("render() error = %v, wantErr %v", err, tt.wantErr)
+			if err := writeChart(tt.args.path, tt.args.chart); (err != nil) != tt.wantErr {
+				t.Errorf("writeChart() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			b, err := os.ReadFile(tt.args.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(b), "Bucket Size Bytes") {
+				t.Errorf("writeChart() wrote %q, want the chart title", b)
 			}
 		})
 	}
 }
+
+func Test_nextChartFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for i, want := range []string{"s3bytes.html", "s3bytes1.html", "s3bytes2.html"} {
+		got, err := nextChartFile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("nextChartFile() #%d = %v, want %v", i, got, want)
+		}
+		if err := os.WriteFile(got, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
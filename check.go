@@ -0,0 +1,189 @@
+package s3bytes
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+// checkOperators is the operators of the check rules. Two-character operators come first
+// so that ">=" is not taken as ">".
+var checkOperators = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// CheckRule represents a condition such as "bytes > 5TiB", which a metric violates when it holds.
+// The field is one of "value", "bytes" and "objects", where "bytes" and "objects" are the value
+// restricted to BucketSizeBytes and NumberOfObjects respectively. The threshold accepts the units
+// of sizes such as "5TiB" and "500GB" as well as plain numbers.
+type CheckRule struct {
+	field     string
+	operator  string
+	threshold float64
+	raw       string
+}
+
+// ParseCheckRule parses the rule. A threshold alone such as "5TiB" means "value > 5TiB".
+func ParseCheckRule(s string) (*CheckRule, error) {
+	s = strings.TrimSpace(s)
+	rule := &CheckRule{
+		field:    "value",
+		operator: ">",
+		raw:      s,
+	}
+	for _, op := range checkOperators {
+		if i := strings.Index(s, op); i >= 0 {
+			rule.field = strings.ToLower(strings.TrimSpace(s[:i]))
+			rule.operator = op
+			rule.raw = strings.TrimSpace(s[i+len(op):])
+			break
+		}
+	}
+	switch rule.field {
+	case "value", "bytes", "objects":
+	default:
+		return nil, fmt.Errorf("unsupported check field: %q", rule.field)
+	}
+	threshold, err := humanize.ParseBytes(rule.raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid check threshold: %q", rule.raw)
+	}
+	rule.threshold = float64(threshold)
+	return rule, nil
+}
+
+// String returns the string representation of the rule.
+func (r *CheckRule) String() string {
+	return r.field + " " + r.operator + " " + r.raw
+}
+
+// validate checks that the field of the rule applies to the metric name.
+func (r *CheckRule) validate(metricName MetricName) error {
+	if r.field == "bytes" && metricName != MetricNameBucketSizeBytes ||
+		r.field == "objects" && metricName != MetricNameNumberOfObjects {
+		return fmt.Errorf("check rule %q does not apply to %s", r, metricName)
+	}
+	return nil
+}
+
+// match reports whether the value holds the condition of the rule.
+func (r *CheckRule) match(value float64) bool {
+	switch r.operator {
+	case ">=":
+		return value >= r.threshold
+	case "<=":
+		return value <= r.threshold
+	case "==":
+		return value == r.threshold
+	case "!=":
+		return value != r.threshold
+	case ">":
+		return value > r.threshold
+	case "<":
+		return value < r.threshold
+	default:
+		return false
+	}
+}
+
+// Violation represents a metric or the total that violates a rule, or a metric with an anomalous change.
+// Metric is nil if the total violates the rule, and Rule is nil if the metric has an anomalous change.
+type Violation struct {
+	Rule   *CheckRule
+	Metric *Metric
+	Value  float64
+	text   string
+}
+
+// String returns the description of the violation with the humanized value.
+func (v *Violation) String() string {
+	return v.text
+}
+
+// CheckResult represents the result of the check. Data holds the metrics violating any of the rules
+// in the order of the input, so that they can be rendered in the same way as the other commands.
+type CheckResult struct {
+	Data       *MetricData
+	Violations []*Violation
+}
+
+// OK reports whether no violations are found.
+func (r *CheckResult) OK() bool {
+	return len(r.Violations) == 0
+}
+
+// Check evaluates the rules against each metric and the total rule against the total of the data.
+// The anomalous changes found by ListAnomalies are regarded as violations as well.
+// Metrics without datapoints and the prefixes nested in another prefix are not evaluated.
+// totalRule may be nil.
+func Check(data *MetricData, rules []*CheckRule, totalRule *CheckRule) (*CheckResult, error) {
+	result := &CheckResult{
+		Data: &MetricData{
+			Header:  data.Header,
+			Metrics: make([]*Metric, 0),
+		},
+		Violations: make([]*Violation, 0),
+	}
+	for _, metric := range data.Metrics {
+		if metric.Status == DataStatusNoData || !isTopLevel(metric) {
+			continue
+		}
+		violated := metric.IsAnomalous()
+		if violated {
+			result.Violations = append(result.Violations, &Violation{
+				Metric: metric,
+				Value:  metric.Change,
+				text: fmt.Sprintf("%s: anomaly on %s: %s (%+.2f%%, z-score %+.2f)",
+					getChartLabel(metric), metric.AnomalyDate.UTC().Format(time.DateOnly),
+					formatChange(data, metric.Change), metric.ChangePercent, metric.ZScore),
+			})
+		}
+		for _, rule := range rules {
+			if err := rule.validate(metric.MetricName); err != nil {
+				return nil, err
+			}
+			if !rule.match(metric.Value) {
+				continue
+			}
+			violated = true
+			result.Violations = append(result.Violations, &Violation{
+				Rule:   rule,
+				Metric: metric,
+				Value:  metric.Value,
+				text:   fmt.Sprintf("%s: %s (%s)", getChartLabel(metric), rule, formatValue(data, metric.Value)),
+			})
+		}
+		if violated {
+			result.Data.Metrics = append(result.Data.Metrics, metric)
+			if !metric.MetricName.isLatency() {
+				result.Data.Total += int64(metric.Value)
+			}
+		}
+	}
+	if totalRule != nil {
+		if len(data.Metrics) > 0 {
+			if err := totalRule.validate(data.Metrics[0].MetricName); err != nil {
+				return nil, err
+			}
+			if data.Metrics[0].MetricName.isLatency() {
+				return nil, fmt.Errorf("check rule %q does not apply to the total of %s", totalRule, data.Metrics[0].MetricName)
+			}
+		}
+		if value := float64(data.Total); totalRule.match(value) {
+			result.Violations = append(result.Violations, &Violation{
+				Rule:  totalRule,
+				Value: value,
+				text:  fmt.Sprintf("total: %s (%s)", totalRule, formatValue(data, value)),
+			})
+		}
+	}
+	return result, nil
+}
+
+// formatChange formats the signed change of a value in the same way as the value.
+func formatChange(data *MetricData, change float64) string {
+	if change < 0 {
+		return "-" + formatValue(data, -change)
+	}
+	return "+" + formatValue(data, change)
+}
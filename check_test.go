@@ -0,0 +1,380 @@
+package s3bytes
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseCheckRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    *CheckRule
+		wantErr bool
+	}{
+		{
+			name: "bytes",
+			s:    "bytes > 5TiB",
+			want: &CheckRule{
+				field:     "bytes",
+				operator:  ">",
+				threshold: 5 << 40,
+				raw:       "5TiB",
+			},
+			wantErr: false,
+		},
+		{
+			name: "objects with two-character operator",
+			s:    "Objects>=1000000",
+			want: &CheckRule{
+				field:     "objects",
+				operator:  ">=",
+				threshold: 1000000,
+				raw:       "1000000",
+			},
+			wantErr: false,
+		},
+		{
+			name: "threshold only",
+			s:    " 50 TiB ",
+			want: &CheckRule{
+				field:     "value",
+				operator:  ">",
+				threshold: 50 << 40,
+				raw:       "50 TiB",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "unknown field",
+			s:       "size > 5TiB",
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "invalid threshold",
+			s:       "bytes > unknown",
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			s:       "",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCheckRule(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseCheckRule() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCheckRule() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckRule_String(t *testing.T) {
+	rule, err := ParseCheckRule("5TiB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rule.String(), "value > 5TiB"; got != want {
+		t.Errorf("CheckRule.String() = %v, want %v", got, want)
+	}
+}
+
+func TestCheckRule_match(t *testing.T) {
+	tests := []struct {
+		operator string
+		value    float64
+		want     bool
+	}{
+		{">", 11, true},
+		{">", 10, false},
+		{">=", 10, true},
+		{"<", 9, true},
+		{"<=", 11, false},
+		{"==", 10, true},
+		{"!=", 10, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.operator, func(t *testing.T) {
+			rule := &CheckRule{operator: tt.operator, threshold: 10}
+			if got := rule.match(tt.value); got != tt.want {
+				t.Errorf("CheckRule.match(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	mustRule := func(s string) *CheckRule {
+		rule, err := ParseCheckRule(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rule
+	}
+	data := &MetricData{
+		Header: header,
+		Metrics: []*Metric{
+			{
+				BucketName: "bucket0",
+				MetricName: MetricNameBucketSizeBytes,
+				Value:      4096,
+				Status:     DataStatusOK,
+			},
+			{
+				BucketName: "bucket1",
+				MetricName: MetricNameBucketSizeBytes,
+				Value:      1024,
+				Status:     DataStatusOK,
+			},
+			{
+				BucketName: "bucket2",
+				MetricName: MetricNameBucketSizeBytes,
+				Value:      0,
+				Status:     DataStatusNoData,
+			},
+		},
+		Total: 5120,
+	}
+	latency := &MetricData{
+		Header: header,
+		Metrics: []*Metric{
+			{
+				BucketName: "bucket0",
+				MetricName: MetricNameFirstByteLatency,
+				Value:      120.5,
+				Status:     DataStatusOK,
+			},
+		},
+	}
+	type args struct {
+		data      *MetricData
+		rules     []*CheckRule
+		totalRule *CheckRule
+	}
+	tests := []struct {
+		name           string
+		args           args
+		wantData       []string
+		wantViolations []string
+		wantErr        bool
+	}{
+		{
+			name: "bucket violation",
+			args: args{
+				rules: []*CheckRule{mustRule("bytes > 2KiB")},
+			},
+			wantData:       []string{"bucket0"},
+			wantViolations: []string{"bucket0: bytes > 2KiB (4.1 kB)"},
+			wantErr:        false,
+		},
+		{
+			name: "multiple rules and total",
+			args: args{
+				rules:     []*CheckRule{mustRule("bytes > 2KiB"), mustRule("value >= 1KiB")},
+				totalRule: mustRule("5000"),
+			},
+			wantData: []string{"bucket0", "bucket1"},
+			wantViolations: []string{
+				"bucket0: bytes > 2KiB (4.1 kB)",
+				"bucket0: value >= 1KiB (4.1 kB)",
+				"bucket1: value >= 1KiB (1.0 kB)",
+				"total: value > 5000 (5.1 kB)",
+			},
+			wantErr: false,
+		},
+		{
+			name: "no-data bucket is not evaluated",
+			args: args{
+				rules: []*CheckRule{mustRule("bytes < 1")},
+			},
+			wantData:       []string{},
+			wantViolations: []string{},
+			wantErr:        false,
+		},
+		{
+			name: "ok",
+			args: args{
+				rules:     []*CheckRule{mustRule("5TiB")},
+				totalRule: mustRule("50TiB"),
+			},
+			wantData:       []string{},
+			wantViolations: []string{},
+			wantErr:        false,
+		},
+		{
+			name: "field does not apply",
+			args: args{
+				rules: []*CheckRule{mustRule("objects > 1000")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "total field does not apply",
+			args: args{
+				totalRule: mustRule("objects > 1000"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "latency",
+			args: args{
+				data:  latency,
+				rules: []*CheckRule{mustRule("100")},
+			},
+			wantData:       []string{"bucket0"},
+			wantViolations: []string{"bucket0: value > 100 (120.50)"},
+			wantErr:        false,
+		},
+		{
+			name: "total of latency",
+			args: args{
+				data:      latency,
+				totalRule: mustRule("100"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := data
+			if tt.args.data != nil {
+				d = tt.args.data
+			}
+			got, err := Check(d, tt.args.rules, tt.args.totalRule)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			names := make([]string, 0)
+			for _, metric := range got.Data.Metrics {
+				names = append(names, metric.BucketName)
+			}
+			if !reflect.DeepEqual(names, tt.wantData) {
+				t.Errorf("Check() data = %v, want %v", names, tt.wantData)
+			}
+			violations := make([]string, 0)
+			for _, violation := range got.Violations {
+				violations = append(violations, violation.String())
+			}
+			if !reflect.DeepEqual(violations, tt.wantViolations) {
+				t.Errorf("Check() violations = %v, want %v", violations, tt.wantViolations)
+			}
+			if got.OK() != (len(tt.wantViolations) == 0) {
+				t.Errorf("CheckResult.OK() = %v, want %v", got.OK(), len(tt.wantViolations) == 0)
+			}
+		})
+	}
+}
+
+func TestCheck_anomaly(t *testing.T) {
+	mustRule := func(s string) *CheckRule {
+		rule, err := ParseCheckRule(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rule
+	}
+	data := &MetricData{
+		Header: append(slices.Clone(header), anomalyHeader...),
+		Metrics: []*Metric{
+			{
+				BucketName: "bucket0",
+				MetricName: MetricNameBucketSizeBytes,
+				Value:      1024,
+				Status:     DataStatusOK,
+				Anomaly: Anomaly{
+					AnomalyDate:   time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC),
+					Change:        -3072,
+					ChangePercent: -75,
+					ZScore:        -3100,
+				},
+			},
+			{
+				BucketName: "bucket1",
+				MetricName: MetricNameBucketSizeBytes,
+				Value:      4096,
+				Status:     DataStatusOK,
+			},
+			{
+				BucketName: "bucket2",
+				MetricName: MetricNameBucketSizeBytes,
+				Value:      2048,
+				Status:     DataStatusOK,
+				Anomaly: Anomaly{
+					AnomalyDate:   time.Date(2026, 1, 21, 0, 0, 0, 0, time.UTC),
+					Change:        1024,
+					ChangePercent: 100,
+					ZScore:        12.5,
+				},
+			},
+		},
+		Total: 7168,
+	}
+	type args struct {
+		rules []*CheckRule
+	}
+	tests := []struct {
+		name           string
+		args           args
+		wantData       []string
+		wantViolations []string
+	}{
+		{
+			name:     "anomalies",
+			args:     args{},
+			wantData: []string{"bucket0", "bucket2"},
+			wantViolations: []string{
+				"bucket0: anomaly on 2026-01-20: -3.1 kB (-75.00%, z-score -3100.00)",
+				"bucket2: anomaly on 2026-01-21: +1.0 kB (+100.00%, z-score +12.50)",
+			},
+		},
+		{
+			name: "anomalies and rules",
+			args: args{
+				rules: []*CheckRule{mustRule("bytes > 3KiB")},
+			},
+			wantData: []string{"bucket0", "bucket1", "bucket2"},
+			wantViolations: []string{
+				"bucket0: anomaly on 2026-01-20: -3.1 kB (-75.00%, z-score -3100.00)",
+				"bucket1: bytes > 3KiB (4.1 kB)",
+				"bucket2: anomaly on 2026-01-21: +1.0 kB (+100.00%, z-score +12.50)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Check(data, tt.args.rules, nil)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			names := make([]string, 0)
+			for _, metric := range got.Data.Metrics {
+				names = append(names, metric.BucketName)
+			}
+			if !reflect.DeepEqual(names, tt.wantData) {
+				t.Errorf("Check() data = %v, want %v", names, tt.wantData)
+			}
+			violations := make([]string, 0)
+			for _, violation := range got.Violations {
+				violations = append(violations, violation.String())
+			}
+			if !reflect.DeepEqual(violations, tt.wantViolations) {
+				t.Errorf("Check() violations = %v, want %v", violations, tt.wantViolations)
+			}
+		})
+	}
+}
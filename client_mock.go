@@ -14,7 +14,15 @@ var (
 
 // mockS3 is a mock for the s3 client.
 type mockS3 struct {
-	ListBucketsFunc func(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error)
+	ListBucketsFunc                     func(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error)
+	ListDirectoryBucketsFunc            func(ctx context.Context, params *s3.ListDirectoryBucketsInput, optFns ...func(*s3.Options)) (*s3.ListDirectoryBucketsOutput, error)
+	ListObjectsV2Func                   func(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	GetBucketLocationFunc               func(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error)
+	GetBucketTaggingFunc                func(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error)
+	GetBucketVersioningFunc             func(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error)
+	GetBucketLifecycleConfigurationFunc func(ctx context.Context, params *s3.GetBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLifecycleConfigurationOutput, error)
+	GetBucketEncryptionFunc             func(ctx context.Context, params *s3.GetBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error)
+	GetObjectLockConfigurationFunc      func(ctx context.Context, params *s3.GetObjectLockConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetObjectLockConfigurationOutput, error)
 }
 
 // mockCloudWatch is a mock for the cloudwatch client.
@@ -27,6 +35,46 @@ func (m *mockS3) ListBuckets(ctx context.Context, params *s3.ListBucketsInput, o
 	return m.ListBucketsFunc(ctx, params, optFns...)
 }
 
+// ListDirectoryBuckets is a wrapper for the ListDirectoryBuckets method.
+func (m *mockS3) ListDirectoryBuckets(ctx context.Context, params *s3.ListDirectoryBucketsInput, optFns ...func(*s3.Options)) (*s3.ListDirectoryBucketsOutput, error) {
+	return m.ListDirectoryBucketsFunc(ctx, params, optFns...)
+}
+
+// ListObjectsV2 is a wrapper for the ListObjectsV2 method.
+func (m *mockS3) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	return m.ListObjectsV2Func(ctx, params, optFns...)
+}
+
+// GetBucketLocation is a wrapper for the GetBucketLocation method.
+func (m *mockS3) GetBucketLocation(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error) {
+	return m.GetBucketLocationFunc(ctx, params, optFns...)
+}
+
+// GetBucketTagging is a wrapper for the GetBucketTagging method.
+func (m *mockS3) GetBucketTagging(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+	return m.GetBucketTaggingFunc(ctx, params, optFns...)
+}
+
+// GetBucketVersioning is a wrapper for the GetBucketVersioning method.
+func (m *mockS3) GetBucketVersioning(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
+	return m.GetBucketVersioningFunc(ctx, params, optFns...)
+}
+
+// GetBucketLifecycleConfiguration is a wrapper for the GetBucketLifecycleConfiguration method.
+func (m *mockS3) GetBucketLifecycleConfiguration(ctx context.Context, params *s3.GetBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLifecycleConfigurationOutput, error) {
+	return m.GetBucketLifecycleConfigurationFunc(ctx, params, optFns...)
+}
+
+// GetBucketEncryption is a wrapper for the GetBucketEncryption method.
+func (m *mockS3) GetBucketEncryption(ctx context.Context, params *s3.GetBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error) {
+	return m.GetBucketEncryptionFunc(ctx, params, optFns...)
+}
+
+// GetObjectLockConfiguration is a wrapper for the GetObjectLockConfiguration method.
+func (m *mockS3) GetObjectLockConfiguration(ctx context.Context, params *s3.GetObjectLockConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetObjectLockConfigurationOutput, error) {
+	return m.GetObjectLockConfigurationFunc(ctx, params, optFns...)
+}
+
 // GetMetricData is a wrapper for the GetMetricData method.
 func (m *mockCloudWatch) GetMetricData(ctx context.Context, params *cloudwatch.GetMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
 	return m.GetMetricDataFunc(ctx, params, optFns...)
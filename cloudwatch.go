@@ -3,8 +3,8 @@ package s3bytes
 import (
 	"context"
 	"fmt"
+	"iter"
 	"slices"
-	"sync/atomic"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -17,24 +17,75 @@ var (
 	namespace      = aws.String("AWS/S3")
 	bucketNameKey  = aws.String("BucketName")
 	storageTypeKey = aws.String("StorageType")
+	filterIDKey    = aws.String("FilterId")
 	period         = aws.Int32(86400)
-	stat           = aws.String("Average")
-	startTime      = aws.Time(time.Now().Add(-48 * time.Hour))
-	endTime        = aws.Time(time.Now())
+	averageStat    = aws.String("Average")
+	sumStat        = aws.String("Sum")
 )
 
-func (man *Manager) getMetrics(ctx context.Context, buckets []s3types.Bucket, region string) ([]*Metric, int64, error) {
+// storageLookback is the time window of the daily storage metrics, which covers the latest datapoint.
+const storageLookback = 48 * time.Hour
+
+// now returns the current time, from which the time window of each run is computed.
+// It is a variable so that tests can fix the time.
+var now = time.Now
+
+// CloudWatchSource is the source that fetches the daily storage metrics or the request metrics from CloudWatch.
+// It is the default source of the manager.
+type CloudWatchSource struct {
+	client *Client
+}
+
+// NewCloudWatchSource creates a new source that queries CloudWatch with the client.
+func NewCloudWatchSource(client *Client) *CloudWatchSource {
+	return &CloudWatchSource{
+		client: client,
+	}
+}
+
+// Buckets returns the names of the buckets in the region.
+func (s *CloudWatchSource) Buckets(ctx context.Context, region, prefix string) ([]string, error) {
+	return getBuckets(ctx, s.client, region, prefix)
+}
+
+// listBuckets returns the buckets in the region along with their creation dates.
+func (s *CloudWatchSource) listBuckets(ctx context.Context, region, prefix string) ([]s3types.Bucket, error) {
+	return listBuckets(ctx, s.client, region, prefix)
+}
+
+// Metrics fetches the metrics of the buckets in the query with GetMetricData.
+func (s *CloudWatchSource) Metrics(ctx context.Context, query *SourceQuery) ([]*Metric, error) {
+	return s.getMetricsFromQueries(ctx, newMetricDataQueries(query), query)
+}
+
+// MetricPages fetches the metrics of the buckets in the query with GetMetricData,
+// and yields the metrics of each page as it returns.
+func (s *CloudWatchSource) MetricPages(ctx context.Context, query *SourceQuery) iter.Seq2[[]*Metric, error] {
+	return s.getMetricPages(ctx, newMetricDataQueries(query), query)
+}
+
+// newMetricDataQueries builds a query of GetMetricData for each bucket in the query, labeled with the bucket name.
+// The storage metrics are dimensioned by the storage type, and the request metrics by the filter ID.
+// The storage type of the directory buckets is resolved per bucket, see StorageType.forBucket.
+func newMetricDataQueries(query *SourceQuery) []cwtypes.MetricDataQuery {
 	var (
-		total       int64
-		metricName  = aws.String(man.metricName.String())
-		storageType = aws.String(man.storageType.String())
-		queries     = make([]cwtypes.MetricDataQuery, 0, MaxQueries)
-		metrics     = make([]*Metric, 0, MaxQueries*2)
+		metricName = aws.String(query.MetricName.String())
+		queries    = make([]cwtypes.MetricDataQuery, 0, len(query.Buckets))
 	)
-	for i, bucket := range buckets {
-		query := cwtypes.MetricDataQuery{
+	for i, bucket := range query.Buckets {
+		dimension := cwtypes.Dimension{
+			Name:  storageTypeKey,
+			Value: aws.String(query.StorageType.forBucket(bucket).String()),
+		}
+		if query.MetricName.isRequest() {
+			dimension = cwtypes.Dimension{
+				Name:  filterIDKey,
+				Value: aws.String(query.FilterID),
+			}
+		}
+		q := cwtypes.MetricDataQuery{
 			Id:    aws.String(fmt.Sprintf("m%d", i)),
-			Label: bucket.Name,
+			Label: aws.String(bucket),
 			MetricStat: &cwtypes.MetricStat{
 				Metric: &cwtypes.Metric{
 					Namespace:  namespace,
@@ -42,89 +93,206 @@ func (man *Manager) getMetrics(ctx context.Context, buckets []s3types.Bucket, re
 					Dimensions: []cwtypes.Dimension{
 						{
 							Name:  bucketNameKey,
-							Value: bucket.Name,
-						},
-						{
-							Name:  storageTypeKey,
-							Value: storageType,
+							Value: aws.String(bucket),
 						},
+						dimension,
 					},
 				},
 				Period: period,
-				Stat:   stat,
+				Stat:   query.MetricName.stat(),
 			},
 		}
-		queries = append(queries, query)
-		if len(queries) < MaxQueries {
-			continue
-		}
-		m, n, err := man.getMetricsFromQueries(ctx, queries, region)
+		queries = append(queries, q)
+	}
+	return queries
+}
+
+// getMetricsFromQueries collects the metrics of all pages of GetMetricData for the queries.
+func (s *CloudWatchSource) getMetricsFromQueries(ctx context.Context, queries []cwtypes.MetricDataQuery, query *SourceQuery) ([]*Metric, error) {
+	metrics := make([]*Metric, 0, len(queries))
+	for page, err := range s.getMetricPages(ctx, queries, query) {
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
-		metrics = append(metrics, m...)
-		total += n
-		queries = make([]cwtypes.MetricDataQuery, 0, MaxQueries)
+		metrics = append(metrics, page...)
 	}
-	if len(queries) > 0 {
-		m, n, err := man.getMetricsFromQueries(ctx, queries, region)
-		if err != nil {
-			return nil, 0, err
+	return metrics, nil
+}
+
+// getMetricPages yields the metrics of each page of GetMetricData for the queries.
+// The next page is requested only after the previous page is consumed.
+func (s *CloudWatchSource) getMetricPages(ctx context.Context, queries []cwtypes.MetricDataQuery, query *SourceQuery) iter.Seq2[[]*Metric, error] {
+	return func(yield func([]*Metric, error) bool) {
+		var (
+			token *string
+			end   = query.end()
+			start = end.Add(-storageLookback)
+			opt   = func(o *cloudwatch.Options) { o.Region = query.Region }
+		)
+		if query.MetricName.isRequest() {
+			start = end.Add(-time.Duration(aws.ToInt32(period)) * time.Second)
+		}
+		for {
+			in := &cloudwatch.GetMetricDataInput{
+				StartTime:         aws.Time(start),
+				EndTime:           aws.Time(end),
+				MetricDataQueries: queries,
+				NextToken:         token,
+			}
+			out, err := s.client.GetMetricData(ctx, in, opt)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			metrics := make([]*Metric, 0, len(out.MetricDataResults))
+			for _, result := range out.MetricDataResults {
+				var (
+					bucket                   = aws.ToString(result.Label)
+					value, timestamp, status = getDatapoint(result)
+				)
+				metric := &Metric{
+					BucketName:  bucket,
+					Region:      query.Region,
+					MetricName:  query.MetricName,
+					StorageType: query.StorageType.forBucket(bucket),
+					Value:       value,
+					Status:      status,
+					Timestamp:   timestamp,
+					Source:      SourceTypeCloudWatch,
+					BucketType:  getBucketType(bucket),
+				}
+				metrics = append(metrics, metric)
+			}
+			if !yield(metrics, nil) {
+				return
+			}
+			token = out.NextToken
+			if token == nil {
+				return
+			}
 		}
-		metrics = append(metrics, m...)
-		total += n
 	}
-	return metrics, total, nil
 }
 
-func (man *Manager) getMetricsFromQueries(ctx context.Context, queries []cwtypes.MetricDataQuery, region string) ([]*Metric, int64, error) {
+// getSeries fetches all the daily datapoints of the buckets in the query from start to the end time.
+// It returns a series for each bucket in the order of the query, with the datapoints sorted oldest first.
+// Since the values of a query may span multiple pages, the datapoints are accumulated by the label.
+func (s *CloudWatchSource) getSeries(ctx context.Context, query *SourceQuery, start time.Time) ([]*series, error) {
 	var (
-		total   int64
 		token   *string
-		metrics = make([]*Metric, 0, MaxQueries)
-		opt     = func(o *cloudwatch.Options) { o.Region = region }
+		queries = newMetricDataQueries(query)
+		ret     = make([]*series, len(query.Buckets))
+		index   = make(map[string]*series, len(query.Buckets))
+		opt     = func(o *cloudwatch.Options) { o.Region = query.Region }
 	)
+	for i, bucket := range query.Buckets {
+		ret[i] = &series{
+			bucket: bucket,
+			points: make([]point, 0),
+		}
+		index[bucket] = ret[i]
+	}
 	for {
 		in := &cloudwatch.GetMetricDataInput{
-			StartTime:         startTime,
-			EndTime:           endTime,
+			StartTime:         aws.Time(start),
+			EndTime:           aws.Time(query.end()),
 			MetricDataQueries: queries,
 			NextToken:         token,
 		}
-		out, err := man.client.GetMetricData(ctx, in, opt)
+		out, err := s.client.GetMetricData(ctx, in, opt)
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
 		for _, result := range out.MetricDataResults {
-			var value float64
-			if len(result.Values) == 0 {
-				value = 0
-			} else {
-				value = slices.Max(result.Values)
+			target, ok := index[aws.ToString(result.Label)]
+			if !ok {
+				continue
 			}
-			metric := &Metric{
-				BucketName:  aws.ToString(result.Label),
-				Region:      region,
-				MetricName:  man.metricName,
-				StorageType: man.storageType,
-				Value:       value,
-			}
-			if man.filterExpr != nil {
-				ok, err := man.filterExpr.Eval(metric)
-				if err != nil {
-					return nil, 0, err
-				}
-				if !ok {
-					continue
+			for i, value := range result.Values {
+				if i < len(result.Timestamps) {
+					target.points = append(target.points, point{
+						timestamp: result.Timestamps[i],
+						value:     value,
+					})
 				}
 			}
-			metrics = append(metrics, metric)
-			atomic.AddInt64(&total, int64(metric.Value))
 		}
 		token = out.NextToken
 		if token == nil {
 			break
 		}
 	}
-	return metrics, total, nil
+	for _, target := range ret {
+		slices.SortFunc(target.points, func(a, b point) int {
+			return a.timestamp.Compare(b.timestamp)
+		})
+	}
+	return ret, nil
+}
+
+// isRequest reports whether the metric is one of the request metrics, which are published
+// per filter of the request metrics configuration of the bucket instead of per storage type.
+func (t MetricName) isRequest() bool {
+	return t >= MetricNameAllRequests && t <= MetricNameTotalRequestLatency
+}
+
+// IsBytes reports whether the value of the metric is in bytes.
+func (t MetricName) IsBytes() bool {
+	switch t {
+	case MetricNameBucketSizeBytes, MetricNameBytesDownloaded, MetricNameBytesUploaded,
+		MetricNameSelectBytesScanned, MetricNameSelectBytesReturned:
+		return true
+	default:
+		return false
+	}
+}
+
+// isLatency reports whether the metric is one of the latencies, which are averaged per request in milliseconds,
+// so that their values have fractions and cannot be summed up into the total.
+func (t MetricName) isLatency() bool {
+	return t == MetricNameFirstByteLatency || t == MetricNameTotalRequestLatency
+}
+
+// stat returns the statistic of the metric. The daily storage metrics and the latencies are averaged,
+// and the other request metrics are summed up over the period.
+func (t MetricName) stat() *string {
+	switch {
+	case t.isLatency():
+		return averageStat
+	case t.isRequest():
+		return sumStat
+	default:
+		return averageStat
+	}
+}
+
+// forBucket returns the storage type to query for the bucket. The objects in the directory buckets
+// are all stored in S3 Express One Zone, so the standard storage is read as ExpressOneZoneStorage for them.
+func (t StorageType) forBucket(bucket string) StorageType {
+	if t == StorageTypeStandardStorage && isDirectoryBucket(bucket) {
+		return StorageTypeExpressOneZoneStorage
+	}
+	return t
+}
+
+// getDatapoint returns the largest value in the result with its timestamp and the data status.
+func getDatapoint(result cwtypes.MetricDataResult) (float64, time.Time, DataStatus) {
+	if len(result.Values) == 0 {
+		return 0, time.Time{}, DataStatusNoData
+	}
+	i := 0
+	for j, v := range result.Values {
+		if v > result.Values[i] {
+			i = j
+		}
+	}
+	var timestamp time.Time
+	if i < len(result.Timestamps) {
+		timestamp = result.Timestamps[i]
+	}
+	status := DataStatusOK
+	if result.StatusCode == cwtypes.StatusCodePartialData {
+		status = DataStatusPartial
+	}
+	return result.Values[i], timestamp, status
 }
@@ -5,202 +5,27 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
-	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
-	"github.com/nekrassov01/filter"
-	"golang.org/x/sync/semaphore"
 )
 
-func TestManager_getMetrics(t *testing.T) {
+func TestCloudWatchSource_getMetricsFromQueries(t *testing.T) {
 	type fields struct {
-		client      *Client
-		metricName  MetricName
-		storageType StorageType
-		prefix      *string
-		regions     []string
-		filterExpr  filterExpr
-		filterRaw   string
-		sem         *semaphore.Weighted
-	}
-	type args struct {
-		ctx     context.Context
-		buckets []s3types.Bucket
-		region  string
-	}
-	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    []*Metric
-		want1   int64
-		wantErr bool
-	}{
-		{
-			name: "success",
-			fields: fields{
-				client: newMockClient(
-					nil,
-					&mockCloudWatch{
-						GetMetricDataFunc: func(_ context.Context, _ *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
-							return &cloudwatch.GetMetricDataOutput{
-								MetricDataResults: []cwtypes.MetricDataResult{
-									{
-										Label:  aws.String("bucket0"),
-										Values: []float64{1024, 2048},
-									},
-									{
-										Label:  aws.String("bucket1"),
-										Values: []float64{0},
-									},
-								},
-								NextToken: nil,
-							}, nil
-						},
-					},
-				),
-				metricName:  MetricNameBucketSizeBytes,
-				storageType: StorageTypeStandardStorage,
-			},
-			args: args{
-				ctx: context.Background(),
-				buckets: []s3types.Bucket{
-					{Name: aws.String("bucket0")},
-					{Name: aws.String("bucket1")},
-				},
-				region: "ap-northeast-1",
-			},
-			want: []*Metric{
-				{
-					BucketName:  "bucket0",
-					Region:      "ap-northeast-1",
-					MetricName:  MetricNameBucketSizeBytes,
-					StorageType: StorageTypeStandardStorage,
-					Value:       2048,
-				},
-				{
-					BucketName:  "bucket1",
-					Region:      "ap-northeast-1",
-					MetricName:  MetricNameBucketSizeBytes,
-					StorageType: StorageTypeStandardStorage,
-					Value:       0,
-				},
-			},
-			want1:   2048,
-			wantErr: false,
-		},
-		{
-			name: "error",
-			fields: fields{
-				client: newMockClient(
-					nil,
-					&mockCloudWatch{
-						GetMetricDataFunc: func(_ context.Context, _ *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
-							return nil, errors.New("error")
-						},
-					},
-				),
-				metricName:  MetricNameBucketSizeBytes,
-				storageType: StorageTypeStandardStorage,
-			},
-			args: args{
-				ctx: context.Background(),
-				buckets: []s3types.Bucket{
-					{Name: aws.String("bucket0")},
-				},
-				region: "ap-northeast-1",
-			},
-			want:    nil,
-			want1:   0,
-			wantErr: true,
-		},
-		{
-			name: "filter returns false",
-			fields: fields{
-				client: newMockClient(
-					nil,
-					&mockCloudWatch{
-						GetMetricDataFunc: func(_ context.Context, _ *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
-							return &cloudwatch.GetMetricDataOutput{
-								MetricDataResults: []cwtypes.MetricDataResult{
-									{
-										Label:  aws.String("bucket0"),
-										Values: []float64{1024, 2048},
-									},
-								},
-								NextToken: nil,
-							}, nil
-						},
-					},
-				),
-				metricName:  MetricNameBucketSizeBytes,
-				storageType: StorageTypeStandardStorage,
-				filterExpr:  func() filterExpr { expr, _ := filter.Parse(`bytes == 0`); return expr }(),
-				filterRaw:   "bytes == 0",
-			},
-			args: args{
-				ctx: context.Background(),
-				buckets: []s3types.Bucket{
-					{Name: aws.String("bucket0")},
-				},
-				region: "ap-northeast-1",
-			},
-			want:    []*Metric{},
-			want1:   0,
-			wantErr: false,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			man := &Manager{
-				client:      tt.fields.client,
-				metricName:  tt.fields.metricName,
-				storageType: tt.fields.storageType,
-				prefix:      tt.fields.prefix,
-				regions:     tt.fields.regions,
-				filterExpr:  tt.fields.filterExpr,
-				filterRaw:   tt.fields.filterRaw,
-				sem:         tt.fields.sem,
-			}
-			got, got1, err := man.getMetrics(tt.args.ctx, tt.args.buckets, tt.args.region)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Manager.getMetrics() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Manager.getMetrics() got = %v, want %v", got, tt.want)
-			}
-			if got1 != tt.want1 {
-				t.Errorf("Manager.getMetrics() got1 = %v, want %v", got1, tt.want1)
-			}
-		})
-	}
-}
-
-func TestManager_getMetricsFromQueries(t *testing.T) {
-	type fields struct {
-		client      *Client
-		metricName  MetricName
-		storageType StorageType
-		prefix      *string
-		regions     []string
-		filterExpr  filterExpr
-		filterRaw   string
-		sem         *semaphore.Weighted
+		client *Client
 	}
 	type args struct {
 		ctx     context.Context
 		queries []cwtypes.MetricDataQuery
-		region  string
+		query   *SourceQuery
 	}
 	tests := []struct {
 		name    string
 		fields  fields
 		args    args
 		want    []*Metric
-		want1   int64
 		wantErr bool
 	}{
 		{
@@ -226,8 +51,6 @@ func TestManager_getMetricsFromQueries(t *testing.T) {
 						},
 					},
 				),
-				metricName:  MetricNameBucketSizeBytes,
-				storageType: StorageTypeStandardStorage,
 			},
 			args: args{
 				ctx: context.Background(),
@@ -277,7 +100,11 @@ func TestManager_getMetricsFromQueries(t *testing.T) {
 						},
 					},
 				},
-				region: "ap-northeast-1",
+				query: &SourceQuery{
+					Region:      "ap-northeast-1",
+					MetricName:  MetricNameBucketSizeBytes,
+					StorageType: StorageTypeStandardStorage,
+				},
 			},
 			want: []*Metric{
 				{
@@ -286,6 +113,9 @@ func TestManager_getMetricsFromQueries(t *testing.T) {
 					MetricName:  MetricNameBucketSizeBytes,
 					StorageType: StorageTypeStandardStorage,
 					Value:       2048,
+					Status:      DataStatusOK,
+					Source:      SourceTypeCloudWatch,
+					BucketType:  BucketTypeGeneralPurpose,
 				},
 				{
 					BucketName:  "bucket1",
@@ -293,9 +123,11 @@ func TestManager_getMetricsFromQueries(t *testing.T) {
 					MetricName:  MetricNameBucketSizeBytes,
 					StorageType: StorageTypeStandardStorage,
 					Value:       0,
+					Status:      DataStatusOK,
+					Source:      SourceTypeCloudWatch,
+					BucketType:  BucketTypeGeneralPurpose,
 				},
 			},
-			want1:   2048,
 			wantErr: false,
 		},
 		{
@@ -309,8 +141,6 @@ func TestManager_getMetricsFromQueries(t *testing.T) {
 						},
 					},
 				),
-				metricName:  MetricNameBucketSizeBytes,
-				storageType: StorageTypeStandardStorage,
 			},
 			args: args{
 				ctx: context.Background(),
@@ -338,68 +168,15 @@ func TestManager_getMetricsFromQueries(t *testing.T) {
 						},
 					},
 				},
-				region: "ap-northeast-1",
+				query: &SourceQuery{
+					Region:      "ap-northeast-1",
+					MetricName:  MetricNameBucketSizeBytes,
+					StorageType: StorageTypeStandardStorage,
+				},
 			},
 			want:    nil,
-			want1:   0,
 			wantErr: true,
 		},
-		{
-			name: "filter returns false",
-			fields: fields{
-				client: newMockClient(
-					nil,
-					&mockCloudWatch{
-						GetMetricDataFunc: func(_ context.Context, _ *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
-							return &cloudwatch.GetMetricDataOutput{
-								MetricDataResults: []cwtypes.MetricDataResult{
-									{
-										Label:  aws.String("bucket0"),
-										Values: []float64{1024, 2048},
-									},
-								},
-								NextToken: nil,
-							}, nil
-						},
-					},
-				),
-				metricName:  MetricNameBucketSizeBytes,
-				storageType: StorageTypeStandardStorage,
-				filterExpr:  func() filterExpr { expr, _ := filter.Parse(`bytes == 0`); return expr }(),
-				filterRaw:   "bytes == 0",
-			},
-			args: args{
-				ctx: context.Background(),
-				queries: []cwtypes.MetricDataQuery{
-					{
-						Id:    aws.String("m0"),
-						Label: aws.String("bucket0"),
-						MetricStat: &cwtypes.MetricStat{
-							Metric: &cwtypes.Metric{
-								Namespace:  aws.String("AWS/S3"),
-								MetricName: aws.String(MetricNameBucketSizeBytes.String()),
-								Dimensions: []cwtypes.Dimension{
-									{
-										Name:  aws.String("BucketName"),
-										Value: aws.String("bucket0"),
-									},
-									{
-										Name:  aws.String("StorageType"),
-										Value: aws.String(StorageTypeStandardStorage.String()),
-									},
-								},
-							},
-							Period: aws.Int32(86400),
-							Stat:   aws.String("Average"),
-						},
-					},
-				},
-				region: "ap-northeast-1",
-			},
-			want:    []*Metric{},
-			want1:   0,
-			wantErr: false,
-		},
 		{
 			name: "result values nil",
 			fields: fields{
@@ -419,10 +196,6 @@ func TestManager_getMetricsFromQueries(t *testing.T) {
 						},
 					},
 				),
-				metricName:  MetricNameBucketSizeBytes,
-				storageType: StorageTypeStandardStorage,
-				filterExpr:  nil,
-				filterRaw:   "",
 			},
 			args: args{
 				ctx: context.Background(),
@@ -450,7 +223,11 @@ func TestManager_getMetricsFromQueries(t *testing.T) {
 						},
 					},
 				},
-				region: "ap-northeast-1",
+				query: &SourceQuery{
+					Region:      "ap-northeast-1",
+					MetricName:  MetricNameBucketSizeBytes,
+					StorageType: StorageTypeStandardStorage,
+				},
 			},
 			want: []*Metric{
 				{
@@ -459,9 +236,11 @@ func TestManager_getMetricsFromQueries(t *testing.T) {
 					MetricName:  MetricNameBucketSizeBytes,
 					StorageType: StorageTypeStandardStorage,
 					Value:       0,
+					Status:      DataStatusNoData,
+					Source:      SourceTypeCloudWatch,
+					BucketType:  BucketTypeGeneralPurpose,
 				},
 			},
-			want1:   0,
 			wantErr: false,
 		},
 		{
@@ -496,8 +275,6 @@ func TestManager_getMetricsFromQueries(t *testing.T) {
 						},
 					},
 				),
-				metricName:  MetricNameBucketSizeBytes,
-				storageType: StorageTypeStandardStorage,
 			},
 			args: args{
 				ctx: context.Background(),
@@ -547,7 +324,11 @@ func TestManager_getMetricsFromQueries(t *testing.T) {
 						},
 					},
 				},
-				region: "ap-northeast-1",
+				query: &SourceQuery{
+					Region:      "ap-northeast-1",
+					MetricName:  MetricNameBucketSizeBytes,
+					StorageType: StorageTypeStandardStorage,
+				},
 			},
 			want: []*Metric{
 				{
@@ -556,6 +337,9 @@ func TestManager_getMetricsFromQueries(t *testing.T) {
 					MetricName:  MetricNameBucketSizeBytes,
 					StorageType: StorageTypeStandardStorage,
 					Value:       1024,
+					Status:      DataStatusOK,
+					Source:      SourceTypeCloudWatch,
+					BucketType:  BucketTypeGeneralPurpose,
 				},
 				{
 					BucketName:  "bucket1",
@@ -563,34 +347,498 @@ func TestManager_getMetricsFromQueries(t *testing.T) {
 					MetricName:  MetricNameBucketSizeBytes,
 					StorageType: StorageTypeStandardStorage,
 					Value:       2048,
+					Status:      DataStatusOK,
+					Source:      SourceTypeCloudWatch,
+					BucketType:  BucketTypeGeneralPurpose,
 				},
 			},
-			want1:   3072,
 			wantErr: false,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			man := &Manager{
-				client:      tt.fields.client,
-				metricName:  tt.fields.metricName,
-				storageType: tt.fields.storageType,
-				prefix:      tt.fields.prefix,
-				regions:     tt.fields.regions,
-				filterExpr:  tt.fields.filterExpr,
-				filterRaw:   tt.fields.filterRaw,
-				sem:         tt.fields.sem,
+			s := &CloudWatchSource{
+				client: tt.fields.client,
 			}
-			got, got1, err := man.getMetricsFromQueries(tt.args.ctx, tt.args.queries, tt.args.region)
+			got, err := s.getMetricsFromQueries(tt.args.ctx, tt.args.queries, tt.args.query)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("Manager.getMetricsFromQueries() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("CloudWatchSource.getMetricsFromQueries() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Manager.getMetricsFromQueries() got = %v, want %v", got, tt.want)
+				t.Errorf("CloudWatchSource.getMetricsFromQueries() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getDatapoint(t *testing.T) {
+	var (
+		t0 = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		t1 = time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	)
+	type want struct {
+		value     float64
+		timestamp time.Time
+		status    DataStatus
+	}
+	tests := []struct {
+		name   string
+		result cwtypes.MetricDataResult
+		want   want
+	}{
+		{
+			name: "ok",
+			result: cwtypes.MetricDataResult{
+				Values:     []float64{1024, 2048},
+				Timestamps: []time.Time{t0, t1},
+				StatusCode: cwtypes.StatusCodeComplete,
+			},
+			want: want{
+				value:     2048,
+				timestamp: t1,
+				status:    DataStatusOK,
+			},
+		},
+		{
+			name: "partial",
+			result: cwtypes.MetricDataResult{
+				Values:     []float64{4096},
+				Timestamps: []time.Time{t0},
+				StatusCode: cwtypes.StatusCodePartialData,
+			},
+			want: want{
+				value:     4096,
+				timestamp: t0,
+				status:    DataStatusPartial,
+			},
+		},
+		{
+			name: "no data",
+			result: cwtypes.MetricDataResult{
+				Values:     nil,
+				StatusCode: cwtypes.StatusCodeComplete,
+			},
+			want: want{
+				value:     0,
+				timestamp: time.Time{},
+				status:    DataStatusNoData,
+			},
+		},
+		{
+			name: "timestamps missing",
+			result: cwtypes.MetricDataResult{
+				Values: []float64{0},
+			},
+			want: want{
+				value:     0,
+				timestamp: time.Time{},
+				status:    DataStatusOK,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, timestamp, status := getDatapoint(tt.result)
+			if value != tt.want.value {
+				t.Errorf("getDatapoint() value = %v, want %v", value, tt.want.value)
+			}
+			if !timestamp.Equal(tt.want.timestamp) {
+				t.Errorf("getDatapoint() timestamp = %v, want %v", timestamp, tt.want.timestamp)
+			}
+			if status != tt.want.status {
+				t.Errorf("getDatapoint() status = %v, want %v", status, tt.want.status)
+			}
+		})
+	}
+}
+
+func TestCloudWatchSource_Metrics(t *testing.T) {
+	type args struct {
+		query *SourceQuery
+	}
+	tests := []struct {
+		name          string
+		args          args
+		wantDimension cwtypes.Dimension
+		wantStat      string
+		wantDuration  time.Duration
+		wantEnd       time.Time
+	}{
+		{
+			name: "storage metric",
+			args: args{
+				query: &SourceQuery{
+					Region:      "ap-northeast-1",
+					Buckets:     []string{"bucket0"},
+					MetricName:  MetricNameBucketSizeBytes,
+					StorageType: StorageTypeStandardStorage,
+				},
+			},
+			wantDimension: cwtypes.Dimension{
+				Name:  aws.String("StorageType"),
+				Value: aws.String("StandardStorage"),
+			},
+			wantStat:     "Average",
+			wantDuration: storageLookback,
+			wantEnd:      testNow,
+		},
+		{
+			name: "end time",
+			args: args{
+				query: &SourceQuery{
+					Region:      "ap-northeast-1",
+					Buckets:     []string{"bucket0"},
+					MetricName:  MetricNameBucketSizeBytes,
+					StorageType: StorageTypeStandardStorage,
+					EndTime:     testNow.Add(-time.Hour),
+				},
+			},
+			wantDimension: cwtypes.Dimension{
+				Name:  aws.String("StorageType"),
+				Value: aws.String("StandardStorage"),
+			},
+			wantStat:     "Average",
+			wantDuration: storageLookback,
+			wantEnd:      testNow.Add(-time.Hour),
+		},
+		{
+			name: "directory bucket",
+			args: args{
+				query: &SourceQuery{
+					Region:      "ap-northeast-1",
+					Buckets:     []string{"bucket0--apne1-az4--x-s3"},
+					MetricName:  MetricNameBucketSizeBytes,
+					StorageType: StorageTypeStandardStorage,
+				},
+			},
+			wantDimension: cwtypes.Dimension{
+				Name:  aws.String("StorageType"),
+				Value: aws.String("ExpressOneZoneStorage"),
+			},
+			wantStat:     "Average",
+			wantDuration: storageLookback,
+			wantEnd:      testNow,
+		},
+		{
+			name: "directory bucket objects",
+			args: args{
+				query: &SourceQuery{
+					Region:      "ap-northeast-1",
+					Buckets:     []string{"bucket0--apne1-az4--x-s3"},
+					MetricName:  MetricNameNumberOfObjects,
+					StorageType: StorageTypeAllStorageTypes,
+				},
+			},
+			wantDimension: cwtypes.Dimension{
+				Name:  aws.String("StorageType"),
+				Value: aws.String("AllStorageTypes"),
+			},
+			wantStat:     "Average",
+			wantDuration: storageLookback,
+			wantEnd:      testNow,
+		},
+		{
+			name: "request metric",
+			args: args{
+				query: &SourceQuery{
+					Region:     "ap-northeast-1",
+					Buckets:    []string{"bucket0"},
+					MetricName: MetricNameAllRequests,
+					FilterID:   "EntireBucket",
+				},
+			},
+			wantDimension: cwtypes.Dimension{
+				Name:  aws.String("FilterId"),
+				Value: aws.String("EntireBucket"),
+			},
+			wantStat:     "Sum",
+			wantDuration: 24 * time.Hour,
+			wantEnd:      testNow,
+		},
+		{
+			name: "latency metric",
+			args: args{
+				query: &SourceQuery{
+					Region:     "ap-northeast-1",
+					Buckets:    []string{"bucket0"},
+					MetricName: MetricNameFirstByteLatency,
+					FilterID:   "documents",
+				},
+			},
+			wantDimension: cwtypes.Dimension{
+				Name:  aws.String("FilterId"),
+				Value: aws.String("documents"),
+			},
+			wantStat:     "Average",
+			wantDuration: 24 * time.Hour,
+			wantEnd:      testNow,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var in *cloudwatch.GetMetricDataInput
+			s := NewCloudWatchSource(newMockClient(
+				nil,
+				&mockCloudWatch{
+					GetMetricDataFunc: func(_ context.Context, params *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+						in = params
+						return &cloudwatch.GetMetricDataOutput{
+							MetricDataResults: []cwtypes.MetricDataResult{
+								{
+									Label: params.MetricDataQueries[0].Label,
+								},
+							},
+						}, nil
+					},
+				},
+			))
+			got, err := s.Metrics(context.Background(), tt.args.query)
+			if err != nil {
+				t.Fatalf("CloudWatchSource.Metrics() error = %v", err)
+			}
+			if len(got) != 1 || got[0].Status != DataStatusNoData {
+				t.Errorf("CloudWatchSource.Metrics() = %v, want a metric without datapoints", got)
+			}
+			if want := getBucketType(tt.args.query.Buckets[0]); got[0].BucketType != want {
+				t.Errorf("CloudWatchSource.Metrics() bucket type = %v, want %v", got[0].BucketType, want)
+			}
+			stat := in.MetricDataQueries[0].MetricStat
+			if dimension := stat.Metric.Dimensions[1]; !reflect.DeepEqual(dimension, tt.wantDimension) {
+				t.Errorf("CloudWatchSource.Metrics() dimension = %v, want %v", dimension, tt.wantDimension)
+			}
+			if aws.ToString(stat.Stat) != tt.wantStat {
+				t.Errorf("CloudWatchSource.Metrics() stat = %v, want %v", aws.ToString(stat.Stat), tt.wantStat)
+			}
+			if d := aws.ToTime(in.EndTime).Sub(aws.ToTime(in.StartTime)); d != tt.wantDuration {
+				t.Errorf("CloudWatchSource.Metrics() duration = %v, want %v", d, tt.wantDuration)
+			}
+			if end := aws.ToTime(in.EndTime); !end.Equal(tt.wantEnd) {
+				t.Errorf("CloudWatchSource.Metrics() end time = %v, want %v", end, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestMetricName_IsBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		tr   MetricName
+		want bool
+	}{
+		{
+			name: "bucket size bytes",
+			tr:   MetricNameBucketSizeBytes,
+			want: true,
+		},
+		{
+			name: "bytes downloaded",
+			tr:   MetricNameBytesDownloaded,
+			want: true,
+		},
+		{
+			name: "number of objects",
+			tr:   MetricNameNumberOfObjects,
+			want: false,
+		},
+		{
+			name: "all requests",
+			tr:   MetricNameAllRequests,
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tr.IsBytes(); got != tt.want {
+				t.Errorf("MetricName.IsBytes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetricName_isRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		tr   MetricName
+		want bool
+	}{
+		{
+			name: "none",
+			tr:   MetricNameNone,
+			want: false,
+		},
+		{
+			name: "bucket size bytes",
+			tr:   MetricNameBucketSizeBytes,
+			want: false,
+		},
+		{
+			name: "number of objects",
+			tr:   MetricNameNumberOfObjects,
+			want: false,
+		},
+		{
+			name: "all requests",
+			tr:   MetricNameAllRequests,
+			want: true,
+		},
+		{
+			name: "4xx errors",
+			tr:   MetricName4xxErrors,
+			want: true,
+		},
+		{
+			name: "total request latency",
+			tr:   MetricNameTotalRequestLatency,
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tr.isRequest(); got != tt.want {
+				t.Errorf("MetricName.isRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStorageType_forBucket(t *testing.T) {
+	type args struct {
+		bucket string
+	}
+	tests := []struct {
+		name string
+		t    StorageType
+		args args
+		want StorageType
+	}{
+		{
+			name: "general purpose bucket",
+			t:    StorageTypeStandardStorage,
+			args: args{
+				bucket: "bucket0",
+			},
+			want: StorageTypeStandardStorage,
+		},
+		{
+			name: "directory bucket",
+			t:    StorageTypeStandardStorage,
+			args: args{
+				bucket: "bucket0--apne1-az4--x-s3",
+			},
+			want: StorageTypeExpressOneZoneStorage,
+		},
+		{
+			name: "directory bucket with all storage types",
+			t:    StorageTypeAllStorageTypes,
+			args: args{
+				bucket: "bucket0--apne1-az4--x-s3",
+			},
+			want: StorageTypeAllStorageTypes,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.t.forBucket(tt.args.bucket); got != tt.want {
+				t.Errorf("StorageType.forBucket() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloudWatchSource_getSeries(t *testing.T) {
+	points := newTestPoints(10, linearValue)
+	s := NewCloudWatchSource(newMockClient(nil, newMockSeriesCloudWatch(points)))
+	got, err := s.getSeries(context.Background(), &SourceQuery{
+		Region:      "ap-northeast-1",
+		Buckets:     []string{"bucket0", "bucket1"},
+		MetricName:  MetricNameBucketSizeBytes,
+		StorageType: StorageTypeStandardStorage,
+	}, testSeriesOrigin)
+	if err != nil {
+		t.Fatalf("CloudWatchSource.getSeries() error = %v", err)
+	}
+	want := []*series{
+		{bucket: "bucket0", points: points},
+		{bucket: "bucket1", points: []point{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CloudWatchSource.getSeries() = %v, want %v", got, want)
+	}
+}
+
+func TestCloudWatchSource_MetricPages(t *testing.T) {
+	var calls int
+	client := newMockClient(
+		nil,
+		&mockCloudWatch{
+			GetMetricDataFunc: func(_ context.Context, params *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+				calls++
+				if params.NextToken == nil {
+					return &cloudwatch.GetMetricDataOutput{
+						MetricDataResults: []cwtypes.MetricDataResult{
+							{Label: aws.String("bucket0"), Values: []float64{1024}},
+						},
+						NextToken: aws.String("token0"),
+					}, nil
+				}
+				return &cloudwatch.GetMetricDataOutput{
+					MetricDataResults: []cwtypes.MetricDataResult{
+						{Label: aws.String("bucket1"), Values: []float64{2048}},
+					},
+				}, nil
+			},
+		},
+	)
+	query := &SourceQuery{
+		Region:      "ap-northeast-1",
+		Buckets:     []string{"bucket0", "bucket1"},
+		MetricName:  MetricNameBucketSizeBytes,
+		StorageType: StorageTypeStandardStorage,
+		EndTime:     testNow,
+	}
+	tests := []struct {
+		name      string
+		limit     int
+		want      [][]string
+		wantCalls int
+	}{
+		{
+			name:      "all pages",
+			limit:     0,
+			want:      [][]string{{"bucket0"}, {"bucket1"}},
+			wantCalls: 2,
+		},
+		{
+			name:      "stop after first page",
+			limit:     1,
+			want:      [][]string{{"bucket0"}},
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = 0
+			s := NewCloudWatchSource(client)
+			got := make([][]string, 0)
+			for page, err := range s.MetricPages(context.Background(), query) {
+				if err != nil {
+					t.Fatalf("CloudWatchSource.MetricPages() error = %v", err)
+				}
+				names := make([]string, 0, len(page))
+				for _, metric := range page {
+					names = append(names, metric.BucketName)
+				}
+				got = append(got, names)
+				if len(got) == tt.limit {
+					break
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CloudWatchSource.MetricPages() = %v, want %v", got, tt.want)
 			}
-			if got1 != tt.want1 {
-				t.Errorf("Manager.getMetricsFromQueries() got1 = %v, want %v", got1, tt.want1)
+			if calls != tt.wantCalls {
+				t.Errorf("CloudWatchSource.MetricPages() calls = %v, want %v", calls, tt.wantCalls)
 			}
 		})
 	}
@@ -2,10 +2,18 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/dustin/go-humanize"
 	"github.com/nekrassov01/logger/integrations/awssdk"
 	"github.com/nekrassov01/logger/log"
@@ -15,9 +23,47 @@ import (
 
 const name = "s3bytes"
 
+// errViolation is returned by the check subcommand if any rule is violated.
+var errViolation = errors.New("violations found")
+
+// checkError wraps the errors of the check subcommand other than violations,
+// so that they exit with the status distinct from violations.
+type checkError struct {
+	err error
+}
+
+func (e *checkError) Error() string {
+	return e.err.Error()
+}
+
+func (e *checkError) Unwrap() error {
+	return e.err
+}
+
+// wrapCheckError wraps the error of the check subcommand into checkError unless it is a violation.
+func wrapCheckError(err error) error {
+	if err == nil || errors.Is(err, errViolation) {
+		return err
+	}
+	return &checkError{err: err}
+}
+
 var logger = log.NewLogger(log.NewCLIHandler(io.Discard))
 
 func newCmd(w, ew io.Writer) *cli.Command {
+	configPath := &cli.StringFlag{
+		Name:        "config",
+		Usage:       "set path to config file with defaults and presets",
+		Sources:     cli.EnvVars("S3BYTES_CONFIG"),
+		DefaultText: "$XDG_CONFIG_HOME/s3bytes/config.yaml",
+	}
+
+	presetName := &cli.StringFlag{
+		Name:    "preset",
+		Usage:   "set name of preset in config file",
+		Sources: cli.EnvVars("S3BYTES_PRESET"),
+	}
+
 	profile := &cli.StringFlag{
 		Name:    "profile",
 		Aliases: []string{"p"},
@@ -55,6 +101,12 @@ func newCmd(w, ew io.Writer) *cli.Command {
 		Value:   s3bytes.StorageTypeStandardStorage.String(),
 	}
 
+	filterID := &cli.StringFlag{
+		Name:  "filter-id",
+		Usage: "set filter id of request metrics configuration for request metrics",
+		Value: s3bytes.DefaultFilterID,
+	}
+
 	prefix := &cli.StringFlag{
 		Name:    "prefix",
 		Aliases: []string{"P"},
@@ -67,6 +119,35 @@ func newCmd(w, ew io.Writer) *cli.Command {
 		Usage:   "set filter expression for metric values",
 	}
 
+	tag := &cli.StringSliceFlag{
+		Name:    "tag",
+		Aliases: []string{"t"},
+		Usage:   "set bucket tag keys to fetch as columns",
+	}
+
+	enrich := &cli.BoolFlag{
+		Name:  "enrich",
+		Usage: "add creation date, versioning, lifecycle, encryption and object lock of buckets as columns",
+	}
+
+	groupBy := &cli.StringFlag{
+		Name:    "group-by",
+		Aliases: []string{"g"},
+		Usage:   "set field to aggregate values by, such as \"region\" or \"tag_team\"",
+	}
+
+	scan := &cli.StringFlag{
+		Name:  "scan",
+		Usage: "set scan mode to compute exact values by listing objects",
+		Value: s3bytes.ScanModeNone.String(),
+	}
+
+	noData := &cli.StringFlag{
+		Name:  "no-data",
+		Usage: "set how to handle buckets without datapoints",
+		Value: s3bytes.NoDataModeShow.String(),
+	}
+
 	output := &cli.StringFlag{
 		Name:    "output",
 		Aliases: []string{"o"},
@@ -75,7 +156,76 @@ func newCmd(w, ew io.Writer) *cli.Command {
 		Value:   s3bytes.OutputTypeCompressedText.String(),
 	}
 
+	chartType := &cli.StringFlag{
+		Name:        "chart-type",
+		Usage:       "set chart type",
+		DefaultText: "treemap for prefixes, pie otherwise",
+	}
+
+	chartOut := &cli.StringFlag{
+		Name:  "chart-out",
+		Usage: "set path of chart file, or \"-\" for stdout",
+	}
+
+	chartTop := &cli.IntFlag{
+		Name:  "chart-top",
+		Usage: "set maximum number of items in chart including \"others\"",
+		Value: s3bytes.MaxChartItems,
+	}
+
+	chartMinPercent := &cli.FloatFlag{
+		Name:  "chart-min-percent",
+		Usage: "set minimum share in percent of buckets named in pie chart",
+	}
+
+	policy := &cli.StringFlag{
+		Name:    "policy",
+		Usage:   "set path to policy file in JSON or YAML to evaluate quotas",
+		Sources: cli.EnvVars("S3BYTES_POLICY"),
+	}
+
+	webhookURL := &cli.StringFlag{
+		Name:    "webhook-url",
+		Usage:   "set webhook url to notify of violations and policy breaches",
+		Sources: cli.EnvVars("S3BYTES_WEBHOOK_URL"),
+	}
+
+	webhookTemplate := &cli.StringFlag{
+		Name:  "webhook-template",
+		Usage: "set path to text/template file of webhook payload",
+	}
+
+	webhookDryRun := &cli.BoolFlag{
+		Name:  "webhook-dry-run",
+		Usage: "write webhook payload to stderr instead of posting it",
+	}
+
+	noOpen := &cli.BoolFlag{
+		Name:    "no-open",
+		Usage:   "do not open chart file in browser",
+		Sources: cli.EnvVars("S3BYTES_NO_OPEN"),
+	}
+
+	noProgress := &cli.BoolFlag{
+		Name:    "no-progress",
+		Usage:   "do not show progress on stderr",
+		Sources: cli.EnvVars("S3BYTES_NO_PROGRESS"),
+	}
+
 	before := func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+		// load config file and apply the preset to the flags not specified
+		conf, err := loadConfig(cmd.String(configPath.Name))
+		if err != nil {
+			return ctx, err
+		}
+		p, err := conf.resolve(cmd.String(presetName.Name))
+		if err != nil {
+			return ctx, err
+		}
+		if err := p.apply(cmd); err != nil {
+			return ctx, err
+		}
+
 		// load aws config with the specified profile
 		cfg, err := s3bytes.LoadConfig(ctx, cmd.String(profile.Name))
 		if err != nil {
@@ -120,23 +270,81 @@ func newCmd(w, ew io.Writer) *cli.Command {
 		return ctx, nil
 	}
 
-	action := func(ctx context.Context, cmd *cli.Command) error {
+	setup := func(cmd *cli.Command) (*s3bytes.Manager, *view, error) {
 		// parse metric name passed as string
 		metricName, err := s3bytes.ParseMetricName(cmd.String(metricName.Name))
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
 		// parse storage type passed as string
 		storageType, err := s3bytes.ParseStorageType(cmd.String(storageType.Name))
 		if err != nil {
-			return err
+			return nil, nil, err
+		}
+
+		// parse scan mode passed as string
+		scanMode, err := s3bytes.ParseScanMode(cmd.String(scan.Name))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// parse no-data mode passed as string
+		noDataMode, err := s3bytes.ParseNoDataMode(cmd.String(noData.Name))
+		if err != nil {
+			return nil, nil, err
 		}
 
 		// parse output type passed as string
 		outputType, err := s3bytes.ParseOutputType(cmd.String(output.Name))
 		if err != nil {
-			return err
+			return nil, nil, err
+		}
+
+		// parse chart type passed as string if specified
+		var chartTypeValue s3bytes.ChartType
+		if s := cmd.String(chartType.Name); s != "" {
+			chartTypeValue, err = s3bytes.ParseChartType(s)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		// validate the number of items and the minimum share in chart
+		if n := cmd.Int(chartTop.Name); n < 1 {
+			return nil, nil, fmt.Errorf("invalid chart top: %d", n)
+		}
+		if p := cmd.Float(chartMinPercent.Name); p < 0 || p >= 100 {
+			return nil, nil, fmt.Errorf("invalid chart min percent: %v", p)
+		}
+
+		// load policy file if specified
+		var policyValue *s3bytes.Policy
+		if path := cmd.String(policy.Name); path != "" {
+			policyValue, err = s3bytes.LoadPolicy(path)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		// create notifier if webhook is specified
+		var notifier *s3bytes.Notifier
+		if url, dryRun := cmd.String(webhookURL.Name), cmd.Bool(webhookDryRun.Name); url != "" || dryRun {
+			opts := make([]s3bytes.NotifierOption, 0, 2)
+			if path := cmd.String(webhookTemplate.Name); path != "" {
+				b, err := os.ReadFile(path)
+				if err != nil {
+					return nil, nil, err
+				}
+				opts = append(opts, s3bytes.WithTemplate(string(b)))
+			}
+			if dryRun {
+				opts = append(opts, s3bytes.WithDryRun(ew))
+			}
+			notifier, err = s3bytes.NewNotifier(url, opts...)
+			if err != nil {
+				return nil, nil, err
+			}
 		}
 
 		// logging at process start
@@ -144,35 +352,77 @@ func newCmd(w, ew io.Writer) *cli.Command {
 			"started",
 			"metricName", metricName,
 			"storageType", storageType,
+			"scan", scanMode,
+			"noData", noDataMode,
 			"output", outputType,
 		)
 
 		// get aws config from the metadata
-		cfg := cmd.Metadata["config"].(aws.Config)
+		cfg := cmd.Root().Metadata["config"].(aws.Config)
 
 		// create a new client
 		client := s3bytes.NewClient(cfg)
 
-		// initialize the manager
-		man := s3bytes.NewManager(client)
+		// fetch the tags that the policy matches as well as the selected ones
+		tagKeys := cmd.StringSlice(tag.Name)
+		if policyValue != nil {
+			tagKeys = append(tagKeys, policyValue.TagKeys()...)
+		}
 
-		// set regions to the manager
-		if err := man.SetRegion(cmd.StringSlice(region.Name)); err != nil {
-			return err
+		// initialize the manager with the options
+		man := s3bytes.NewManager(client,
+			s3bytes.WithRegion(cmd.StringSlice(region.Name)...),
+			s3bytes.WithMetric(metricName, storageType),
+			s3bytes.WithFilterID(cmd.String(filterID.Name)),
+			s3bytes.WithPrefix(cmd.String(prefix.Name)),
+			s3bytes.WithFilter(cmd.String(filter.Name)),
+			s3bytes.WithScan(scanMode),
+			s3bytes.WithNoData(noDataMode),
+			s3bytes.WithTags(tagKeys...),
+			s3bytes.WithEnrich(cmd.Bool(enrich.Name)),
+		)
+
+		// validate all settings of the manager at once
+		if err := man.Validate(); err != nil {
+			return nil, nil, err
 		}
 
-		// set metric name and strorage type to the manager
-		if err := man.SetMetric(metricName, storageType); err != nil {
-			return err
+		// collect options to render the result
+		v := &view{
+			policy:     policyValue,
+			notifier:   notifier,
+			groupBy:    cmd.String(groupBy.Name),
+			outputType: outputType,
+			opts: []s3bytes.RendererOption{
+				s3bytes.WithHighlight(noDataMode == s3bytes.NoDataModeHighlight),
+				s3bytes.WithChartType(chartTypeValue),
+				s3bytes.WithChartOutput(cmd.String(chartOut.Name)),
+				s3bytes.WithNoOpen(cmd.Bool(noOpen.Name)),
+				s3bytes.WithTopN(cmd.Int(chartTop.Name)),
+				s3bytes.WithMinPercent(cmd.Float(chartMinPercent.Name)),
+			},
 		}
 
-		// set prefix to the manager
-		if err := man.SetPrefix(cmd.String(prefix.Name)); err != nil {
+		return man, v, nil
+	}
+
+	report := func(cmd *cli.Command, man *s3bytes.Manager) error {
+		// progress is drawn only on a terminal so that it does not pollute logs
+		if cmd.Bool(noProgress.Name) || !isTerminal(ew) {
+			return nil
+		}
+		return man.Subscribe(newProgress(ew, len(man.Regions())).observe)
+	}
+
+	action := func(ctx context.Context, cmd *cli.Command) error {
+		// set up the manager with the common options
+		man, v, err := setup(cmd)
+		if err != nil {
 			return err
 		}
 
-		// set filter to the manager
-		if err := man.SetFilter(cmd.String(filter.Name)); err != nil {
+		// show progress of the regions on stderr
+		if err := report(cmd, man); err != nil {
 			return err
 		}
 
@@ -186,9 +436,88 @@ func newCmd(w, ew io.Writer) *cli.Command {
 		// sort metrics
 		s3bytes.SortMetrics(data)
 
-		// render result
-		ren := s3bytes.NewRenderer(w, data, outputType)
-		if err := ren.Render(); err != nil {
+		// evaluate quotas, aggregate, render and notify of the result
+		if data, err = present(ctx, w, v, data, nil); err != nil {
+			return err
+		}
+
+		// logging at process stop with total bytes
+		logger.Info(
+			"stopped",
+			"total", humanize.Comma(data.Total),
+		)
+
+		return nil
+	}
+
+	bucket := &cli.StringSliceFlag{
+		Name:    "bucket",
+		Aliases: []string{"b"},
+		Usage:   "set target bucket names",
+	}
+
+	top := &cli.IntFlag{
+		Name:    "top",
+		Aliases: []string{"n"},
+		Usage:   "set number of largest buckets to target from a normal run",
+	}
+
+	depth := &cli.IntFlag{
+		Name:    "depth",
+		Aliases: []string{"d"},
+		Usage:   "set depth of prefixes to aggregate",
+		Value:   1,
+	}
+
+	prefixesAction := func(ctx context.Context, cmd *cli.Command) error {
+		// set up the manager with the common options
+		man, v, err := setup(cmd)
+		if err != nil {
+			return err
+		}
+
+		// show progress of the regions on stderr
+		if err := report(cmd, man); err != nil {
+			return err
+		}
+
+		// collect target buckets
+		buckets := cmd.StringSlice(bucket.Name)
+		if n := cmd.Int(top.Name); n > 0 {
+			data, err := man.List(ctx)
+			if err != nil {
+				return err
+			}
+			s3bytes.SortMetrics(data)
+			for _, metric := range data.Metrics[:min(n, len(data.Metrics))] {
+				if !slices.Contains(buckets, metric.BucketName) {
+					buckets = append(buckets, metric.BucketName)
+				}
+			}
+		}
+		if len(buckets) == 0 {
+			return errors.New("no target buckets: specify --bucket or --top")
+		}
+
+		// logging target buckets
+		logger.Info(
+			"prefixes",
+			"buckets", buckets,
+			"depth", cmd.Int(depth.Name),
+		)
+
+		// run prefix aggregation
+		data, err := man.ListPrefixes(ctx, buckets, cmd.Int(depth.Name))
+		if err != nil {
+			return err
+		}
+		debug(man)
+
+		// sort metrics
+		s3bytes.SortMetrics(data)
+
+		// evaluate quotas, aggregate, render and notify of the result
+		if data, err = present(ctx, w, v, data, nil); err != nil {
 			return err
 		}
 
@@ -201,6 +530,529 @@ func newCmd(w, ew io.Writer) *cli.Command {
 		return nil
 	}
 
+	prefixes := &cli.Command{
+		Name:        "prefixes",
+		Usage:       "Aggregate sizes by prefix in buckets",
+		Description: "Aggregate sizes by prefix up to the specified depth by listing objects in buckets.",
+		Action:      prefixesAction,
+		Flags:       []cli.Flag{bucket, top, depth},
+	}
+
+	manifest := &cli.StringSliceFlag{
+		Name:    "manifest",
+		Aliases: []string{"M"},
+		Usage:   "set paths to inventory manifest.json or directories containing it",
+	}
+
+	inventoryDepth := &cli.IntFlag{
+		Name:    "depth",
+		Aliases: []string{"d"},
+		Usage:   "set depth of prefixes to aggregate, 0 means per bucket",
+	}
+
+	inventoryAction := func(ctx context.Context, cmd *cli.Command) error {
+		// set up the manager with the common options
+		man, v, err := setup(cmd)
+		if err != nil {
+			return err
+		}
+
+		// collect target manifests
+		manifests := cmd.StringSlice(manifest.Name)
+		if len(manifests) == 0 {
+			return errors.New("no inventory manifests: specify --manifest")
+		}
+
+		// run inventory aggregation
+		data, err := man.ListInventory(ctx, manifests, cmd.Int(inventoryDepth.Name))
+		if err != nil {
+			return err
+		}
+		debug(man)
+
+		// sort metrics
+		s3bytes.SortMetrics(data)
+
+		// evaluate quotas, aggregate, render and notify of the result
+		if data, err = present(ctx, w, v, data, nil); err != nil {
+			return err
+		}
+
+		// logging at process stop with total bytes
+		logger.Info(
+			"stopped",
+			"total", humanize.Comma(data.Total),
+		)
+
+		return nil
+	}
+
+	inventory := &cli.Command{
+		Name:        "inventory",
+		Usage:       "Aggregate sizes from S3 Inventory reports",
+		Description: "Aggregate sizes by bucket or prefix from S3 Inventory reports in CSV, ORC or Parquet format in the local file system.",
+		Action:      inventoryAction,
+		Flags:       []cli.Flag{manifest, inventoryDepth},
+	}
+
+	maxRule := &cli.StringSliceFlag{
+		Name:  "max",
+		Usage: "set rule violated by buckets, such as \"bytes > 5TiB\" or \"5TiB\"",
+	}
+
+	maxTotal := &cli.StringFlag{
+		Name:  "max-total",
+		Usage: "set rule violated by total, such as \"50TiB\"",
+	}
+
+	anomalyLookback := &cli.IntFlag{
+		Name:  "lookback",
+		Usage: "set number of days of datapoints to examine",
+		Value: 30,
+	}
+
+	anomalyWindow := &cli.IntFlag{
+		Name:  "window",
+		Usage: "set number of trailing day-over-day changes to compare with",
+		Value: s3bytes.DefaultAnomalyWindow,
+	}
+
+	zScore := &cli.FloatFlag{
+		Name:  "z-score",
+		Usage: "set absolute z-score from which a change is anomalous",
+		Value: s3bytes.DefaultAnomalyZScore,
+	}
+
+	minChange := &cli.FloatFlag{
+		Name:  "min-change",
+		Usage: "set minimum absolute change in percent of an anomaly",
+	}
+
+	anomaly := &cli.BoolFlag{
+		Name:  "anomaly",
+		Usage: "regard anomalous day-over-day changes as violations",
+	}
+
+	// newDetector creates the anomaly detector from the flags
+	newDetector := func(cmd *cli.Command, opts ...s3bytes.AnomalyOption) (*s3bytes.AnomalyDetector, error) {
+		return s3bytes.NewAnomalyDetector(append([]s3bytes.AnomalyOption{
+			s3bytes.WithAnomalyWindow(cmd.Int(anomalyWindow.Name)),
+			s3bytes.WithZScore(cmd.Float(zScore.Name)),
+			s3bytes.WithMinChange(cmd.Float(minChange.Name)),
+		}, opts...)...)
+	}
+
+	runCheck := func(ctx context.Context, cmd *cli.Command) error {
+		// parse rules for buckets and total
+		rules := make([]*s3bytes.CheckRule, 0)
+		for _, s := range cmd.StringSlice(maxRule.Name) {
+			rule, err := s3bytes.ParseCheckRule(s)
+			if err != nil {
+				return err
+			}
+			rules = append(rules, rule)
+		}
+		var totalRule *s3bytes.CheckRule
+		if s := cmd.String(maxTotal.Name); s != "" {
+			rule, err := s3bytes.ParseCheckRule(s)
+			if err != nil {
+				return err
+			}
+			totalRule = rule
+		}
+		var detector *s3bytes.AnomalyDetector
+		if cmd.Bool(anomaly.Name) {
+			// only the latest change is examined so that a past anomaly does not fail every run
+			d, err := newDetector(cmd, s3bytes.WithLatestOnly(true))
+			if err != nil {
+				return err
+			}
+			detector = d
+		}
+		if len(rules) == 0 && totalRule == nil && detector == nil {
+			return errors.New("no check rules: specify --max, --max-total or --anomaly")
+		}
+
+		// set up the manager with the common options
+		man, v, err := setup(cmd)
+		if err != nil {
+			return err
+		}
+
+		// show progress of the regions on stderr
+		if err := report(cmd, man); err != nil {
+			return err
+		}
+
+		// run list operation, or anomaly detection over the series if enabled
+		var data *s3bytes.MetricData
+		if detector != nil {
+			data, err = man.ListAnomalies(ctx, cmd.Int(anomalyLookback.Name), detector)
+		} else {
+			data, err = man.List(ctx)
+		}
+		if err != nil {
+			return err
+		}
+		debug(man)
+
+		// sort metrics
+		s3bytes.SortMetrics(data)
+
+		// evaluate rules
+		result, err := s3bytes.Check(data, rules, totalRule)
+		if err != nil {
+			return err
+		}
+
+		// evaluate quotas, aggregate, render and notify of violating buckets
+		if _, err := present(ctx, w, v, result.Data, nil); err != nil {
+			return err
+		}
+
+		// logging violations
+		for _, violation := range result.Violations {
+			logger.Warn(violation.String())
+		}
+
+		// logging at process stop with the number of violations
+		logger.Info(
+			"stopped",
+			"total", humanize.Comma(data.Total),
+			"violations", len(result.Violations),
+		)
+
+		if !result.OK() {
+			messages := make([]string, len(result.Violations))
+			for i, violation := range result.Violations {
+				messages[i] = violation.String()
+			}
+			notify(ctx, v, &s3bytes.Notification{
+				Title:    fmt.Sprintf("s3bytes: %d violations found", len(result.Violations)),
+				Messages: messages,
+				Data:     result.Data,
+			})
+			return errViolation
+		}
+		return nil
+	}
+
+	// errors of the check subcommand exit with the status distinct from violations
+	checkAction := func(ctx context.Context, cmd *cli.Command) error {
+		return wrapCheckError(runCheck(ctx, cmd))
+	}
+
+	checkUsageError := func(_ context.Context, _ *cli.Command, err error, _ bool) error {
+		return wrapCheckError(err)
+	}
+
+	check := &cli.Command{
+		Name:         "check",
+		Usage:        "Check sizes against thresholds",
+		Description:  "Check sizes of buckets and their total against thresholds, and exit with 1 if any rule is violated or any anomaly is found, and 2 on errors.",
+		Action:       checkAction,
+		OnUsageError: checkUsageError,
+		Flags:        []cli.Flag{maxRule, maxTotal, anomaly, anomalyLookback, anomalyWindow, zScore, minChange},
+	}
+
+	lookback := &cli.IntFlag{
+		Name:  "lookback",
+		Usage: "set number of days of datapoints to fit",
+		Value: 90,
+	}
+
+	model := &cli.StringFlag{
+		Name:  "model",
+		Usage: "set forecast model",
+		Value: s3bytes.ForecastModelLinear.String(),
+	}
+
+	quota := &cli.StringFlag{
+		Name:  "quota",
+		Usage: "set quota to estimate date reached, such as \"5TiB\"",
+	}
+
+	forecastAction := func(ctx context.Context, cmd *cli.Command) error {
+		// parse forecast model passed as string
+		modelValue, err := s3bytes.ParseForecastModel(cmd.String(model.Name))
+		if err != nil {
+			return err
+		}
+
+		// parse quota passed as string if specified
+		var quotaValue float64
+		if s := cmd.String(quota.Name); s != "" {
+			n, err := humanize.ParseBytes(s)
+			if err != nil {
+				return fmt.Errorf("invalid quota: %q", s)
+			}
+			quotaValue = float64(n)
+		}
+
+		// set up the manager with the common options
+		man, v, err := setup(cmd)
+		if err != nil {
+			return err
+		}
+
+		// show progress of the regions on stderr
+		if err := report(cmd, man); err != nil {
+			return err
+		}
+
+		// logging forecast settings
+		logger.Info(
+			"forecast",
+			"lookback", cmd.Int(lookback.Name),
+			"model", modelValue,
+			"quota", quotaValue,
+		)
+
+		// run forecast
+		data, err := man.ListForecast(ctx, cmd.Int(lookback.Name), modelValue, quotaValue)
+		if err != nil {
+			return err
+		}
+		debug(man)
+
+		// sort metrics
+		s3bytes.SortMetrics(data)
+
+		// evaluate quotas, aggregate, render and notify of the result
+		if data, err = present(ctx, w, v, data, nil); err != nil {
+			return err
+		}
+
+		// logging at process stop with total bytes
+		logger.Info(
+			"stopped",
+			"total", humanize.Comma(data.Total),
+		)
+
+		return nil
+	}
+
+	forecast := &cli.Command{
+		Name:        "forecast",
+		Usage:       "Forecast sizes from historical datapoints",
+		Description: "Fit a trend to the daily datapoints of buckets, and project sizes 30, 90 and 365 days ahead and the date a quota will be reached.",
+		Action:      forecastAction,
+		Flags:       []cli.Flag{lookback, model, quota},
+	}
+
+	anomaliesAction := func(ctx context.Context, cmd *cli.Command) error {
+		// create anomaly detector from the flags
+		detector, err := newDetector(cmd)
+		if err != nil {
+			return err
+		}
+
+		// set up the manager with the common options
+		man, v, err := setup(cmd)
+		if err != nil {
+			return err
+		}
+
+		// show progress of the regions on stderr
+		if err := report(cmd, man); err != nil {
+			return err
+		}
+
+		// logging anomaly detection settings
+		logger.Info(
+			"anomalies",
+			"lookback", cmd.Int(anomalyLookback.Name),
+			"window", cmd.Int(anomalyWindow.Name),
+			"zScore", cmd.Float(zScore.Name),
+			"minChange", cmd.Float(minChange.Name),
+		)
+
+		// run anomaly detection
+		data, err := man.ListAnomalies(ctx, cmd.Int(anomalyLookback.Name), detector)
+		if err != nil {
+			return err
+		}
+		debug(man)
+
+		// sort metrics
+		s3bytes.SortMetrics(data)
+
+		// count anomalies before the metrics are aggregated
+		n := 0
+		for _, metric := range data.Metrics {
+			if metric.IsAnomalous() {
+				n++
+			}
+		}
+
+		// evaluate quotas, aggregate, render and notify of the result
+		if data, err = present(ctx, w, v, data, nil); err != nil {
+			return err
+		}
+
+		// logging at process stop with the number of anomalies
+		logger.Info(
+			"stopped",
+			"total", humanize.Comma(data.Total),
+			"anomalies", n,
+		)
+
+		return nil
+	}
+
+	anomalies := &cli.Command{
+		Name:        "anomalies",
+		Usage:       "Detect sudden growth or shrinkage from historical datapoints",
+		Description: "Compare each day-over-day change of buckets with the trailing changes, and report the most anomalous change with its date and magnitude.",
+		Action:      anomaliesAction,
+		Flags:       []cli.Flag{anomalyLookback, anomalyWindow, zScore, minChange},
+	}
+
+	interval := &cli.DurationFlag{
+		Name:    "interval",
+		Aliases: []string{"n"},
+		Usage:   "set interval between refreshes",
+		Value:   5 * time.Minute,
+	}
+
+	watchAction := func(ctx context.Context, cmd *cli.Command) error {
+		// validate interval between refreshes
+		d := cmd.Duration(interval.Name)
+		if d < time.Second {
+			return fmt.Errorf("invalid interval: %s, must be at least 1s", d)
+		}
+
+		// set up the manager with the common options
+		man, v, err := setup(cmd)
+		if err != nil {
+			return err
+		}
+
+		// show progress of the regions on stderr
+		if err := report(cmd, man); err != nil {
+			return err
+		}
+
+		// charts and files cannot be redrawn in place
+		switch v.outputType {
+		case s3bytes.OutputTypeChart, s3bytes.OutputTypeHTML, s3bytes.OutputTypeSVG, s3bytes.OutputTypePNG:
+			return fmt.Errorf("unsupported output type for watch: %q", v.outputType)
+		}
+
+		// stop refreshing on interrupt
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		// logging watch settings
+		logger.Info(
+			"watch",
+			"interval", d,
+		)
+
+		// refresh the result at every interval with the delta from the previous refresh
+		var (
+			prev *s3bytes.MetricData
+			tty  = isTerminal(w)
+		)
+		refresh := func(ctx context.Context) error {
+			data, err := man.List(ctx)
+			if err != nil {
+				return err
+			}
+			debug(man)
+			s3bytes.SortMetrics(data)
+			var delta int64
+			compare := func(data *s3bytes.MetricData) {
+				delta = s3bytes.CompareMetrics(data, prev)
+				prev = data
+				if tty {
+					fmt.Fprintf(w, "%sEvery %s: %s\n\n", clearScreen, d, time.Now().Format(time.DateTime))
+				}
+			}
+			if data, err = present(ctx, w, v, data, compare); err != nil {
+				return err
+			}
+			logger.Info(
+				"refreshed",
+				"total", humanize.Comma(data.Total),
+				"delta", humanize.Comma(delta),
+			)
+			return nil
+		}
+		if err := watch(ctx, d, refresh); err != nil {
+			return err
+		}
+
+		// logging at process stop
+		logger.Info("stopped")
+
+		return nil
+	}
+
+	watchCmd := &cli.Command{
+		Name:        "watch",
+		Usage:       "Refresh sizes at intervals",
+		Description: "Rerun the listing at every interval, redraw the table in place with the delta from the previous refresh, and exit on Ctrl-C.",
+		Action:      watchAction,
+		Flags:       []cli.Flag{interval},
+	}
+
+	tuiDepth := &cli.IntFlag{
+		Name:    "depth",
+		Aliases: []string{"d"},
+		Usage:   "set depth of prefixes to drill into",
+		Value:   3,
+	}
+
+	tuiAction := func(ctx context.Context, cmd *cli.Command) error {
+		// validate depth of prefixes
+		d := cmd.Int(tuiDepth.Name)
+		if d < 1 {
+			return fmt.Errorf("invalid depth: %d", d)
+		}
+
+		// the interactive view needs a terminal to draw on
+		if !isTerminal(w) {
+			return errors.New("tui requires a terminal")
+		}
+
+		// set up the manager with the common options
+		man, _, err := setup(cmd)
+		if err != nil {
+			return err
+		}
+
+		// stop fetching in the background on exit
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		// logging tui settings
+		logger.Info(
+			"tui",
+			"regions", man.Regions(),
+			"depth", d,
+		)
+
+		// run the interactive view until quit
+		p := tea.NewProgram(newTUIModel(ctx, man, d), tea.WithContext(ctx), tea.WithOutput(w), tea.WithAltScreen())
+		if _, err := p.Run(); err != nil {
+			return err
+		}
+
+		// logging at process stop
+		logger.Info("stopped")
+
+		return nil
+	}
+
+	tuiCmd := &cli.Command{
+		Name:        "tui",
+		Usage:       "Browse sizes interactively",
+		Description: "Fetch all regions in the background with the progress of each, and browse them with sorting, filtering and drill-down from region to bucket to prefix.",
+		Action:      tuiAction,
+		Flags:       []cli.Flag{tuiDepth},
+	}
+
 	return &cli.Command{
 		Name:                  name,
 		Version:               s3bytes.Version(),
@@ -212,11 +1064,141 @@ func newCmd(w, ew io.Writer) *cli.Command {
 		ErrWriter:             ew,
 		Before:                before,
 		Action:                action,
-		Flags:                 []cli.Flag{profile, loglevel, region, prefix, filter, metricName, storageType, output},
+		Commands:              []*cli.Command{prefixes, inventory, check, forecast, anomalies, watchCmd, tuiCmd},
+		Flags:                 []cli.Flag{configPath, presetName, profile, loglevel, noProgress, region, prefix, filter, metricName, storageType, filterID, tag, enrich, groupBy, scan, noData, output, chartType, chartOut, chartTop, chartMinPercent, policy, webhookURL, webhookTemplate, webhookDryRun, noOpen},
 		Metadata:              map[string]any{},
 	}
 }
 
+// clearScreen moves the cursor to the top left and clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
+// watch runs the refresh immediately and then at every interval until the context is canceled.
+// The cancellation is regarded as a clean exit even if it interrupts a refresh.
+func watch(ctx context.Context, interval time.Duration, refresh func(context.Context) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for ctx.Err() == nil {
+		if err := refresh(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// isTerminal reports whether the writer is a terminal, in which the result can be redrawn in place.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// view holds the policy to evaluate, the notifier, the field to group by, and the options to render the result.
+type view struct {
+	policy     *s3bytes.Policy
+	notifier   *s3bytes.Notifier
+	groupBy    string
+	outputType s3bytes.OutputType
+	opts       []s3bytes.RendererOption
+}
+
+// present evaluates the policy, aggregates the metrics by the group-by field, renders the result,
+// and notifies of the rows exceeding the policy limits after it is rendered, in the same way for every command.
+// The hook is called with the data to render just before rendering if specified, and the rendered data is returned.
+func present(ctx context.Context, w io.Writer, v *view, data *s3bytes.MetricData, hook func(*s3bytes.MetricData)) (*s3bytes.MetricData, error) {
+	breach, err := evaluate(v, data)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = group(v, data); err != nil {
+		return nil, err
+	}
+	if hook != nil {
+		hook(data)
+	}
+	ren := s3bytes.NewRenderer(w, data, v.outputType, v.opts...)
+	if err := ren.Render(); err != nil {
+		return nil, err
+	}
+	notify(ctx, v, breach)
+	return data, nil
+}
+
+// evaluate annotates the metrics with the compliance status if the policy is specified,
+// logs the number of metrics for each status, and returns the notification of the rows exceeding the limits if any.
+func evaluate(v *view, data *s3bytes.MetricData) (*s3bytes.Notification, error) {
+	if v.policy == nil {
+		return nil, nil
+	}
+	report, err := v.policy.Evaluate(data)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info(
+		"policy",
+		"ok", report.OK,
+		"warning", report.Warning,
+		"critical", report.Critical,
+		"none", report.None,
+	)
+	n := report.Warning + report.Critical
+	if n == 0 {
+		return nil, nil
+	}
+	breached := &s3bytes.MetricData{
+		Header: data.Header,
+	}
+	for _, metric := range data.Metrics {
+		if metric.Compliance == s3bytes.ComplianceStatusWarning || metric.Compliance == s3bytes.ComplianceStatusCritical {
+			breached.Metrics = append(breached.Metrics, metric)
+			breached.Total += int64(metric.Value)
+		}
+	}
+	return &s3bytes.Notification{
+		Title: fmt.Sprintf("s3bytes: %d rows exceed policy limits", n),
+		Data:  breached,
+	}, nil
+}
+
+// group aggregates the metrics by the field if group-by is specified, and sorts the groups.
+func group(v *view, data *s3bytes.MetricData) (*s3bytes.MetricData, error) {
+	if v.groupBy == "" {
+		return data, nil
+	}
+	grouped, err := s3bytes.GroupMetrics(data, v.groupBy)
+	if err != nil {
+		return nil, err
+	}
+	s3bytes.SortMetrics(grouped)
+	return grouped, nil
+}
+
+// notify posts the notification if both the notifier and the notification are specified.
+// A failure is logged rather than returned, so that it neither suppresses the rendered result nor overrides the exit status.
+func notify(ctx context.Context, v *view, notification *s3bytes.Notification) {
+	if v.notifier == nil || notification == nil {
+		return
+	}
+	if err := v.notifier.Notify(ctx, notification); err != nil {
+		logger.Error("failed to notify", "title", notification.Title, "error", err)
+		return
+	}
+	logger.Info("notified", "title", notification.Title)
+}
+
 func debug(man *s3bytes.Manager) {
 	logger.Debug("ManagerState: " + man.String())
 }
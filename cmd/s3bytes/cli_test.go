@@ -2,16 +2,80 @@ package main
 
 import (
 	"context"
+	"errors"
 	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/nekrassov01/s3bytes"
+	"github.com/urfave/cli/v3"
 )
 
+// writeConfig writes the config file to a temporary directory and returns its path.
+func writeConfig(t *testing.T, s string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(s), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
 func Test_cli(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	conf := writeConfig(t, `
+presets:
+  bad-output:
+    output: unknown
+  bad-region:
+    regions: [unknown]
+  bad-metric:
+    metric-name: unknown
+`)
 	tests := []struct {
 		name    string
 		args    []string
 		wantErr bool
 	}{
+		{
+			name:    "config not found",
+			args:    []string{name, "--config", "unknown.yaml"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid config",
+			args:    []string{name, "--config", writeConfig(t, "unknown: true")},
+			wantErr: true,
+		},
+		{
+			name:    "preset without config",
+			args:    []string{name, "--preset", "unknown"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown preset",
+			args:    []string{name, "--config", conf, "--preset", "unknown"},
+			wantErr: true,
+		},
+		{
+			name:    "preset with unknown output type",
+			args:    []string{name, "--config", conf, "--preset", "bad-output"},
+			wantErr: true,
+		},
+		{
+			name:    "preset with unknown region",
+			args:    []string{name, "--config", conf, "--preset", "bad-region"},
+			wantErr: true,
+		},
+		{
+			name:    "preset with unknown metric name",
+			args:    []string{name, "--config", conf, "--preset", "bad-metric"},
+			wantErr: true,
+		},
 		{
 			name:    "unknown profile",
 			args:    []string{name, "-p", "unknown"},
@@ -37,11 +101,156 @@ func Test_cli(t *testing.T) {
 			args:    []string{name, "-s", "unknown"},
 			wantErr: true,
 		},
+		{
+			name:    "unknown scan mode",
+			args:    []string{name, "--scan", "unknown"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown no-data mode",
+			args:    []string{name, "--no-data", "unknown"},
+			wantErr: true,
+		},
 		{
 			name:    "unknown output type",
 			args:    []string{name, "-o", "unknown"},
 			wantErr: true,
 		},
+		{
+			name:    "unknown chart type",
+			args:    []string{name, "--chart-type", "unknown"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid chart top",
+			args:    []string{name, "--chart-top", "0"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid chart min percent",
+			args:    []string{name, "--chart-min-percent", "100"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid filter id",
+			args:    []string{name, "--metric-name", "AllRequests", "--filter-id", "invalid/id"},
+			wantErr: true,
+		},
+		{
+			name:    "request metric with scan",
+			args:    []string{name, "--metric-name", "AllRequests", "--scan", "fallback"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid tag key",
+			args:    []string{name, "--tag", strings.Repeat("a", 129)},
+			wantErr: true,
+		},
+		{
+			name:    "policy not found",
+			args:    []string{name, "--policy", "unknown.json"},
+			wantErr: true,
+		},
+		{
+			name:    "webhook template not found",
+			args:    []string{name, "--webhook-dry-run", "--webhook-template", "unknown.tmpl"},
+			wantErr: true,
+		},
+		{
+			name:    "check no rules",
+			args:    []string{name, "check"},
+			wantErr: true,
+		},
+		{
+			name:    "check invalid rule",
+			args:    []string{name, "check", "--max", "bytes > unknown"},
+			wantErr: true,
+		},
+		{
+			name:    "check unknown field",
+			args:    []string{name, "check", "--max", "size > 5TiB"},
+			wantErr: true,
+		},
+		{
+			name:    "check invalid total rule",
+			args:    []string{name, "check", "--max-total", "unknown"},
+			wantErr: true,
+		},
+		{
+			name:    "forecast unknown model",
+			args:    []string{name, "forecast", "--model", "unknown"},
+			wantErr: true,
+		},
+		{
+			name:    "forecast invalid quota",
+			args:    []string{name, "forecast", "--quota", "unknown"},
+			wantErr: true,
+		},
+		{
+			name:    "forecast invalid lookback",
+			args:    []string{name, "forecast", "--lookback", "1"},
+			wantErr: true,
+		},
+		{
+			name:    "anomalies invalid window",
+			args:    []string{name, "anomalies", "--window", "1"},
+			wantErr: true,
+		},
+		{
+			name:    "anomalies invalid z-score",
+			args:    []string{name, "anomalies", "--z-score", "0"},
+			wantErr: true,
+		},
+		{
+			name:    "anomalies lookback shorter than window",
+			args:    []string{name, "anomalies", "--lookback", "7"},
+			wantErr: true,
+		},
+		{
+			name:    "check anomaly invalid min change",
+			args:    []string{name, "check", "--anomaly", "--min-change", "-1"},
+			wantErr: true,
+		},
+		{
+			name:    "watch invalid interval",
+			args:    []string{name, "watch", "--interval", "500ms"},
+			wantErr: true,
+		},
+		{
+			name:    "watch unsupported output type",
+			args:    []string{name, "watch", "-o", "html"},
+			wantErr: true,
+		},
+		{
+			name:    "tui invalid depth",
+			args:    []string{name, "tui", "--depth", "0"},
+			wantErr: true,
+		},
+		{
+			name:    "tui without terminal",
+			args:    []string{name, "tui"},
+			wantErr: true,
+		},
+		{
+			name:    "prefixes unknown output type",
+			args:    []string{name, "prefixes", "-o", "unknown", "-b", "bucket0"},
+			wantErr: true,
+		},
+		{
+			name:    "prefixes no target buckets",
+			args:    []string{name, "prefixes"},
+			wantErr: true,
+		},
+		{
+			name:    "inventory no manifests",
+			args:    []string{name, "inventory"},
+			wantErr: true,
+		},
+		{
+			name:    "inventory manifest not found",
+			args:    []string{name, "inventory", "-M", "unknown"},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -53,3 +262,533 @@ func Test_cli(t *testing.T) {
 		})
 	}
 }
+
+func Test_present(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte("rules:\n  - name: large\n    bytes:\n      warning: 1KiB\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := s3bytes.LoadPolicy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newData := func() *s3bytes.MetricData {
+		return &s3bytes.MetricData{
+			Header: []string{"BucketName", "Region", "Value"},
+			Metrics: []*s3bytes.Metric{
+				{
+					BucketName:  "bucket0",
+					Region:      "ap-northeast-1",
+					MetricName:  s3bytes.MetricNameBucketSizeBytes,
+					StorageType: s3bytes.StorageTypeStandardStorage,
+					Value:       2048,
+				},
+				{
+					BucketName:  "bucket1",
+					Region:      "ap-northeast-1",
+					MetricName:  s3bytes.MetricNameBucketSizeBytes,
+					StorageType: s3bytes.StorageTypeStandardStorage,
+					Value:       1,
+				},
+				{
+					BucketName:  "bucket2",
+					Region:      "us-east-1",
+					MetricName:  s3bytes.MetricNameBucketSizeBytes,
+					StorageType: s3bytes.StorageTypeStandardStorage,
+					Value:       4096,
+				},
+			},
+			Total: 6145,
+		}
+	}
+	tests := []struct {
+		name       string
+		groupBy    string
+		want       string
+		wantNotify bool
+		wantErr    bool
+	}{
+		{
+			name:       "policy",
+			groupBy:    "",
+			want:       "BucketName\tRegion\tValue\tCompliance\tRule\nbucket0\tap-northeast-1\t2048\twarning\tlarge\nbucket1\tap-northeast-1\t1\tok\tlarge\nbucket2\tus-east-1\t4096\twarning\tlarge\n",
+			wantNotify: true,
+			wantErr:    false,
+		},
+		{
+			name:       "group",
+			groupBy:    "region",
+			want:       "Group\tMetricName\tStorageType\tValue\nus-east-1\tBucketSizeBytes\tStandardStorage\t4096\nap-northeast-1\tBucketSizeBytes\tStandardStorage\t2049\n",
+			wantNotify: true,
+			wantErr:    false,
+		},
+		{
+			name:       "invalid group-by",
+			groupBy:    "unknown",
+			want:       "",
+			wantNotify: false,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, payload strings.Builder
+			notifier, err := s3bytes.NewNotifier("", s3bytes.WithDryRun(&payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := &view{
+				policy:     policy,
+				notifier:   notifier,
+				groupBy:    tt.groupBy,
+				outputType: s3bytes.OutputTypeTSV,
+			}
+			hooked := false
+			_, err = present(context.Background(), &out, v, newData(), func(*s3bytes.MetricData) { hooked = true })
+			if (err != nil) != tt.wantErr {
+				t.Errorf("present() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if hooked == tt.wantErr {
+				t.Errorf("present() hooked = %v, want %v", hooked, !tt.wantErr)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("present() = %q, want %q", got, tt.want)
+			}
+			if got := payload.Len() > 0; got != tt.wantNotify {
+				t.Errorf("present() notified = %v, want %v", got, tt.wantNotify)
+			}
+		})
+	}
+}
+
+func Test_watch(t *testing.T) {
+	type args struct {
+		stopAt int
+		failAt int
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantCount int
+		wantErr   bool
+	}{
+		{
+			name: "canceled after refreshes",
+			args: args{
+				stopAt: 3,
+			},
+			wantCount: 3,
+			wantErr:   false,
+		},
+		{
+			name: "refresh error",
+			args: args{
+				failAt: 2,
+			},
+			wantCount: 2,
+			wantErr:   true,
+		},
+		{
+			name: "refresh interrupted by cancellation",
+			args: args{
+				stopAt: 2,
+				failAt: 2,
+			},
+			wantCount: 2,
+			wantErr:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			count := 0
+			refresh := func(context.Context) error {
+				count++
+				if count == tt.args.stopAt {
+					cancel()
+				}
+				if count == tt.args.failAt {
+					return errors.New("error")
+				}
+				return nil
+			}
+			err := watch(ctx, time.Millisecond, refresh)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("watch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if count != tt.wantCount {
+				t.Errorf("watch() refreshed %d times, want %d", count, tt.wantCount)
+			}
+		})
+	}
+}
+
+func Test_isTerminal(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tests := []struct {
+		name string
+		w    io.Writer
+		want bool
+	}{
+		{
+			name: "regular file",
+			w:    f,
+			want: false,
+		},
+		{
+			name: "not a file",
+			w:    io.Discard,
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTerminal(tt.w); got != tt.want {
+				t.Errorf("isTerminal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_loadConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, name), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, configFileName), []byte("defaults:\n  output: tsv\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	type args struct {
+		path string
+	}
+	tests := []struct {
+		name       string
+		configHome string
+		args       args
+		want       *config
+		wantErr    bool
+	}{
+		{
+			name:       "default path",
+			configHome: dir,
+			args:       args{},
+			want:       &config{Defaults: preset{Output: "tsv"}},
+			wantErr:    false,
+		},
+		{
+			name:       "default path not found",
+			configHome: t.TempDir(),
+			args:       args{},
+			want:       &config{},
+			wantErr:    false,
+		},
+		{
+			name:       "explicit path",
+			configHome: t.TempDir(),
+			args: args{
+				path: writeConfig(t, `
+defaults:
+  regions: [us-east-1, ap-northeast-1]
+presets:
+  logs:
+    profile: prod
+    prefix: logs-
+    filter: "value > 1073741824"
+    metric-name: NumberOfObjects
+    storage-type: AllStorageTypes
+`),
+			},
+			want: &config{
+				Defaults: preset{Regions: []string{"us-east-1", "ap-northeast-1"}},
+				Presets: map[string]*preset{
+					"logs": {
+						Profile:     "prod",
+						Prefix:      "logs-",
+						Filter:      "value > 1073741824",
+						MetricName:  "NumberOfObjects",
+						StorageType: "AllStorageTypes",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:       "empty file",
+			configHome: t.TempDir(),
+			args: args{
+				path: writeConfig(t, ""),
+			},
+			want:    &config{},
+			wantErr: false,
+		},
+		{
+			name:       "explicit path not found",
+			configHome: dir,
+			args: args{
+				path: filepath.Join(t.TempDir(), "unknown.yaml"),
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:       "unknown key",
+			configHome: t.TempDir(),
+			args: args{
+				path: writeConfig(t, "defaults:\n  region: us-east-1\n"),
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:       "malformed",
+			configHome: t.TempDir(),
+			args: args{
+				path: writeConfig(t, "defaults: ["),
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", tt.configHome)
+			got, err := loadConfig(tt.args.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadConfig() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_config_resolve(t *testing.T) {
+	c := &config{
+		Defaults: preset{
+			Regions: []string{"us-east-1"},
+			Output:  "tsv",
+		},
+		Presets: map[string]*preset{
+			"logs": {
+				Profile: "prod",
+				Prefix:  "logs-",
+				Output:  "json",
+			},
+			"tokyo": {
+				Regions: []string{"ap-northeast-1"},
+			},
+		},
+	}
+	type args struct {
+		name string
+	}
+	tests := []struct {
+		name    string
+		config  *config
+		args    args
+		want    *preset
+		wantErr bool
+	}{
+		{
+			name:   "defaults",
+			config: c,
+			args:   args{},
+			want: &preset{
+				Regions: []string{"us-east-1"},
+				Output:  "tsv",
+			},
+			wantErr: false,
+		},
+		{
+			name:   "preset overrides defaults",
+			config: c,
+			args: args{
+				name: "logs",
+			},
+			want: &preset{
+				Profile: "prod",
+				Regions: []string{"us-east-1"},
+				Prefix:  "logs-",
+				Output:  "json",
+			},
+			wantErr: false,
+		},
+		{
+			name:   "preset overrides regions",
+			config: c,
+			args: args{
+				name: "tokyo",
+			},
+			want: &preset{
+				Regions: []string{"ap-northeast-1"},
+				Output:  "tsv",
+			},
+			wantErr: false,
+		},
+		{
+			name:   "unknown preset",
+			config: c,
+			args: args{
+				name: "unknown",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:   "no presets",
+			config: &config{},
+			args: args{
+				name: "logs",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.resolve(tt.args.name)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("config.resolve() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("config.resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_preset_apply(t *testing.T) {
+	p := &preset{
+		Profile: "prod",
+		Regions: []string{"ap-northeast-1", "us-east-1"},
+		Prefix:  "logs-",
+		Output:  "json",
+	}
+	type want struct {
+		profile string
+		regions []string
+		prefix  string
+		filter  string
+		output  string
+	}
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want want
+	}{
+		{
+			name: "preset",
+			args: []string{name},
+			want: want{
+				profile: "prod",
+				regions: []string{"ap-northeast-1", "us-east-1"},
+				prefix:  "logs-",
+				output:  "json",
+			},
+		},
+		{
+			name: "flags take precedence",
+			args: []string{name, "-r", "eu-west-1", "-P", "app-", "-o", "tsv"},
+			want: want{
+				profile: "prod",
+				regions: []string{"eu-west-1"},
+				prefix:  "app-",
+				output:  "tsv",
+			},
+		},
+		{
+			name: "flags after subcommand take precedence",
+			args: []string{name, "sub", "-r", "eu-west-1"},
+			want: want{
+				profile: "prod",
+				regions: []string{"eu-west-1"},
+				prefix:  "logs-",
+				output:  "json",
+			},
+		},
+		{
+			name: "environment variables take precedence",
+			args: []string{name},
+			env: map[string]string{
+				"AWS_PROFILE":         "dev",
+				"S3BYTES_OUTPUT_TYPE": "markdown",
+			},
+			want: want{
+				profile: "dev",
+				regions: []string{"ap-northeast-1", "us-east-1"},
+				prefix:  "logs-",
+				output:  "markdown",
+			},
+		},
+		{
+			name: "flags take precedence over environment variables",
+			args: []string{name, "-o", "tsv"},
+			env: map[string]string{
+				"S3BYTES_OUTPUT_TYPE": "markdown",
+			},
+			want: want{
+				profile: "prod",
+				regions: []string{"ap-northeast-1", "us-east-1"},
+				prefix:  "logs-",
+				output:  "tsv",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AWS_PROFILE", "")
+			t.Setenv("S3BYTES_OUTPUT_TYPE", "")
+			os.Unsetenv("AWS_PROFILE")
+			os.Unsetenv("S3BYTES_OUTPUT_TYPE")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			var got want
+			action := func(_ context.Context, cmd *cli.Command) error {
+				got = want{
+					profile: cmd.String("profile"),
+					regions: cmd.StringSlice("region"),
+					prefix:  cmd.String("prefix"),
+					filter:  cmd.String("filter"),
+					output:  cmd.String("output"),
+				}
+				return nil
+			}
+			cmd := &cli.Command{
+				Name: name,
+				Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+					return ctx, p.apply(cmd)
+				},
+				Action: action,
+				Commands: []*cli.Command{
+					{Name: "sub", Action: action},
+				},
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "profile", Aliases: []string{"p"}, Sources: cli.EnvVars("AWS_PROFILE")},
+					&cli.StringSliceFlag{Name: "region", Aliases: []string{"r"}, Value: []string{"us-west-2"}},
+					&cli.StringFlag{Name: "prefix", Aliases: []string{"P"}},
+					&cli.StringFlag{Name: "filter", Aliases: []string{"f"}},
+					&cli.StringFlag{Name: "metric-name", Aliases: []string{"m"}, Value: "BucketSizeBytes"},
+					&cli.StringFlag{Name: "storage-type", Aliases: []string{"s"}, Value: "StandardStorage"},
+					&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Sources: cli.EnvVars("S3BYTES_OUTPUT_TYPE"), Value: "compressedtext"},
+				},
+			}
+			if err := cmd.Run(context.Background(), tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("preset.apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// configFileName is the path of the config file relative to the config directory.
+var configFileName = filepath.Join(name, "config.yaml")
+
+// config represents the config file, which holds the defaults of the flags
+// and the named presets overriding the defaults.
+type config struct {
+	Defaults preset             `yaml:"defaults"`
+	Presets  map[string]*preset `yaml:"presets"`
+}
+
+// preset represents the values of the flags. Empty fields are left to the other sources.
+type preset struct {
+	Profile     string   `yaml:"profile"`
+	Regions     []string `yaml:"regions"`
+	Prefix      string   `yaml:"prefix"`
+	Filter      string   `yaml:"filter"`
+	MetricName  string   `yaml:"metric-name"`
+	StorageType string   `yaml:"storage-type"`
+	Output      string   `yaml:"output"`
+}
+
+// defaultConfigPath returns the path of the config file in $XDG_CONFIG_HOME,
+// or in ~/.config if it is not set.
+func defaultConfigPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, configFileName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", configFileName), nil
+}
+
+// loadConfig reads the config file at the path. If the path is empty, the file at the default
+// path is read, and an empty config is returned if it does not exist.
+func loadConfig(path string) (*config, error) {
+	optional := path == ""
+	if optional {
+		p, err := defaultConfigPath()
+		if err != nil {
+			return &config{}, nil
+		}
+		path = p
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if optional && errors.Is(err, fs.ErrNotExist) {
+			return &config{}, nil
+		}
+		return nil, err
+	}
+	return parseConfig(b)
+}
+
+// parseConfig parses the config file. Unknown keys are rejected to catch typos.
+func parseConfig(b []byte) (*config, error) {
+	c := &config{}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid config file: %w", err)
+	}
+	return c, nil
+}
+
+// resolve returns the defaults overridden by the non-empty fields of the named preset.
+// If the name is empty, the defaults are returned as they are.
+func (c *config) resolve(name string) (*preset, error) {
+	p := c.Defaults
+	if name == "" {
+		return &p, nil
+	}
+	v, ok := c.Presets[name]
+	if !ok || v == nil {
+		if len(c.Presets) == 0 {
+			return nil, fmt.Errorf("preset not found: %q", name)
+		}
+		return nil, fmt.Errorf("preset not found: %q, available presets: %s", name, strings.Join(slices.Sorted(maps.Keys(c.Presets)), ", "))
+	}
+	if v.Profile != "" {
+		p.Profile = v.Profile
+	}
+	if len(v.Regions) > 0 {
+		p.Regions = v.Regions
+	}
+	if v.Prefix != "" {
+		p.Prefix = v.Prefix
+	}
+	if v.Filter != "" {
+		p.Filter = v.Filter
+	}
+	if v.MetricName != "" {
+		p.MetricName = v.MetricName
+	}
+	if v.StorageType != "" {
+		p.StorageType = v.StorageType
+	}
+	if v.Output != "" {
+		p.Output = v.Output
+	}
+	return &p, nil
+}
+
+// apply sets the values of the preset to the flags of the command that are set neither
+// on the command line nor by the environment variables, so that the precedence is
+// the command line, the environment variables, the preset, the defaults of the config file,
+// and the defaults of the flags in this order.
+func (p *preset) apply(cmd *cli.Command) error {
+	values := []struct {
+		flag   string
+		values []string
+	}{
+		{"profile", []string{p.Profile}},
+		{"region", p.Regions},
+		{"prefix", []string{p.Prefix}},
+		{"filter", []string{p.Filter}},
+		{"metric-name", []string{p.MetricName}},
+		{"storage-type", []string{p.StorageType}},
+		{"output", []string{p.Output}},
+	}
+	for _, v := range values {
+		if cmd.IsSet(v.flag) {
+			continue
+		}
+		for _, value := range v.values {
+			if value == "" {
+				continue
+			}
+			if err := cmd.Set(v.flag, value); err != nil {
+				return fmt.Errorf("invalid %s in config file: %w", v.flag, err)
+			}
+		}
+	}
+	return nil
+}
@@ -2,14 +2,39 @@ package main
 
 import (
 	"context"
+	"errors"
 	"os"
 )
 
+const (
+	exitCodeOK         = 0
+	exitCodeError      = 1
+	exitCodeViolation  = 1
+	exitCodeCheckError = 2
+)
+
 func main() {
 	ctx := context.Background()
 	cmd := newCmd(os.Stdout, os.Stderr)
-	if err := cmd.Run(ctx, os.Args); err != nil {
+	err := cmd.Run(ctx, os.Args)
+	if err != nil && !errors.Is(err, errViolation) {
 		logger.Error(err.Error())
-		os.Exit(1)
+	}
+	os.Exit(exitCode(err))
+}
+
+// exitCode returns the exit code for the error: 0 if no error, 1 if the check subcommand
+// found violations, 2 if the check subcommand failed, and 1 on errors of the other commands.
+func exitCode(err error) int {
+	var checkErr *checkError
+	switch {
+	case err == nil:
+		return exitCodeOK
+	case errors.Is(err, errViolation):
+		return exitCodeViolation
+	case errors.As(err, &checkErr):
+		return exitCodeCheckError
+	default:
+		return exitCodeError
 	}
 }
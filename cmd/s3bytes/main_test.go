@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func Test_exitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{
+			name: "ok",
+			err:  nil,
+			want: exitCodeOK,
+		},
+		{
+			name: "violation",
+			err:  errViolation,
+			want: exitCodeViolation,
+		},
+		{
+			name: "wrapped violation",
+			err:  fmt.Errorf("check: %w", errViolation),
+			want: exitCodeViolation,
+		},
+		{
+			name: "check error",
+			err:  wrapCheckError(errors.New("error")),
+			want: exitCodeCheckError,
+		},
+		{
+			name: "check violation",
+			err:  wrapCheckError(errViolation),
+			want: exitCodeViolation,
+		},
+		{
+			name: "error",
+			err:  errors.New("error"),
+			want: exitCodeError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_exitCode_commands(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{
+			name: "check error",
+			args: []string{name, "check"},
+			want: exitCodeCheckError,
+		},
+		{
+			name: "check usage error",
+			args: []string{name, "check", "--unknown"},
+			want: exitCodeCheckError,
+		},
+		{
+			name: "usage error",
+			args: []string{name, "--unknown"},
+			want: exitCodeError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newCmd(io.Discard, io.Discard).Run(context.Background(), tt.args)
+			if got := exitCode(err); got != tt.want {
+				t.Errorf("exitCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/nekrassov01/s3bytes"
+)
+
+// clearLine moves the cursor to the beginning of the line and clears the line.
+const clearLine = "\r\033[K"
+
+// progress draws the progress of the regions on a line of the writer. The line is redrawn
+// on each event of the manager, and cleared when all regions are done so that it does not
+// remain above the result. The regions that fail without starting are counted as done
+// but not as running.
+type progress struct {
+	mu      sync.Mutex
+	w       io.Writer
+	regions int
+	running map[string]bool
+	done    int
+	failed  int
+	buckets int
+	batches int
+}
+
+// newProgress creates a new progress for the number of the regions.
+func newProgress(w io.Writer, regions int) *progress {
+	return &progress{
+		w:       w,
+		regions: regions,
+		running: make(map[string]bool),
+	}
+}
+
+// observe updates the progress with the event and redraws the line.
+// The counts are reset when a region starts after all regions are done,
+// so that the progress can be reused for the repeated runs.
+func (p *progress) observe(event s3bytes.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch event.Type {
+	case s3bytes.EventTypeRegionStarted:
+		if p.done >= p.regions {
+			p.done, p.failed, p.buckets, p.batches = 0, 0, 0, 0
+			clear(p.running)
+		}
+		p.running[event.Region] = true
+	case s3bytes.EventTypeBucketsListed:
+		p.buckets += event.Buckets
+	case s3bytes.EventTypeBatchSent:
+		p.batches++
+	case s3bytes.EventTypeRegionFinished:
+		delete(p.running, event.Region)
+		p.done++
+	case s3bytes.EventTypeRegionFailed:
+		delete(p.running, event.Region)
+		p.done++
+		p.failed++
+	}
+	if p.done >= p.regions {
+		fmt.Fprint(p.w, clearLine)
+		return
+	}
+	fmt.Fprintf(p.w, "%sregions: %d/%d done, %d running", clearLine, p.done, p.regions, len(p.running))
+	if p.failed > 0 {
+		fmt.Fprintf(p.w, ", %d failed", p.failed)
+	}
+	fmt.Fprintf(p.w, "  buckets: %d  batches: %d", p.buckets, p.batches)
+}
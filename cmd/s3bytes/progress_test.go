@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/nekrassov01/s3bytes"
+)
+
+func Test_progress_observe(t *testing.T) {
+	tests := []struct {
+		name    string
+		regions int
+		events  []s3bytes.Event
+		want    string
+	}{
+		{
+			name:    "running",
+			regions: 2,
+			events: []s3bytes.Event{
+				{Type: s3bytes.EventTypeRegionStarted, Region: "ap-northeast-1"},
+				{Type: s3bytes.EventTypeBucketsListed, Region: "ap-northeast-1", Buckets: 3},
+				{Type: s3bytes.EventTypeBatchSent, Region: "ap-northeast-1", Buckets: 3},
+			},
+			want: clearLine + "regions: 0/2 done, 1 running  buckets: 3  batches: 1",
+		},
+		{
+			name:    "failed",
+			regions: 2,
+			events: []s3bytes.Event{
+				{Type: s3bytes.EventTypeRegionStarted, Region: "ap-northeast-1"},
+				{Type: s3bytes.EventTypeRegionStarted, Region: "us-east-1"},
+				{Type: s3bytes.EventTypeRegionFailed, Region: "us-east-1", Err: errors.New("error")},
+			},
+			want: clearLine + "regions: 1/2 done, 1 running, 1 failed  buckets: 0  batches: 0",
+		},
+		{
+			name:    "failed without start",
+			regions: 3,
+			events: []s3bytes.Event{
+				{Type: s3bytes.EventTypeRegionStarted, Region: "ap-northeast-1"},
+				{Type: s3bytes.EventTypeRegionFailed, Region: "us-east-1", Err: errors.New("error")},
+			},
+			want: clearLine + "regions: 1/3 done, 1 running, 1 failed  buckets: 0  batches: 0",
+		},
+		{
+			name:    "cleared after failures without start",
+			regions: 2,
+			events: []s3bytes.Event{
+				{Type: s3bytes.EventTypeRegionStarted, Region: "ap-northeast-1"},
+				{Type: s3bytes.EventTypeRegionFailed, Region: "ap-northeast-1", Err: errors.New("error")},
+				{Type: s3bytes.EventTypeRegionFailed, Region: "us-east-1", Err: errors.New("error")},
+			},
+			want: clearLine,
+		},
+		{
+			name:    "done",
+			regions: 1,
+			events: []s3bytes.Event{
+				{Type: s3bytes.EventTypeRegionStarted, Region: "ap-northeast-1"},
+				{Type: s3bytes.EventTypeBucketsListed, Region: "ap-northeast-1", Buckets: 3},
+				{Type: s3bytes.EventTypeRegionFinished, Region: "ap-northeast-1"},
+			},
+			want: clearLine,
+		},
+		{
+			name:    "reset on next run",
+			regions: 1,
+			events: []s3bytes.Event{
+				{Type: s3bytes.EventTypeRegionStarted, Region: "ap-northeast-1"},
+				{Type: s3bytes.EventTypeBucketsListed, Region: "ap-northeast-1", Buckets: 3},
+				{Type: s3bytes.EventTypeRegionFinished, Region: "ap-northeast-1"},
+				{Type: s3bytes.EventTypeRegionStarted, Region: "ap-northeast-1"},
+			},
+			want: clearLine + "regions: 0/1 done, 1 running  buckets: 0  batches: 0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := newProgress(&buf, tt.regions)
+			for _, event := range tt.events {
+				buf.Reset()
+				p.observe(event)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("progress.observe() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,667 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+	"github.com/nekrassov01/s3bytes"
+)
+
+// spinnerFrames is the animation of the progress indicator of the regions being fetched.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// spinnerInterval is the interval between the frames of the progress indicator.
+const spinnerInterval = 100 * time.Millisecond
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true)
+	headerStyle   = lipgloss.NewStyle().Bold(true).Underline(true)
+	selectedStyle = lipgloss.NewStyle().Reverse(true)
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	helpStyle     = lipgloss.NewStyle().Faint(true)
+)
+
+// tuiLevel represents the level of the view that the TUI is browsing.
+type tuiLevel int
+
+const (
+	tuiLevelRegion tuiLevel = iota
+	tuiLevelBucket
+	tuiLevelPrefix
+)
+
+// tuiColumn represents a column of the table in the TUI.
+// Numeric columns are right-aligned and sorted by number,
+// and the values of the metric are formatted according to the units.
+type tuiColumn struct {
+	title   string
+	numeric bool
+	metric  bool
+}
+
+// tuiCell represents a cell of the table. The number is used for the numeric columns,
+// and formatted as the value of the metric if the text is empty in the metric column.
+type tuiCell struct {
+	text string
+	num  float64
+}
+
+// tuiRow represents a row of the table. The key is the name to drill into and to filter by.
+type tuiRow struct {
+	key   string
+	cells []tuiCell
+}
+
+// fetchState holds the progress and the result of a fetch in the background.
+type fetchState struct {
+	data *s3bytes.MetricData
+	err  error
+	done bool
+}
+
+// regionMsg is sent when the fetch of a region finishes.
+type regionMsg struct {
+	region string
+	data   *s3bytes.MetricData
+	err    error
+}
+
+// prefixMsg is sent when the prefix breakdown of a bucket is fetched.
+type prefixMsg struct {
+	bucket string
+	data   *s3bytes.MetricData
+	err    error
+}
+
+// tickMsg advances the progress indicator.
+type tickMsg struct{}
+
+// tuiModel is the model of the TUI, which browses the regions, the buckets in a region
+// and the prefixes in a bucket. The regions are fetched in the background on start,
+// and the prefixes of a bucket are fetched when the bucket is opened for the first time.
+type tuiModel struct {
+	ctx        context.Context
+	man        *s3bytes.Manager
+	metricName s3bytes.MetricName
+	depth      int
+	regions    []string
+	results    map[string]*fetchState
+	prefixes   map[string]*fetchState
+	level      tuiLevel
+	region     string
+	bucket     string
+	prefix     string
+	cursor     int
+	offset     int
+	sortCol    int
+	reverse    bool
+	filter     string
+	editing    bool
+	raw        bool
+	frame      int
+	ticking    bool
+	width      int
+	height     int
+}
+
+// newTUIModel creates a new model that browses the regions of the manager,
+// with the prefixes of a bucket broken down to the depth. The metric name
+// of the manager determines the units of the values.
+func newTUIModel(ctx context.Context, man *s3bytes.Manager, depth int) *tuiModel {
+	regions := man.Regions()
+	return &tuiModel{
+		ctx:        ctx,
+		man:        man,
+		metricName: man.MetricName(),
+		depth:      depth,
+		regions:    regions,
+		results:    make(map[string]*fetchState, len(regions)),
+		prefixes:   make(map[string]*fetchState),
+		sortCol:    -1,
+	}
+}
+
+// Init starts fetching all regions in the background.
+// The concurrency is limited by the manager.
+func (m *tuiModel) Init() tea.Cmd {
+	cmds := make([]tea.Cmd, 0, len(m.regions)+1)
+	for _, region := range m.regions {
+		m.results[region] = &fetchState{}
+		cmds = append(cmds, m.fetchRegion(region))
+	}
+	cmds = append(cmds, m.tick())
+	return tea.Batch(cmds...)
+}
+
+// fetchRegion returns the command to fetch the metrics of the region.
+func (m *tuiModel) fetchRegion(region string) tea.Cmd {
+	return func() tea.Msg {
+		data, err := m.man.ListRegion(m.ctx, region)
+		return regionMsg{region: region, data: data, err: err}
+	}
+}
+
+// fetchPrefixes returns the command to fetch the prefix breakdown of the bucket.
+func (m *tuiModel) fetchPrefixes(bucket string) tea.Cmd {
+	return func() tea.Msg {
+		data, err := m.man.ListPrefixes(m.ctx, []string{bucket}, m.depth)
+		return prefixMsg{bucket: bucket, data: data, err: err}
+	}
+}
+
+// tick returns the command to advance the progress indicator, unless it is already running.
+func (m *tuiModel) tick() tea.Cmd {
+	if m.ticking {
+		return nil
+	}
+	m.ticking = true
+	return tea.Tick(spinnerInterval, func(time.Time) tea.Msg {
+		return tickMsg{}
+	})
+}
+
+// loading reports whether any fetch is in progress.
+func (m *tuiModel) loading() bool {
+	for _, s := range m.results {
+		if !s.done {
+			return true
+		}
+	}
+	for _, s := range m.prefixes {
+		if !s.done {
+			return true
+		}
+	}
+	return false
+}
+
+// Update handles the results of the fetches and the key presses.
+func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case regionMsg:
+		m.results[msg.region] = &fetchState{data: msg.data, err: msg.err, done: true}
+	case prefixMsg:
+		m.prefixes[msg.bucket] = &fetchState{data: msg.data, err: msg.err, done: true}
+	case tickMsg:
+		m.ticking = false
+		if m.loading() {
+			m.frame = (m.frame + 1) % len(spinnerFrames)
+			return m, m.tick()
+		}
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		if m.editing {
+			return m, m.edit(msg)
+		}
+		return m, m.handle(msg)
+	}
+	m.clamp()
+	return m, nil
+}
+
+// edit handles the key presses while typing the filter.
+func (m *tuiModel) edit(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return tea.Quit
+	case tea.KeyEnter:
+		m.editing = false
+	case tea.KeyEsc:
+		m.editing = false
+		m.filter = ""
+	case tea.KeyBackspace:
+		if r := []rune(m.filter); len(r) > 0 {
+			m.filter = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.filter += string(msg.Runes)
+	}
+	m.cursor, m.offset = 0, 0
+	return nil
+}
+
+// handle handles the key presses while browsing.
+func (m *tuiModel) handle(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return tea.Quit
+	case "up", "k":
+		m.cursor--
+	case "down", "j":
+		m.cursor++
+	case "pgup":
+		m.cursor -= m.pageSize()
+	case "pgdown":
+		m.cursor += m.pageSize()
+	case "home", "g":
+		m.cursor = 0
+	case "end", "G":
+		m.cursor = len(m.rows()) - 1
+	case "s":
+		m.sortCol = (m.sortCol+2)%len(m.columns()) - 1
+	case "r":
+		m.reverse = !m.reverse
+	case "u":
+		m.raw = !m.raw
+	case "/":
+		m.editing = true
+	case "enter", "right", "l":
+		return m.open()
+	case "esc", "backspace", "left", "h":
+		m.back()
+	}
+	m.clamp()
+	return nil
+}
+
+// open drills into the selected row. Opening a bucket starts fetching its prefixes
+// if they have not been fetched yet, and a prefix can be opened only if it has sub-prefixes.
+func (m *tuiModel) open() tea.Cmd {
+	rows := m.rows()
+	if m.cursor < 0 || m.cursor >= len(rows) {
+		return nil
+	}
+	key := rows[m.cursor].key
+	switch m.level {
+	case tuiLevelRegion:
+		m.level, m.region = tuiLevelBucket, key
+	case tuiLevelBucket:
+		m.level, m.bucket, m.prefix = tuiLevelPrefix, key, ""
+		if _, ok := m.prefixes[key]; !ok {
+			m.prefixes[key] = &fetchState{}
+			m.reset()
+			return tea.Batch(m.fetchPrefixes(key), m.tick())
+		}
+	case tuiLevelPrefix:
+		if !m.hasChildren(key) {
+			return nil
+		}
+		m.prefix = key
+	}
+	m.reset()
+	return nil
+}
+
+// back goes up to the parent of the current view, clearing the filter if it is set.
+func (m *tuiModel) back() {
+	if m.filter != "" {
+		m.filter = ""
+		m.cursor, m.offset = 0, 0
+		return
+	}
+	switch m.level {
+	case tuiLevelBucket:
+		m.level = tuiLevelRegion
+	case tuiLevelPrefix:
+		if m.prefix != "" {
+			m.prefix = s3bytes.ParentPrefix(m.prefix)
+		} else {
+			m.level = tuiLevelBucket
+		}
+	default:
+		return
+	}
+	m.reset()
+}
+
+// reset clears the filter and the sort column, and moves the cursor to the top on entering another view.
+func (m *tuiModel) reset() {
+	m.filter = ""
+	m.sortCol = -1
+	m.cursor, m.offset = 0, 0
+}
+
+// clamp keeps the cursor within the rows and scrolls the view to the cursor.
+func (m *tuiModel) clamp() {
+	n := len(m.rows())
+	m.cursor = max(min(m.cursor, n-1), 0)
+	size := m.pageSize()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+size {
+		m.offset = m.cursor - size + 1
+	}
+	m.offset = max(min(m.offset, n-size), 0)
+}
+
+// pageSize returns the number of rows that fit in the window.
+// All rows are shown until the size of the window is known.
+func (m *tuiModel) pageSize() int {
+	if m.height <= 0 {
+		return max(len(m.rows()), 1)
+	}
+	return max(m.height-6, 1)
+}
+
+// hasChildren reports whether the prefix of the current bucket has sub-prefixes.
+func (m *tuiModel) hasChildren(prefix string) bool {
+	s := m.prefixes[m.bucket]
+	if s == nil || s.data == nil {
+		return false
+	}
+	return slices.ContainsFunc(s.data.Metrics, func(metric *s3bytes.Metric) bool {
+		return s3bytes.ParentPrefix(metric.Prefix) == prefix
+	})
+}
+
+// columns returns the columns of the current view.
+func (m *tuiModel) columns() []tuiColumn {
+	switch m.level {
+	case tuiLevelBucket:
+		return []tuiColumn{
+			{title: "BucketName"},
+			{title: "StorageType"},
+			{title: "BucketType"},
+			{title: "Status"},
+			{title: "Value", numeric: true, metric: true},
+		}
+	case tuiLevelPrefix:
+		return []tuiColumn{
+			{title: "Prefix"},
+			{title: "SubPrefixes", numeric: true},
+			{title: "Value", numeric: true, metric: true},
+		}
+	default:
+		return []tuiColumn{
+			{title: "Region"},
+			{title: "Progress"},
+			{title: "Buckets", numeric: true},
+			{title: "Value", numeric: true, metric: true},
+		}
+	}
+}
+
+// rows returns the rows of the current view, filtered by the key and sorted by the column.
+// The numeric columns are sorted in descending order and the others in ascending order
+// unless reversed, and the rows are sorted by the value without the sort column.
+func (m *tuiModel) rows() []tuiRow {
+	var rows []tuiRow
+	switch m.level {
+	case tuiLevelRegion:
+		rows = m.regionRows()
+	case tuiLevelBucket:
+		rows = m.bucketRows()
+	case tuiLevelPrefix:
+		rows = m.prefixRows()
+	}
+	if m.filter != "" {
+		f := strings.ToLower(m.filter)
+		rows = slices.DeleteFunc(rows, func(row tuiRow) bool {
+			return !strings.Contains(strings.ToLower(row.key), f)
+		})
+	}
+	cols, col := m.columns(), m.sortColumn()
+	desc := cols[col].numeric != m.reverse
+	slices.SortStableFunc(rows, func(a, b tuiRow) int {
+		var n int
+		if cols[col].numeric {
+			n = cmp.Compare(a.cells[col].num, b.cells[col].num)
+		} else {
+			n = cmp.Compare(a.cells[col].text, b.cells[col].text)
+		}
+		if n == 0 {
+			return cmp.Compare(a.key, b.key)
+		}
+		if desc {
+			return -n
+		}
+		return n
+	})
+	return rows
+}
+
+// sortColumn returns the index of the sort column, which is the value by default.
+func (m *tuiModel) sortColumn() int {
+	if m.sortCol < 0 {
+		return len(m.columns()) - 1
+	}
+	return m.sortCol
+}
+
+// regionRows returns the rows of the regions with the progress of the fetch.
+func (m *tuiModel) regionRows() []tuiRow {
+	rows := make([]tuiRow, 0, len(m.regions))
+	for _, region := range m.regions {
+		s := m.results[region]
+		row := tuiRow{
+			key: region,
+			cells: []tuiCell{
+				{text: region},
+				{text: spinnerFrames[m.frame] + " fetching"},
+				{text: "-"},
+				{text: "-"},
+			},
+		}
+		switch {
+		case s == nil || !s.done:
+		case s.err != nil:
+			row.cells[1].text = "error: " + s.err.Error()
+		default:
+			row.cells[1].text = "done"
+			row.cells[2] = tuiCell{text: strconv.Itoa(len(s.data.Metrics)), num: float64(len(s.data.Metrics))}
+			row.cells[3] = tuiCell{num: float64(s.data.Total)}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// bucketRows returns the rows of the buckets in the current region.
+func (m *tuiModel) bucketRows() []tuiRow {
+	s := m.results[m.region]
+	if s == nil || s.data == nil {
+		return nil
+	}
+	rows := make([]tuiRow, 0, len(s.data.Metrics))
+	for _, metric := range s.data.Metrics {
+		rows = append(rows, tuiRow{
+			key: metric.BucketName,
+			cells: []tuiCell{
+				{text: metric.BucketName},
+				{text: metric.StorageType.String()},
+				{text: metric.BucketType.String()},
+				{text: metric.Status.String()},
+				{num: metric.Value},
+			},
+		})
+	}
+	return rows
+}
+
+// prefixRows returns the rows of the prefixes directly under the current prefix of the current bucket.
+func (m *tuiModel) prefixRows() []tuiRow {
+	s := m.prefixes[m.bucket]
+	if s == nil || s.data == nil {
+		return nil
+	}
+	children := make(map[string]int)
+	for _, metric := range s.data.Metrics {
+		children[s3bytes.ParentPrefix(metric.Prefix)]++
+	}
+	rows := make([]tuiRow, 0)
+	for _, metric := range s.data.Metrics {
+		if s3bytes.ParentPrefix(metric.Prefix) != m.prefix {
+			continue
+		}
+		n := children[metric.Prefix]
+		rows = append(rows, tuiRow{
+			key: metric.Prefix,
+			cells: []tuiCell{
+				{text: metric.Prefix},
+				{text: strconv.Itoa(n), num: float64(n)},
+				{num: metric.Value},
+			},
+		})
+	}
+	return rows
+}
+
+// current returns the state of the fetch that the current view depends on.
+func (m *tuiModel) current() *fetchState {
+	switch m.level {
+	case tuiLevelBucket:
+		return m.results[m.region]
+	case tuiLevelPrefix:
+		return m.prefixes[m.bucket]
+	default:
+		return nil
+	}
+}
+
+// format formats the value of the metric. The values in bytes are formatted with the SI unit
+// and the others with thousands separators, unless the raw values are requested.
+func (m *tuiModel) format(value float64) string {
+	switch {
+	case m.raw:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case m.metricName.IsBytes():
+		return humanize.Bytes(uint64(value))
+	default:
+		return humanize.Comma(int64(value))
+	}
+}
+
+// breadcrumb returns the path of the current view.
+func (m *tuiModel) breadcrumb() string {
+	path := []string{"regions"}
+	if m.level >= tuiLevelBucket {
+		path = append(path, m.region)
+	}
+	if m.level >= tuiLevelPrefix {
+		path = append(path, m.bucket)
+		if m.prefix != "" {
+			path = append(path, m.prefix)
+		}
+	}
+	return strings.Join(path, " > ")
+}
+
+// View renders the breadcrumb, the status line, the table and the help.
+func (m *tuiModel) View() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(name+": "+m.breadcrumb()) + "\n")
+	b.WriteString(m.status() + "\n\n")
+	if s := m.current(); s != nil && !s.done {
+		b.WriteString(spinnerFrames[m.frame] + " fetching...\n")
+	} else if s != nil && s.err != nil {
+		b.WriteString(errorStyle.Render("error: "+s.err.Error()) + "\n")
+	} else {
+		b.WriteString(m.table())
+	}
+	b.WriteString("\n" + helpStyle.Render(m.help()))
+	if m.width <= 0 {
+		return b.String()
+	}
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = lipgloss.NewStyle().MaxWidth(m.width).Render(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// status returns the progress of the regions, the total of the fetched regions,
+// the sort order, the filter and the units.
+func (m *tuiModel) status() string {
+	var (
+		done  int
+		total int64
+	)
+	for _, s := range m.results {
+		if s.done {
+			done++
+			if s.data != nil {
+				total += s.data.Total
+			}
+		}
+	}
+	cols, col := m.columns(), m.sortColumn()
+	order := cols[col].title + " asc"
+	if cols[col].numeric != m.reverse {
+		order = cols[col].title + " desc"
+	}
+	units := "humanized"
+	if m.raw {
+		units = "raw"
+	}
+	ret := fmt.Sprintf("%s  regions: %d/%d  total: %s  sort: %s  units: %s", m.metricName, done, len(m.regions), m.format(float64(total)), order, units)
+	if m.editing {
+		return ret + "  filter: " + m.filter + "_"
+	}
+	if m.filter != "" {
+		return ret + "  filter: " + m.filter
+	}
+	return ret
+}
+
+// table renders the visible rows with the header and the cursor.
+func (m *tuiModel) table() string {
+	var (
+		cols   = m.columns()
+		rows   = m.rows()
+		widths = make([]int, len(cols))
+		lines  = make([][]string, 0, len(rows))
+	)
+	for i, col := range cols {
+		widths[i] = lipgloss.Width(col.title)
+	}
+	end := min(m.offset+m.pageSize(), len(rows))
+	for _, row := range rows[m.offset:end] {
+		line := make([]string, len(cols))
+		for i, col := range cols {
+			line[i] = row.cells[i].text
+			if col.metric && line[i] == "" {
+				line[i] = m.format(row.cells[i].num)
+			}
+			widths[i] = max(widths[i], lipgloss.Width(line[i]))
+		}
+		lines = append(lines, line)
+	}
+	var b strings.Builder
+	header := make([]string, len(cols))
+	for i, col := range cols {
+		header[i] = pad(col.title, widths[i], col.numeric)
+	}
+	b.WriteString(headerStyle.Render(strings.Join(header, "  ")) + "\n")
+	if len(rows) == 0 {
+		b.WriteString("no rows\n")
+		return b.String()
+	}
+	for i, line := range lines {
+		for j, col := range cols {
+			line[j] = pad(line[j], widths[j], col.numeric)
+		}
+		s := strings.Join(line, "  ")
+		if m.offset+i == m.cursor {
+			s = selectedStyle.Render(s)
+		}
+		b.WriteString(s + "\n")
+	}
+	return b.String()
+}
+
+// help returns the key bindings of the current mode.
+func (m *tuiModel) help() string {
+	if m.editing {
+		return "type to filter  enter: apply  esc: clear"
+	}
+	return "↑/↓: move  enter: open  esc: back  s: sort column  r: reverse  /: filter  u: units  q: quit"
+}
+
+// pad pads the text with spaces to the width, on the left for the numeric columns.
+func pad(s string, width int, right bool) string {
+	n := max(width-lipgloss.Width(s), 0)
+	if right {
+		return strings.Repeat(" ", n) + s
+	}
+	return s + strings.Repeat(" ", n)
+}
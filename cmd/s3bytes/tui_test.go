@@ -0,0 +1,449 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nekrassov01/s3bytes"
+)
+
+// testSource is a source that returns the fixed values of the buckets in each region.
+type testSource struct {
+	buckets map[string][]string
+	values  map[string]float64
+}
+
+func (s *testSource) Buckets(_ context.Context, region, _ string) ([]string, error) {
+	buckets, ok := s.buckets[region]
+	if !ok {
+		return nil, errors.New("error")
+	}
+	return buckets, nil
+}
+
+func (s *testSource) Metrics(_ context.Context, query *s3bytes.SourceQuery) ([]*s3bytes.Metric, error) {
+	metrics := make([]*s3bytes.Metric, 0, len(query.Buckets))
+	for _, bucket := range query.Buckets {
+		metrics = append(metrics, &s3bytes.Metric{
+			BucketName:  bucket,
+			Region:      query.Region,
+			MetricName:  query.MetricName,
+			StorageType: query.StorageType,
+			Value:       s.values[bucket],
+			Status:      s3bytes.DataStatusOK,
+			Source:      s3bytes.SourceTypeCloudWatch,
+		})
+	}
+	return metrics, nil
+}
+
+var testTUISource = &testSource{
+	buckets: map[string][]string{
+		"ap-northeast-1": {"bucket0", "bucket1", "bucket2"},
+		"us-east-1":      {"bucket3"},
+	},
+	values: map[string]float64{
+		"bucket0": 1000,
+		"bucket1": 3000,
+		"bucket2": 2000,
+		"bucket3": 500,
+	},
+}
+
+var testTUIPrefixes = &s3bytes.MetricData{
+	Metrics: []*s3bytes.Metric{
+		{BucketName: "bucket1", Prefix: "logs/", Value: 2000},
+		{BucketName: "bucket1", Prefix: "logs/2026/", Value: 1500},
+		{BucketName: "bucket1", Prefix: "logs/2025/", Value: 500},
+		{BucketName: "bucket1", Prefix: "images/", Value: 1000},
+	},
+	Total: 3000,
+}
+
+// newTestTUIModel creates a model with the regions of the test source and eu-west-1, which fails.
+func newTestTUIModel(t *testing.T) *tuiModel {
+	t.Helper()
+	man := s3bytes.NewManager(nil)
+	if err := man.SetRegion([]string{"ap-northeast-1", "us-east-1", "eu-west-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := man.SetMetric(s3bytes.MetricNameBucketSizeBytes, s3bytes.StorageTypeStandardStorage); err != nil {
+		t.Fatal(err)
+	}
+	if err := man.SetSource(testTUISource); err != nil {
+		t.Fatal(err)
+	}
+	return newTUIModel(context.Background(), man, 2)
+}
+
+// load fetches all regions of the model synchronously.
+func load(m *tuiModel) {
+	m.Init()
+	for _, region := range m.regions {
+		m.Update(m.fetchRegion(region)())
+	}
+}
+
+// keys converts the key names into the key messages.
+func keys(names ...string) []tea.KeyMsg {
+	ret := make([]tea.KeyMsg, len(names))
+	for i, name := range names {
+		switch name {
+		case "enter":
+			ret[i] = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			ret[i] = tea.KeyMsg{Type: tea.KeyEsc}
+		case "backspace":
+			ret[i] = tea.KeyMsg{Type: tea.KeyBackspace}
+		case "down":
+			ret[i] = tea.KeyMsg{Type: tea.KeyDown}
+		default:
+			ret[i] = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)}
+		}
+	}
+	return ret
+}
+
+// rowKeys returns the keys of the rows of the current view.
+func rowKeys(m *tuiModel) []string {
+	ret := make([]string, 0)
+	for _, row := range m.rows() {
+		ret = append(ret, row.key)
+	}
+	return ret
+}
+
+func Test_tuiModel_fetchRegion(t *testing.T) {
+	tests := []struct {
+		name      string
+		region    string
+		wantTotal int64
+		wantErr   bool
+	}{
+		{
+			name:      "region",
+			region:    "ap-northeast-1",
+			wantTotal: 6000,
+			wantErr:   false,
+		},
+		{
+			name:    "error",
+			region:  "eu-west-1",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestTUIModel(t)
+			msg, ok := m.fetchRegion(tt.region)().(regionMsg)
+			if !ok {
+				t.Fatalf("fetchRegion() = %T, want regionMsg", msg)
+			}
+			if (msg.err != nil) != tt.wantErr {
+				t.Errorf("fetchRegion() error = %v, wantErr %v", msg.err, tt.wantErr)
+				return
+			}
+			if msg.err != nil {
+				return
+			}
+			if msg.data.Total != tt.wantTotal {
+				t.Errorf("fetchRegion() total = %v, want %v", msg.data.Total, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func Test_tuiModel_Update(t *testing.T) {
+	type want struct {
+		level  tuiLevel
+		region string
+		bucket string
+		prefix string
+		rows   []string
+		cursor int
+		fetch  bool
+	}
+	tests := []struct {
+		name     string
+		keys     []tea.KeyMsg
+		prefixes bool
+		want     want
+	}{
+		{
+			name: "regions by value",
+			keys: nil,
+			want: want{
+				rows: []string{"ap-northeast-1", "us-east-1", "eu-west-1"},
+			},
+		},
+		{
+			name: "regions by name",
+			keys: keys("s"),
+			want: want{
+				rows: []string{"ap-northeast-1", "eu-west-1", "us-east-1"},
+			},
+		},
+		{
+			name: "regions by name reversed",
+			keys: keys("s", "r"),
+			want: want{
+				rows: []string{"us-east-1", "eu-west-1", "ap-northeast-1"},
+			},
+		},
+		{
+			name: "sort column cycled back to value",
+			keys: keys("s", "s", "s", "s"),
+			want: want{
+				rows: []string{"ap-northeast-1", "us-east-1", "eu-west-1"},
+			},
+		},
+		{
+			name: "cursor",
+			keys: keys("down", "down", "down", "k"),
+			want: want{
+				rows:   []string{"ap-northeast-1", "us-east-1", "eu-west-1"},
+				cursor: 1,
+			},
+		},
+		{
+			name: "buckets",
+			keys: keys("enter"),
+			want: want{
+				level:  tuiLevelBucket,
+				region: "ap-northeast-1",
+				rows:   []string{"bucket1", "bucket2", "bucket0"},
+			},
+		},
+		{
+			name: "buckets by value ascending",
+			keys: keys("enter", "r"),
+			want: want{
+				level:  tuiLevelBucket,
+				region: "ap-northeast-1",
+				rows:   []string{"bucket0", "bucket2", "bucket1"},
+			},
+		},
+		{
+			name: "filter",
+			keys: keys("enter", "/", "B", "u", "c", "k", "e", "t", "x", "backspace", "2", "enter"),
+			want: want{
+				level:  tuiLevelBucket,
+				region: "ap-northeast-1",
+				rows:   []string{"bucket2"},
+			},
+		},
+		{
+			name: "filter cleared",
+			keys: keys("enter", "/", "2", "esc"),
+			want: want{
+				level:  tuiLevelBucket,
+				region: "ap-northeast-1",
+				rows:   []string{"bucket1", "bucket2", "bucket0"},
+			},
+		},
+		{
+			name: "filter cleared before going back",
+			keys: keys("enter", "/", "2", "enter", "esc"),
+			want: want{
+				level:  tuiLevelBucket,
+				region: "ap-northeast-1",
+				rows:   []string{"bucket1", "bucket2", "bucket0"},
+			},
+		},
+		{
+			name: "back to regions",
+			keys: keys("enter", "esc"),
+			want: want{
+				region: "ap-northeast-1",
+				rows:   []string{"ap-northeast-1", "us-east-1", "eu-west-1"},
+			},
+		},
+		{
+			name: "prefixes fetched",
+			keys: keys("enter", "enter"),
+			want: want{
+				level:  tuiLevelPrefix,
+				region: "ap-northeast-1",
+				bucket: "bucket1",
+				rows:   []string{},
+				fetch:  true,
+			},
+		},
+		{
+			name:     "prefixes",
+			keys:     keys("enter", "enter"),
+			prefixes: true,
+			want: want{
+				level:  tuiLevelPrefix,
+				region: "ap-northeast-1",
+				bucket: "bucket1",
+				rows:   []string{"logs/", "images/"},
+			},
+		},
+		{
+			name:     "sub-prefixes",
+			keys:     keys("enter", "enter", "enter"),
+			prefixes: true,
+			want: want{
+				level:  tuiLevelPrefix,
+				region: "ap-northeast-1",
+				bucket: "bucket1",
+				prefix: "logs/",
+				rows:   []string{"logs/2026/", "logs/2025/"},
+			},
+		},
+		{
+			name:     "prefix without sub-prefixes",
+			keys:     keys("enter", "enter", "enter", "enter"),
+			prefixes: true,
+			want: want{
+				level:  tuiLevelPrefix,
+				region: "ap-northeast-1",
+				bucket: "bucket1",
+				prefix: "logs/",
+				rows:   []string{"logs/2026/", "logs/2025/"},
+			},
+		},
+		{
+			name:     "back to top-level prefixes",
+			keys:     keys("enter", "enter", "enter", "h"),
+			prefixes: true,
+			want: want{
+				level:  tuiLevelPrefix,
+				region: "ap-northeast-1",
+				bucket: "bucket1",
+				rows:   []string{"logs/", "images/"},
+			},
+		},
+		{
+			name:     "back to buckets",
+			keys:     keys("enter", "enter", "h"),
+			prefixes: true,
+			want: want{
+				level:  tuiLevelBucket,
+				region: "ap-northeast-1",
+				bucket: "bucket1",
+				rows:   []string{"bucket1", "bucket2", "bucket0"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestTUIModel(t)
+			load(m)
+			if tt.prefixes {
+				m.Update(prefixMsg{bucket: "bucket1", data: testTUIPrefixes})
+			}
+			var fetch bool
+			for _, key := range tt.keys {
+				_, cmd := m.Update(key)
+				if cmd != nil {
+					fetch = true
+				}
+			}
+			got := want{
+				level:  m.level,
+				region: m.region,
+				bucket: m.bucket,
+				prefix: m.prefix,
+				rows:   rowKeys(m),
+				cursor: m.cursor,
+				fetch:  fetch,
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tuiModel.Update() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_tuiModel_View(t *testing.T) {
+	tests := []struct {
+		name     string
+		loaded   bool
+		keys     []tea.KeyMsg
+		contains []string
+	}{
+		{
+			name:     "progress",
+			loaded:   false,
+			contains: []string{"regions: 0/3", "⠋ fetching"},
+		},
+		{
+			name:     "regions",
+			loaded:   true,
+			contains: []string{"regions: 3/3", "total: 6.5 kB", "ap-northeast-1", "6.0 kB", "error: error", "sort: Value desc"},
+		},
+		{
+			name:     "raw units",
+			loaded:   true,
+			keys:     keys("u"),
+			contains: []string{"total: 6500", "6000", "units: raw"},
+		},
+		{
+			name:     "buckets",
+			loaded:   true,
+			keys:     keys("enter", "s"),
+			contains: []string{"regions > ap-northeast-1", "BucketName", "bucket1", "3.0 kB", "sort: BucketName asc"},
+		},
+		{
+			name:     "filter",
+			loaded:   true,
+			keys:     keys("/", "u", "s"),
+			contains: []string{"filter: us_", "us-east-1", "enter: apply"},
+		},
+		{
+			name:     "failed region",
+			loaded:   true,
+			keys:     keys("G", "enter"),
+			contains: []string{"regions > eu-west-1", "error: error"},
+		},
+		{
+			name:     "fetching prefixes",
+			loaded:   true,
+			keys:     keys("enter", "enter"),
+			contains: []string{"regions > ap-northeast-1 > bucket1", "fetching..."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestTUIModel(t)
+			if tt.loaded {
+				load(m)
+			} else {
+				m.Init()
+			}
+			for _, key := range tt.keys {
+				m.Update(key)
+			}
+			got := m.View()
+			for _, s := range tt.contains {
+				if !strings.Contains(got, s) {
+					t.Errorf("tuiModel.View() = %v, want to contain %v", got, s)
+				}
+			}
+		})
+	}
+}
+
+func Test_tuiModel_clamp(t *testing.T) {
+	m := newTestTUIModel(t)
+	load(m)
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 8})
+	for range 5 {
+		m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	if m.cursor != 2 {
+		t.Errorf("tuiModel.cursor = %v, want %v", m.cursor, 2)
+	}
+	if m.offset != 1 {
+		t.Errorf("tuiModel.offset = %v, want %v", m.offset, 1)
+	}
+	if got := strings.Count(m.View(), "\n"); got > 8 {
+		t.Errorf("tuiModel.View() = %v lines, want at most %v", got+1, 8)
+	}
+}
@@ -3,22 +3,32 @@ package s3bytes
 import (
 	"context"
 	"regexp"
-	"runtime"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 )
 
-var (
-	// NumWorker is the number of workers for concurrent processing.
-	NumWorker = int64(runtime.NumCPU()*2 + 1)
-
-	// MaxQueries is the maximum number of queries for GetMetricData.
+const (
+	// MaxQueries is the maximum number of queries for GetMetricData, which is the upper limit of the batch size.
 	// See: https://docs.aws.amazon.com/AmazonCloudWatch/latest/APIReference/API_MetricDataQuery.html
 	MaxQueries = 500
 
-	// MaxChartItems is the maximum number of items in a chart.
+	// DefaultConcurrency is the concurrency of the manager specified by default, which can be overridden with WithConcurrency.
+	DefaultConcurrency = 16
+
+	// MaxChartItems is the default maximum number of items in a chart, which can be overridden with WithTopN.
 	MaxChartItems = 11
+)
+
+var (
+	// NumWorker is the concurrency of the managers created afterward without WithConcurrency.
+	//
+	// Deprecated: Use WithConcurrency instead.
+	NumWorker = int64(DefaultConcurrency)
+
+	// DefaultFilterID is the filter ID of the request metrics specified by default,
+	// which is the name the S3 console gives to the filter for the entire bucket.
+	DefaultFilterID = "EntireBucket"
 
 	// DefaultRegion is the region speficied by default.
 	DefaultRegion = "us-east-1"
@@ -47,6 +57,7 @@ var (
 
 var (
 	bucketPrefixPattern = regexp.MustCompile(`^[a-z0-9.-]{1,63}$`)
+	filterIDPattern     = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,64}$`)
 	allowedRegions      = map[string]struct{}{
 		"af-south-1":     {},
 		"ap-east-1":      {},
@@ -81,6 +92,17 @@ var (
 		"us-west-1":      {},
 		"us-west-2":      {},
 	}
+	zoneDirections = map[string]string{
+		"north":     "n",
+		"northeast": "ne",
+		"east":      "e",
+		"southeast": "se",
+		"south":     "s",
+		"southwest": "sw",
+		"west":      "w",
+		"northwest": "nw",
+		"central":   "c",
+	}
 )
 
 // LoadConfig loads the aws config.
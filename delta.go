@@ -0,0 +1,51 @@
+package s3bytes
+
+import "slices"
+
+// deltaHeader is the column appended to the header of the data compared by CompareMetrics.
+var deltaHeader = []string{
+	"Delta",
+}
+
+// metricKey identifies the row of a metric across the results of repeated runs.
+type metricKey struct {
+	bucket      string
+	region      string
+	prefix      string
+	storageType StorageType
+	group       string
+}
+
+// key returns the identity of the row of the metric.
+func (t *Metric) key() metricKey {
+	return metricKey{
+		bucket:      t.BucketName,
+		region:      t.Region,
+		prefix:      t.Prefix,
+		storageType: t.StorageType,
+		group:       t.Group,
+	}
+}
+
+// CompareMetrics sets the difference of the value of each metric from the row of the same bucket,
+// region, prefix, storage type and group in the previous data, and appends the Delta column to the header.
+// The rows missing from the previous data are regarded as growing from zero, and it returns
+// the difference of the totals. If the previous data is nil, as on the first run, only the column
+// is appended with the deltas left empty, so that the columns stay the same across the runs.
+func CompareMetrics(data, prev *MetricData) int64 {
+	if !slices.Contains(data.Header, deltaHeader[0]) {
+		data.Header = append(slices.Clone(data.Header), deltaHeader...)
+	}
+	if prev == nil {
+		return 0
+	}
+	values := make(map[metricKey]float64, len(prev.Metrics))
+	for _, metric := range prev.Metrics {
+		values[metric.key()] = metric.Value
+	}
+	for _, metric := range data.Metrics {
+		delta := metric.Value - values[metric.key()]
+		metric.Delta = &delta
+	}
+	return data.Total - prev.Total
+}
@@ -0,0 +1,146 @@
+package s3bytes
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestCompareMetrics(t *testing.T) {
+	prev := &MetricData{
+		Header: header,
+		Metrics: []*Metric{
+			{BucketName: "bucket0", Region: "ap-northeast-1", StorageType: StorageTypeStandardStorage, Value: 100},
+			{BucketName: "bucket1", Region: "ap-northeast-1", StorageType: StorageTypeStandardStorage, Value: 200},
+			{BucketName: "bucket1", Region: "ap-northeast-1", StorageType: StorageTypeStandardIAStorage, Value: 50},
+			{BucketName: "bucket2", Region: "ap-northeast-1", StorageType: StorageTypeStandardStorage, Value: 300},
+		},
+		Total: 650,
+	}
+	type args struct {
+		data *MetricData
+		prev *MetricData
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantDeltas []*float64
+		wantHeader []string
+		want       int64
+	}{
+		{
+			name: "delta",
+			args: args{
+				data: &MetricData{
+					Header: header,
+					Metrics: []*Metric{
+						{BucketName: "bucket0", Region: "ap-northeast-1", StorageType: StorageTypeStandardStorage, Value: 150},
+						{BucketName: "bucket1", Region: "ap-northeast-1", StorageType: StorageTypeStandardStorage, Value: 120},
+						{BucketName: "bucket1", Region: "ap-northeast-1", StorageType: StorageTypeStandardIAStorage, Value: 50},
+						{BucketName: "bucket3", Region: "ap-northeast-1", StorageType: StorageTypeStandardStorage, Value: 10},
+					},
+					Total: 330,
+				},
+				prev: prev,
+			},
+			wantDeltas: []*float64{aws.Float64(50), aws.Float64(-80), aws.Float64(0), aws.Float64(10)},
+			wantHeader: append(slices.Clone(header), deltaHeader...),
+			want:       -320,
+		},
+		{
+			name: "same bucket in other region",
+			args: args{
+				data: &MetricData{
+					Header: header,
+					Metrics: []*Metric{
+						{BucketName: "bucket0", Region: "us-east-1", StorageType: StorageTypeStandardStorage, Value: 100},
+					},
+					Total: 100,
+				},
+				prev: prev,
+			},
+			wantDeltas: []*float64{aws.Float64(100)},
+			wantHeader: append(slices.Clone(header), deltaHeader...),
+			want:       -550,
+		},
+		{
+			name: "groups",
+			args: args{
+				data: &MetricData{
+					Header: groupHeader,
+					Metrics: []*Metric{
+						{Group: "team-a", Value: 400},
+						{Group: "team-b", Value: 100},
+					},
+					Total: 500,
+				},
+				prev: &MetricData{
+					Header: groupHeader,
+					Metrics: []*Metric{
+						{Group: "team-a", Value: 300},
+						{Group: "team-b", Value: 200},
+					},
+					Total: 500,
+				},
+			},
+			wantDeltas: []*float64{aws.Float64(100), aws.Float64(-100)},
+			wantHeader: append(slices.Clone(groupHeader), deltaHeader...),
+			want:       0,
+		},
+		{
+			name: "header already compared",
+			args: args{
+				data: &MetricData{
+					Header: append(slices.Clone(header), deltaHeader...),
+					Metrics: []*Metric{
+						{BucketName: "bucket0", Region: "ap-northeast-1", StorageType: StorageTypeStandardStorage, Value: 100},
+					},
+					Total: 100,
+				},
+				prev: prev,
+			},
+			wantDeltas: []*float64{aws.Float64(0)},
+			wantHeader: append(slices.Clone(header), deltaHeader...),
+			want:       -550,
+		},
+		{
+			name: "first run",
+			args: args{
+				data: &MetricData{
+					Header: header,
+					Metrics: []*Metric{
+						{BucketName: "bucket0", Region: "ap-northeast-1", StorageType: StorageTypeStandardStorage, Value: 100},
+					},
+					Total: 100,
+				},
+				prev: nil,
+			},
+			wantDeltas: []*float64{nil},
+			wantHeader: append(slices.Clone(header), deltaHeader...),
+			want:       0,
+		},
+	}
+	sharedHeader := slices.Clone(header)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareMetrics(tt.args.data, tt.args.prev); got != tt.want {
+				t.Errorf("CompareMetrics() = %v, want %v", got, tt.want)
+			}
+			deltas := make([]*float64, 0)
+			for _, metric := range tt.args.data.Metrics {
+				deltas = append(deltas, metric.Delta)
+			}
+			if !reflect.DeepEqual(deltas, tt.wantDeltas) {
+				t.Errorf("CompareMetrics() deltas = %v, want %v", deltas, tt.wantDeltas)
+			}
+			if !reflect.DeepEqual(tt.args.data.Header, tt.wantHeader) {
+				t.Errorf("CompareMetrics() header = %v, want %v", tt.args.data.Header, tt.wantHeader)
+			}
+			if !reflect.DeepEqual(header, sharedHeader) {
+				t.Errorf("CompareMetrics() modified the shared header: %v", header)
+			}
+		})
+	}
+}
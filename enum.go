@@ -35,6 +35,15 @@ const (
 
 	// OutputTypeChart is the output type that means pie chart.
 	OutputTypeChart
+
+	// OutputTypeHTML is the output type that means HTML dashboard.
+	OutputTypeHTML
+
+	// OutputTypeSVG is the output type that means static chart in SVG.
+	OutputTypeSVG
+
+	// OutputTypePNG is the output type that means static chart in PNG.
+	OutputTypePNG
 )
 
 // String returns the string representation of the output type.
@@ -58,6 +67,12 @@ func (t OutputType) String() string {
 		return "tsv"
 	case OutputTypeChart:
 		return "chart"
+	case OutputTypeHTML:
+		return "html"
+	case OutputTypeSVG:
+		return "svg"
+	case OutputTypePNG:
+		return "png"
 	default:
 		return ""
 	}
@@ -87,6 +102,12 @@ func ParseOutputType(s string) (OutputType, error) {
 		return OutputTypeTSV, nil
 	case OutputTypeChart.String():
 		return OutputTypeChart, nil
+	case OutputTypeHTML.String():
+		return OutputTypeHTML, nil
+	case OutputTypeSVG.String():
+		return OutputTypeSVG, nil
+	case OutputTypePNG.String():
+		return OutputTypePNG, nil
 	default:
 		return OutputTypeNone, fmt.Errorf("unsupported output type: %q", s)
 	}
@@ -104,6 +125,54 @@ const (
 
 	// MetricNameNumberOfObjects is the metric name that means number of objects.
 	MetricNameNumberOfObjects
+
+	// MetricNameAllRequests is the request metric name that means the total number of http requests.
+	MetricNameAllRequests
+
+	// MetricNameGetRequests is the request metric name that means the number of http GET requests.
+	MetricNameGetRequests
+
+	// MetricNamePutRequests is the request metric name that means the number of http PUT requests.
+	MetricNamePutRequests
+
+	// MetricNameDeleteRequests is the request metric name that means the number of http DELETE requests.
+	MetricNameDeleteRequests
+
+	// MetricNameHeadRequests is the request metric name that means the number of http HEAD requests.
+	MetricNameHeadRequests
+
+	// MetricNamePostRequests is the request metric name that means the number of http POST requests.
+	MetricNamePostRequests
+
+	// MetricNameSelectRequests is the request metric name that means the number of SelectObjectContent requests.
+	MetricNameSelectRequests
+
+	// MetricNameSelectBytesScanned is the request metric name that means the number of bytes scanned by SelectObjectContent requests.
+	MetricNameSelectBytesScanned
+
+	// MetricNameSelectBytesReturned is the request metric name that means the number of bytes returned by SelectObjectContent requests.
+	MetricNameSelectBytesReturned
+
+	// MetricNameListRequests is the request metric name that means the number of http requests that list the contents of a bucket.
+	MetricNameListRequests
+
+	// MetricNameBytesDownloaded is the request metric name that means the number of bytes downloaded.
+	MetricNameBytesDownloaded
+
+	// MetricNameBytesUploaded is the request metric name that means the number of bytes uploaded.
+	MetricNameBytesUploaded
+
+	// MetricName4xxErrors is the request metric name that means the number of http 4xx client error responses.
+	MetricName4xxErrors
+
+	// MetricName5xxErrors is the request metric name that means the number of http 5xx server error responses.
+	MetricName5xxErrors
+
+	// MetricNameFirstByteLatency is the request metric name that means the per-request time until the first byte is returned in milliseconds.
+	MetricNameFirstByteLatency
+
+	// MetricNameTotalRequestLatency is the request metric name that means the elapsed per-request time from the first byte received to the last byte sent in milliseconds.
+	MetricNameTotalRequestLatency
 )
 
 // String returns the string representation of the metric name.
@@ -115,6 +184,38 @@ func (t MetricName) String() string {
 		return "BucketSizeBytes"
 	case MetricNameNumberOfObjects:
 		return "NumberOfObjects"
+	case MetricNameAllRequests:
+		return "AllRequests"
+	case MetricNameGetRequests:
+		return "GetRequests"
+	case MetricNamePutRequests:
+		return "PutRequests"
+	case MetricNameDeleteRequests:
+		return "DeleteRequests"
+	case MetricNameHeadRequests:
+		return "HeadRequests"
+	case MetricNamePostRequests:
+		return "PostRequests"
+	case MetricNameSelectRequests:
+		return "SelectRequests"
+	case MetricNameSelectBytesScanned:
+		return "SelectBytesScanned"
+	case MetricNameSelectBytesReturned:
+		return "SelectBytesReturned"
+	case MetricNameListRequests:
+		return "ListRequests"
+	case MetricNameBytesDownloaded:
+		return "BytesDownloaded"
+	case MetricNameBytesUploaded:
+		return "BytesUploaded"
+	case MetricName4xxErrors:
+		return "4xxErrors"
+	case MetricName5xxErrors:
+		return "5xxErrors"
+	case MetricNameFirstByteLatency:
+		return "FirstByteLatency"
+	case MetricNameTotalRequestLatency:
+		return "TotalRequestLatency"
 	default:
 		return ""
 	}
@@ -132,6 +233,38 @@ func ParseMetricName(s string) (MetricName, error) {
 		return MetricNameBucketSizeBytes, nil
 	case MetricNameNumberOfObjects.String():
 		return MetricNameNumberOfObjects, nil
+	case MetricNameAllRequests.String():
+		return MetricNameAllRequests, nil
+	case MetricNameGetRequests.String():
+		return MetricNameGetRequests, nil
+	case MetricNamePutRequests.String():
+		return MetricNamePutRequests, nil
+	case MetricNameDeleteRequests.String():
+		return MetricNameDeleteRequests, nil
+	case MetricNameHeadRequests.String():
+		return MetricNameHeadRequests, nil
+	case MetricNamePostRequests.String():
+		return MetricNamePostRequests, nil
+	case MetricNameSelectRequests.String():
+		return MetricNameSelectRequests, nil
+	case MetricNameSelectBytesScanned.String():
+		return MetricNameSelectBytesScanned, nil
+	case MetricNameSelectBytesReturned.String():
+		return MetricNameSelectBytesReturned, nil
+	case MetricNameListRequests.String():
+		return MetricNameListRequests, nil
+	case MetricNameBytesDownloaded.String():
+		return MetricNameBytesDownloaded, nil
+	case MetricNameBytesUploaded.String():
+		return MetricNameBytesUploaded, nil
+	case MetricName4xxErrors.String():
+		return MetricName4xxErrors, nil
+	case MetricName5xxErrors.String():
+		return MetricName5xxErrors, nil
+	case MetricNameFirstByteLatency.String():
+		return MetricNameFirstByteLatency, nil
+	case MetricNameTotalRequestLatency.String():
+		return MetricNameTotalRequestLatency, nil
 	default:
 		return MetricNameNone, fmt.Errorf("unsupported metrics name: %q", s)
 	}
@@ -215,6 +348,9 @@ const (
 	// StorageTypeDeepArchiveStagingStorage is the storage type that means deep archive staging storage.
 	StorageTypeDeepArchiveStagingStorage
 
+	// StorageTypeExpressOneZoneStorage is the storage type that means S3 Express One Zone storage of directory buckets.
+	StorageTypeExpressOneZoneStorage
+
 	// StorageTypeAllStorageTypes is the storage type that means all storage types.
 	StorageTypeAllStorageTypes
 )
@@ -286,8 +422,8 @@ func (t StorageType) String() string {
 		return "DeepArchiveStagingStorage"
 
 	// S3 Express One Zone:
-	// case StorageTypeExpressOneZoneStorage:
-	// 	return "ExpressOneZoneStorage"
+	case StorageTypeExpressOneZoneStorage:
+		return "ExpressOneZoneStorage"
 
 	// Fixed value for metric of NumberOfObjects:
 	case StorageTypeAllStorageTypes:
@@ -367,8 +503,8 @@ func ParseStorageType(s string) (StorageType, error) {
 		return StorageTypeDeepArchiveStagingStorage, nil
 
 	// S3 Express One Zone.String():
-	// case StorageTypeExpressOneZoneStorage.String():
-	// 	return StorageTypeExpressOneZoneStorage, nil
+	case StorageTypeExpressOneZoneStorage.String():
+		return StorageTypeExpressOneZoneStorage, nil
 
 	// Fixed value for metric of NumberOfObjects.String():
 	case StorageTypeAllStorageTypes.String():
@@ -378,3 +514,474 @@ func ParseStorageType(s string) (StorageType, error) {
 		return StorageTypeNone, fmt.Errorf("unsupported storage type: %q", s)
 	}
 }
+
+// ScanMode represents the mode of object listing.
+type ScanMode int
+
+const (
+	// ScanModeNone is the scan mode that means no object listing.
+	ScanModeNone ScanMode = iota
+
+	// ScanModeFallback is the scan mode that lists objects only when CloudWatch has no data.
+	ScanModeFallback
+
+	// ScanModeForce is the scan mode that always lists objects instead of using CloudWatch.
+	ScanModeForce
+)
+
+// String returns the string representation of the scan mode.
+func (t ScanMode) String() string {
+	switch t {
+	case ScanModeNone:
+		return "none"
+	case ScanModeFallback:
+		return "fallback"
+	case ScanModeForce:
+		return "force"
+	default:
+		return ""
+	}
+}
+
+// MarshalJSON returns the JSON representation of the scan mode.
+func (t ScanMode) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// ParseScanMode parses the scan mode from the string representation.
+func ParseScanMode(s string) (ScanMode, error) {
+	switch s {
+	case ScanModeNone.String():
+		return ScanModeNone, nil
+	case ScanModeFallback.String():
+		return ScanModeFallback, nil
+	case ScanModeForce.String():
+		return ScanModeForce, nil
+	default:
+		return ScanModeNone, fmt.Errorf("unsupported scan mode: %q", s)
+	}
+}
+
+// SourceType represents the source from which the metric value was obtained.
+type SourceType int
+
+const (
+	// SourceTypeNone is the source type that means none.
+	SourceTypeNone SourceType = iota
+
+	// SourceTypeCloudWatch is the source type that means CloudWatch metrics.
+	SourceTypeCloudWatch
+
+	// SourceTypeScan is the source type that means object listing.
+	SourceTypeScan
+
+	// SourceTypeInventory is the source type that means S3 Inventory reports.
+	SourceTypeInventory
+)
+
+// String returns the string representation of the source type.
+func (t SourceType) String() string {
+	switch t {
+	case SourceTypeNone:
+		return "none"
+	case SourceTypeCloudWatch:
+		return "cloudwatch"
+	case SourceTypeScan:
+		return "scan"
+	case SourceTypeInventory:
+		return "inventory"
+	default:
+		return ""
+	}
+}
+
+// MarshalJSON returns the JSON representation of the source type.
+func (t SourceType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// ParseSourceType parses the source type from the string representation.
+func ParseSourceType(s string) (SourceType, error) {
+	switch s {
+	case SourceTypeCloudWatch.String():
+		return SourceTypeCloudWatch, nil
+	case SourceTypeScan.String():
+		return SourceTypeScan, nil
+	case SourceTypeInventory.String():
+		return SourceTypeInventory, nil
+	default:
+		return SourceTypeNone, fmt.Errorf("unsupported source type: %q", s)
+	}
+}
+
+// DataStatus represents the status of the datapoints of a metric.
+type DataStatus int
+
+const (
+	// DataStatusNone is the data status that means none.
+	DataStatusNone DataStatus = iota
+
+	// DataStatusOK is the data status that means the datapoints are complete.
+	DataStatusOK
+
+	// DataStatusNoData is the data status that means no datapoints were returned.
+	DataStatusNoData
+
+	// DataStatusPartial is the data status that means the datapoints are partial.
+	DataStatusPartial
+)
+
+// String returns the string representation of the data status.
+func (t DataStatus) String() string {
+	switch t {
+	case DataStatusNone:
+		return "none"
+	case DataStatusOK:
+		return "ok"
+	case DataStatusNoData:
+		return "no-data"
+	case DataStatusPartial:
+		return "partial"
+	default:
+		return ""
+	}
+}
+
+// MarshalJSON returns the JSON representation of the data status.
+func (t DataStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// ParseDataStatus parses the data status from the string representation.
+func ParseDataStatus(s string) (DataStatus, error) {
+	switch s {
+	case DataStatusOK.String():
+		return DataStatusOK, nil
+	case DataStatusNoData.String():
+		return DataStatusNoData, nil
+	case DataStatusPartial.String():
+		return DataStatusPartial, nil
+	default:
+		return DataStatusNone, fmt.Errorf("unsupported data status: %q", s)
+	}
+}
+
+// NoDataMode represents how to handle buckets that have no datapoints.
+type NoDataMode int
+
+const (
+	// NoDataModeNone is the no-data mode that means none.
+	NoDataModeNone NoDataMode = iota
+
+	// NoDataModeShow is the no-data mode that shows buckets without datapoints as is.
+	NoDataModeShow
+
+	// NoDataModeHide is the no-data mode that hides buckets without datapoints.
+	NoDataModeHide
+
+	// NoDataModeHighlight is the no-data mode that highlights buckets without datapoints.
+	NoDataModeHighlight
+)
+
+// String returns the string representation of the no-data mode.
+func (t NoDataMode) String() string {
+	switch t {
+	case NoDataModeNone:
+		return "none"
+	case NoDataModeShow:
+		return "show"
+	case NoDataModeHide:
+		return "hide"
+	case NoDataModeHighlight:
+		return "highlight"
+	default:
+		return ""
+	}
+}
+
+// MarshalJSON returns the JSON representation of the no-data mode.
+func (t NoDataMode) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// ParseNoDataMode parses the no-data mode from the string representation.
+func ParseNoDataMode(s string) (NoDataMode, error) {
+	switch s {
+	case NoDataModeShow.String():
+		return NoDataModeShow, nil
+	case NoDataModeHide.String():
+		return NoDataModeHide, nil
+	case NoDataModeHighlight.String():
+		return NoDataModeHighlight, nil
+	default:
+		return NoDataModeNone, fmt.Errorf("unsupported no-data mode: %q", s)
+	}
+}
+
+// ChartType represents the kind of chart rendered by the chart output type.
+type ChartType int
+
+const (
+	// ChartTypeNone is the chart type that means none.
+	// The chart is chosen automatically: a treemap for prefixes and a pie otherwise.
+	ChartTypeNone ChartType = iota
+
+	// ChartTypePie is the chart type that means a pie of the largest buckets.
+	ChartTypePie
+
+	// ChartTypeBar is the chart type that means a horizontal bar of the largest buckets.
+	ChartTypeBar
+
+	// ChartTypeTreeMap is the chart type that means a treemap of regions and buckets.
+	ChartTypeTreeMap
+
+	// ChartTypeSunburst is the chart type that means a sunburst of account, regions, storage types, and buckets.
+	ChartTypeSunburst
+)
+
+// String returns the string representation of the chart type.
+func (t ChartType) String() string {
+	switch t {
+	case ChartTypeNone:
+		return "none"
+	case ChartTypePie:
+		return "pie"
+	case ChartTypeBar:
+		return "bar"
+	case ChartTypeTreeMap:
+		return "treemap"
+	case ChartTypeSunburst:
+		return "sunburst"
+	default:
+		return ""
+	}
+}
+
+// MarshalJSON returns the JSON representation of the chart type.
+func (t ChartType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// ParseChartType parses the chart type from the string representation.
+func ParseChartType(s string) (ChartType, error) {
+	switch s {
+	case ChartTypePie.String():
+		return ChartTypePie, nil
+	case ChartTypeBar.String():
+		return ChartTypeBar, nil
+	case ChartTypeTreeMap.String():
+		return ChartTypeTreeMap, nil
+	case ChartTypeSunburst.String():
+		return ChartTypeSunburst, nil
+	default:
+		return ChartTypeNone, fmt.Errorf("unsupported chart type: %q", s)
+	}
+}
+
+// ComplianceStatus represents the status of a metric evaluated against a policy.
+type ComplianceStatus int
+
+const (
+	// ComplianceStatusNone is the compliance status that means no policy rule applies.
+	ComplianceStatusNone ComplianceStatus = iota
+
+	// ComplianceStatusOK is the compliance status that means the value is within the limits.
+	ComplianceStatusOK
+
+	// ComplianceStatusWarning is the compliance status that means the value exceeds the warning level.
+	ComplianceStatusWarning
+
+	// ComplianceStatusCritical is the compliance status that means the value exceeds the critical level.
+	ComplianceStatusCritical
+)
+
+// String returns the string representation of the compliance status.
+func (t ComplianceStatus) String() string {
+	switch t {
+	case ComplianceStatusNone:
+		return "none"
+	case ComplianceStatusOK:
+		return "ok"
+	case ComplianceStatusWarning:
+		return "warning"
+	case ComplianceStatusCritical:
+		return "critical"
+	default:
+		return ""
+	}
+}
+
+// MarshalJSON returns the JSON representation of the compliance status.
+func (t ComplianceStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// ParseComplianceStatus parses the compliance status from the string representation.
+func ParseComplianceStatus(s string) (ComplianceStatus, error) {
+	switch s {
+	case ComplianceStatusOK.String():
+		return ComplianceStatusOK, nil
+	case ComplianceStatusWarning.String():
+		return ComplianceStatusWarning, nil
+	case ComplianceStatusCritical.String():
+		return ComplianceStatusCritical, nil
+	default:
+		return ComplianceStatusNone, fmt.Errorf("unsupported compliance status: %q", s)
+	}
+}
+
+// BucketType represents the type of a bucket.
+type BucketType int
+
+const (
+	// BucketTypeNone is the bucket type that means none.
+	BucketTypeNone BucketType = iota
+
+	// BucketTypeGeneralPurpose is the bucket type that means a general purpose bucket.
+	BucketTypeGeneralPurpose
+
+	// BucketTypeDirectory is the bucket type that means a directory bucket of S3 Express One Zone.
+	BucketTypeDirectory
+)
+
+// String returns the string representation of the bucket type.
+func (t BucketType) String() string {
+	switch t {
+	case BucketTypeNone:
+		return "none"
+	case BucketTypeGeneralPurpose:
+		return "general-purpose"
+	case BucketTypeDirectory:
+		return "directory"
+	default:
+		return ""
+	}
+}
+
+// MarshalJSON returns the JSON representation of the bucket type.
+func (t BucketType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// ParseBucketType parses the bucket type from the string representation.
+func ParseBucketType(s string) (BucketType, error) {
+	switch s {
+	case BucketTypeGeneralPurpose.String():
+		return BucketTypeGeneralPurpose, nil
+	case BucketTypeDirectory.String():
+		return BucketTypeDirectory, nil
+	default:
+		return BucketTypeNone, fmt.Errorf("unsupported bucket type: %q", s)
+	}
+}
+
+// ForecastModel represents the model fitted to the daily series to forecast the values.
+type ForecastModel int
+
+const (
+	// ForecastModelNone is the forecast model that means none.
+	ForecastModelNone ForecastModel = iota
+
+	// ForecastModelLinear is the forecast model that means a linear trend.
+	ForecastModelLinear
+
+	// ForecastModelSeasonal is the forecast model that means a linear trend with a weekly seasonality.
+	ForecastModelSeasonal
+)
+
+// String returns the string representation of the forecast model.
+func (t ForecastModel) String() string {
+	switch t {
+	case ForecastModelNone:
+		return "none"
+	case ForecastModelLinear:
+		return "linear"
+	case ForecastModelSeasonal:
+		return "seasonal"
+	default:
+		return ""
+	}
+}
+
+// MarshalJSON returns the JSON representation of the forecast model.
+func (t ForecastModel) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// ParseForecastModel parses the forecast model from the string representation.
+func ParseForecastModel(s string) (ForecastModel, error) {
+	switch s {
+	case ForecastModelLinear.String():
+		return ForecastModelLinear, nil
+	case ForecastModelSeasonal.String():
+		return ForecastModelSeasonal, nil
+	default:
+		return ForecastModelNone, fmt.Errorf("unsupported forecast model: %q", s)
+	}
+}
+
+// EventType represents the type of the progress event reported to the observers of the manager.
+type EventType int
+
+const (
+	// EventTypeNone is the event type that means none.
+	EventTypeNone EventType = iota
+
+	// EventTypeRegionStarted is the event type that means the listing of a region has started.
+	EventTypeRegionStarted
+
+	// EventTypeBucketsListed is the event type that means the buckets in a region have been listed.
+	EventTypeBucketsListed
+
+	// EventTypeBatchSent is the event type that means a batch of queries has been sent to the source.
+	EventTypeBatchSent
+
+	// EventTypeRegionFinished is the event type that means the listing of a region has finished.
+	EventTypeRegionFinished
+
+	// EventTypeRegionFailed is the event type that means the listing of a region has failed.
+	EventTypeRegionFailed
+)
+
+// String returns the string representation of the event type.
+func (t EventType) String() string {
+	switch t {
+	case EventTypeNone:
+		return "none"
+	case EventTypeRegionStarted:
+		return "regionstarted"
+	case EventTypeBucketsListed:
+		return "bucketslisted"
+	case EventTypeBatchSent:
+		return "batchsent"
+	case EventTypeRegionFinished:
+		return "regionfinished"
+	case EventTypeRegionFailed:
+		return "regionfailed"
+	default:
+		return ""
+	}
+}
+
+// MarshalJSON returns the JSON representation of the event type.
+func (t EventType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// ParseEventType parses the event type from the string representation.
+func ParseEventType(s string) (EventType, error) {
+	switch s {
+	case EventTypeRegionStarted.String():
+		return EventTypeRegionStarted, nil
+	case EventTypeBucketsListed.String():
+		return EventTypeBucketsListed, nil
+	case EventTypeBatchSent.String():
+		return EventTypeBatchSent, nil
+	case EventTypeRegionFinished.String():
+		return EventTypeRegionFinished, nil
+	case EventTypeRegionFailed.String():
+		return EventTypeRegionFailed, nil
+	default:
+		return EventTypeNone, fmt.Errorf("unsupported event type: %q", s)
+	}
+}
@@ -52,6 +52,21 @@ func TestOutputType_String(t *testing.T) {
 			tr:   OutputTypeChart,
 			want: "chart",
 		},
+		{
+			name: "html",
+			tr:   OutputTypeHTML,
+			want: "html",
+		},
+		{
+			name: "svg",
+			tr:   OutputTypeSVG,
+			want: "svg",
+		},
+		{
+			name: "png",
+			tr:   OutputTypePNG,
+			want: "png",
+		},
 		{
 			name: "none",
 			tr:   OutputTypeNone,
@@ -114,6 +129,21 @@ func TestOutputType_MarshalJSON(t *testing.T) {
 			tr:   OutputTypeChart,
 			want: []byte(`"chart"`),
 		},
+		{
+			name: "html",
+			tr:   OutputTypeHTML,
+			want: []byte(`"html"`),
+		},
+		{
+			name: "svg",
+			tr:   OutputTypeSVG,
+			want: []byte(`"svg"`),
+		},
+		{
+			name: "png",
+			tr:   OutputTypePNG,
+			want: []byte(`"png"`),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -203,6 +233,30 @@ func TestParseOutputType(t *testing.T) {
 			want:    OutputTypeChart,
 			wantErr: false,
 		},
+		{
+			name: "html",
+			args: args{
+				s: "html",
+			},
+			want:    OutputTypeHTML,
+			wantErr: false,
+		},
+		{
+			name: "svg",
+			args: args{
+				s: "svg",
+			},
+			want:    OutputTypeSVG,
+			wantErr: false,
+		},
+		{
+			name: "png",
+			args: args{
+				s: "png",
+			},
+			want:    OutputTypePNG,
+			wantErr: false,
+		},
 		{
 			name: "unsupported",
 			args: args{
@@ -242,6 +296,86 @@ func TestMetricName_String(t *testing.T) {
 			tr:   MetricNameNumberOfObjects,
 			want: "NumberOfObjects",
 		},
+		{
+			name: "all requests",
+			tr:   MetricNameAllRequests,
+			want: "AllRequests",
+		},
+		{
+			name: "get requests",
+			tr:   MetricNameGetRequests,
+			want: "GetRequests",
+		},
+		{
+			name: "put requests",
+			tr:   MetricNamePutRequests,
+			want: "PutRequests",
+		},
+		{
+			name: "delete requests",
+			tr:   MetricNameDeleteRequests,
+			want: "DeleteRequests",
+		},
+		{
+			name: "head requests",
+			tr:   MetricNameHeadRequests,
+			want: "HeadRequests",
+		},
+		{
+			name: "post requests",
+			tr:   MetricNamePostRequests,
+			want: "PostRequests",
+		},
+		{
+			name: "select requests",
+			tr:   MetricNameSelectRequests,
+			want: "SelectRequests",
+		},
+		{
+			name: "select bytes scanned",
+			tr:   MetricNameSelectBytesScanned,
+			want: "SelectBytesScanned",
+		},
+		{
+			name: "select bytes returned",
+			tr:   MetricNameSelectBytesReturned,
+			want: "SelectBytesReturned",
+		},
+		{
+			name: "list requests",
+			tr:   MetricNameListRequests,
+			want: "ListRequests",
+		},
+		{
+			name: "bytes downloaded",
+			tr:   MetricNameBytesDownloaded,
+			want: "BytesDownloaded",
+		},
+		{
+			name: "bytes uploaded",
+			tr:   MetricNameBytesUploaded,
+			want: "BytesUploaded",
+		},
+		{
+			name: "4xx errors",
+			tr:   MetricName4xxErrors,
+			want: "4xxErrors",
+		},
+		{
+			name: "5xx errors",
+			tr:   MetricName5xxErrors,
+			want: "5xxErrors",
+		},
+		{
+			name: "first byte latency",
+			tr:   MetricNameFirstByteLatency,
+			want: "FirstByteLatency",
+		},
+		{
+			name: "total request latency",
+			tr:   MetricNameTotalRequestLatency,
+			want: "TotalRequestLatency",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -269,6 +403,86 @@ func TestMetricName_MarshalJSON(t *testing.T) {
 			tr:   MetricNameNumberOfObjects,
 			want: []byte(`"NumberOfObjects"`),
 		},
+		{
+			name: "all requests",
+			tr:   MetricNameAllRequests,
+			want: []byte(`"AllRequests"`),
+		},
+		{
+			name: "get requests",
+			tr:   MetricNameGetRequests,
+			want: []byte(`"GetRequests"`),
+		},
+		{
+			name: "put requests",
+			tr:   MetricNamePutRequests,
+			want: []byte(`"PutRequests"`),
+		},
+		{
+			name: "delete requests",
+			tr:   MetricNameDeleteRequests,
+			want: []byte(`"DeleteRequests"`),
+		},
+		{
+			name: "head requests",
+			tr:   MetricNameHeadRequests,
+			want: []byte(`"HeadRequests"`),
+		},
+		{
+			name: "post requests",
+			tr:   MetricNamePostRequests,
+			want: []byte(`"PostRequests"`),
+		},
+		{
+			name: "select requests",
+			tr:   MetricNameSelectRequests,
+			want: []byte(`"SelectRequests"`),
+		},
+		{
+			name: "select bytes scanned",
+			tr:   MetricNameSelectBytesScanned,
+			want: []byte(`"SelectBytesScanned"`),
+		},
+		{
+			name: "select bytes returned",
+			tr:   MetricNameSelectBytesReturned,
+			want: []byte(`"SelectBytesReturned"`),
+		},
+		{
+			name: "list requests",
+			tr:   MetricNameListRequests,
+			want: []byte(`"ListRequests"`),
+		},
+		{
+			name: "bytes downloaded",
+			tr:   MetricNameBytesDownloaded,
+			want: []byte(`"BytesDownloaded"`),
+		},
+		{
+			name: "bytes uploaded",
+			tr:   MetricNameBytesUploaded,
+			want: []byte(`"BytesUploaded"`),
+		},
+		{
+			name: "4xx errors",
+			tr:   MetricName4xxErrors,
+			want: []byte(`"4xxErrors"`),
+		},
+		{
+			name: "5xx errors",
+			tr:   MetricName5xxErrors,
+			want: []byte(`"5xxErrors"`),
+		},
+		{
+			name: "first byte latency",
+			tr:   MetricNameFirstByteLatency,
+			want: []byte(`"FirstByteLatency"`),
+		},
+		{
+			name: "total request latency",
+			tr:   MetricNameTotalRequestLatency,
+			want: []byte(`"TotalRequestLatency"`),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -310,6 +524,134 @@ func TestParseMetricName(t *testing.T) {
 			want:    MetricNameNumberOfObjects,
 			wantErr: false,
 		},
+		{
+			name: "all requests",
+			args: args{
+				s: "AllRequests",
+			},
+			want:    MetricNameAllRequests,
+			wantErr: false,
+		},
+		{
+			name: "get requests",
+			args: args{
+				s: "GetRequests",
+			},
+			want:    MetricNameGetRequests,
+			wantErr: false,
+		},
+		{
+			name: "put requests",
+			args: args{
+				s: "PutRequests",
+			},
+			want:    MetricNamePutRequests,
+			wantErr: false,
+		},
+		{
+			name: "delete requests",
+			args: args{
+				s: "DeleteRequests",
+			},
+			want:    MetricNameDeleteRequests,
+			wantErr: false,
+		},
+		{
+			name: "head requests",
+			args: args{
+				s: "HeadRequests",
+			},
+			want:    MetricNameHeadRequests,
+			wantErr: false,
+		},
+		{
+			name: "post requests",
+			args: args{
+				s: "PostRequests",
+			},
+			want:    MetricNamePostRequests,
+			wantErr: false,
+		},
+		{
+			name: "select requests",
+			args: args{
+				s: "SelectRequests",
+			},
+			want:    MetricNameSelectRequests,
+			wantErr: false,
+		},
+		{
+			name: "select bytes scanned",
+			args: args{
+				s: "SelectBytesScanned",
+			},
+			want:    MetricNameSelectBytesScanned,
+			wantErr: false,
+		},
+		{
+			name: "select bytes returned",
+			args: args{
+				s: "SelectBytesReturned",
+			},
+			want:    MetricNameSelectBytesReturned,
+			wantErr: false,
+		},
+		{
+			name: "list requests",
+			args: args{
+				s: "ListRequests",
+			},
+			want:    MetricNameListRequests,
+			wantErr: false,
+		},
+		{
+			name: "bytes downloaded",
+			args: args{
+				s: "BytesDownloaded",
+			},
+			want:    MetricNameBytesDownloaded,
+			wantErr: false,
+		},
+		{
+			name: "bytes uploaded",
+			args: args{
+				s: "BytesUploaded",
+			},
+			want:    MetricNameBytesUploaded,
+			wantErr: false,
+		},
+		{
+			name: "4xx errors",
+			args: args{
+				s: "4xxErrors",
+			},
+			want:    MetricName4xxErrors,
+			wantErr: false,
+		},
+		{
+			name: "5xx errors",
+			args: args{
+				s: "5xxErrors",
+			},
+			want:    MetricName5xxErrors,
+			wantErr: false,
+		},
+		{
+			name: "first byte latency",
+			args: args{
+				s: "FirstByteLatency",
+			},
+			want:    MetricNameFirstByteLatency,
+			wantErr: false,
+		},
+		{
+			name: "total request latency",
+			args: args{
+				s: "TotalRequestLatency",
+			},
+			want:    MetricNameTotalRequestLatency,
+			wantErr: false,
+		},
 		{
 			name: "unsupported",
 			args: args{
@@ -449,6 +791,11 @@ func TestStorageType_String(t *testing.T) {
 			tr:   StorageTypeDeepArchiveStagingStorage,
 			want: "DeepArchiveStagingStorage",
 		},
+		{
+			name: "ExpressOneZoneStorage",
+			tr:   StorageTypeExpressOneZoneStorage,
+			want: "ExpressOneZoneStorage",
+		},
 		{
 			name: "AllStorageTypes",
 			tr:   StorageTypeAllStorageTypes,
@@ -586,6 +933,11 @@ func TestStorageType_MarshalJSON(t *testing.T) {
 			tr:   StorageTypeDeepArchiveStagingStorage,
 			want: []byte(`"DeepArchiveStagingStorage"`),
 		},
+		{
+			name: "ExpressOneZoneStorage",
+			tr:   StorageTypeExpressOneZoneStorage,
+			want: []byte(`"ExpressOneZoneStorage"`),
+		},
 		{
 			name: "AllStorageTypes",
 			tr:   StorageTypeAllStorageTypes,
@@ -797,6 +1149,14 @@ func TestParseStorageType(t *testing.T) {
 			want:    StorageTypeDeepArchiveStagingStorage,
 			wantErr: false,
 		},
+		{
+			name: "ExpressOneZoneStorage",
+			args: args{
+				s: "ExpressOneZoneStorage",
+			},
+			want:    StorageTypeExpressOneZoneStorage,
+			wantErr: false,
+		},
 		{
 			name: "AllStorageTypes",
 			args: args{
@@ -867,3 +1227,1186 @@ func TestParseStorageType(t *testing.T) {
 		})
 	}
 }
+
+func TestScanMode_String(t *testing.T) {
+	tests := []struct {
+		name string
+		tr   ScanMode
+		want string
+	}{
+		{
+			name: "none",
+			tr:   ScanModeNone,
+			want: "none",
+		},
+		{
+			name: "fallback",
+			tr:   ScanModeFallback,
+			want: "fallback",
+		},
+		{
+			name: "force",
+			tr:   ScanModeForce,
+			want: "force",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tr.String(); got != tt.want {
+				t.Errorf("ScanMode.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanMode_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		tr      ScanMode
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "fallback",
+			tr:   ScanModeFallback,
+			want: []byte(`"fallback"`),
+		},
+		{
+			name: "force",
+			tr:   ScanModeForce,
+			want: []byte(`"force"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.tr.MarshalJSON()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ScanMode.MarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ScanMode.MarshalJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseScanMode(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    ScanMode
+		wantErr bool
+	}{
+		{
+			name: "none",
+			args: args{
+				s: "none",
+			},
+			want:    ScanModeNone,
+			wantErr: false,
+		},
+		{
+			name: "fallback",
+			args: args{
+				s: "fallback",
+			},
+			want:    ScanModeFallback,
+			wantErr: false,
+		},
+		{
+			name: "force",
+			args: args{
+				s: "force",
+			},
+			want:    ScanModeForce,
+			wantErr: false,
+		},
+		{
+			name: "unsupported",
+			args: args{
+				s: "unsupported",
+			},
+			want:    ScanModeNone,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseScanMode(tt.args.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseScanMode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseScanMode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceType_String(t *testing.T) {
+	tests := []struct {
+		name string
+		tr   SourceType
+		want string
+	}{
+		{
+			name: "none",
+			tr:   SourceTypeNone,
+			want: "none",
+		},
+		{
+			name: "cloudwatch",
+			tr:   SourceTypeCloudWatch,
+			want: "cloudwatch",
+		},
+		{
+			name: "scan",
+			tr:   SourceTypeScan,
+			want: "scan",
+		},
+		{
+			name: "inventory",
+			tr:   SourceTypeInventory,
+			want: "inventory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tr.String(); got != tt.want {
+				t.Errorf("SourceType.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceType_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		tr      SourceType
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "cloudwatch",
+			tr:   SourceTypeCloudWatch,
+			want: []byte(`"cloudwatch"`),
+		},
+		{
+			name: "scan",
+			tr:   SourceTypeScan,
+			want: []byte(`"scan"`),
+		},
+		{
+			name: "inventory",
+			tr:   SourceTypeInventory,
+			want: []byte(`"inventory"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.tr.MarshalJSON()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SourceType.MarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SourceType.MarshalJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSourceType(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    SourceType
+		wantErr bool
+	}{
+		{
+			name: "cloudwatch",
+			args: args{
+				s: "cloudwatch",
+			},
+			want:    SourceTypeCloudWatch,
+			wantErr: false,
+		},
+		{
+			name: "scan",
+			args: args{
+				s: "scan",
+			},
+			want:    SourceTypeScan,
+			wantErr: false,
+		},
+		{
+			name: "inventory",
+			args: args{
+				s: "inventory",
+			},
+			want:    SourceTypeInventory,
+			wantErr: false,
+		},
+		{
+			name: "unsupported",
+			args: args{
+				s: "unsupported",
+			},
+			want:    SourceTypeNone,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSourceType(tt.args.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSourceType() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseSourceType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataStatus_String(t *testing.T) {
+	tests := []struct {
+		name string
+		tr   DataStatus
+		want string
+	}{
+		{
+			name: "none",
+			tr:   DataStatusNone,
+			want: "none",
+		},
+		{
+			name: "ok",
+			tr:   DataStatusOK,
+			want: "ok",
+		},
+		{
+			name: "no data",
+			tr:   DataStatusNoData,
+			want: "no-data",
+		},
+		{
+			name: "partial",
+			tr:   DataStatusPartial,
+			want: "partial",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tr.String(); got != tt.want {
+				t.Errorf("DataStatus.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataStatus_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		tr      DataStatus
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			tr:   DataStatusOK,
+			want: []byte(`"ok"`),
+		},
+		{
+			name: "no data",
+			tr:   DataStatusNoData,
+			want: []byte(`"no-data"`),
+		},
+		{
+			name: "partial",
+			tr:   DataStatusPartial,
+			want: []byte(`"partial"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.tr.MarshalJSON()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DataStatus.MarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DataStatus.MarshalJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDataStatus(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    DataStatus
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			args: args{
+				s: "ok",
+			},
+			want:    DataStatusOK,
+			wantErr: false,
+		},
+		{
+			name: "no data",
+			args: args{
+				s: "no-data",
+			},
+			want:    DataStatusNoData,
+			wantErr: false,
+		},
+		{
+			name: "partial",
+			args: args{
+				s: "partial",
+			},
+			want:    DataStatusPartial,
+			wantErr: false,
+		},
+		{
+			name: "unsupported",
+			args: args{
+				s: "unsupported",
+			},
+			want:    DataStatusNone,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDataStatus(tt.args.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseDataStatus() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseDataStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoDataMode_String(t *testing.T) {
+	tests := []struct {
+		name string
+		tr   NoDataMode
+		want string
+	}{
+		{
+			name: "none",
+			tr:   NoDataModeNone,
+			want: "none",
+		},
+		{
+			name: "show",
+			tr:   NoDataModeShow,
+			want: "show",
+		},
+		{
+			name: "hide",
+			tr:   NoDataModeHide,
+			want: "hide",
+		},
+		{
+			name: "highlight",
+			tr:   NoDataModeHighlight,
+			want: "highlight",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tr.String(); got != tt.want {
+				t.Errorf("NoDataMode.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoDataMode_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		tr      NoDataMode
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "show",
+			tr:   NoDataModeShow,
+			want: []byte(`"show"`),
+		},
+		{
+			name: "hide",
+			tr:   NoDataModeHide,
+			want: []byte(`"hide"`),
+		},
+		{
+			name: "highlight",
+			tr:   NoDataModeHighlight,
+			want: []byte(`"highlight"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.tr.MarshalJSON()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NoDataMode.MarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NoDataMode.MarshalJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNoDataMode(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    NoDataMode
+		wantErr bool
+	}{
+		{
+			name: "show",
+			args: args{
+				s: "show",
+			},
+			want:    NoDataModeShow,
+			wantErr: false,
+		},
+		{
+			name: "hide",
+			args: args{
+				s: "hide",
+			},
+			want:    NoDataModeHide,
+			wantErr: false,
+		},
+		{
+			name: "highlight",
+			args: args{
+				s: "highlight",
+			},
+			want:    NoDataModeHighlight,
+			wantErr: false,
+		},
+		{
+			name: "unsupported",
+			args: args{
+				s: "unsupported",
+			},
+			want:    NoDataModeNone,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNoDataMode(tt.args.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseNoDataMode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseNoDataMode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChartType_String(t *testing.T) {
+	tests := []struct {
+		name string
+		tr   ChartType
+		want string
+	}{
+		{
+			name: "none",
+			tr:   ChartTypeNone,
+			want: "none",
+		},
+		{
+			name: "pie",
+			tr:   ChartTypePie,
+			want: "pie",
+		},
+		{
+			name: "bar",
+			tr:   ChartTypeBar,
+			want: "bar",
+		},
+		{
+			name: "treemap",
+			tr:   ChartTypeTreeMap,
+			want: "treemap",
+		},
+		{
+			name: "sunburst",
+			tr:   ChartTypeSunburst,
+			want: "sunburst",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tr.String(); got != tt.want {
+				t.Errorf("ChartType.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChartType_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		tr      ChartType
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "none",
+			tr:   ChartTypeNone,
+			want: []byte(`"none"`),
+		},
+		{
+			name: "pie",
+			tr:   ChartTypePie,
+			want: []byte(`"pie"`),
+		},
+		{
+			name: "bar",
+			tr:   ChartTypeBar,
+			want: []byte(`"bar"`),
+		},
+		{
+			name: "treemap",
+			tr:   ChartTypeTreeMap,
+			want: []byte(`"treemap"`),
+		},
+		{
+			name: "sunburst",
+			tr:   ChartTypeSunburst,
+			want: []byte(`"sunburst"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.tr.MarshalJSON()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ChartType.MarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChartType.MarshalJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseChartType(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    ChartType
+		wantErr bool
+	}{
+		{
+			name: "pie",
+			args: args{
+				s: "pie",
+			},
+			want:    ChartTypePie,
+			wantErr: false,
+		},
+		{
+			name: "bar",
+			args: args{
+				s: "bar",
+			},
+			want:    ChartTypeBar,
+			wantErr: false,
+		},
+		{
+			name: "treemap",
+			args: args{
+				s: "treemap",
+			},
+			want:    ChartTypeTreeMap,
+			wantErr: false,
+		},
+		{
+			name: "sunburst",
+			args: args{
+				s: "sunburst",
+			},
+			want:    ChartTypeSunburst,
+			wantErr: false,
+		},
+		{
+			name: "unsupported",
+			args: args{
+				s: "unsupported",
+			},
+			want:    ChartTypeNone,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseChartType(tt.args.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseChartType() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseChartType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComplianceStatus_String(t *testing.T) {
+	tests := []struct {
+		name string
+		tr   ComplianceStatus
+		want string
+	}{
+		{
+			name: "none",
+			tr:   ComplianceStatusNone,
+			want: "none",
+		},
+		{
+			name: "ok",
+			tr:   ComplianceStatusOK,
+			want: "ok",
+		},
+		{
+			name: "warning",
+			tr:   ComplianceStatusWarning,
+			want: "warning",
+		},
+		{
+			name: "critical",
+			tr:   ComplianceStatusCritical,
+			want: "critical",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tr.String(); got != tt.want {
+				t.Errorf("ComplianceStatus.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComplianceStatus_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		tr      ComplianceStatus
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			tr:   ComplianceStatusOK,
+			want: []byte(`"ok"`),
+		},
+		{
+			name: "warning",
+			tr:   ComplianceStatusWarning,
+			want: []byte(`"warning"`),
+		},
+		{
+			name: "critical",
+			tr:   ComplianceStatusCritical,
+			want: []byte(`"critical"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.tr.MarshalJSON()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ComplianceStatus.MarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ComplianceStatus.MarshalJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseComplianceStatus(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    ComplianceStatus
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			args: args{
+				s: "ok",
+			},
+			want:    ComplianceStatusOK,
+			wantErr: false,
+		},
+		{
+			name: "warning",
+			args: args{
+				s: "warning",
+			},
+			want:    ComplianceStatusWarning,
+			wantErr: false,
+		},
+		{
+			name: "critical",
+			args: args{
+				s: "critical",
+			},
+			want:    ComplianceStatusCritical,
+			wantErr: false,
+		},
+		{
+			name: "unsupported",
+			args: args{
+				s: "unsupported",
+			},
+			want:    ComplianceStatusNone,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseComplianceStatus(tt.args.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseComplianceStatus() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseComplianceStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBucketType_String(t *testing.T) {
+	tests := []struct {
+		name string
+		tr   BucketType
+		want string
+	}{
+		{
+			name: "none",
+			tr:   BucketTypeNone,
+			want: "none",
+		},
+		{
+			name: "general purpose",
+			tr:   BucketTypeGeneralPurpose,
+			want: "general-purpose",
+		},
+		{
+			name: "directory",
+			tr:   BucketTypeDirectory,
+			want: "directory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tr.String(); got != tt.want {
+				t.Errorf("BucketType.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBucketType_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		tr      BucketType
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "general purpose",
+			tr:   BucketTypeGeneralPurpose,
+			want: []byte(`"general-purpose"`),
+		},
+		{
+			name: "directory",
+			tr:   BucketTypeDirectory,
+			want: []byte(`"directory"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.tr.MarshalJSON()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BucketType.MarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BucketType.MarshalJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBucketType(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    BucketType
+		wantErr bool
+	}{
+		{
+			name: "general purpose",
+			args: args{
+				s: "general-purpose",
+			},
+			want:    BucketTypeGeneralPurpose,
+			wantErr: false,
+		},
+		{
+			name: "directory",
+			args: args{
+				s: "directory",
+			},
+			want:    BucketTypeDirectory,
+			wantErr: false,
+		},
+		{
+			name: "unsupported",
+			args: args{
+				s: "unsupported",
+			},
+			want:    BucketTypeNone,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBucketType(tt.args.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseBucketType() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseBucketType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForecastModel_String(t *testing.T) {
+	tests := []struct {
+		name string
+		tr   ForecastModel
+		want string
+	}{
+		{
+			name: "none",
+			tr:   ForecastModelNone,
+			want: "none",
+		},
+		{
+			name: "linear",
+			tr:   ForecastModelLinear,
+			want: "linear",
+		},
+		{
+			name: "seasonal",
+			tr:   ForecastModelSeasonal,
+			want: "seasonal",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tr.String(); got != tt.want {
+				t.Errorf("ForecastModel.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForecastModel_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		tr      ForecastModel
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "linear",
+			tr:   ForecastModelLinear,
+			want: []byte(`"linear"`),
+		},
+		{
+			name: "seasonal",
+			tr:   ForecastModelSeasonal,
+			want: []byte(`"seasonal"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.tr.MarshalJSON()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ForecastModel.MarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ForecastModel.MarshalJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseForecastModel(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    ForecastModel
+		wantErr bool
+	}{
+		{
+			name: "linear",
+			args: args{
+				s: "linear",
+			},
+			want:    ForecastModelLinear,
+			wantErr: false,
+		},
+		{
+			name: "seasonal",
+			args: args{
+				s: "seasonal",
+			},
+			want:    ForecastModelSeasonal,
+			wantErr: false,
+		},
+		{
+			name: "unsupported",
+			args: args{
+				s: "unsupported",
+			},
+			want:    ForecastModelNone,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseForecastModel(tt.args.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseForecastModel() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseForecastModel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventType_String(t *testing.T) {
+	tests := []struct {
+		name string
+		tr   EventType
+		want string
+	}{
+		{
+			name: "none",
+			tr:   EventTypeNone,
+			want: "none",
+		},
+		{
+			name: "region started",
+			tr:   EventTypeRegionStarted,
+			want: "regionstarted",
+		},
+		{
+			name: "buckets listed",
+			tr:   EventTypeBucketsListed,
+			want: "bucketslisted",
+		},
+		{
+			name: "batch sent",
+			tr:   EventTypeBatchSent,
+			want: "batchsent",
+		},
+		{
+			name: "region finished",
+			tr:   EventTypeRegionFinished,
+			want: "regionfinished",
+		},
+		{
+			name: "region failed",
+			tr:   EventTypeRegionFailed,
+			want: "regionfailed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tr.String(); got != tt.want {
+				t.Errorf("EventType.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventType_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		tr      EventType
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "region started",
+			tr:   EventTypeRegionStarted,
+			want: []byte(`"regionstarted"`),
+		},
+		{
+			name: "buckets listed",
+			tr:   EventTypeBucketsListed,
+			want: []byte(`"bucketslisted"`),
+		},
+		{
+			name: "batch sent",
+			tr:   EventTypeBatchSent,
+			want: []byte(`"batchsent"`),
+		},
+		{
+			name: "region finished",
+			tr:   EventTypeRegionFinished,
+			want: []byte(`"regionfinished"`),
+		},
+		{
+			name: "region failed",
+			tr:   EventTypeRegionFailed,
+			want: []byte(`"regionfailed"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.tr.MarshalJSON()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("EventType.MarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EventType.MarshalJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEventType(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    EventType
+		wantErr bool
+	}{
+		{
+			name: "region started",
+			args: args{
+				s: "regionstarted",
+			},
+			want:    EventTypeRegionStarted,
+			wantErr: false,
+		},
+		{
+			name: "buckets listed",
+			args: args{
+				s: "bucketslisted",
+			},
+			want:    EventTypeBucketsListed,
+			wantErr: false,
+		},
+		{
+			name: "batch sent",
+			args: args{
+				s: "batchsent",
+			},
+			want:    EventTypeBatchSent,
+			wantErr: false,
+		},
+		{
+			name: "region finished",
+			args: args{
+				s: "regionfinished",
+			},
+			want:    EventTypeRegionFinished,
+			wantErr: false,
+		},
+		{
+			name: "region failed",
+			args: args{
+				s: "regionfailed",
+			},
+			want:    EventTypeRegionFailed,
+			wantErr: false,
+		},
+		{
+			name: "unsupported",
+			args: args{
+				s: "unsupported",
+			},
+			want:    EventTypeNone,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEventType(tt.args.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseEventType() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseEventType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
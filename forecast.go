@@ -0,0 +1,178 @@
+package s3bytes
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// minTrendPoints is the minimum number of datapoints to fit a trend.
+	minTrendPoints = 2
+
+	// seasonLength is the number of days in a season of the seasonal model.
+	seasonLength = 7
+
+	// minSeasonalPoints is the minimum number of datapoints to estimate the seasonality,
+	// so that each day of the week is observed at least twice.
+	minSeasonalPoints = seasonLength * 2
+
+	// maxQuotaDays is the horizon in days from the origin beyond which the quota is regarded as never reached.
+	maxQuotaDays = 100 * 365
+)
+
+// forecastHeader is the columns appended to the header of the forecast.
+var forecastHeader = []string{
+	"GrowthPerDay",
+	"Forecast30d",
+	"Forecast90d",
+	"Forecast365d",
+	"QuotaDate",
+}
+
+// Forecast represents the values projected from the trend of the daily series of a bucket.
+// GrowthPerDay is the slope of the trend, the ForecastNd fields are the values projected
+// N days after the last datapoint, and QuotaDate is the date on which the trend reaches the quota.
+// QuotaDate is zero if no quota is given or the trend never reaches it.
+type Forecast struct {
+	GrowthPerDay float64   `json:",omitzero"`
+	Forecast30d  float64   `json:",omitzero"`
+	Forecast90d  float64   `json:",omitzero"`
+	Forecast365d float64   `json:",omitzero"`
+	QuotaDate    time.Time `json:",omitzero"`
+}
+
+// trend represents the least squares line fitted to a series, whose x-axis is the number
+// of days since the origin, and the mean deviation from the line for each day of the week
+// if the seasonality is estimated.
+type trend struct {
+	origin    time.Time
+	intercept float64
+	slope     float64
+	seasonal  []float64
+}
+
+// fitTrend fits the model to the datapoints. The seasonal model falls back to the linear
+// one if the datapoints are too few to estimate the seasonality.
+func fitTrend(points []point, model ForecastModel) (*trend, error) {
+	if len(points) < minTrendPoints {
+		return nil, fmt.Errorf("too few datapoints to fit a trend: %d", len(points))
+	}
+	t := &trend{
+		origin: points[0].timestamp,
+	}
+	var sumX, sumY, sumXX, sumXY float64
+	for _, p := range points {
+		x := t.days(p.timestamp)
+		sumX += x
+		sumY += p.value
+		sumXX += x * x
+		sumXY += x * p.value
+	}
+	n := float64(len(points))
+	if d := n*sumXX - sumX*sumX; d != 0 {
+		t.slope = (n*sumXY - sumX*sumY) / d
+	}
+	t.intercept = (sumY - t.slope*sumX) / n
+	if model == ForecastModelSeasonal && len(points) >= minSeasonalPoints {
+		var (
+			sums   = make([]float64, seasonLength)
+			counts = make([]float64, seasonLength)
+		)
+		for _, p := range points {
+			i := p.timestamp.UTC().Weekday()
+			sums[i] += p.value - t.linear(p.timestamp)
+			counts[i]++
+		}
+		t.seasonal = make([]float64, seasonLength)
+		for i := range t.seasonal {
+			if counts[i] > 0 {
+				t.seasonal[i] = sums[i] / counts[i]
+			}
+		}
+	}
+	return t, nil
+}
+
+// days returns the number of days from the origin to the timestamp.
+func (t *trend) days(timestamp time.Time) float64 {
+	return float64(timestamp.Sub(t.origin)) / float64(day)
+}
+
+// linear returns the value of the line at the timestamp.
+func (t *trend) linear(timestamp time.Time) float64 {
+	return t.intercept + t.slope*t.days(timestamp)
+}
+
+// at returns the value projected at the timestamp, which is never negative.
+func (t *trend) at(timestamp time.Time) float64 {
+	v := t.linear(timestamp)
+	if t.seasonal != nil {
+		v += t.seasonal[timestamp.UTC().Weekday()]
+	}
+	return math.Max(v, 0)
+}
+
+// quotaDate returns the date on which the line reaches the quota, or the date of the last
+// datapoint if its value already reaches it. It returns the zero time if the line is not
+// growing or reaches the quota beyond maxQuotaDays, since the quota is never reached in
+// practice. The days are counted in float64 before converting to a date, which does not
+// overflow for the tiny slopes. The seasonality is ignored.
+func (t *trend) quotaDate(quota float64, last point) time.Time {
+	switch {
+	case last.value >= quota:
+		return last.timestamp.UTC().Truncate(day)
+	case t.slope <= 0:
+		return time.Time{}
+	}
+	days := (quota - t.intercept) / t.slope
+	if math.IsNaN(days) || math.IsInf(days, 0) || days > maxQuotaDays {
+		return time.Time{}
+	}
+	whole := math.Floor(days)
+	date := t.origin.AddDate(0, 0, int(whole)).Add(time.Duration((days - whole) * float64(day)))
+	if date.Before(last.timestamp) {
+		date = last.timestamp
+	}
+	return date.UTC().Truncate(day)
+}
+
+// forecast returns the forecast of the series from the trend. The horizons count from the last datapoint,
+// and the values are rounded to integers since both the bytes and the objects are counted in integers.
+func (t *trend) forecast(s *series, quota float64) Forecast {
+	last := s.last().timestamp
+	f := Forecast{
+		GrowthPerDay: math.Round(t.slope),
+		Forecast30d:  math.Round(t.at(last.Add(30 * day))),
+		Forecast90d:  math.Round(t.at(last.Add(90 * day))),
+		Forecast365d: math.Round(t.at(last.Add(365 * day))),
+	}
+	if quota > 0 {
+		f.QuotaDate = t.quotaDate(quota, s.last())
+	}
+	return f
+}
+
+// ListForecast fits the model to the daily series of each bucket over the last lookback days
+// and projects the values 30, 90 and 365 days ahead. If quota is positive, the date on which
+// each bucket reaches it is estimated as well. The series are fetched from CloudWatch with the
+// same queries as List, so the storage metrics are the only metrics supported. Each row holds
+// the latest datapoint as the value, and the buckets with too few datapoints to fit a trend
+// are left without the forecast.
+func (man *Manager) ListForecast(ctx context.Context, lookback int, model ForecastModel, quota float64) (*MetricData, error) {
+	if model == ForecastModelNone {
+		return nil, fmt.Errorf("unsupported forecast model: %q", model)
+	}
+	if quota < 0 {
+		return nil, fmt.Errorf("invalid quota: %v", quota)
+	}
+	if man.metricName.isRequest() {
+		return nil, fmt.Errorf("%s metric is not supported for forecast", man.metricName)
+	}
+	return man.listSeries(ctx, lookback, forecastHeader, func(metric *Metric, s *series) {
+		if t, err := fitTrend(s.points, model); err == nil {
+			metric.Forecast = t.forecast(s, quota)
+		}
+	})
+}
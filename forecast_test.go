@@ -0,0 +1,447 @@
+package s3bytes
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"golang.org/x/sync/semaphore"
+)
+
+// linearValue grows by 100 a day from 1000.
+func linearValue(i int, _ time.Time) float64 {
+	return 1000 + 100*float64(i)
+}
+
+// seasonalValue grows by 100 a day from 1000 and jumps by 200 on Sundays.
+func seasonalValue(i int, t time.Time) float64 {
+	v := linearValue(i, t)
+	if t.Weekday() == time.Sunday {
+		v += 200
+	}
+	return v
+}
+
+func Test_fitTrend(t *testing.T) {
+	type args struct {
+		points []point
+		model  ForecastModel
+	}
+	tests := []struct {
+		name          string
+		args          args
+		wantIntercept float64
+		wantSlope     float64
+		wantSeasonal  bool
+		wantErr       bool
+	}{
+		{
+			name: "linear",
+			args: args{
+				points: newTestPoints(10, linearValue),
+				model:  ForecastModelLinear,
+			},
+			wantIntercept: 1000,
+			wantSlope:     100,
+		},
+		{
+			name: "flat",
+			args: args{
+				points: newTestPoints(10, func(int, time.Time) float64 { return 500 }),
+				model:  ForecastModelLinear,
+			},
+			wantIntercept: 500,
+			wantSlope:     0,
+		},
+		{
+			name: "seasonal",
+			args: args{
+				points: newTestPoints(28, seasonalValue),
+				model:  ForecastModelSeasonal,
+			},
+			wantIntercept: 1010.8374384236455,
+			wantSlope:     101.31362889983579,
+			wantSeasonal:  true,
+		},
+		{
+			name: "seasonal with too few datapoints",
+			args: args{
+				points: newTestPoints(10, linearValue),
+				model:  ForecastModelSeasonal,
+			},
+			wantIntercept: 1000,
+			wantSlope:     100,
+		},
+		{
+			name: "same timestamps",
+			args: args{
+				points: []point{
+					{timestamp: testSeriesOrigin, value: 100},
+					{timestamp: testSeriesOrigin, value: 300},
+				},
+				model: ForecastModelLinear,
+			},
+			wantIntercept: 200,
+			wantSlope:     0,
+		},
+		{
+			name: "too few datapoints",
+			args: args{
+				points: newTestPoints(1, linearValue),
+				model:  ForecastModelLinear,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fitTrend(tt.args.points, tt.args.model)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("fitTrend() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if math.Abs(got.intercept-tt.wantIntercept) > 1e-6 {
+				t.Errorf("fitTrend() intercept = %v, want %v", got.intercept, tt.wantIntercept)
+			}
+			if math.Abs(got.slope-tt.wantSlope) > 1e-6 {
+				t.Errorf("fitTrend() slope = %v, want %v", got.slope, tt.wantSlope)
+			}
+			if (got.seasonal != nil) != tt.wantSeasonal {
+				t.Errorf("fitTrend() seasonal = %v, wantSeasonal %v", got.seasonal, tt.wantSeasonal)
+			}
+		})
+	}
+}
+
+func Test_trend_forecast(t *testing.T) {
+	type args struct {
+		points []point
+		model  ForecastModel
+		quota  float64
+	}
+	tests := []struct {
+		name string
+		args args
+		want Forecast
+	}{
+		{
+			name: "linear",
+			args: args{
+				points: newTestPoints(10, linearValue),
+				model:  ForecastModelLinear,
+			},
+			want: Forecast{
+				GrowthPerDay: 100,
+				Forecast30d:  4900,
+				Forecast90d:  10900,
+				Forecast365d: 38400,
+			},
+		},
+		{
+			name: "seasonal",
+			args: args{
+				points: newTestPoints(28, seasonalValue),
+				model:  ForecastModelSeasonal,
+			},
+			want: Forecast{
+				GrowthPerDay: 101,
+				Forecast30d:  6760,
+				Forecast90d:  12833,
+				Forecast365d: 40701,
+			},
+		},
+		{
+			name: "linear over seasonal series",
+			args: args{
+				points: newTestPoints(28, seasonalValue),
+				model:  ForecastModelLinear,
+			},
+			want: Forecast{
+				GrowthPerDay: 101,
+				Forecast30d:  6786,
+				Forecast90d:  12865,
+				Forecast365d: 40726,
+			},
+		},
+		{
+			name: "shrinking",
+			args: args{
+				points: newTestPoints(10, func(i int, _ time.Time) float64 { return 1000 - 100*float64(i) }),
+				model:  ForecastModelLinear,
+				quota:  2000,
+			},
+			want: Forecast{
+				GrowthPerDay: -100,
+			},
+		},
+		{
+			name: "quota",
+			args: args{
+				points: newTestPoints(10, linearValue),
+				model:  ForecastModelLinear,
+				quota:  2900,
+			},
+			want: Forecast{
+				GrowthPerDay: 100,
+				Forecast30d:  4900,
+				Forecast90d:  10900,
+				Forecast365d: 38400,
+				QuotaDate:    time.Date(2026, 1, 24, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "quota reached",
+			args: args{
+				points: newTestPoints(10, linearValue),
+				model:  ForecastModelLinear,
+				quota:  1500,
+			},
+			want: Forecast{
+				GrowthPerDay: 100,
+				Forecast30d:  4900,
+				Forecast90d:  10900,
+				Forecast365d: 38400,
+				QuotaDate:    time.Date(2026, 1, 14, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "quota beyond horizon",
+			args: args{
+				points: newTestPoints(10, func(i int, _ time.Time) float64 { return 1000 + float64(i) }),
+				model:  ForecastModelLinear,
+				quota:  1e12,
+			},
+			want: Forecast{
+				GrowthPerDay: 1,
+				Forecast30d:  1039,
+				Forecast90d:  1099,
+				Forecast365d: 1374,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, err := fitTrend(tt.args.points, tt.args.model)
+			if err != nil {
+				t.Fatalf("fitTrend() error = %v", err)
+			}
+			s := &series{bucket: "bucket0", points: tt.args.points}
+			if got := tr.forecast(s, tt.args.quota); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("trend.forecast() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManager_ListForecast(t *testing.T) {
+	listBuckets := func(_ context.Context, _ *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+		return &s3.ListBucketsOutput{
+			Buckets: []s3types.Bucket{
+				{Name: aws.String("bucket0")},
+				{Name: aws.String("bucket1")},
+			},
+		}, nil
+	}
+	points := newTestPoints(10, linearValue)
+	type fields struct {
+		client     *Client
+		metricName MetricName
+		noDataMode NoDataMode
+	}
+	type args struct {
+		lookback int
+		model    ForecastModel
+		quota    float64
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *MetricData
+		wantErr bool
+	}{
+		{
+			name: "linear",
+			fields: fields{
+				client: newMockClient(
+					&mockS3{
+						ListDirectoryBucketsFunc: listNoDirectoryBuckets,
+						ListBucketsFunc:          listBuckets,
+					},
+					newMockSeriesCloudWatch(points),
+				),
+				metricName: MetricNameBucketSizeBytes,
+			},
+			args: args{
+				lookback: 90,
+				model:    ForecastModelLinear,
+				quota:    2900,
+			},
+			want: &MetricData{
+				Header: append(slices.Clone(header), forecastHeader...),
+				Metrics: []*Metric{
+					{
+						BucketName:  "bucket0",
+						Region:      "ap-northeast-1",
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeStandardStorage,
+						Value:       1900,
+						Status:      DataStatusOK,
+						Timestamp:   points[9].timestamp,
+						Source:      SourceTypeCloudWatch,
+						BucketType:  BucketTypeGeneralPurpose,
+						Forecast: Forecast{
+							GrowthPerDay: 100,
+							Forecast30d:  4900,
+							Forecast90d:  10900,
+							Forecast365d: 38400,
+							QuotaDate:    time.Date(2026, 1, 24, 0, 0, 0, 0, time.UTC),
+						},
+					},
+					{
+						BucketName:  "bucket1",
+						Region:      "ap-northeast-1",
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeStandardStorage,
+						Status:      DataStatusNoData,
+						Source:      SourceTypeCloudWatch,
+						BucketType:  BucketTypeGeneralPurpose,
+					},
+				},
+				Total: 1900,
+			},
+		},
+		{
+			name: "hide no data",
+			fields: fields{
+				client: newMockClient(
+					&mockS3{
+						ListDirectoryBucketsFunc: listNoDirectoryBuckets,
+						ListBucketsFunc:          listBuckets,
+					},
+					newMockSeriesCloudWatch(points[9:]),
+				),
+				metricName: MetricNameBucketSizeBytes,
+				noDataMode: NoDataModeHide,
+			},
+			args: args{
+				lookback: 90,
+				model:    ForecastModelSeasonal,
+			},
+			want: &MetricData{
+				Header: append(slices.Clone(header), forecastHeader...),
+				Metrics: []*Metric{
+					{
+						BucketName:  "bucket0",
+						Region:      "ap-northeast-1",
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeStandardStorage,
+						Value:       1900,
+						Status:      DataStatusOK,
+						Timestamp:   points[9].timestamp,
+						Source:      SourceTypeCloudWatch,
+						BucketType:  BucketTypeGeneralPurpose,
+					},
+				},
+				Total: 1900,
+			},
+		},
+		{
+			name: "metric error",
+			fields: fields{
+				client: newMockClient(
+					&mockS3{
+						ListDirectoryBucketsFunc: listNoDirectoryBuckets,
+						ListBucketsFunc:          listBuckets,
+					},
+					&mockCloudWatch{
+						GetMetricDataFunc: func(_ context.Context, _ *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+							return nil, errors.New("error")
+						},
+					},
+				),
+				metricName: MetricNameBucketSizeBytes,
+			},
+			args: args{
+				lookback: 90,
+				model:    ForecastModelLinear,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid lookback",
+			fields: fields{
+				metricName: MetricNameBucketSizeBytes,
+			},
+			args: args{
+				lookback: MaxLookbackDays + 1,
+				model:    ForecastModelLinear,
+			},
+			wantErr: true,
+		},
+		{
+			name: "no model",
+			fields: fields{
+				metricName: MetricNameBucketSizeBytes,
+			},
+			args: args{
+				lookback: 90,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative quota",
+			fields: fields{
+				metricName: MetricNameBucketSizeBytes,
+			},
+			args: args{
+				lookback: 90,
+				model:    ForecastModelLinear,
+				quota:    -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "request metric",
+			fields: fields{
+				metricName: MetricNameAllRequests,
+			},
+			args: args{
+				lookback: 90,
+				model:    ForecastModelLinear,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			man := &Manager{
+				client:      tt.fields.client,
+				metricName:  tt.fields.metricName,
+				storageType: StorageTypeStandardStorage,
+				regions:     []string{"ap-northeast-1"},
+				noDataMode:  tt.fields.noDataMode,
+				sem:         semaphore.NewWeighted(int64(DefaultConcurrency)),
+			}
+			got, err := man.ListForecast(context.Background(), tt.args.lookback, tt.args.model, tt.args.quota)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Manager.ListForecast() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Manager.ListForecast() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package s3bytes
+
+import "fmt"
+
+// noGroup is the group of the metrics whose group-by field is empty, such as the buckets without the tag.
+const noGroup = "(none)"
+
+// groupHeader is the header of the metrics aggregated by GroupMetrics.
+var groupHeader = []string{
+	"Group",
+	"MetricName",
+	"StorageType",
+	"Value",
+}
+
+// GroupMetrics aggregates the values of the metrics by the field of the key, which is one of the
+// string fields accepted by the filter such as "region", "storageType" and "tag_team".
+// The groups are in the order of their first metric, and the prefixes nested in another prefix
+// are not counted twice. Total is carried over from the data.
+func GroupMetrics(data *MetricData, key string) (*MetricData, error) {
+	var (
+		groups = make([]*Metric, 0)
+		index  = make(map[string]*Metric)
+	)
+	for _, metric := range data.Metrics {
+		if !isTopLevel(metric) {
+			continue
+		}
+		v, err := metric.GetField(key)
+		if err != nil {
+			return nil, err
+		}
+		name, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("unsupported group-by key: %q", key)
+		}
+		if name == "" {
+			name = noGroup
+		}
+		group, ok := index[name]
+		if !ok {
+			group = &Metric{
+				MetricName:  metric.MetricName,
+				StorageType: metric.StorageType,
+				Status:      DataStatusOK,
+				Source:      metric.Source,
+				Group:       name,
+			}
+			index[name] = group
+			groups = append(groups, group)
+		}
+		group.Value += metric.Value
+	}
+	return &MetricData{
+		Header:  groupHeader,
+		Metrics: groups,
+		Total:   data.Total,
+	}, nil
+}
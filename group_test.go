@@ -0,0 +1,167 @@
+package s3bytes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupMetrics(t *testing.T) {
+	data := &MetricData{
+		Header: header,
+		Metrics: []*Metric{
+			{
+				BucketName:  "bucket0",
+				Region:      "ap-northeast-1",
+				MetricName:  MetricNameBucketSizeBytes,
+				StorageType: StorageTypeStandardStorage,
+				Value:       4096,
+				Status:      DataStatusOK,
+				Source:      SourceTypeCloudWatch,
+				Tags:        map[string]string{"team": "platform"},
+			},
+			{
+				BucketName:  "bucket1",
+				Region:      "us-east-1",
+				MetricName:  MetricNameBucketSizeBytes,
+				StorageType: StorageTypeStandardStorage,
+				Value:       2048,
+				Status:      DataStatusOK,
+				Source:      SourceTypeCloudWatch,
+			},
+			{
+				BucketName:  "bucket2",
+				Region:      "ap-northeast-1",
+				MetricName:  MetricNameBucketSizeBytes,
+				StorageType: StorageTypeStandardStorage,
+				Value:       1024,
+				Status:      DataStatusOK,
+				Source:      SourceTypeCloudWatch,
+				Tags:        map[string]string{"team": "platform"},
+			},
+		},
+		Total: 7168,
+	}
+	type args struct {
+		data *MetricData
+		key  string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *MetricData
+		wantErr bool
+	}{
+		{
+			name: "tag",
+			args: args{
+				data: data,
+				key:  "tag_team",
+			},
+			want: &MetricData{
+				Header: groupHeader,
+				Metrics: []*Metric{
+					{
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeStandardStorage,
+						Value:       5120,
+						Status:      DataStatusOK,
+						Source:      SourceTypeCloudWatch,
+						Group:       "platform",
+					},
+					{
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeStandardStorage,
+						Value:       2048,
+						Status:      DataStatusOK,
+						Source:      SourceTypeCloudWatch,
+						Group:       noGroup,
+					},
+				},
+				Total: 7168,
+			},
+			wantErr: false,
+		},
+		{
+			name: "region",
+			args: args{
+				data: data,
+				key:  "region",
+			},
+			want: &MetricData{
+				Header: groupHeader,
+				Metrics: []*Metric{
+					{
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeStandardStorage,
+						Value:       5120,
+						Status:      DataStatusOK,
+						Source:      SourceTypeCloudWatch,
+						Group:       "ap-northeast-1",
+					},
+					{
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeStandardStorage,
+						Value:       2048,
+						Status:      DataStatusOK,
+						Source:      SourceTypeCloudWatch,
+						Group:       "us-east-1",
+					},
+				},
+				Total: 7168,
+			},
+			wantErr: false,
+		},
+		{
+			name: "nested prefixes",
+			args: args{
+				data: &MetricData{
+					Header: prefixHeader,
+					Metrics: []*Metric{
+						{BucketName: "bucket0", Prefix: "a/", Value: 3},
+						{BucketName: "bucket0", Prefix: "a/b/", Value: 2},
+						{BucketName: "bucket1", Prefix: "c/", Value: 1},
+					},
+					Total: 4,
+				},
+				key: "bucket",
+			},
+			want: &MetricData{
+				Header: groupHeader,
+				Metrics: []*Metric{
+					{Value: 3, Status: DataStatusOK, Group: "bucket0"},
+					{Value: 1, Status: DataStatusOK, Group: "bucket1"},
+				},
+				Total: 4,
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown key",
+			args: args{
+				data: data,
+				key:  "unknown",
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported key",
+			args: args{
+				data: data,
+				key:  "value",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GroupMetrics(tt.args.data, tt.args.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GroupMetrics() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GroupMetrics() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
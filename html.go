@@ -0,0 +1,221 @@
+package s3bytes
+
+import (
+	"bytes"
+	"cmp"
+	"html/template"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/go-echarts/go-echarts/v2/components"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+// dashboardTemplate is the template of the summary card and the table placed above the charts.
+// The table is sorted by clicking the header and filtered by the search box without any dependency.
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(`
+<style>
+  .s3bytes { font-family: sans-serif; margin: 24px auto; max-width: 1280px; }
+  .s3bytes .cards { display: flex; gap: 16px; margin-bottom: 24px; }
+  .s3bytes .card { flex: 1; border: 1px solid #ddd; border-radius: 8px; padding: 16px; }
+  .s3bytes .card .label { color: #666; font-size: 12px; }
+  .s3bytes .card .value { font-size: 24px; margin-top: 4px; }
+  .s3bytes input { width: 100%; padding: 8px; margin-bottom: 8px; box-sizing: border-box; }
+  .s3bytes table { width: 100%; border-collapse: collapse; font-size: 13px; }
+  .s3bytes th, .s3bytes td { border-bottom: 1px solid #eee; padding: 6px 8px; text-align: left; }
+  .s3bytes th { cursor: pointer; background: #f7f7f7; user-select: none; }
+  .s3bytes td.number { text-align: right; }
+</style>
+<div class="s3bytes">
+  <h1>{{ .Title }}</h1>
+  <div class="cards">
+    <div class="card"><div class="label">Total</div><div class="value">{{ .Total }}</div></div>
+    <div class="card"><div class="label">Buckets</div><div class="value">{{ .Buckets }}</div></div>
+    <div class="card"><div class="label">Largest bucket</div><div class="value">{{ .Largest }}</div></div>
+  </div>
+  <input id="s3bytes-search" type="search" placeholder="Search">
+  <table id="s3bytes-table">
+    <thead><tr>{{ range .Header }}<th>{{ . }}</th>{{ end }}</tr></thead>
+    <tbody>{{ range .Rows }}
+      <tr>{{ range . }}<td{{ if .Number }} class="number" data-value="{{ .Value }}"{{ end }}>{{ .Text }}</td>{{ end }}</tr>{{ end }}
+    </tbody>
+  </table>
+</div>
+<script>
+  (function () {
+    var table = document.getElementById("s3bytes-table");
+    var tbody = table.tBodies[0];
+    document.getElementById("s3bytes-search").addEventListener("input", function (e) {
+      var q = e.target.value.toLowerCase();
+      Array.prototype.forEach.call(tbody.rows, function (row) {
+        row.style.display = row.textContent.toLowerCase().indexOf(q) < 0 ? "none" : "";
+      });
+    });
+    Array.prototype.forEach.call(table.tHead.rows[0].cells, function (th, i) {
+      var asc = false;
+      th.addEventListener("click", function () {
+        asc = !asc;
+        var rows = Array.prototype.slice.call(tbody.rows);
+        rows.sort(function (a, b) {
+          var x = a.cells[i], y = b.cells[i];
+          var c = x.dataset.value !== undefined
+            ? Number(x.dataset.value) - Number(y.dataset.value)
+            : x.textContent.localeCompare(y.textContent);
+          return asc ? c : -c;
+        });
+        rows.forEach(function (row) { tbody.appendChild(row); });
+      });
+    });
+  })();
+</script>
+`))
+
+// dashboard represents the contents of the summary card and the table.
+type dashboard struct {
+	Title   string
+	Total   string
+	Buckets int
+	Largest string
+	Header  []string
+	Rows    [][]dashboardCell
+}
+
+// dashboardCell represents a cell of the table. Number cells are sorted by value.
+type dashboardCell struct {
+	Text   string
+	Value  float64
+	Number bool
+}
+
+// newDashboard summarizes the data for the dashboard. The total is shown as "-" for the latencies,
+// which are not summed up.
+func newDashboard(data *MetricData) *dashboard {
+	d := &dashboard{
+		Title:   "s3bytes",
+		Total:   "-",
+		Largest: "-",
+		Header:  data.Header,
+		Rows:    make([][]dashboardCell, 0, len(data.Metrics)),
+	}
+	var (
+		buckets = make(map[string]struct{})
+		largest *Metric
+	)
+	for _, metric := range data.Metrics {
+		if d.Title == "s3bytes" {
+			if title := getChartTitle(metric.MetricName); title != "" {
+				d.Title = title
+			}
+		}
+		buckets[metric.BucketName] = struct{}{}
+		if largest == nil || metric.Value > largest.Value {
+			largest = metric
+		}
+		texts := metric.toTSV(data.Header)
+		row := make([]dashboardCell, len(texts))
+		for i, text := range texts {
+			row[i] = dashboardCell{Text: text}
+			if data.Header[i] == "Value" {
+				row[i].Value = metric.Value
+				row[i].Number = true
+			}
+		}
+		d.Rows = append(d.Rows, row)
+	}
+	d.Buckets = len(buckets)
+	if len(data.Metrics) == 0 || !data.Metrics[0].MetricName.isLatency() {
+		d.Total = formatValue(data, float64(data.Total))
+	}
+	if largest != nil {
+		d.Largest = getChartLabel(largest) + " (" + formatValue(data, largest.Value) + ")"
+	}
+	return d
+}
+
+// getGroupPieItems aggregates the values by the key for the pie chart in descending order of value.
+func getGroupPieItems(data *MetricData, key func(*Metric) string) []opts.PieData {
+	var (
+		keys   = make([]string, 0)
+		values = make(map[string]float64)
+	)
+	for _, metric := range data.Metrics {
+		if metric.Value == 0 || !isTopLevel(metric) {
+			continue
+		}
+		k := key(metric)
+		if _, ok := values[k]; !ok {
+			keys = append(keys, k)
+		}
+		values[k] += metric.Value
+	}
+	slices.SortStableFunc(keys, func(a, b string) int {
+		return cmp.Compare(values[b], values[a])
+	})
+	items := make([]opts.PieData, len(keys))
+	for i, k := range keys {
+		items[i] = opts.PieData{
+			Name:  k,
+			Value: values[k],
+		}
+	}
+	return items
+}
+
+// toHTML renders a single page with the summary card, the table, and the charts by region,
+// by storage type, and of the largest buckets. The page is written to the file of the chart output
+// if specified, or to the writer of the renderer otherwise. The echarts library is not embedded,
+// so the charts are loaded from the assets host of go-echarts when the page is opened.
+func (ren *Renderer) toHTML() error {
+	if ren.chartOut == "" || ren.chartOut == "-" {
+		return ren.writeHTML(ren.w)
+	}
+	f, err := os.Create(ren.chartOut)
+	if err != nil {
+		return err
+	}
+	if err := ren.writeHTML(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeHTML writes the page of toHTML to the writer.
+func (ren *Renderer) writeHTML(w io.Writer) error {
+	var (
+		d     = newDashboard(ren.Data)
+		page  = components.NewPage()
+		chart = make([]components.Charter, 0, 3)
+	)
+	if pie := newPie(d.Title+" by Region", withPieValues(ren.Data, getGroupPieItems(ren.Data, func(m *Metric) string { return m.Region }))); pie != nil {
+		chart = append(chart, pie)
+	}
+	if pie := newPie(d.Title+" by Storage Type", withPieValues(ren.Data, getGroupPieItems(ren.Data, func(m *Metric) string { return m.StorageType.String() }))); pie != nil {
+		chart = append(chart, pie)
+	}
+	if title, names, items := getBarItems(ren.Data, ren.getTopN()); len(items) > 0 {
+		chart = append(chart, newBar(title+" of Top Buckets", names, items))
+	}
+	page.SetPageTitle(chartFilePrefix)
+	page.SetLayout(components.PageFlexLayout)
+	page.AddCharts(chart...)
+	var buf bytes.Buffer
+	if err := page.Render(&buf); err != nil {
+		return err
+	}
+	var head bytes.Buffer
+	if err := dashboardTemplate.Execute(&head, d); err != nil {
+		return err
+	}
+	body := []byte("<body>")
+	html := buf.Bytes()
+	i := bytes.Index(html, body)
+	if i < 0 {
+		_, err := io.Copy(w, io.MultiReader(&head, &buf))
+		return err
+	}
+	i += len(body)
+	_, err := io.Copy(w, io.MultiReader(bytes.NewReader(html[:i]), &head, bytes.NewReader(html[i:])))
+	return err
+}
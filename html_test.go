@@ -0,0 +1,235 @@
+package s3bytes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+var testDashboardMetricData = &MetricData{
+	Header: header,
+	Metrics: []*Metric{
+		{
+			BucketName:  "bucket0",
+			Region:      "ap-northeast-1",
+			MetricName:  MetricNameBucketSizeBytes,
+			StorageType: StorageTypeStandardStorage,
+			Value:       1024,
+			Status:      DataStatusOK,
+			Timestamp:   testTimestamp,
+			Source:      SourceTypeCloudWatch,
+		},
+		{
+			BucketName:  "bucket0",
+			Region:      "ap-northeast-1",
+			MetricName:  MetricNameBucketSizeBytes,
+			StorageType: StorageTypeGlacierStorage,
+			Value:       2048,
+			Status:      DataStatusOK,
+			Timestamp:   testTimestamp,
+			Source:      SourceTypeCloudWatch,
+		},
+		{
+			BucketName:  "<bucket1>",
+			Region:      "us-east-1",
+			MetricName:  MetricNameBucketSizeBytes,
+			StorageType: StorageTypeStandardStorage,
+			Value:       4096,
+			Status:      DataStatusOK,
+			Timestamp:   testTimestamp,
+			Source:      SourceTypeCloudWatch,
+		},
+		{
+			BucketName:  "bucket2",
+			Region:      "us-east-1",
+			MetricName:  MetricNameBucketSizeBytes,
+			StorageType: StorageTypeStandardStorage,
+			Value:       0,
+			Status:      DataStatusNoData,
+			Source:      SourceTypeCloudWatch,
+		},
+	},
+	Total: 7168,
+}
+
+func Test_newDashboard(t *testing.T) {
+	tests := []struct {
+		name string
+		data *MetricData
+		want *dashboard
+	}{
+		{
+			name: "size",
+			data: testDashboardMetricData,
+			want: &dashboard{
+				Title:   "Bucket Size Bytes",
+				Total:   "7.2 kB",
+				Buckets: 3,
+				Largest: "<bucket1> (4.1 kB)",
+			},
+		},
+		{
+			name: "objects",
+			data: testObjectMetricData,
+			want: &dashboard{
+				Title:   "Number Of Objects",
+				Total:   "0",
+				Buckets: 2,
+				Largest: "bucket0 (20)",
+			},
+		},
+		{
+			name: "latency",
+			data: &MetricData{
+				Header: header,
+				Metrics: []*Metric{
+					{BucketName: "bucket0", MetricName: MetricNameFirstByteLatency, Value: 12.345},
+				},
+			},
+			want: &dashboard{
+				Title:   "FirstByteLatency",
+				Total:   "-",
+				Buckets: 1,
+				Largest: "bucket0 (12.35)",
+			},
+		},
+		{
+			name: "empty",
+			data: &MetricData{Header: header},
+			want: &dashboard{
+				Title:   "s3bytes",
+				Total:   "0",
+				Buckets: 0,
+				Largest: "-",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newDashboard(tt.data)
+			if got.Title != tt.want.Title || got.Total != tt.want.Total || got.Buckets != tt.want.Buckets || got.Largest != tt.want.Largest {
+				t.Errorf("newDashboard() = %+v, want %+v", got, tt.want)
+			}
+			if len(got.Rows) != len(tt.data.Metrics) {
+				t.Errorf("newDashboard() rows = %d, want %d", len(got.Rows), len(tt.data.Metrics))
+			}
+		})
+	}
+}
+
+func Test_getGroupPieItems(t *testing.T) {
+	tests := []struct {
+		name string
+		key  func(*Metric) string
+		want []opts.PieData
+	}{
+		{
+			name: "region",
+			key:  func(m *Metric) string { return m.Region },
+			want: []opts.PieData{
+				{Name: "us-east-1", Value: float64(4096)},
+				{Name: "ap-northeast-1", Value: float64(3072)},
+			},
+		},
+		{
+			name: "storage type",
+			key:  func(m *Metric) string { return m.StorageType.String() },
+			want: []opts.PieData{
+				{Name: "StandardStorage", Value: float64(5120)},
+				{Name: "GlacierStorage", Value: float64(2048)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getGroupPieItems(testDashboardMetricData, tt.key); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getGroupPieItems() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderer_toHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     *MetricData
+		contains []string
+	}{
+		{
+			name: "normal",
+			data: testDashboardMetricData,
+			contains: []string{
+				"<html>",
+				"<h1>Bucket Size Bytes</h1>",
+				`<div class="value">7.2 kB</div>`,
+				"<th>BucketName</th>",
+				`<td class="number" data-value="2048">2048</td>`,
+				"<td>&lt;bucket1&gt;</td>",
+				"Bucket Size Bytes by Region",
+				"Bucket Size Bytes by Storage Type",
+				"s3bytes-search",
+			},
+		},
+		{
+			name: "empty",
+			data: &MetricData{Header: header},
+			contains: []string{
+				"<html>",
+				`<div class="value">-</div>`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			ren := &Renderer{
+				Data:       tt.data,
+				OutputType: OutputTypeHTML,
+				w:          w,
+				chartOut:   "-",
+			}
+			if err := ren.Render(); err != nil {
+				t.Fatalf("Renderer.Render() error = %v", err)
+			}
+			got := w.String()
+			for _, s := range tt.contains {
+				if !strings.Contains(got, s) {
+					t.Errorf("Renderer.Render() does not contain %q", s)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderer_toHTML_chartOut(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.html")
+	w := &bytes.Buffer{}
+	ren := &Renderer{
+		Data:       testDashboardMetricData,
+		OutputType: OutputTypeHTML,
+		w:          w,
+		chartOut:   path,
+	}
+	if err := ren.Render(); err != nil {
+		t.Fatalf("Renderer.Render() error = %v", err)
+	}
+	if w.Len() != 0 {
+		t.Errorf("Renderer.Render() wrote %d bytes to the writer, want 0", w.Len())
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "s3bytes-search") {
+		t.Errorf("Renderer.Render() file does not contain the dashboard")
+	}
+	ren.chartOut = filepath.Join(path, "unknown", "report.html")
+	if err := ren.Render(); err == nil {
+		t.Errorf("Renderer.Render() error = nil, want error for invalid path")
+	}
+}
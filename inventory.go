@@ -0,0 +1,648 @@
+package s3bytes
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// inventoryManifestName is the file name of the manifest of an S3 Inventory report.
+	inventoryManifestName = "manifest.json"
+
+	// inventoryFormatCSV is the file format of the reports in gzip-compressed CSV.
+	inventoryFormatCSV = "CSV"
+
+	// inventoryFormatORC is the file format of the reports in Apache ORC.
+	inventoryFormatORC = "ORC"
+
+	// inventoryFormatParquet is the file format of the reports in Apache Parquet, in upper case
+	// since the format in the manifest is compared case-insensitively.
+	inventoryFormatParquet = "PARQUET"
+)
+
+// inventoryManifest represents the manifest of an S3 Inventory report.
+type inventoryManifest struct {
+	SourceBucket      string          `json:"sourceBucket"`
+	DestinationBucket string          `json:"destinationBucket"`
+	CreationTimestamp string          `json:"creationTimestamp"`
+	FileFormat        string          `json:"fileFormat"`
+	FileSchema        string          `json:"fileSchema"`
+	Files             []inventoryFile `json:"files"`
+}
+
+// inventoryFile represents a data file listed in the manifest.
+type inventoryFile struct {
+	Key  string `json:"key"`
+	Size int64  `json:"size"`
+}
+
+// timestamp returns the creation time of the report, or the zero time if it is unknown.
+func (m *inventoryManifest) timestamp() time.Time {
+	n, err := strconv.ParseInt(m.CreationTimestamp, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(n).UTC()
+}
+
+// The normalized names of the fields used for aggregation, which are spelled in PascalCase
+// in the schema of CSV reports and in snake_case in the schema of ORC and Parquet reports.
+const (
+	inventoryFieldKey          = "key"
+	inventoryFieldSize         = "size"
+	inventoryFieldStorageClass = "storageclass"
+	inventoryFieldAccessTier   = "intelligenttieringaccesstier"
+	inventoryFieldDeleteMarker = "isdeletemarker"
+)
+
+// inventoryFieldName normalizes the name of the field in the schema of any format.
+func inventoryFieldName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", ""))
+}
+
+// inventoryColumns holds the positions of the columns used for aggregation.
+// Optional columns that are not present in the schema are set to -1.
+type inventoryColumns struct {
+	n            int
+	key          int
+	size         int
+	storageClass int
+	accessTier   int
+	deleteMarker int
+}
+
+// parseInventorySchema parses the comma-separated file schema of a CSV report.
+func parseInventorySchema(schema string) (*inventoryColumns, error) {
+	cols := &inventoryColumns{
+		key:          -1,
+		size:         -1,
+		storageClass: -1,
+		accessTier:   -1,
+		deleteMarker: -1,
+	}
+	fields := strings.Split(schema, ",")
+	cols.n = len(fields)
+	for i, field := range fields {
+		switch inventoryFieldName(field) {
+		case inventoryFieldKey:
+			cols.key = i
+		case inventoryFieldSize:
+			cols.size = i
+		case inventoryFieldStorageClass:
+			cols.storageClass = i
+		case inventoryFieldAccessTier:
+			cols.accessTier = i
+		case inventoryFieldDeleteMarker:
+			cols.deleteMarker = i
+		}
+	}
+	if cols.key < 0 || cols.size < 0 {
+		return nil, fmt.Errorf("inventory schema must contain Key and Size: %q", schema)
+	}
+	return cols, nil
+}
+
+// inventoryObject represents an object, a version or a delete marker recorded in the report.
+type inventoryObject struct {
+	key          string
+	size         int64
+	hasSize      bool
+	storageClass string
+	accessTier   string
+	deleteMarker bool
+}
+
+// inventoryKind is the kind of the values held by a column of the columnar formats.
+type inventoryKind int
+
+const (
+	inventoryKindString inventoryKind = iota
+	inventoryKindInt
+	inventoryKindBool
+)
+
+// inventoryColumn holds the values of a column of a row group of Parquet or a stripe of ORC.
+// Only the slice of the kind is used, and null values are held as the zero value with valid set to false.
+type inventoryColumn struct {
+	kind    inventoryKind
+	strings []string
+	ints    []int64
+	bools   []bool
+	valid   []bool
+}
+
+// appendValue appends the i-th value of the other column of the same kind.
+func (c *inventoryColumn) appendValue(other *inventoryColumn, i int) {
+	switch c.kind {
+	case inventoryKindString:
+		c.strings = append(c.strings, other.strings[i])
+	case inventoryKindInt:
+		c.ints = append(c.ints, other.ints[i])
+	case inventoryKindBool:
+		c.bools = append(c.bools, other.bools[i])
+	}
+	c.valid = append(c.valid, true)
+}
+
+// appendNull appends a null value.
+func (c *inventoryColumn) appendNull() {
+	switch c.kind {
+	case inventoryKindString:
+		c.strings = append(c.strings, "")
+	case inventoryKindInt:
+		c.ints = append(c.ints, 0)
+	case inventoryKindBool:
+		c.bools = append(c.bools, false)
+	}
+	c.valid = append(c.valid, false)
+}
+
+// appendRows appends the non-null values of the other column of the same kind to the rows
+// in which valid is true, and null values to the others.
+func (c *inventoryColumn) appendRows(other *inventoryColumn, valid []bool) error {
+	j := 0
+	for _, ok := range valid {
+		if !ok {
+			c.appendNull()
+			continue
+		}
+		if j >= other.len() {
+			return errors.New("too few values for the rows")
+		}
+		c.appendValue(other, j)
+		j++
+	}
+	return nil
+}
+
+// len returns the number of the values.
+func (c *inventoryColumn) len() int {
+	switch c.kind {
+	case inventoryKindString:
+		return len(c.strings)
+	case inventoryKindInt:
+		return len(c.ints)
+	default:
+		return len(c.bools)
+	}
+}
+
+// isValid reports whether the i-th value is present. A missing column has no values.
+func (c *inventoryColumn) isValid(i int) bool {
+	return c != nil && i < len(c.valid) && c.valid[i]
+}
+
+// inventoryColumnSet holds the columns of a row group or a stripe used for aggregation.
+// The optional columns that are not present in the schema are nil.
+type inventoryColumnSet struct {
+	n            int
+	key          *inventoryColumn
+	size         *inventoryColumn
+	storageClass *inventoryColumn
+	accessTier   *inventoryColumn
+	deleteMarker *inventoryColumn
+}
+
+// objects returns the objects in the rows of the columns.
+func (s *inventoryColumnSet) objects() iter.Seq[inventoryObject] {
+	return func(yield func(inventoryObject) bool) {
+		for i := range s.n {
+			obj := inventoryObject{}
+			if s.key.isValid(i) {
+				obj.key = s.key.strings[i]
+			}
+			if s.size.isValid(i) {
+				obj.size = s.size.ints[i]
+				obj.hasSize = true
+			}
+			if s.storageClass.isValid(i) {
+				obj.storageClass = s.storageClass.strings[i]
+			}
+			if s.accessTier.isValid(i) {
+				obj.accessTier = s.accessTier.strings[i]
+			}
+			if s.deleteMarker.isValid(i) {
+				obj.deleteMarker = s.deleteMarker.bools[i]
+			}
+			if !yield(obj) {
+				return
+			}
+		}
+	}
+}
+
+// inventoryFieldKinds maps the normalized names of the fields used for aggregation to their kinds.
+var inventoryFieldKinds = map[string]inventoryKind{
+	inventoryFieldKey:          inventoryKindString,
+	inventoryFieldSize:         inventoryKindInt,
+	inventoryFieldStorageClass: inventoryKindString,
+	inventoryFieldAccessTier:   inventoryKindString,
+	inventoryFieldDeleteMarker: inventoryKindBool,
+}
+
+// set sets the column of the normalized field name.
+func (s *inventoryColumnSet) set(field string, col *inventoryColumn) {
+	switch field {
+	case inventoryFieldKey:
+		s.key = col
+	case inventoryFieldSize:
+		s.size = col
+	case inventoryFieldStorageClass:
+		s.storageClass = col
+	case inventoryFieldAccessTier:
+		s.accessTier = col
+	case inventoryFieldDeleteMarker:
+		s.deleteMarker = col
+	}
+}
+
+// ListInventory aggregates the values from S3 Inventory reports stored in the local file system.
+// Each path is either a manifest.json or a directory containing it, and the data files listed
+// in the manifest are looked up relative to it. If depth is 0, one row is emitted per bucket
+// in the same form as the CloudWatch path; otherwise the values are broken down by prefix
+// up to the depth in the same form as ListPrefixes. The region of the source bucket is resolved
+// with GetBucketLocation, since the manifest does not record it. The creation date is not set
+// with the bucket metadata, since it is only returned by ListBuckets.
+func (man *Manager) ListInventory(ctx context.Context, paths []string, depth int) (*MetricData, error) {
+	if depth < 0 {
+		return nil, fmt.Errorf("invalid depth: %d", depth)
+	}
+	if man.metricName.isRequest() {
+		return nil, fmt.Errorf("%s metric is not supported for inventory reports", man.metricName)
+	}
+	data := &MetricData{
+		Header:  man.withTagHeader(man.withMetadataHeader(header)),
+		Metrics: make([]*Metric, 0),
+	}
+	if depth > 0 {
+		data.Header = man.withTagHeader(man.withMetadataHeader(prefixHeader))
+	}
+	for _, path := range paths {
+		manifest, dir, err := readInventoryManifest(path)
+		if err != nil {
+			return nil, err
+		}
+		if man.prefix != nil && !strings.HasPrefix(manifest.SourceBucket, *man.prefix) {
+			continue
+		}
+		region, err := getBucketRegion(ctx, man.client, manifest.SourceBucket)
+		if err != nil {
+			return nil, err
+		}
+		root, err := man.readInventory(ctx, manifest, dir, depth)
+		if err != nil {
+			return nil, err
+		}
+		if depth > 0 {
+			base := &Metric{
+				BucketName: manifest.SourceBucket,
+				Region:     region,
+				BucketType: getBucketType(manifest.SourceBucket),
+			}
+			if err := man.enrichMetadata(ctx, []*Metric{base}, region, nil); err != nil {
+				return nil, err
+			}
+			if err := man.enrichTags(ctx, []*Metric{base}, region); err != nil {
+				return nil, err
+			}
+			m, n, err := man.getPrefixMetrics(root, base, manifest.timestamp(), SourceTypeInventory)
+			if err != nil {
+				return nil, err
+			}
+			data.Metrics = append(data.Metrics, m...)
+			data.Total += n
+			continue
+		}
+		storageType := man.storageType.forBucket(manifest.SourceBucket)
+		metric := &Metric{
+			BucketName:  manifest.SourceBucket,
+			Region:      region,
+			MetricName:  man.metricName,
+			StorageType: storageType,
+			Value:       root.total().value(man.metricName, storageType),
+			Status:      DataStatusOK,
+			Timestamp:   manifest.timestamp(),
+			Source:      SourceTypeInventory,
+			BucketType:  getBucketType(manifest.SourceBucket),
+		}
+		if err := man.enrichMetadata(ctx, []*Metric{metric}, region, nil); err != nil {
+			return nil, err
+		}
+		if err := man.enrichTags(ctx, []*Metric{metric}, region); err != nil {
+			return nil, err
+		}
+		ok, err := man.accept(metric)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			data.Metrics = append(data.Metrics, metric)
+			data.Total += int64(metric.Value)
+		}
+	}
+	return data, nil
+}
+
+// readInventoryManifest reads the manifest and returns it with the directory it is placed in.
+func readInventoryManifest(path string) (*inventoryManifest, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if info.IsDir() {
+		path = filepath.Join(path, inventoryManifestName)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	var manifest inventoryManifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return nil, "", fmt.Errorf("failed to parse inventory manifest: %w", err)
+	}
+	if manifest.SourceBucket == "" {
+		return nil, "", fmt.Errorf("inventory manifest has no source bucket: %q", path)
+	}
+	return &manifest, filepath.Dir(path), nil
+}
+
+// readInventory reads all data files of the report and builds the tree of prefixes up to the depth.
+// Data files are read concurrently up to the concurrency of the manager at a time.
+func (man *Manager) readInventory(ctx context.Context, manifest *inventoryManifest, dir string, depth int) (*prefixNode, error) {
+	format := strings.ToUpper(manifest.FileFormat)
+	var cols *inventoryColumns
+	switch format {
+	case inventoryFormatCSV:
+		c, err := parseInventorySchema(manifest.FileSchema)
+		if err != nil {
+			return nil, err
+		}
+		cols = c
+	case inventoryFormatORC, inventoryFormatParquet:
+	default:
+		return nil, fmt.Errorf("unsupported inventory file format: %q", manifest.FileFormat)
+	}
+	var (
+		mu    sync.Mutex
+		root  = &prefixNode{result: scanResult{}}
+		nodes = map[string]*prefixNode{"": root}
+	)
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(man.workers())
+	for _, file := range manifest.Files {
+		g.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			path, err := resolveInventoryFile(dir, file.Key)
+			if err != nil {
+				return err
+			}
+			results, err := readInventoryFile(path, format, cols, depth)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for prefix, result := range results {
+				getPrefixNode(nodes, prefix).result.merge(result)
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// getPrefixNode returns the node of the prefix, creating it and its missing ancestors.
+func getPrefixNode(nodes map[string]*prefixNode, prefix string) *prefixNode {
+	if node, ok := nodes[prefix]; ok {
+		return node
+	}
+	parent := getPrefixNode(nodes, ParentPrefix(prefix))
+	node := &prefixNode{
+		prefix: prefix,
+		depth:  parent.depth + 1,
+		result: scanResult{},
+	}
+	nodes[prefix] = node
+	parent.children = append(parent.children, node)
+	return node
+}
+
+// resolveInventoryFile finds the local path of the data file. The key is looked up
+// under the directory of the manifest and its parent, dropping the leading elements
+// of the key one by one, so that both a full copy of the destination bucket and
+// a copy of the report directory alone can be read.
+func resolveInventoryFile(dir, key string) (string, error) {
+	elems := strings.Split(key, "/")
+	for _, base := range []string{dir, filepath.Dir(dir)} {
+		for i := range elems {
+			path := filepath.Join(append([]string{base}, elems[i:]...)...)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("inventory file not found: %q", key)
+}
+
+// readInventoryFile aggregates the objects in the data file by the prefix at the depth.
+// Delete markers are skipped, while noncurrent versions are counted as CloudWatch does.
+// The columns are only used for CSV, since ORC and Parquet files carry their own schema.
+func readInventoryFile(path, format string, cols *inventoryColumns, depth int) (map[string]scanResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var objects iter.Seq2[inventoryObject, error]
+	switch format {
+	case inventoryFormatCSV:
+		var r io.Reader = f
+		if strings.HasSuffix(path, ".gz") {
+			gz, err := gzip.NewReader(f)
+			if err != nil {
+				return nil, err
+			}
+			defer gz.Close()
+			r = gz
+		}
+		objects = csvInventoryObjects(r, cols)
+	case inventoryFormatORC, inventoryFormatParquet:
+		info, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		if format == inventoryFormatORC {
+			objects = orcInventoryObjects(f, info.Size())
+		} else {
+			objects = parquetInventoryObjects(f, info.Size())
+		}
+	default:
+		return nil, fmt.Errorf("unsupported inventory file format: %q", format)
+	}
+	results := make(map[string]scanResult)
+	for obj, err := range objects {
+		if err != nil {
+			return nil, fmt.Errorf("failed to read inventory file: %q: %w", path, err)
+		}
+		if obj.deleteMarker || !obj.hasSize {
+			continue
+		}
+		prefix := prefixAt(obj.key, depth)
+		result, ok := results[prefix]
+		if !ok {
+			result = scanResult{}
+			results[prefix] = result
+		}
+		result.add(inventoryStorageType(obj.storageClass, obj.accessTier), obj.size, 1)
+	}
+	return results, nil
+}
+
+// csvInventoryObjects returns the objects in the CSV data file. The keys, which are URL-encoded
+// only in CSV reports, are decoded.
+func csvInventoryObjects(r io.Reader, cols *inventoryColumns) iter.Seq2[inventoryObject, error] {
+	return func(yield func(inventoryObject, error) bool) {
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = cols.n
+		reader.ReuseRecord = true
+		for {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield(inventoryObject{}, err)
+				return
+			}
+			obj := inventoryObject{
+				key:          record[cols.key],
+				deleteMarker: cols.deleteMarker >= 0 && strings.EqualFold(record[cols.deleteMarker], "true"),
+			}
+			if unescaped, err := url.QueryUnescape(obj.key); err == nil {
+				obj.key = unescaped
+			}
+			if record[cols.size] != "" {
+				size, err := strconv.ParseInt(record[cols.size], 10, 64)
+				if err != nil && !obj.deleteMarker {
+					yield(inventoryObject{}, fmt.Errorf("invalid size: %q", record[cols.size]))
+					return
+				}
+				obj.size = size
+				obj.hasSize = err == nil
+			}
+			if cols.storageClass >= 0 {
+				obj.storageClass = record[cols.storageClass]
+			}
+			if cols.accessTier >= 0 {
+				obj.accessTier = record[cols.accessTier]
+			}
+			if !yield(obj, nil) {
+				return
+			}
+		}
+	}
+}
+
+// prefixAt returns the prefix of the key truncated at the depth.
+// If the key is shallower than the depth, the prefix of the key itself is returned.
+func prefixAt(key string, depth int) string {
+	end := 0
+	for range depth {
+		i := strings.Index(key[end:], prefixDelimiter)
+		if i < 0 {
+			break
+		}
+		end += i + len(prefixDelimiter)
+	}
+	return key[:end]
+}
+
+// inventoryStorageType converts the storage class and the access tier of Intelligent-Tiering
+// recorded in the report into the storage type of CloudWatch.
+func inventoryStorageType(class, tier string) StorageType {
+	if s3types.ObjectStorageClass(class) != s3types.ObjectStorageClassIntelligentTiering {
+		return storageTypeFromClass(s3types.ObjectStorageClass(class))
+	}
+	switch tier {
+	case "INFREQUENT":
+		return StorageTypeIntelligentTieringIAStorage
+	case "ARCHIVE_INSTANT_ACCESS":
+		return StorageTypeIntelligentTieringAIAStorage
+	case "ARCHIVE":
+		return StorageTypeIntelligentTieringAAStorage
+	case "DEEP_ARCHIVE":
+		return StorageTypeIntelligentTieringDAAStorage
+	default:
+		return StorageTypeIntelligentTieringFAStorage
+	}
+}
+
+// decompressSnappy decompresses the block in the Snappy format without the framing.
+func decompressSnappy(b []byte) ([]byte, error) {
+	return snappy.Decode(nil, b)
+}
+
+// decompressGzip decompresses the gzip stream of the expected size.
+func decompressGzip(b []byte, size int) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	return readAllSized(gz, size)
+}
+
+// decompressDeflate decompresses the raw deflate stream of the expected size.
+func decompressDeflate(b []byte, size int) ([]byte, error) {
+	fr := flate.NewReader(bytes.NewReader(b))
+	defer fr.Close()
+	return readAllSized(fr, size)
+}
+
+// zstdDecoder returns the decoder of Zstandard shared by the goroutines reading the data files.
+var zstdDecoder = sync.OnceValues(func() (*zstd.Decoder, error) {
+	return zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+})
+
+// decompressZstd decompresses the Zstandard frames of the expected size.
+func decompressZstd(b []byte, size int) ([]byte, error) {
+	dec, err := zstdDecoder()
+	if err != nil {
+		return nil, err
+	}
+	return dec.DecodeAll(b, make([]byte, 0, max(size, 0)))
+}
+
+// readAllSized reads all of the reader into a buffer allocated for the expected size.
+func readAllSized(r io.Reader, size int) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, max(size, 0)))
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,702 @@
+package s3bytes
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+const testInventorySchema = "Bucket, Key, VersionId, IsLatest, IsDeleteMarker, Size, StorageClass, IntelligentTieringAccessTier"
+
+// testInventoryColumn is a column of the test reports in the columnar formats, in which nil is null.
+type testInventoryColumn struct {
+	name   string
+	kind   inventoryKind
+	values []any
+}
+
+// testInventoryColumns converts the CSV rows in the test schema into the columns named as in the columnar formats.
+func testInventoryColumns(rows ...string) []testInventoryColumn {
+	cols := []testInventoryColumn{
+		{name: "bucket", kind: inventoryKindString},
+		{name: "key", kind: inventoryKindString},
+		{name: "version_id", kind: inventoryKindString},
+		{name: "is_latest", kind: inventoryKindBool},
+		{name: "is_delete_marker", kind: inventoryKindBool},
+		{name: "size", kind: inventoryKindInt},
+		{name: "storage_class", kind: inventoryKindString},
+		{name: "intelligent_tiering_access_tier", kind: inventoryKindString},
+	}
+	for _, row := range rows {
+		record, err := csv.NewReader(strings.NewReader(row)).Read()
+		if err != nil {
+			panic(err)
+		}
+		for i, field := range record {
+			var v any
+			switch {
+			case field == "":
+			case cols[i].kind == inventoryKindBool:
+				v = field == "true"
+			case cols[i].kind == inventoryKindInt:
+				n, err := strconv.ParseInt(field, 10, 64)
+				if err != nil {
+					panic(err)
+				}
+				v = n
+			default:
+				v = field
+			}
+			cols[i].values = append(cols[i].values, v)
+		}
+	}
+	return cols
+}
+
+// writeTestInventory writes a report of the bucket under the directory in the layout of the destination bucket,
+// and returns the directory containing the manifest. The rows are given in CSV, and converted into the format.
+func writeTestInventory(t *testing.T, dir, bucket, format string, rows ...string) string {
+	t.Helper()
+	var (
+		key      = "inventory/" + bucket + "/config/data/0"
+		manifest = filepath.Join(dir, "inventory", bucket, "config", "2026-01-01T00-00Z")
+		b        []byte
+	)
+	switch format {
+	case "Parquet":
+		key += ".parquet"
+		b = writeTestParquet(t, testInventoryColumns(rows...), testParquetOptions{codec: parquetCodecSnappy, dictionary: true, groupSize: 4, pageSize: 2})
+	case "ORC":
+		key += ".orc"
+		b = writeTestORC(t, testInventoryColumns(rows...), testORCOptions{compression: orcCompressionZlib, dictionary: true, stripeSize: 4})
+	default:
+		key += ".csv.gz"
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write([]byte(strings.Join(rows, "\n") + "\n")); err != nil {
+			t.Fatal(err)
+		}
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+		b = buf.Bytes()
+	}
+	if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(key)), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(manifest, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, key), b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := `{
+  "sourceBucket": "` + bucket + `",
+  "destinationBucket": "arn:aws:s3:::destination",
+  "version": "2016-11-30",
+  "creationTimestamp": "1767225600000",
+  "fileFormat": "` + format + `",
+  "fileSchema": "` + testInventorySchema + `",
+  "files": [{"key": "` + key + `", "size": 0, "MD5checksum": ""}]
+}`
+	if err := os.WriteFile(filepath.Join(manifest, inventoryManifestName), []byte(m), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return manifest
+}
+
+func TestManager_ListInventory(t *testing.T) {
+	dir := t.TempDir()
+	rows := []string{
+		`"%s","root.txt","","true","false","1","STANDARD",""`,
+		`"%s","logs/a.log","v1","true","false","10","STANDARD",""`,
+		`"%s","logs/a.log","v0","false","false","20","STANDARD",""`,
+		`"%s","logs/a.log","v2","false","true","","",""`,
+		`"%s","logs/2026/b+c.log","","true","false","100","STANDARD",""`,
+		`"%s","data/d.bin","","true","false","1000","GLACIER",""`,
+		`"%s","data/e.bin","","true","false","10000","INTELLIGENT_TIERING","INFREQUENT"`,
+	}
+	rowsOf := func(bucket string) []string {
+		ret := make([]string, len(rows))
+		for i, row := range rows {
+			ret[i] = fmt.Sprintf(row, bucket)
+		}
+		return ret
+	}
+	bucket0 := writeTestInventory(t, dir, "bucket0", "CSV", rowsOf("bucket0")...)
+	bucket1 := writeTestInventory(t, dir, "bucket1", "CSV",
+		`"bucket1","f.txt","","true","false","5","STANDARD",""`,
+	)
+	parquet := writeTestInventory(t, dir, "bucket2", "Parquet", rowsOf("bucket2")...)
+	orc := writeTestInventory(t, dir, "bucket3", "ORC", rowsOf("bucket3")...)
+	unsupported := writeTestInventory(t, dir, "bucket4", "JSON", rowsOf("bucket4")...)
+	denied := writeTestInventory(t, dir, "denied", "CSV", rowsOf("denied")...)
+	client := newMockClient(
+		&mockS3{
+			GetBucketLocationFunc: func(_ context.Context, params *s3.GetBucketLocationInput, _ ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error) {
+				switch aws.ToString(params.Bucket) {
+				case "bucket0":
+					return &s3.GetBucketLocationOutput{LocationConstraint: types.BucketLocationConstraintApNortheast1}, nil
+				case "denied":
+					return nil, &smithy.GenericAPIError{Code: "AccessDenied", Message: "Access Denied"}
+				default:
+					return &s3.GetBucketLocationOutput{}, nil
+				}
+			},
+			GetBucketTaggingFunc: func(_ context.Context, _ *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+				o := &s3.Options{}
+				for _, fn := range optFns {
+					fn(o)
+				}
+				return &s3.GetBucketTaggingOutput{
+					TagSet: []types.Tag{{Key: aws.String("region"), Value: aws.String(o.Region)}},
+				}, nil
+			},
+			GetBucketVersioningFunc: func(_ context.Context, _ *s3.GetBucketVersioningInput, _ ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
+				return &s3.GetBucketVersioningOutput{Status: types.BucketVersioningStatusEnabled}, nil
+			},
+			GetBucketLifecycleConfigurationFunc: func(_ context.Context, _ *s3.GetBucketLifecycleConfigurationInput, _ ...func(*s3.Options)) (*s3.GetBucketLifecycleConfigurationOutput, error) {
+				return nil, &smithy.GenericAPIError{Code: "NoSuchLifecycleConfiguration"}
+			},
+			GetBucketEncryptionFunc: func(_ context.Context, _ *s3.GetBucketEncryptionInput, _ ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error) {
+				return nil, &smithy.GenericAPIError{Code: "AccessDenied"}
+			},
+			GetObjectLockConfigurationFunc: func(_ context.Context, _ *s3.GetObjectLockConfigurationInput, _ ...func(*s3.Options)) (*s3.GetObjectLockConfigurationOutput, error) {
+				return nil, &smithy.GenericAPIError{Code: "ObjectLockConfigurationNotFoundError"}
+			},
+		},
+		nil,
+	)
+	timestamp := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	type fields struct {
+		metricName  MetricName
+		storageType StorageType
+		prefix      *string
+		tagKeys     []string
+		enrich      bool
+	}
+	type args struct {
+		paths []string
+		depth int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *MetricData
+		wantErr bool
+	}{
+		{
+			name: "bucket",
+			fields: fields{
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+			},
+			args: args{
+				paths: []string{bucket0, filepath.Join(bucket1, inventoryManifestName)},
+				depth: 0,
+			},
+			want: &MetricData{
+				Header: header,
+				Metrics: []*Metric{
+					{
+						BucketName:  "bucket0",
+						Region:      "ap-northeast-1",
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeStandardStorage,
+						Value:       131,
+						Status:      DataStatusOK,
+						Timestamp:   timestamp,
+						Source:      SourceTypeInventory,
+						BucketType:  BucketTypeGeneralPurpose,
+					},
+					{
+						BucketName:  "bucket1",
+						Region:      "us-east-1",
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeStandardStorage,
+						Value:       5,
+						Status:      DataStatusOK,
+						Timestamp:   timestamp,
+						Source:      SourceTypeInventory,
+						BucketType:  BucketTypeGeneralPurpose,
+					},
+				},
+				Total: 136,
+			},
+			wantErr: false,
+		},
+		{
+			name: "objects with bucket prefix",
+			fields: fields{
+				metricName:  MetricNameNumberOfObjects,
+				storageType: StorageTypeAllStorageTypes,
+				prefix:      func() *string { s := "bucket0"; return &s }(),
+			},
+			args: args{
+				paths: []string{bucket0, bucket1},
+				depth: 0,
+			},
+			want: &MetricData{
+				Header: header,
+				Metrics: []*Metric{
+					{
+						BucketName:  "bucket0",
+						Region:      "ap-northeast-1",
+						MetricName:  MetricNameNumberOfObjects,
+						StorageType: StorageTypeAllStorageTypes,
+						Value:       6,
+						Status:      DataStatusOK,
+						Timestamp:   timestamp,
+						Source:      SourceTypeInventory,
+						BucketType:  BucketTypeGeneralPurpose,
+					},
+				},
+				Total: 6,
+			},
+			wantErr: false,
+		},
+		{
+			name: "prefix",
+			fields: fields{
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeIntelligentTieringIAStorage,
+			},
+			args: args{
+				paths: []string{bucket0},
+				depth: 2,
+			},
+			want: &MetricData{
+				Header: prefixHeader,
+				Metrics: []*Metric{
+					{
+						BucketName:  "bucket0",
+						Region:      "ap-northeast-1",
+						Prefix:      "/",
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeIntelligentTieringIAStorage,
+						Value:       0,
+						Status:      DataStatusOK,
+						Timestamp:   timestamp,
+						Source:      SourceTypeInventory,
+						BucketType:  BucketTypeGeneralPurpose,
+					},
+					{
+						BucketName:  "bucket0",
+						Region:      "ap-northeast-1",
+						Prefix:      "data/",
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeIntelligentTieringIAStorage,
+						Value:       10000,
+						Status:      DataStatusOK,
+						Timestamp:   timestamp,
+						Source:      SourceTypeInventory,
+						BucketType:  BucketTypeGeneralPurpose,
+					},
+					{
+						BucketName:  "bucket0",
+						Region:      "ap-northeast-1",
+						Prefix:      "logs/",
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeIntelligentTieringIAStorage,
+						Value:       0,
+						Status:      DataStatusOK,
+						Timestamp:   timestamp,
+						Source:      SourceTypeInventory,
+						BucketType:  BucketTypeGeneralPurpose,
+					},
+					{
+						BucketName:  "bucket0",
+						Region:      "ap-northeast-1",
+						Prefix:      "logs/2026/",
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeIntelligentTieringIAStorage,
+						Value:       0,
+						Status:      DataStatusOK,
+						Timestamp:   timestamp,
+						Source:      SourceTypeInventory,
+						BucketType:  BucketTypeGeneralPurpose,
+					},
+				},
+				Total: 10000,
+			},
+			wantErr: false,
+		},
+		{
+			name: "parquet",
+			fields: fields{
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+			},
+			args: args{
+				paths: []string{parquet},
+				depth: 0,
+			},
+			want: &MetricData{
+				Header: header,
+				Metrics: []*Metric{
+					{
+						BucketName:  "bucket2",
+						Region:      "us-east-1",
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeStandardStorage,
+						Value:       131,
+						Status:      DataStatusOK,
+						Timestamp:   timestamp,
+						Source:      SourceTypeInventory,
+						BucketType:  BucketTypeGeneralPurpose,
+					},
+				},
+				Total: 131,
+			},
+			wantErr: false,
+		},
+		{
+			name: "orc",
+			fields: fields{
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+			},
+			args: args{
+				paths: []string{orc},
+				depth: 0,
+			},
+			want: &MetricData{
+				Header: header,
+				Metrics: []*Metric{
+					{
+						BucketName:  "bucket3",
+						Region:      "us-east-1",
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeStandardStorage,
+						Value:       131,
+						Status:      DataStatusOK,
+						Timestamp:   timestamp,
+						Source:      SourceTypeInventory,
+						BucketType:  BucketTypeGeneralPurpose,
+					},
+				},
+				Total: 131,
+			},
+			wantErr: false,
+		},
+		{
+			name: "unsupported format",
+			fields: fields{
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+			},
+			args: args{
+				paths: []string{unsupported},
+				depth: 0,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "location error",
+			fields: fields{
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+			},
+			args: args{
+				paths: []string{denied},
+				depth: 0,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "manifest not found",
+			fields: fields{
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+			},
+			args: args{
+				paths: []string{filepath.Join(dir, "unknown")},
+				depth: 0,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "invalid depth",
+			fields: fields{
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+			},
+			args: args{
+				paths: []string{bucket0},
+				depth: -1,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "request metric",
+			fields: fields{
+				metricName: MetricNameAllRequests,
+			},
+			args: args{
+				paths: []string{bucket0},
+				depth: 0,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "tags",
+			fields: fields{
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+				tagKeys:     []string{"region", "team"},
+			},
+			args: args{
+				paths: []string{bucket0},
+				depth: 0,
+			},
+			want: &MetricData{
+				Header: append(slices.Clone(header), "Tag:region", "Tag:team"),
+				Metrics: []*Metric{
+					{
+						BucketName:  "bucket0",
+						Region:      "ap-northeast-1",
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeStandardStorage,
+						Value:       131,
+						Status:      DataStatusOK,
+						Timestamp:   timestamp,
+						Source:      SourceTypeInventory,
+						BucketType:  BucketTypeGeneralPurpose,
+						Tags:        map[string]string{"region": "ap-northeast-1"},
+					},
+				},
+				Total: 131,
+			},
+			wantErr: false,
+		},
+		{
+			name: "enrich",
+			fields: fields{
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+				enrich:      true,
+			},
+			args: args{
+				paths: []string{bucket1},
+				depth: 1,
+			},
+			want: &MetricData{
+				Header: append(slices.Clone(prefixHeader), metadataHeader...),
+				Metrics: []*Metric{
+					{
+						BucketName:  "bucket1",
+						Region:      "us-east-1",
+						Prefix:      "/",
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeStandardStorage,
+						Value:       5,
+						Status:      DataStatusOK,
+						Timestamp:   timestamp,
+						Source:      SourceTypeInventory,
+						BucketType:  BucketTypeGeneralPurpose,
+						BucketMetadata: BucketMetadata{
+							Versioning: statusEnabled,
+							Lifecycle:  statusDisabled,
+							Encryption: statusUnknown,
+							ObjectLock: statusDisabled,
+						},
+					},
+				},
+				Total: 5,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			man := &Manager{
+				client:      client,
+				metricName:  tt.fields.metricName,
+				storageType: tt.fields.storageType,
+				prefix:      tt.fields.prefix,
+				tagKeys:     tt.fields.tagKeys,
+				enrich:      tt.fields.enrich,
+			}
+			got, err := man.ListInventory(context.Background(), tt.args.paths, tt.args.depth)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Manager.ListInventory() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != nil {
+				slices.SortFunc(got.Metrics, func(a, b *Metric) int { return strings.Compare(a.Prefix, b.Prefix) })
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Manager.ListInventory() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseInventorySchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		want    *inventoryColumns
+		wantErr bool
+	}{
+		{
+			name:   "full",
+			schema: testInventorySchema,
+			want: &inventoryColumns{
+				n:            8,
+				key:          1,
+				size:         5,
+				storageClass: 6,
+				accessTier:   7,
+				deleteMarker: 4,
+			},
+			wantErr: false,
+		},
+		{
+			name:   "minimal",
+			schema: "Bucket, Key, Size",
+			want: &inventoryColumns{
+				n:            3,
+				key:          1,
+				size:         2,
+				storageClass: -1,
+				accessTier:   -1,
+				deleteMarker: -1,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "no size",
+			schema:  "Bucket, Key",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseInventorySchema(tt.schema)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseInventorySchema() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseInventorySchema() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_prefixAt(t *testing.T) {
+	type args struct {
+		key   string
+		depth int
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "depth 0",
+			args: args{
+				key:   "logs/2026/a.log",
+				depth: 0,
+			},
+			want: "",
+		},
+		{
+			name: "depth 1",
+			args: args{
+				key:   "logs/2026/a.log",
+				depth: 1,
+			},
+			want: "logs/",
+		},
+		{
+			name: "shallower than depth",
+			args: args{
+				key:   "logs/2026/a.log",
+				depth: 5,
+			},
+			want: "logs/2026/",
+		},
+		{
+			name: "top level object",
+			args: args{
+				key:   "a.log",
+				depth: 1,
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := prefixAt(tt.args.key, tt.args.depth); got != tt.want {
+				t.Errorf("prefixAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_inventoryStorageType(t *testing.T) {
+	type args struct {
+		class string
+		tier  string
+	}
+	tests := []struct {
+		name string
+		args args
+		want StorageType
+	}{
+		{
+			name: "standard",
+			args: args{
+				class: "STANDARD",
+			},
+			want: StorageTypeStandardStorage,
+		},
+		{
+			name: "intelligent tiering archive",
+			args: args{
+				class: "INTELLIGENT_TIERING",
+				tier:  "ARCHIVE",
+			},
+			want: StorageTypeIntelligentTieringAAStorage,
+		},
+		{
+			name: "intelligent tiering without tier",
+			args: args{
+				class: "INTELLIGENT_TIERING",
+			},
+			want: StorageTypeIntelligentTieringFAStorage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inventoryStorageType(tt.args.class, tt.args.tier); got != tt.want {
+				t.Errorf("inventoryStorageType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,73 +1,292 @@
 package s3bytes
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
 	"sync"
-	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
+// errStopped is returned when the consumer of the metrics stops before all metrics are fetched.
+var errStopped = errors.New("stopped by the consumer")
+
 // List retrieves the metrics data for all regions and returns it as a MetricData struct.
-// It uses concurrency to fetch the data from multiple regions simultaneously,
-// and handles errors gracefully by canceling the context if any error occurs.
+// It collects the metrics from Stream, so that the regions are fetched concurrently
+// and the remaining fetches are canceled if any error occurs.
+// The time window of the metrics ends at the time of each call, so that the manager
+// can be reused to refresh the result.
 func (man *Manager) List(ctx context.Context) (*MetricData, error) {
-	var (
-		total             int64
-		wg                sync.WaitGroup
-		cancelCtx, cancel = context.WithCancel(ctx)
-		size              = MaxQueries * 2 * len(man.regions)
-		metricsChan       = make(chan []*Metric, size)
-		errorChan         = make(chan error, 1)
-		data              = &MetricData{
-			Header:  header,
-			Metrics: make([]*Metric, 0, size),
+	data := &MetricData{
+		Header:  man.withTagHeader(man.withMetadataHeader(header)),
+		Metrics: make([]*Metric, 0),
+	}
+	for metric, err := range man.Stream(ctx) {
+		if err != nil {
+			return nil, err
 		}
-	)
-	defer cancel()
-	errorFunc := func(err error) {
-		select {
-		case errorChan <- err:
-		default:
+		data.Metrics = append(data.Metrics, metric)
+		if !metric.MetricName.isLatency() {
+			data.Total += int64(metric.Value)
 		}
 	}
-	for _, region := range man.regions {
-		if err := man.sem.Acquire(cancelCtx, 1); err != nil {
-			return nil, err
+	return data, nil
+}
+
+// Stream returns an iterator over the metrics of all regions, which yields the metrics as each page
+// of the source returns instead of buffering the whole result. The regions are fetched concurrently
+// in the same way as List, and each region waits for the consumer before fetching the next page,
+// so that a slow consumer holds back the fetches. Breaking the loop or canceling the context stops
+// the remaining fetches, and the regions stopped by breaking the loop are reported as finished.
+// An error is yielded as the last element with a nil metric.
+// The metrics of different regions are interleaved in no particular order.
+func (man *Manager) Stream(ctx context.Context) iter.Seq2[*Metric, error] {
+	return func(yield func(*Metric, error) bool) {
+		var (
+			wg                sync.WaitGroup
+			cancelCtx, cancel = context.WithCancelCause(ctx)
+			pagesChan         = make(chan []*Metric)
+			errorChan         = make(chan error, 1)
+			end               = now()
+		)
+		defer func() {
+			cancel(errStopped)
+			wg.Wait()
+		}()
+		errorFunc := func(err error) {
+			select {
+			case errorChan <- err:
+			default:
+			}
+			cancel(err)
+		}
+		send := func(metrics []*Metric) bool {
+			select {
+			case pagesChan <- metrics:
+				return true
+			case <-cancelCtx.Done():
+				return false
+			}
 		}
 		wg.Go(func() {
-			defer man.sem.Release(1)
-			buckets, err := man.getBuckets(cancelCtx, region)
-			if err != nil {
-				errorFunc(err)
+			defer close(pagesChan)
+			var rg sync.WaitGroup
+			defer rg.Wait()
+			for i, region := range man.regions {
+				if err := man.sem.Acquire(cancelCtx, 1); err != nil {
+					// report the regions that never start, so that the observers see all regions done
+					for _, region := range man.regions[i:] {
+						man.notifyResult(cancelCtx, region, err)
+					}
+					errorFunc(err)
+					return
+				}
+				rg.Go(func() {
+					defer man.sem.Release(1)
+					if err := man.streamRegion(cancelCtx, region, end, send); err != nil {
+						errorFunc(err)
+					}
+				})
+			}
+		})
+		for {
+			select {
+			case metrics, ok := <-pagesChan:
+				if !ok {
+					select {
+					case err := <-errorChan:
+						yield(nil, err)
+					default:
+					}
+					return
+				}
+				for _, metric := range metrics {
+					if !yield(metric, nil) {
+						return
+					}
+				}
+			case err := <-errorChan:
+				yield(nil, err)
 				return
 			}
-			m, n, err := man.getMetrics(cancelCtx, buckets, region)
+		}
+	}
+}
+
+// ListRegion retrieves the metrics data for a single region in the same way as List, so that
+// the regions can be fetched one by one to report the progress of each. The region does not need
+// to be one of the regions of the manager, and the concurrency is limited in the same way as List.
+func (man *Manager) ListRegion(ctx context.Context, region string) (*MetricData, error) {
+	if _, ok := allowedRegions[region]; !ok {
+		return nil, fmt.Errorf("unsupported region: %s", region)
+	}
+	if err := man.sem.Acquire(ctx, 1); err != nil {
+		return nil, err
+	}
+	defer man.sem.Release(1)
+	metrics, total, err := man.listRegion(ctx, region, now())
+	if err != nil {
+		return nil, err
+	}
+	return &MetricData{
+		Header:  man.withTagHeader(man.withMetadataHeader(header)),
+		Metrics: metrics,
+		Total:   total,
+	}, nil
+}
+
+// listRegion collects the metrics streamed from the region and the total of their values.
+func (man *Manager) listRegion(ctx context.Context, region string, end time.Time) ([]*Metric, int64, error) {
+	var (
+		total   int64
+		metrics = make([]*Metric, 0)
+	)
+	err := man.streamRegion(ctx, region, end, func(m []*Metric) bool {
+		metrics = append(metrics, m...)
+		return true
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, metric := range metrics {
+		total += int64(metric.Value)
+	}
+	return metrics, total, nil
+}
+
+// streamRegion lists the buckets in the region and streams their metrics to yield,
+// reporting the progress of the region to the observers.
+func (man *Manager) streamRegion(ctx context.Context, region string, end time.Time, yield func([]*Metric) bool) error {
+	man.notify(Event{Type: EventTypeRegionStarted, Region: region})
+	buckets, err := discoverBuckets(ctx, man.getSource(), region, aws.ToString(man.prefix))
+	if err != nil {
+		return man.notifyResult(ctx, region, err)
+	}
+	man.notify(Event{Type: EventTypeBucketsListed, Region: region, Buckets: len(buckets)})
+	return man.notifyResult(ctx, region, man.streamMetrics(ctx, buckets, region, end, yield))
+}
+
+// streamMetrics fetches the metrics of the buckets from the source in batches of the batch size,
+// and passes the metrics accepted by the filter to yield as each page of the source returns.
+// In the fallback scan mode, the buckets without datapoints are measured again by listing objects
+// after all batches. If yield returns false, it stops fetching and returns errStopped or the error
+// of the context. The creation dates of the discovered buckets are carried to the enrichment.
+func (man *Manager) streamMetrics(ctx context.Context, buckets []s3types.Bucket, region string, end time.Time, yield func([]*Metric) bool) error {
+	var (
+		source  = man.getSource()
+		created = creationDates(buckets)
+		pending = make([]string, 0)
+	)
+	for batch := range slices.Chunk(bucketNames(buckets), man.batch()) {
+		man.notify(Event{Type: EventTypeBatchSent, Region: region, Buckets: len(batch)})
+		for page, err := range metricPages(ctx, source, man.newSourceQuery(region, batch, end)) {
 			if err != nil {
-				errorFunc(err)
-				return
+				return err
 			}
-			atomic.AddInt64(&total, n)
-			select {
-			case metricsChan <- m:
-			case <-cancelCtx.Done():
-				return
+			targets := make([]*Metric, 0, len(page))
+			for _, metric := range page {
+				if metric.Status == DataStatusNoData && man.scanMode == ScanModeFallback {
+					pending = append(pending, metric.BucketName)
+					continue
+				}
+				targets = append(targets, metric)
 			}
-		})
+			if err := man.emitMetrics(ctx, targets, region, created, yield); err != nil {
+				return err
+			}
+		}
 	}
-	go func() {
-		wg.Wait()
-		close(metricsChan)
-	}()
-	for {
-		select {
-		case m, ok := <-metricsChan:
-			if !ok {
-				data.Total = atomic.LoadInt64(&total)
-				return data, nil
+	if len(pending) > 0 {
+		scan := man.newScanSource()
+		for batch := range slices.Chunk(pending, man.batch()) {
+			man.notify(Event{Type: EventTypeBatchSent, Region: region, Buckets: len(batch)})
+			m, err := scan.Metrics(ctx, man.newSourceQuery(region, batch, end))
+			if err != nil {
+				return err
+			}
+			if err := man.emitMetrics(ctx, m, region, created, yield); err != nil {
+				return err
 			}
-			data.Metrics = append(data.Metrics, m...)
-		case err := <-errorChan:
-			cancel()
-			return nil, err
 		}
 	}
+	return nil
+}
+
+// emitMetrics sets the bucket metadata and the selected tags to the metrics before filtering,
+// so that the filter can refer to them, and passes the metrics accepted by the filter to yield.
+func (man *Manager) emitMetrics(ctx context.Context, metrics []*Metric, region string, created map[string]time.Time, yield func([]*Metric) bool) error {
+	if err := man.enrichMetadata(ctx, metrics, region, created); err != nil {
+		return err
+	}
+	if err := man.enrichTags(ctx, metrics, region); err != nil {
+		return err
+	}
+	accepted := make([]*Metric, 0, len(metrics))
+	for _, metric := range metrics {
+		ok, err := man.accept(metric)
+		if err != nil {
+			return err
+		}
+		if ok {
+			accepted = append(accepted, metric)
+		}
+	}
+	if len(accepted) > 0 && !yield(accepted) {
+		return cmp.Or(ctx.Err(), errStopped)
+	}
+	return nil
+}
+
+// getSource returns the source to fetch the metrics from.
+// The force scan mode takes precedence over the source set to the manager.
+func (man *Manager) getSource() Source {
+	switch {
+	case man.scanMode == ScanModeForce:
+		return man.newScanSource()
+	case man.source != nil:
+		return man.source
+	default:
+		return NewCloudWatchSource(man.client)
+	}
+}
+
+// newScanSource creates a new scan source that scans buckets up to the concurrency of the manager at a time.
+func (man *Manager) newScanSource() *ScanSource {
+	return &ScanSource{
+		client:      man.client,
+		concurrency: man.workers(),
+	}
+}
+
+// newSourceQuery creates a query for the batch of buckets with the metric settings of the manager,
+// whose time window ends at end.
+func (man *Manager) newSourceQuery(region string, buckets []string, end time.Time) *SourceQuery {
+	query := &SourceQuery{
+		Region:      region,
+		Buckets:     buckets,
+		MetricName:  man.metricName,
+		StorageType: man.storageType,
+		EndTime:     end,
+	}
+	if man.metricName.isRequest() {
+		query.FilterID = cmp.Or(man.filterID, DefaultFilterID)
+	}
+	return query
+}
+
+// accept reports whether the metric should be included in the result.
+func (man *Manager) accept(metric *Metric) (bool, error) {
+	if man.noDataMode == NoDataModeHide && metric.Status == DataStatusNoData {
+		return false, nil
+	}
+	if man.filterExpr == nil {
+		return true, nil
+	}
+	return man.filterExpr.Eval(metric)
 }
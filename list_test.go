@@ -3,17 +3,65 @@ package s3bytes
 import (
 	"context"
 	"errors"
+	"iter"
 	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/nekrassov01/filter"
 	"golang.org/x/sync/semaphore"
 )
 
+// mockSource is a mock implementation of Source.
+type mockSource struct {
+	BucketsFunc func(ctx context.Context, region, prefix string) ([]string, error)
+	MetricsFunc func(ctx context.Context, query *SourceQuery) ([]*Metric, error)
+}
+
+func (m *mockSource) Buckets(ctx context.Context, region, prefix string) ([]string, error) {
+	return m.BucketsFunc(ctx, region, prefix)
+}
+
+func (m *mockSource) Metrics(ctx context.Context, query *SourceQuery) ([]*Metric, error) {
+	return m.MetricsFunc(ctx, query)
+}
+
+// newTestBuckets returns the buckets of the names without creation dates.
+func newTestBuckets(names ...string) []s3types.Bucket {
+	buckets := make([]s3types.Bucket, len(names))
+	for i, name := range names {
+		buckets[i] = s3types.Bucket{Name: aws.String(name)}
+	}
+	return buckets
+}
+
+// mockPagedSource is a mock implementation of PagedSource, which yields a page for each bucket.
+type mockPagedSource struct {
+	mockSource
+	pages atomic.Int64
+}
+
+func (m *mockPagedSource) MetricPages(ctx context.Context, query *SourceQuery) iter.Seq2[[]*Metric, error] {
+	return func(yield func([]*Metric, error) bool) {
+		for _, bucket := range query.Buckets {
+			m.pages.Add(1)
+			metrics, err := m.MetricsFunc(ctx, &SourceQuery{Region: query.Region, Buckets: []string{bucket}})
+			if !yield(metrics, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
 func TestManager_List(t *testing.T) {
 	type fields struct {
 		client      *Client
@@ -38,6 +86,7 @@ func TestManager_List(t *testing.T) {
 			fields: fields{
 				client: newMockClient(
 					&mockS3{
+						ListDirectoryBucketsFunc: listNoDirectoryBuckets,
 						ListBucketsFunc: func(_ context.Context, _ *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
 							out := &s3.ListBucketsOutput{
 								Buckets: []s3types.Bucket{
@@ -67,7 +116,7 @@ func TestManager_List(t *testing.T) {
 				regions:     []string{"ap-northeast-1"},
 				metricName:  MetricNameBucketSizeBytes,
 				storageType: StorageTypeStandardStorage,
-				sem:         semaphore.NewWeighted(NumWorker),
+				sem:         semaphore.NewWeighted(int64(DefaultConcurrency)),
 			},
 			args: args{
 				ctx: context.Background(),
@@ -81,17 +130,77 @@ func TestManager_List(t *testing.T) {
 						MetricName:  MetricNameBucketSizeBytes,
 						StorageType: StorageTypeStandardStorage,
 						Value:       2048,
+						Status:      DataStatusOK,
+						Source:      SourceTypeCloudWatch,
+						BucketType:  BucketTypeGeneralPurpose,
 					},
 				},
 				Total: 2048,
 			},
 			wantErr: false,
 		},
+		{
+			name: "latency",
+			fields: fields{
+				client: newMockClient(
+					&mockS3{
+						ListDirectoryBucketsFunc: listNoDirectoryBuckets,
+						ListBucketsFunc: func(_ context.Context, _ *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+							out := &s3.ListBucketsOutput{
+								Buckets: []s3types.Bucket{
+									{
+										Name:         aws.String("bucket0"),
+										BucketRegion: aws.String("ap-northeast-1"),
+									},
+								},
+							}
+							return out, nil
+						},
+					},
+					&mockCloudWatch{
+						GetMetricDataFunc: func(_ context.Context, _ *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+							return &cloudwatch.GetMetricDataOutput{
+								MetricDataResults: []cwtypes.MetricDataResult{
+									{
+										Id:     aws.String("m0"),
+										Label:  aws.String("bucket0"),
+										Values: []float64{12.5},
+									},
+								},
+							}, nil
+						},
+					},
+				),
+				regions:    []string{"ap-northeast-1"},
+				metricName: MetricNameFirstByteLatency,
+				sem:        semaphore.NewWeighted(int64(DefaultConcurrency)),
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			want: &MetricData{
+				Header: header,
+				Metrics: []*Metric{
+					{
+						BucketName: "bucket0",
+						Region:     "ap-northeast-1",
+						MetricName: MetricNameFirstByteLatency,
+						Value:      12.5,
+						Status:     DataStatusOK,
+						Source:     SourceTypeCloudWatch,
+						BucketType: BucketTypeGeneralPurpose,
+					},
+				},
+				Total: 0,
+			},
+			wantErr: false,
+		},
 		{
 			name: "metric error",
 			fields: fields{
 				client: newMockClient(
 					&mockS3{
+						ListDirectoryBucketsFunc: listNoDirectoryBuckets,
 						ListBucketsFunc: func(_ context.Context, _ *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
 							out := &s3.ListBucketsOutput{
 								Buckets: []s3types.Bucket{
@@ -114,7 +223,7 @@ func TestManager_List(t *testing.T) {
 				regions:     []string{"ap-northeast-1"},
 				metricName:  MetricNameBucketSizeBytes,
 				storageType: StorageTypeStandardStorage,
-				sem:         semaphore.NewWeighted(NumWorker),
+				sem:         semaphore.NewWeighted(int64(DefaultConcurrency)),
 			},
 			args: args{
 				ctx: context.Background(),
@@ -126,6 +235,7 @@ func TestManager_List(t *testing.T) {
 			fields: fields{
 				client: newMockClient(
 					&mockS3{
+						ListDirectoryBucketsFunc: listNoDirectoryBuckets,
 						ListBucketsFunc: func(_ context.Context, _ *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
 							return nil, errors.New("error")
 						},
@@ -148,7 +258,7 @@ func TestManager_List(t *testing.T) {
 				regions:     []string{"ap-northeast-1"},
 				metricName:  MetricNameBucketSizeBytes,
 				storageType: StorageTypeStandardStorage,
-				sem:         semaphore.NewWeighted(NumWorker),
+				sem:         semaphore.NewWeighted(int64(DefaultConcurrency)),
 			},
 			args: args{
 				ctx: context.Background(),
@@ -177,3 +287,1034 @@ func TestManager_List(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_List_concurrent(t *testing.T) {
+	var inflight, peak atomic.Int64
+	source := &mockSource{
+		BucketsFunc: func(_ context.Context, region, _ string) ([]string, error) {
+			return []string{region + "-bucket0", region + "-bucket1", region + "-bucket2"}, nil
+		},
+		MetricsFunc: func(_ context.Context, query *SourceQuery) ([]*Metric, error) {
+			n := inflight.Add(1)
+			defer inflight.Add(-1)
+			for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+			}
+			time.Sleep(time.Millisecond)
+			metrics := make([]*Metric, len(query.Buckets))
+			for i, bucket := range query.Buckets {
+				metrics[i] = &Metric{BucketName: bucket, Region: query.Region, Value: 1024, Status: DataStatusOK}
+			}
+			return metrics, nil
+		},
+	}
+	var events atomic.Int64
+	man := NewManager(nil,
+		WithRegion("ap-northeast-1", "us-east-1", "eu-west-1", "us-west-2"),
+		WithMetric(MetricNameBucketSizeBytes, StorageTypeStandardStorage),
+		WithFilter(`value > 0`),
+		WithSource(source),
+		WithObserver(func(Event) { events.Add(1) }),
+		WithConcurrency(2),
+		WithBatchSize(2),
+	)
+	if err := man.Validate(); err != nil {
+		t.Fatalf("Manager.Validate() error = %v", err)
+	}
+	const calls = 8
+	totals := make([]int64, calls)
+	errs := make([]error, calls)
+	var wg sync.WaitGroup
+	for i := range calls {
+		wg.Go(func() {
+			data, err := man.List(context.Background())
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			totals[i] = data.Total
+		})
+	}
+	wg.Wait()
+	for i := range calls {
+		if errs[i] != nil {
+			t.Fatalf("Manager.List() error = %v", errs[i])
+		}
+		if totals[i] != 12*1024 {
+			t.Errorf("Manager.List() total = %v, want %v", totals[i], 12*1024)
+		}
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("Manager.List() concurrent batches = %v, want at most %v", p, 2)
+	}
+	// 4 regions with the start, the buckets, 2 batches and the finish
+	if n := events.Load(); n != calls*4*5 {
+		t.Errorf("Manager.List() events = %v, want %v", n, calls*4*5)
+	}
+}
+
+func TestManager_Stream(t *testing.T) {
+	buckets := func(_ context.Context, region, _ string) ([]string, error) {
+		if region == "eu-west-1" {
+			return nil, errors.New("error")
+		}
+		return []string{region + "-bucket0", region + "-bucket1", region + "-bucket2"}, nil
+	}
+	metrics := func(_ context.Context, query *SourceQuery) ([]*Metric, error) {
+		ret := make([]*Metric, 0, len(query.Buckets))
+		for _, bucket := range query.Buckets {
+			ret = append(ret, &Metric{BucketName: bucket, Region: query.Region, Value: 1024, Status: DataStatusOK})
+		}
+		return ret, nil
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	type fields struct {
+		regions []string
+	}
+	type args struct {
+		ctx   context.Context
+		limit int
+	}
+	tests := []struct {
+		name         string
+		fields       fields
+		args         args
+		want         []string
+		wantPages    int64
+		wantFinished int64
+		wantFailed   int64
+		wantErr      bool
+	}{
+		{
+			name: "all regions",
+			fields: fields{
+				regions: []string{"ap-northeast-1", "us-east-1"},
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			want: []string{
+				"ap-northeast-1-bucket0", "ap-northeast-1-bucket1", "ap-northeast-1-bucket2",
+				"us-east-1-bucket0", "us-east-1-bucket1", "us-east-1-bucket2",
+			},
+			wantPages:    6,
+			wantFinished: 2,
+			wantErr:      false,
+		},
+		{
+			name: "break",
+			fields: fields{
+				regions: []string{"ap-northeast-1"},
+			},
+			args: args{
+				ctx:   context.Background(),
+				limit: 1,
+			},
+			want:         []string{"ap-northeast-1-bucket0"},
+			wantPages:    2,
+			wantFinished: 1,
+			wantErr:      false,
+		},
+		{
+			name: "region error",
+			fields: fields{
+				regions: []string{"eu-west-1"},
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			want:       []string{},
+			wantFailed: 1,
+			wantErr:    true,
+		},
+		{
+			name: "canceled",
+			fields: fields{
+				regions: []string{"ap-northeast-1", "us-east-1"},
+			},
+			args: args{
+				ctx: canceled,
+			},
+			want:       []string{},
+			wantFailed: 2,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &mockPagedSource{
+				mockSource: mockSource{
+					BucketsFunc: buckets,
+					MetricsFunc: metrics,
+				},
+			}
+			var finished, failed atomic.Int64
+			man := &Manager{
+				regions: tt.fields.regions,
+				source:  source,
+				observers: []Observer{
+					func(e Event) {
+						switch e.Type {
+						case EventTypeRegionFinished:
+							finished.Add(1)
+						case EventTypeRegionFailed:
+							failed.Add(1)
+						}
+					},
+				},
+				sem: semaphore.NewWeighted(int64(DefaultConcurrency)),
+			}
+			var (
+				got  = make([]string, 0)
+				errs int
+			)
+			for metric, err := range man.Stream(tt.args.ctx) {
+				if err != nil {
+					errs++
+					continue
+				}
+				got = append(got, metric.BucketName)
+				if len(got) == tt.args.limit {
+					break
+				}
+			}
+			if (errs > 0) != tt.wantErr || errs > 1 {
+				t.Errorf("Manager.Stream() errors = %v, wantErr %v", errs, tt.wantErr)
+				return
+			}
+			slices.Sort(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Manager.Stream() = %v, want %v", got, tt.want)
+			}
+			if finished.Load() != tt.wantFinished || failed.Load() != tt.wantFailed {
+				t.Errorf("Manager.Stream() finished = %v, failed = %v, want %v and %v", finished.Load(), failed.Load(), tt.wantFinished, tt.wantFailed)
+			}
+			if tt.wantPages > 0 && source.pages.Load() > tt.wantPages {
+				t.Errorf("Manager.Stream() pages = %v, want at most %v", source.pages.Load(), tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestManager_ListRegion(t *testing.T) {
+	client := newMockClient(
+		&mockS3{
+			ListDirectoryBucketsFunc: listNoDirectoryBuckets,
+			ListBucketsFunc: func(_ context.Context, params *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+				if aws.ToString(params.BucketRegion) != "us-east-1" {
+					return nil, errors.New("unexpected region")
+				}
+				return &s3.ListBucketsOutput{
+					Buckets: []s3types.Bucket{
+						{
+							Name:         aws.String("bucket0"),
+							BucketRegion: aws.String("us-east-1"),
+						},
+					},
+				}, nil
+			},
+		},
+		&mockCloudWatch{
+			GetMetricDataFunc: func(_ context.Context, _ *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+				return &cloudwatch.GetMetricDataOutput{
+					MetricDataResults: []cwtypes.MetricDataResult{
+						{
+							Id:     aws.String("m0"),
+							Label:  aws.String("bucket0"),
+							Values: []float64{2048},
+						},
+					},
+				}, nil
+			},
+		},
+	)
+	type args struct {
+		region string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *MetricData
+		wantErr bool
+	}{
+		{
+			name: "region not in manager",
+			args: args{
+				region: "us-east-1",
+			},
+			want: &MetricData{
+				Header: header,
+				Metrics: []*Metric{
+					{
+						BucketName:  "bucket0",
+						Region:      "us-east-1",
+						MetricName:  MetricNameBucketSizeBytes,
+						StorageType: StorageTypeStandardStorage,
+						Value:       2048,
+						Status:      DataStatusOK,
+						Source:      SourceTypeCloudWatch,
+						BucketType:  BucketTypeGeneralPurpose,
+					},
+				},
+				Total: 2048,
+			},
+			wantErr: false,
+		},
+		{
+			name: "bucket error",
+			args: args{
+				region: "eu-west-1",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "unsupported region",
+			args: args{
+				region: "unknown",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			man := &Manager{
+				client:      client,
+				regions:     []string{"ap-northeast-1"},
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+				sem:         semaphore.NewWeighted(int64(DefaultConcurrency)),
+			}
+			got, err := man.ListRegion(context.Background(), tt.args.region)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Manager.ListRegion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Manager.ListRegion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManager_listRegion(t *testing.T) {
+	buckets := func(_ context.Context, _, _ string) ([]string, error) {
+		return []string{"bucket0", "bucket1", "bucket2"}, nil
+	}
+	metrics := func(_ context.Context, query *SourceQuery) ([]*Metric, error) {
+		ret := make([]*Metric, 0, len(query.Buckets))
+		for _, bucket := range query.Buckets {
+			ret = append(ret, &Metric{BucketName: bucket, Region: query.Region, Value: 1024, Status: DataStatusOK})
+		}
+		return ret, nil
+	}
+	errBuckets := errors.New("buckets error")
+	errMetrics := errors.New("metrics error")
+	type fields struct {
+		source Source
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		wantTotal  int64
+		wantEvents []Event
+		wantErr    bool
+	}{
+		{
+			name: "success",
+			fields: fields{
+				source: &mockSource{
+					BucketsFunc: buckets,
+					MetricsFunc: metrics,
+				},
+			},
+			wantTotal: 3072,
+			wantEvents: []Event{
+				{Type: EventTypeRegionStarted, Region: "ap-northeast-1"},
+				{Type: EventTypeBucketsListed, Region: "ap-northeast-1", Buckets: 3},
+				{Type: EventTypeBatchSent, Region: "ap-northeast-1", Buckets: 2},
+				{Type: EventTypeBatchSent, Region: "ap-northeast-1", Buckets: 1},
+				{Type: EventTypeRegionFinished, Region: "ap-northeast-1"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "buckets error",
+			fields: fields{
+				source: &mockSource{
+					BucketsFunc: func(_ context.Context, _, _ string) ([]string, error) {
+						return nil, errBuckets
+					},
+					MetricsFunc: metrics,
+				},
+			},
+			wantEvents: []Event{
+				{Type: EventTypeRegionStarted, Region: "ap-northeast-1"},
+				{Type: EventTypeRegionFailed, Region: "ap-northeast-1", Err: errBuckets},
+			},
+			wantErr: true,
+		},
+		{
+			name: "metrics error",
+			fields: fields{
+				source: &mockSource{
+					BucketsFunc: buckets,
+					MetricsFunc: func(_ context.Context, _ *SourceQuery) ([]*Metric, error) {
+						return nil, errMetrics
+					},
+				},
+			},
+			wantEvents: []Event{
+				{Type: EventTypeRegionStarted, Region: "ap-northeast-1"},
+				{Type: EventTypeBucketsListed, Region: "ap-northeast-1", Buckets: 3},
+				{Type: EventTypeBatchSent, Region: "ap-northeast-1", Buckets: 2},
+				{Type: EventTypeRegionFailed, Region: "ap-northeast-1", Err: errMetrics},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []Event
+			man := &Manager{
+				source: tt.fields.source,
+				observers: []Observer{
+					func(e Event) { events = append(events, e) },
+				},
+				batchSize: 2,
+				sem:       semaphore.NewWeighted(int64(DefaultConcurrency)),
+			}
+			_, total, err := man.listRegion(context.Background(), "ap-northeast-1", testNow)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Manager.listRegion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if total != tt.wantTotal {
+				t.Errorf("Manager.listRegion() total = %v, want %v", total, tt.wantTotal)
+			}
+			if !reflect.DeepEqual(events, tt.wantEvents) {
+				t.Errorf("Manager.listRegion() events = %v, want %v", events, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestManager_streamMetrics(t *testing.T) {
+	type fields struct {
+		client      *Client
+		metricName  MetricName
+		storageType StorageType
+		prefix      *string
+		regions     []string
+		filterExpr  filterExpr
+		filterRaw   string
+		scanMode    ScanMode
+		tagKeys     []string
+		source      Source
+		batchSize   int
+		sem         *semaphore.Weighted
+	}
+	type args struct {
+		ctx     context.Context
+		buckets []string
+		region  string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    []*Metric
+		want1   int64
+		wantErr bool
+	}{
+		{
+			name: "success",
+			fields: fields{
+				client: newMockClient(
+					nil,
+					&mockCloudWatch{
+						GetMetricDataFunc: func(_ context.Context, _ *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+							return &cloudwatch.GetMetricDataOutput{
+								MetricDataResults: []cwtypes.MetricDataResult{
+									{
+										Label:  aws.String("bucket0"),
+										Values: []float64{1024, 2048},
+									},
+									{
+										Label:  aws.String("bucket1"),
+										Values: []float64{0},
+									},
+								},
+								NextToken: nil,
+							}, nil
+						},
+					},
+				),
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+			},
+			args: args{
+				ctx:     context.Background(),
+				buckets: []string{"bucket0", "bucket1"},
+				region:  "ap-northeast-1",
+			},
+			want: []*Metric{
+				{
+					BucketName:  "bucket0",
+					Region:      "ap-northeast-1",
+					MetricName:  MetricNameBucketSizeBytes,
+					StorageType: StorageTypeStandardStorage,
+					Value:       2048,
+					Status:      DataStatusOK,
+					Source:      SourceTypeCloudWatch,
+					BucketType:  BucketTypeGeneralPurpose,
+				},
+				{
+					BucketName:  "bucket1",
+					Region:      "ap-northeast-1",
+					MetricName:  MetricNameBucketSizeBytes,
+					StorageType: StorageTypeStandardStorage,
+					Value:       0,
+					Status:      DataStatusOK,
+					Source:      SourceTypeCloudWatch,
+					BucketType:  BucketTypeGeneralPurpose,
+				},
+			},
+			want1:   2048,
+			wantErr: false,
+		},
+		{
+			name: "error",
+			fields: fields{
+				client: newMockClient(
+					nil,
+					&mockCloudWatch{
+						GetMetricDataFunc: func(_ context.Context, _ *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+							return nil, errors.New("error")
+						},
+					},
+				),
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+			},
+			args: args{
+				ctx:     context.Background(),
+				buckets: []string{"bucket0"},
+				region:  "ap-northeast-1",
+			},
+			want:    nil,
+			want1:   0,
+			wantErr: true,
+		},
+		{
+			name: "filter returns false",
+			fields: fields{
+				client: newMockClient(
+					nil,
+					&mockCloudWatch{
+						GetMetricDataFunc: func(_ context.Context, _ *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+							return &cloudwatch.GetMetricDataOutput{
+								MetricDataResults: []cwtypes.MetricDataResult{
+									{
+										Label:  aws.String("bucket0"),
+										Values: []float64{1024, 2048},
+									},
+								},
+								NextToken: nil,
+							}, nil
+						},
+					},
+				),
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+				filterExpr:  func() filterExpr { expr, _ := filter.Parse(`bytes == 0`); return expr }(),
+				filterRaw:   "bytes == 0",
+			},
+			args: args{
+				ctx:     context.Background(),
+				buckets: []string{"bucket0"},
+				region:  "ap-northeast-1",
+			},
+			want:    []*Metric{},
+			want1:   0,
+			wantErr: false,
+		},
+		{
+			name: "force scan",
+			fields: fields{
+				client: newMockClient(
+					&mockS3{
+						ListObjectsV2Func: func(_ context.Context, params *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+							return &s3.ListObjectsV2Output{
+								Contents: []s3types.Object{
+									{
+										Key:          aws.String(aws.ToString(params.Bucket) + "/key0"),
+										Size:         aws.Int64(1024),
+										StorageClass: s3types.ObjectStorageClassStandard,
+									},
+									{
+										Key:          aws.String(aws.ToString(params.Bucket) + "/key1"),
+										Size:         aws.Int64(4096),
+										StorageClass: s3types.ObjectStorageClassGlacier,
+									},
+								},
+							}, nil
+						},
+					},
+					nil,
+				),
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+				scanMode:    ScanModeForce,
+			},
+			args: args{
+				ctx:     context.Background(),
+				buckets: []string{"bucket0"},
+				region:  "ap-northeast-1",
+			},
+			want: []*Metric{
+				{
+					BucketName:  "bucket0",
+					Region:      "ap-northeast-1",
+					MetricName:  MetricNameBucketSizeBytes,
+					StorageType: StorageTypeStandardStorage,
+					Value:       1024,
+					Status:      DataStatusOK,
+					Timestamp:   testNow,
+					Source:      SourceTypeScan,
+					BucketType:  BucketTypeGeneralPurpose,
+				},
+			},
+			want1:   1024,
+			wantErr: false,
+		},
+		{
+			name: "fallback to scan",
+			fields: fields{
+				client: newMockClient(
+					&mockS3{
+						ListObjectsV2Func: func(_ context.Context, _ *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+							return &s3.ListObjectsV2Output{
+								Contents: []s3types.Object{
+									{
+										Key:  aws.String("key0"),
+										Size: aws.Int64(512),
+									},
+								},
+							}, nil
+						},
+					},
+					&mockCloudWatch{
+						GetMetricDataFunc: func(_ context.Context, _ *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+							return &cloudwatch.GetMetricDataOutput{
+								MetricDataResults: []cwtypes.MetricDataResult{
+									{
+										Label:  aws.String("bucket0"),
+										Values: []float64{1024},
+									},
+									{
+										Label:  aws.String("bucket1"),
+										Values: nil,
+									},
+								},
+								NextToken: nil,
+							}, nil
+						},
+					},
+				),
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+				scanMode:    ScanModeFallback,
+			},
+			args: args{
+				ctx:     context.Background(),
+				buckets: []string{"bucket0", "bucket1"},
+				region:  "ap-northeast-1",
+			},
+			want: []*Metric{
+				{
+					BucketName:  "bucket0",
+					Region:      "ap-northeast-1",
+					MetricName:  MetricNameBucketSizeBytes,
+					StorageType: StorageTypeStandardStorage,
+					Value:       1024,
+					Status:      DataStatusOK,
+					Source:      SourceTypeCloudWatch,
+					BucketType:  BucketTypeGeneralPurpose,
+				},
+				{
+					BucketName:  "bucket1",
+					Region:      "ap-northeast-1",
+					MetricName:  MetricNameBucketSizeBytes,
+					StorageType: StorageTypeStandardStorage,
+					Value:       512,
+					Status:      DataStatusOK,
+					Timestamp:   testNow,
+					Source:      SourceTypeScan,
+					BucketType:  BucketTypeGeneralPurpose,
+				},
+			},
+			want1:   1536,
+			wantErr: false,
+		},
+		{
+			name: "custom source in batches",
+			fields: fields{
+				metricName:  MetricNameNumberOfObjects,
+				storageType: StorageTypeAllStorageTypes,
+				source: &mockSource{
+					MetricsFunc: func(_ context.Context, query *SourceQuery) ([]*Metric, error) {
+						if len(query.Buckets) > 2 {
+							return nil, errors.New("too many buckets")
+						}
+						metrics := make([]*Metric, len(query.Buckets))
+						for i, bucket := range query.Buckets {
+							metrics[i] = &Metric{
+								BucketName:  bucket,
+								Region:      query.Region,
+								MetricName:  query.MetricName,
+								StorageType: query.StorageType,
+								Value:       10,
+								Status:      DataStatusOK,
+							}
+						}
+						return metrics, nil
+					},
+				},
+				batchSize: 2,
+			},
+			args: args{
+				ctx:     context.Background(),
+				buckets: []string{"bucket0", "bucket1", "bucket2"},
+				region:  "ap-northeast-1",
+			},
+			want: []*Metric{
+				{
+					BucketName:  "bucket0",
+					Region:      "ap-northeast-1",
+					MetricName:  MetricNameNumberOfObjects,
+					StorageType: StorageTypeAllStorageTypes,
+					Value:       10,
+					Status:      DataStatusOK,
+				},
+				{
+					BucketName:  "bucket1",
+					Region:      "ap-northeast-1",
+					MetricName:  MetricNameNumberOfObjects,
+					StorageType: StorageTypeAllStorageTypes,
+					Value:       10,
+					Status:      DataStatusOK,
+				},
+				{
+					BucketName:  "bucket2",
+					Region:      "ap-northeast-1",
+					MetricName:  MetricNameNumberOfObjects,
+					StorageType: StorageTypeAllStorageTypes,
+					Value:       10,
+					Status:      DataStatusOK,
+				},
+			},
+			want1:   30,
+			wantErr: false,
+		},
+		{
+			name: "filter by tag",
+			fields: fields{
+				client: newMockClient(
+					&mockS3{
+						GetBucketTaggingFunc: func(_ context.Context, params *s3.GetBucketTaggingInput, _ ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+							if aws.ToString(params.Bucket) == "bucket1" {
+								return nil, &smithy.GenericAPIError{Code: "NoSuchTagSet"}
+							}
+							return &s3.GetBucketTaggingOutput{
+								TagSet: []s3types.Tag{
+									{Key: aws.String("team"), Value: aws.String("platform")},
+									{Key: aws.String("owner"), Value: aws.String("alice")},
+								},
+							}, nil
+						},
+					},
+					&mockCloudWatch{
+						GetMetricDataFunc: func(_ context.Context, _ *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+							return &cloudwatch.GetMetricDataOutput{
+								MetricDataResults: []cwtypes.MetricDataResult{
+									{
+										Label:  aws.String("bucket0"),
+										Values: []float64{2048},
+									},
+									{
+										Label:  aws.String("bucket1"),
+										Values: []float64{1024},
+									},
+								},
+								NextToken: nil,
+							}, nil
+						},
+					},
+				),
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+				filterExpr:  func() filterExpr { expr, _ := filter.Parse(`tag_team == "platform"`); return expr }(),
+				filterRaw:   `tag_team == "platform"`,
+				tagKeys:     []string{"team", "env"},
+			},
+			args: args{
+				ctx:     context.Background(),
+				buckets: []string{"bucket0", "bucket1"},
+				region:  "ap-northeast-1",
+			},
+			want: []*Metric{
+				{
+					BucketName:  "bucket0",
+					Region:      "ap-northeast-1",
+					MetricName:  MetricNameBucketSizeBytes,
+					StorageType: StorageTypeStandardStorage,
+					Value:       2048,
+					Status:      DataStatusOK,
+					Source:      SourceTypeCloudWatch,
+					BucketType:  BucketTypeGeneralPurpose,
+					Tags:        map[string]string{"team": "platform"},
+				},
+			},
+			want1:   2048,
+			wantErr: false,
+		},
+		{
+			name: "tags denied",
+			fields: fields{
+				client: newMockClient(
+					&mockS3{
+						GetBucketTaggingFunc: func(_ context.Context, _ *s3.GetBucketTaggingInput, _ ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+							return nil, &smithy.GenericAPIError{Code: "AccessDenied"}
+						},
+					},
+					&mockCloudWatch{
+						GetMetricDataFunc: func(_ context.Context, _ *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+							return &cloudwatch.GetMetricDataOutput{
+								MetricDataResults: []cwtypes.MetricDataResult{
+									{
+										Label:  aws.String("bucket0"),
+										Values: []float64{2048},
+									},
+								},
+								NextToken: nil,
+							}, nil
+						},
+					},
+				),
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+				tagKeys:     []string{"team"},
+			},
+			args: args{
+				ctx:     context.Background(),
+				buckets: []string{"bucket0"},
+				region:  "ap-northeast-1",
+			},
+			want: []*Metric{
+				{
+					BucketName:  "bucket0",
+					Region:      "ap-northeast-1",
+					MetricName:  MetricNameBucketSizeBytes,
+					StorageType: StorageTypeStandardStorage,
+					Value:       2048,
+					Status:      DataStatusOK,
+					Source:      SourceTypeCloudWatch,
+					BucketType:  BucketTypeGeneralPurpose,
+					Tags:        map[string]string{"team": statusUnknown},
+				},
+			},
+			want1:   2048,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			man := &Manager{
+				client:      tt.fields.client,
+				metricName:  tt.fields.metricName,
+				storageType: tt.fields.storageType,
+				prefix:      tt.fields.prefix,
+				regions:     tt.fields.regions,
+				filterExpr:  tt.fields.filterExpr,
+				filterRaw:   tt.fields.filterRaw,
+				scanMode:    tt.fields.scanMode,
+				tagKeys:     tt.fields.tagKeys,
+				source:      tt.fields.source,
+				batchSize:   tt.fields.batchSize,
+				sem:         tt.fields.sem,
+			}
+			var (
+				got  = make([]*Metric, 0)
+				got1 int64
+			)
+			err := man.streamMetrics(tt.args.ctx, newTestBuckets(tt.args.buckets...), tt.args.region, testNow, func(m []*Metric) bool {
+				for _, metric := range m {
+					got = append(got, metric)
+					got1 += int64(metric.Value)
+				}
+				return true
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Manager.streamMetrics() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Manager.streamMetrics() got = %v, want %v", got, tt.want)
+			}
+			if got1 != tt.want1 {
+				t.Errorf("Manager.streamMetrics() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestManager_newSourceQuery(t *testing.T) {
+	type fields struct {
+		metricName  MetricName
+		storageType StorageType
+		filterID    string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   *SourceQuery
+	}{
+		{
+			name: "storage metric",
+			fields: fields{
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+				filterID:    "documents",
+			},
+			want: &SourceQuery{
+				Region:      "ap-northeast-1",
+				Buckets:     []string{"bucket0"},
+				MetricName:  MetricNameBucketSizeBytes,
+				StorageType: StorageTypeStandardStorage,
+				EndTime:     testNow,
+			},
+		},
+		{
+			name: "request metric",
+			fields: fields{
+				metricName: MetricNameAllRequests,
+				filterID:   "documents",
+			},
+			want: &SourceQuery{
+				Region:     "ap-northeast-1",
+				Buckets:    []string{"bucket0"},
+				MetricName: MetricNameAllRequests,
+				FilterID:   "documents",
+				EndTime:    testNow,
+			},
+		},
+		{
+			name: "request metric with default filter id",
+			fields: fields{
+				metricName: MetricNameAllRequests,
+			},
+			want: &SourceQuery{
+				Region:     "ap-northeast-1",
+				Buckets:    []string{"bucket0"},
+				MetricName: MetricNameAllRequests,
+				FilterID:   DefaultFilterID,
+				EndTime:    testNow,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			man := &Manager{
+				metricName:  tt.fields.metricName,
+				storageType: tt.fields.storageType,
+				filterID:    tt.fields.filterID,
+			}
+			if got := man.newSourceQuery("ap-northeast-1", []string{"bucket0"}, testNow); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Manager.newSourceQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManager_accept(t *testing.T) {
+	type fields struct {
+		filterExpr filterExpr
+		noDataMode NoDataMode
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		metric  *Metric
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:   "no filter",
+			fields: fields{},
+			metric: &Metric{Status: DataStatusNoData},
+			want:   true,
+		},
+		{
+			name: "hide no data",
+			fields: fields{
+				noDataMode: NoDataModeHide,
+			},
+			metric: &Metric{Status: DataStatusNoData},
+			want:   false,
+		},
+		{
+			name: "hide keeps ok",
+			fields: fields{
+				noDataMode: NoDataModeHide,
+			},
+			metric: &Metric{Status: DataStatusOK},
+			want:   true,
+		},
+		{
+			name: "filter by status",
+			fields: fields{
+				filterExpr: func() filterExpr { expr, _ := filter.Parse(`status == "partial"`); return expr }(),
+			},
+			metric: &Metric{Status: DataStatusPartial},
+			want:   true,
+		},
+		{
+			name: "filter by timestamp",
+			fields: fields{
+				filterExpr: func() filterExpr { expr, _ := filter.Parse(`timestamp > 2026-01-01T00:00:00Z`); return expr }(),
+			},
+			metric: &Metric{Timestamp: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)},
+			want:   false,
+		},
+		{
+			name: "unknown field",
+			fields: fields{
+				filterExpr: func() filterExpr { expr, _ := filter.Parse(`unknown == 1`); return expr }(),
+			},
+			metric:  &Metric{},
+			want:    false,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			man := &Manager{
+				filterExpr: tt.fields.filterExpr,
+				noDataMode: tt.fields.noDataMode,
+			}
+			got, err := man.accept(tt.metric)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Manager.accept() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Manager.accept() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
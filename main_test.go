@@ -2,29 +2,21 @@ package s3bytes
 
 import (
 	"testing"
+	"time"
 )
 
+// testNow is the fixed current time of the test, at which the time window of each run ends.
+var testNow = time.Now()
+
 // TestMain is the entry point of the test.
 func TestMain(m *testing.M) {
-	var (
-		originalMaxQueries    = setMaxQueries(2)
-		originalMaxChartItems = setMaxChartItems(3)
-	)
-	defer func() {
-		setMaxQueries(originalMaxQueries)
-		setMaxChartItems(originalMaxChartItems)
-	}()
+	originalNow := setNow(func() time.Time { return testNow })
+	defer setNow(originalNow)
 	m.Run()
 }
 
-func setMaxQueries(n int) (original int) {
-	original = MaxQueries
-	MaxQueries = n
-	return original
-}
-
-func setMaxChartItems(n int) (original int) {
-	original = MaxChartItems
-	MaxChartItems = n
+func setNow(f func() time.Time) (original func() time.Time) {
+	original = now
+	now = f
 	return original
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/nekrassov01/filter"
@@ -16,47 +17,241 @@ type (
 )
 
 // Manager is a manager struct for the s3bytes package.
+// Once configured, a manager can be reused across concurrent calls of List and the other listing
+// methods, which share the concurrency of the manager. The settings must not be changed during the calls.
 type Manager struct {
 	client      *Client `json:"-"`
 	metricName  MetricName
 	storageType StorageType
+	filterID    string
 	prefix      *string
 	regions     []string
 	filterExpr  filterExpr
 	filterRaw   string
+	scanMode    ScanMode
+	noDataMode  NoDataMode
+	tagKeys     []string
+	enrich      bool
+	source      Source
+	observers   []Observer
+	concurrency int
+	batchSize   int
 	sem         *semaphore.Weighted
 }
 
-// NewManager creates a new manager.
-func NewManager(client *Client) *Manager {
-	return &Manager{
-		client:  client,
-		regions: DefaultRegions,
-		sem:     semaphore.NewWeighted(NumWorker),
+// ManagerOption is a functional option for the manager.
+// The options only set the values, which are checked all at once by Validate.
+type ManagerOption func(*Manager)
+
+// WithRegion sets the target regions. If no region is specified, DefaultRegions is used.
+func WithRegion(regions ...string) ManagerOption {
+	return func(man *Manager) {
+		if len(regions) > 0 {
+			man.regions = slices.Clone(regions)
+		}
+	}
+}
+
+// WithMetric sets the metric name and storage type.
+// The storage type does not apply to the request metrics, and is set to none for them.
+func WithMetric(metricName MetricName, storageType StorageType) ManagerOption {
+	return func(man *Manager) {
+		man.metricName = metricName
+		man.storageType = storageType
+		if metricName.isRequest() {
+			man.storageType = StorageTypeNone
+		}
+	}
+}
+
+// WithFilterID sets the filter ID of the request metrics configuration.
+func WithFilterID(id string) ManagerOption {
+	return func(man *Manager) {
+		man.filterID = id
+	}
+}
+
+// WithPrefix sets the prefix of the bucket names.
+func WithPrefix(prefix string) ManagerOption {
+	return func(man *Manager) {
+		man.prefix = nil
+		if prefix != "" {
+			man.prefix = aws.String(prefix)
+		}
+	}
+}
+
+// WithFilter sets the filter expressions. The expressions that fail to parse are reported by Validate.
+func WithFilter(raw string) ManagerOption {
+	return func(man *Manager) {
+		man.filterRaw = raw
+		man.filterExpr = nil
+		if raw != "" {
+			man.filterExpr, _ = filter.Parse(raw)
+		}
+	}
+}
+
+// WithScan sets the scan mode for listing objects.
+func WithScan(mode ScanMode) ManagerOption {
+	return func(man *Manager) {
+		man.scanMode = mode
+	}
+}
+
+// WithNoData sets how to handle buckets that have no datapoints.
+func WithNoData(mode NoDataMode) ManagerOption {
+	return func(man *Manager) {
+		man.noDataMode = mode
+	}
+}
+
+// WithTags sets the keys of the bucket tags to fetch with GetBucketTagging.
+// The duplicate keys are ignored.
+func WithTags(keys ...string) ManagerOption {
+	return func(man *Manager) {
+		man.tagKeys = make([]string, 0, len(keys))
+		for _, key := range keys {
+			if !slices.Contains(man.tagKeys, key) {
+				man.tagKeys = append(man.tagKeys, key)
+			}
+		}
+	}
+}
+
+// WithEnrich sets whether to add the bucket metadata to the metrics.
+func WithEnrich(enrich bool) ManagerOption {
+	return func(man *Manager) {
+		man.enrich = enrich
+	}
+}
+
+// WithSource sets the source from which buckets are discovered and their values are fetched.
+func WithSource(source Source) ManagerOption {
+	return func(man *Manager) {
+		man.source = source
+	}
+}
+
+// WithObserver adds the observer that receives the progress of each region.
+func WithObserver(observer Observer) ManagerOption {
+	return func(man *Manager) {
+		man.observers = append(man.observers, observer)
+	}
+}
+
+// WithConcurrency sets the maximum number of the regions processed at a time, which also limits
+// the buckets and prefixes processed at a time in a region. The default is NumWorker, which is DefaultConcurrency
+// unless it is changed.
+func WithConcurrency(n int) ManagerOption {
+	return func(man *Manager) {
+		man.concurrency = n
+	}
+}
+
+// WithBatchSize sets the number of the buckets queried at a time, from 1 to MaxQueries.
+// The default is MaxQueries.
+func WithBatchSize(n int) ManagerOption {
+	return func(man *Manager) {
+		man.batchSize = n
 	}
 }
 
+// NewManager creates a new manager with the options.
+// Call Validate before listing, since the options are not checked on creation.
+func NewManager(client *Client, opts ...ManagerOption) *Manager {
+	man := &Manager{
+		client:      client,
+		regions:     slices.Clone(DefaultRegions),
+		concurrency: int(NumWorker),
+		batchSize:   MaxQueries,
+	}
+	for _, opt := range opts {
+		opt(man)
+	}
+	man.sem = semaphore.NewWeighted(int64(man.workers()))
+	return man
+}
+
+// Validate checks all settings of the manager at once, including the combinations of the settings
+// that the setters check depending on the order of the calls, and returns the joined errors.
+func (man *Manager) Validate() error {
+	errs := []error{
+		checkRegions(man.regions),
+		checkMetric(man.metricName, man.storageType),
+		checkFilterID(man.filterID),
+		checkScan(man.scanMode, man.metricName),
+		checkNoData(man.noDataMode),
+		checkTags(man.tagKeys),
+	}
+	if man.metricName == MetricNameNone {
+		errs = append(errs, errors.New("metric name must be set"))
+	}
+	if man.prefix != nil {
+		errs = append(errs, checkPrefix(*man.prefix))
+	}
+	if man.filterRaw != "" && man.filterExpr == nil {
+		_, err := filter.Parse(man.filterRaw)
+		errs = append(errs, fmt.Errorf("failed to parse filter: %w", err))
+	}
+	if slices.ContainsFunc(man.observers, func(observer Observer) bool { return observer == nil }) {
+		errs = append(errs, errors.New("observer must not be nil"))
+	}
+	if man.concurrency < 1 {
+		errs = append(errs, fmt.Errorf("invalid concurrency: %d", man.concurrency))
+	}
+	if man.batchSize < 1 || man.batchSize > MaxQueries {
+		errs = append(errs, fmt.Errorf("invalid batch size: %d: must be between 1 and %d", man.batchSize, MaxQueries))
+	}
+	return errors.Join(errs...)
+}
+
+// workers returns the concurrency of the manager, or DefaultConcurrency if it is not positive,
+// so that the semaphore and the limits of the goroutines never block forever even if Validate is skipped.
+func (man *Manager) workers() int {
+	if man.concurrency < 1 {
+		return DefaultConcurrency
+	}
+	return man.concurrency
+}
+
+// batch returns the batch size of the manager, or MaxQueries if it is out of range.
+func (man *Manager) batch() int {
+	if man.batchSize < 1 || man.batchSize > MaxQueries {
+		return MaxQueries
+	}
+	return man.batchSize
+}
+
 // SetRegion sets the specified regions.
 func (man *Manager) SetRegion(regions []string) error {
 	if len(regions) == 0 {
 		return nil
 	}
-	for _, region := range regions {
-		if _, ok := allowedRegions[region]; !ok {
-			return fmt.Errorf("unsupported region: %s", region)
-		}
+	if err := checkRegions(regions); err != nil {
+		return err
 	}
-	man.regions = regions
+	man.regions = slices.Clone(regions)
 	return nil
 }
 
+// Regions returns the target regions.
+func (man *Manager) Regions() []string {
+	return slices.Clone(man.regions)
+}
+
+// MetricName returns the target metric name.
+func (man *Manager) MetricName() MetricName {
+	return man.metricName
+}
+
 // SetPrefix sets the prefix.
 func (man *Manager) SetPrefix(prefix string) error {
 	if prefix == "" {
 		return nil
 	}
-	if !bucketPrefixPattern.MatchString(prefix) {
-		return fmt.Errorf("invalid prefix: %q", prefix)
+	if err := checkPrefix(prefix); err != nil {
+		return err
 	}
 	man.prefix = aws.String(prefix)
 	return nil
@@ -77,31 +272,192 @@ func (man *Manager) SetFilter(raw string) error {
 }
 
 // SetMetric sets the metric name and storage type.
+// The storage type does not apply to the request metrics, and is set to none for them.
 func (man *Manager) SetMetric(metricName MetricName, storageType StorageType) error {
-	if metricName == MetricNameBucketSizeBytes && storageType == StorageTypeAllStorageTypes {
-		return errors.New("BucketSizeBytes metric does not support AllStorageTypes")
+	if metricName.isRequest() {
+		if err := checkScan(man.scanMode, metricName); err != nil {
+			return err
+		}
+		man.metricName = metricName
+		man.storageType = StorageTypeNone
+		return nil
 	}
-	if metricName == MetricNameNumberOfObjects && storageType != StorageTypeAllStorageTypes {
-		return errors.New("NumberOfObjects metric only supports AllStorageTypes")
+	if err := checkMetric(metricName, storageType); err != nil {
+		return err
 	}
 	man.metricName = metricName
 	man.storageType = storageType
 	return nil
 }
 
+// SetFilterID sets the filter ID of the request metrics configuration to query the request metrics for.
+// If no filter ID is set, DefaultFilterID is used.
+func (man *Manager) SetFilterID(id string) error {
+	if id == "" {
+		return nil
+	}
+	if err := checkFilterID(id); err != nil {
+		return err
+	}
+	man.filterID = id
+	return nil
+}
+
+// SetScan sets the scan mode for listing objects.
+// The request metrics cannot be computed by listing objects, so they support no scan mode.
+func (man *Manager) SetScan(mode ScanMode) error {
+	if err := checkScan(mode, man.metricName); err != nil {
+		return err
+	}
+	man.scanMode = mode
+	return nil
+}
+
+// SetNoData sets how to handle buckets that have no datapoints.
+func (man *Manager) SetNoData(mode NoDataMode) error {
+	if err := checkNoData(mode); err != nil {
+		return err
+	}
+	man.noDataMode = mode
+	return nil
+}
+
+// SetTags sets the keys of the bucket tags to fetch with GetBucketTagging.
+// The tags are added to the metrics as the "Tag:<key>" columns, and can be referred to
+// as "tag_<key>" in the filter expressions.
+func (man *Manager) SetTags(keys []string) error {
+	if err := checkTags(keys); err != nil {
+		return err
+	}
+	WithTags(keys...)(man)
+	return nil
+}
+
+// SetEnrich sets whether to add the creation date, the versioning status, the presence of
+// lifecycle rules, the default encryption and the object lock status of the buckets.
+// The enrichment costs four requests per bucket, and the creation dates are carried over from the bucket discovery.
+func (man *Manager) SetEnrich(enrich bool) {
+	man.enrich = enrich
+}
+
+// SetSource sets the source from which buckets are discovered and their values are fetched.
+// If no source is set, the values are fetched from CloudWatch.
+func (man *Manager) SetSource(source Source) error {
+	if source == nil {
+		return errors.New("source must not be nil")
+	}
+	man.source = source
+	return nil
+}
+
+// Subscribe adds the observer that receives the progress of each region during List, ListRegion,
+// ListForecast and ListAnomalies: the start, the number of the buckets listed, each batch of queries
+// sent to the source, and the finish or the failure.
+func (man *Manager) Subscribe(observer Observer) error {
+	if observer == nil {
+		return errors.New("observer must not be nil")
+	}
+	man.observers = append(man.observers, observer)
+	return nil
+}
+
 // String returns a string representation of the manager.
 func (man *Manager) String() string {
 	s := struct {
 		MetricName  string   `json:"metricName"`
 		StorageType string   `json:"storageType"`
+		FilterID    string   `json:"filterId,omitempty"`
 		Prefix      *string  `json:"prefix"`
 		Regions     []string `json:"regions"`
+		ScanMode    string   `json:"scanMode"`
+		NoDataMode  string   `json:"noDataMode"`
+		Tags        []string `json:"tags,omitempty"`
+		Enrich      bool     `json:"enrich,omitempty"`
 	}{
 		MetricName:  man.metricName.String(),
 		StorageType: man.storageType.String(),
+		FilterID:    man.filterID,
 		Prefix:      man.prefix,
 		Regions:     man.regions,
+		ScanMode:    man.scanMode.String(),
+		NoDataMode:  man.noDataMode.String(),
+		Tags:        man.tagKeys,
+		Enrich:      man.enrich,
 	}
 	b, _ := json.Marshal(s)
 	return string(b)
 }
+
+// checkRegions checks that all regions are supported.
+func checkRegions(regions []string) error {
+	if len(regions) == 0 {
+		return errors.New("no regions specified")
+	}
+	for _, region := range regions {
+		if _, ok := allowedRegions[region]; !ok {
+			return fmt.Errorf("unsupported region: %s", region)
+		}
+	}
+	return nil
+}
+
+// checkPrefix checks that the prefix can be a part of bucket names.
+func checkPrefix(prefix string) error {
+	if !bucketPrefixPattern.MatchString(prefix) {
+		return fmt.Errorf("invalid prefix: %q", prefix)
+	}
+	return nil
+}
+
+// checkMetric checks that the metric supports the storage type.
+// The request metrics have no storage type, so any storage type is regarded as none for them.
+func checkMetric(metricName MetricName, storageType StorageType) error {
+	if metricName == MetricNameBucketSizeBytes && storageType == StorageTypeAllStorageTypes {
+		return errors.New("BucketSizeBytes metric does not support AllStorageTypes")
+	}
+	if metricName == MetricNameNumberOfObjects && storageType != StorageTypeAllStorageTypes {
+		return errors.New("NumberOfObjects metric only supports AllStorageTypes")
+	}
+	return nil
+}
+
+// checkFilterID checks the filter ID of the request metrics configuration. An empty ID means DefaultFilterID.
+func checkFilterID(id string) error {
+	if id != "" && !filterIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid filter id: %q", id)
+	}
+	return nil
+}
+
+// checkScan checks that the scan mode is supported for the metric.
+func checkScan(mode ScanMode, metricName MetricName) error {
+	switch mode {
+	case ScanModeNone, ScanModeFallback, ScanModeForce:
+	default:
+		return fmt.Errorf("unsupported scan mode: %d", mode)
+	}
+	if mode != ScanModeNone && metricName.isRequest() {
+		return fmt.Errorf("%s metric does not support scan mode: %s", metricName, mode)
+	}
+	return nil
+}
+
+// checkNoData checks that the no-data mode is supported.
+func checkNoData(mode NoDataMode) error {
+	switch mode {
+	case NoDataModeNone, NoDataModeShow, NoDataModeHide, NoDataModeHighlight:
+	default:
+		return fmt.Errorf("unsupported no-data mode: %d", mode)
+	}
+	return nil
+}
+
+// checkTags checks the keys of the bucket tags.
+func checkTags(keys []string) error {
+	for _, key := range keys {
+		if key == "" || len(key) > maxTagKeyLength {
+			return fmt.Errorf("invalid tag key: %q", key)
+		}
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package s3bytes
 
 import (
 	"context"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -14,6 +16,7 @@ func TestNewManager(t *testing.T) {
 	type args struct {
 		ctx    context.Context
 		client *Client
+		opts   []ManagerOption
 	}
 	tests := []struct {
 		name string
@@ -32,6 +35,8 @@ func TestNewManager(t *testing.T) {
 				storageType: StorageTypeNone,
 				prefix:      nil,
 				regions:     DefaultRegions,
+				concurrency: DefaultConcurrency,
+				batchSize:   MaxQueries,
 			},
 		},
 		{
@@ -46,20 +51,252 @@ func TestNewManager(t *testing.T) {
 				storageType: StorageTypeNone,
 				prefix:      nil,
 				regions:     DefaultRegions,
+				concurrency: DefaultConcurrency,
+				batchSize:   MaxQueries,
+			},
+		},
+		{
+			name: "with options",
+			args: args{
+				ctx:    context.Background(),
+				client: nil,
+				opts: []ManagerOption{
+					WithRegion("ap-northeast-1", "us-east-1"),
+					WithMetric(MetricNameBucketSizeBytes, StorageTypeStandardStorage),
+					WithFilterID("documents"),
+					WithPrefix("prod"),
+					WithScan(ScanModeFallback),
+					WithNoData(NoDataModeHide),
+					WithTags("team", "env", "team"),
+					WithEnrich(true),
+					WithConcurrency(4),
+					WithBatchSize(100),
+				},
+			},
+			want: &Manager{
+				client:      nil,
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+				filterID:    "documents",
+				prefix:      aws.String("prod"),
+				regions:     []string{"ap-northeast-1", "us-east-1"},
+				scanMode:    ScanModeFallback,
+				noDataMode:  NoDataModeHide,
+				tagKeys:     []string{"team", "env"},
+				enrich:      true,
+				concurrency: 4,
+				batchSize:   100,
+			},
+		},
+		{
+			name: "request metric without storage type",
+			args: args{
+				ctx:    context.Background(),
+				client: nil,
+				opts: []ManagerOption{
+					WithMetric(MetricNameGetRequests, StorageTypeStandardStorage),
+					WithRegion(),
+					WithPrefix(""),
+				},
+			},
+			want: &Manager{
+				client:      nil,
+				metricName:  MetricNameGetRequests,
+				storageType: StorageTypeNone,
+				prefix:      nil,
+				regions:     DefaultRegions,
+				concurrency: DefaultConcurrency,
+				batchSize:   MaxQueries,
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := NewManager(tt.args.client)
-			opts := cmpopts.IgnoreUnexported(*got)
-			if diff := cmp.Diff(got, tt.want, opts); diff != "" {
+			got := NewManager(tt.args.client, tt.args.opts...)
+			opts := []cmp.Option{
+				cmp.AllowUnexported(Manager{}),
+				cmpopts.IgnoreFields(Manager{}, "client", "sem"),
+			}
+			if diff := cmp.Diff(got, tt.want, opts...); diff != "" {
 				t.Errorf("NewManager() mismatch (-want +got):\n%s", diff)
 			}
 		})
 	}
 }
 
+func TestNewManager_numWorker(t *testing.T) {
+	original := NumWorker
+	defer func() { NumWorker = original }()
+	NumWorker = 4
+	if got := NewManager(nil).concurrency; got != 4 {
+		t.Errorf("NewManager() concurrency = %v, want %v", got, 4)
+	}
+	if got := NewManager(nil, WithConcurrency(8)).concurrency; got != 8 {
+		t.Errorf("NewManager() concurrency = %v, want %v", got, 8)
+	}
+}
+
+func TestManager_Validate(t *testing.T) {
+	type args struct {
+		opts []ManagerOption
+	}
+	tests := []struct {
+		name     string
+		args     args
+		wantErrs []string
+	}{
+		{
+			name: "valid",
+			args: args{
+				opts: []ManagerOption{
+					WithRegion("ap-northeast-1"),
+					WithMetric(MetricNameBucketSizeBytes, StorageTypeStandardStorage),
+					WithPrefix("prod"),
+					WithFilter(`bytes > 0`),
+					WithTags("team"),
+					WithObserver(func(Event) {}),
+					WithConcurrency(1),
+					WithBatchSize(MaxQueries),
+				},
+			},
+			wantErrs: nil,
+		},
+		{
+			name: "scan before request metric",
+			args: args{
+				opts: []ManagerOption{
+					WithScan(ScanModeForce),
+					WithMetric(MetricNameAllRequests, StorageTypeNone),
+				},
+			},
+			wantErrs: []string{"AllRequests metric does not support scan mode: force"},
+		},
+		{
+			name: "no metric",
+			args: args{
+				opts: nil,
+			},
+			wantErrs: []string{"metric name must be set"},
+		},
+		{
+			name: "all errors joined",
+			args: args{
+				opts: []ManagerOption{
+					WithRegion("invalid"),
+					WithMetric(MetricNameNumberOfObjects, StorageTypeStandardStorage),
+					WithFilterID("invalid id"),
+					WithPrefix("INVALID"),
+					WithFilter(`bytes >`),
+					WithNoData(NoDataMode(99)),
+					WithTags(""),
+					WithObserver(nil),
+					WithConcurrency(0),
+					WithBatchSize(MaxQueries + 1),
+				},
+			},
+			wantErrs: []string{
+				"unsupported region: invalid",
+				"NumberOfObjects metric only supports AllStorageTypes",
+				`invalid filter id: "invalid id"`,
+				"unsupported no-data mode: 99",
+				`invalid tag key: ""`,
+				`invalid prefix: "INVALID"`,
+				"failed to parse filter",
+				"observer must not be nil",
+				"invalid concurrency: 0",
+				"invalid batch size: 501",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			man := NewManager(nil, tt.args.opts...)
+			err := man.Validate()
+			if (err != nil) != (len(tt.wantErrs) > 0) {
+				t.Errorf("Manager.Validate() error = %v, wantErrs %v", err, tt.wantErrs)
+				return
+			}
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Manager.Validate() error = %v, want %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestManager_workers(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+		want        int
+	}{
+		{
+			name:        "set",
+			concurrency: 4,
+			want:        4,
+		},
+		{
+			name:        "zero",
+			concurrency: 0,
+			want:        DefaultConcurrency,
+		},
+		{
+			name:        "negative",
+			concurrency: -1,
+			want:        DefaultConcurrency,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			man := NewManager(nil, WithConcurrency(tt.concurrency))
+			if got := man.workers(); got != tt.want {
+				t.Errorf("Manager.workers() = %v, want %v", got, tt.want)
+			}
+			if !man.sem.TryAcquire(1) {
+				t.Errorf("Manager.sem cannot be acquired with concurrency %d", tt.concurrency)
+			}
+		})
+	}
+}
+
+func TestManager_batch(t *testing.T) {
+	tests := []struct {
+		name      string
+		batchSize int
+		want      int
+	}{
+		{
+			name:      "set",
+			batchSize: 100,
+			want:      100,
+		},
+		{
+			name:      "zero",
+			batchSize: 0,
+			want:      MaxQueries,
+		},
+		{
+			name:      "negative",
+			batchSize: -1,
+			want:      MaxQueries,
+		},
+		{
+			name:      "too large",
+			batchSize: MaxQueries + 1,
+			want:      MaxQueries,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			man := NewManager(nil, WithBatchSize(tt.batchSize))
+			if got := man.batch(); got != tt.want {
+				t.Errorf("Manager.batch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestManager_SetRegions(t *testing.T) {
 	type fields struct {
 		client      *Client
@@ -117,6 +354,29 @@ func TestManager_SetRegions(t *testing.T) {
 	}
 }
 
+func TestManager_Regions(t *testing.T) {
+	man := &Manager{
+		regions: []string{"ap-northeast-1", "us-east-1"},
+	}
+	got := man.Regions()
+	if want := []string{"ap-northeast-1", "us-east-1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Manager.Regions() = %v, want %v", got, want)
+	}
+	got[0] = "eu-west-1"
+	if man.regions[0] != "ap-northeast-1" {
+		t.Errorf("Manager.Regions() shares the regions of the manager: %v", man.regions)
+	}
+}
+
+func TestManager_MetricName(t *testing.T) {
+	man := &Manager{
+		metricName: MetricNameFirstByteLatency,
+	}
+	if got := man.MetricName(); got != MetricNameFirstByteLatency {
+		t.Errorf("Manager.MetricName() = %v, want %v", got, MetricNameFirstByteLatency)
+	}
+}
+
 func TestManager_SetPrefix(t *testing.T) {
 	type fields struct {
 		client      *Client
@@ -368,6 +628,7 @@ func TestManager_SetMetric(t *testing.T) {
 		storageType StorageType
 		prefix      *string
 		regions     []string
+		scanMode    ScanMode
 		sem         *semaphore.Weighted
 	}
 	type args struct {
@@ -404,6 +665,25 @@ func TestManager_SetMetric(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "request metric",
+			args: args{
+				metricName:  MetricNameAllRequests,
+				storageType: StorageTypeStandardStorage,
+			},
+			wantErr: false,
+		},
+		{
+			name: "request metric with scan",
+			fields: fields{
+				scanMode: ScanModeFallback,
+			},
+			args: args{
+				metricName:  MetricNameAllRequests,
+				storageType: StorageTypeStandardStorage,
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -413,10 +693,281 @@ func TestManager_SetMetric(t *testing.T) {
 				storageType: tt.fields.storageType,
 				prefix:      tt.fields.prefix,
 				regions:     tt.fields.regions,
+				scanMode:    tt.fields.scanMode,
 				sem:         tt.fields.sem,
 			}
 			if err := man.SetMetric(tt.args.metricName, tt.args.storageType); (err != nil) != tt.wantErr {
 				t.Errorf("Manager.SetMetric() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && tt.args.metricName.isRequest() && man.storageType != StorageTypeNone {
+				t.Errorf("Manager.SetMetric() storageType = %v, want %v", man.storageType, StorageTypeNone)
+			}
+		})
+	}
+}
+
+func TestManager_SetFilterID(t *testing.T) {
+	type args struct {
+		id string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			args: args{
+				id: "",
+			},
+			want:    "",
+			wantErr: false,
+		},
+		{
+			name: "valid",
+			args: args{
+				id: "documents-2024_v1.0",
+			},
+			want:    "documents-2024_v1.0",
+			wantErr: false,
+		},
+		{
+			name: "invalid character",
+			args: args{
+				id: "documents/2024",
+			},
+			wantErr: true,
+		},
+		{
+			name: "too long",
+			args: args{
+				id: strings.Repeat("a", 65),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			man := &Manager{}
+			err := man.SetFilterID(tt.args.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Manager.SetFilterID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && man.filterID != tt.want {
+				t.Errorf("Manager.SetFilterID() = %v, want %v", man.filterID, tt.want)
+			}
+		})
+	}
+}
+
+func TestManager_SetScan(t *testing.T) {
+	type fields struct {
+		metricName MetricName
+	}
+	type args struct {
+		mode ScanMode
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "fallback",
+			fields: fields{
+				metricName: MetricNameBucketSizeBytes,
+			},
+			args: args{
+				mode: ScanModeFallback,
+			},
+			wantErr: false,
+		},
+		{
+			name: "unsupported",
+			fields: fields{
+				metricName: MetricNameBucketSizeBytes,
+			},
+			args: args{
+				mode: ScanMode(99),
+			},
+			wantErr: true,
+		},
+		{
+			name: "request metric without scan",
+			fields: fields{
+				metricName: MetricNameGetRequests,
+			},
+			args: args{
+				mode: ScanModeNone,
+			},
+			wantErr: false,
+		},
+		{
+			name: "request metric with scan",
+			fields: fields{
+				metricName: MetricNameGetRequests,
+			},
+			args: args{
+				mode: ScanModeForce,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			man := &Manager{
+				metricName: tt.fields.metricName,
+			}
+			if err := man.SetScan(tt.args.mode); (err != nil) != tt.wantErr {
+				t.Errorf("Manager.SetScan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestManager_SetTags(t *testing.T) {
+	type args struct {
+		keys []string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			args: args{
+				keys: nil,
+			},
+			want:    []string{},
+			wantErr: false,
+		},
+		{
+			name: "valid",
+			args: args{
+				keys: []string{"team", "env"},
+			},
+			want:    []string{"team", "env"},
+			wantErr: false,
+		},
+		{
+			name: "duplicated",
+			args: args{
+				keys: []string{"team", "env", "team"},
+			},
+			want:    []string{"team", "env"},
+			wantErr: false,
+		},
+		{
+			name: "empty key",
+			args: args{
+				keys: []string{"team", ""},
+			},
+			wantErr: true,
+		},
+		{
+			name: "too long key",
+			args: args{
+				keys: []string{strings.Repeat("a", 129)},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			man := &Manager{}
+			err := man.SetTags(tt.args.keys)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Manager.SetTags() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(man.tagKeys, tt.want) {
+				t.Errorf("Manager.SetTags() = %v, want %v", man.tagKeys, tt.want)
+			}
+		})
+	}
+}
+
+func TestManager_SetSource(t *testing.T) {
+	type args struct {
+		source Source
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    Source
+		wantErr bool
+	}{
+		{
+			name: "scan",
+			args: args{
+				source: NewScanSource(nil),
+			},
+			want:    NewScanSource(nil),
+			wantErr: false,
+		},
+		{
+			name: "nil",
+			args: args{
+				source: nil,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			man := &Manager{}
+			if err := man.SetSource(tt.args.source); (err != nil) != tt.wantErr {
+				t.Errorf("Manager.SetSource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(man.source, tt.want) {
+				t.Errorf("Manager.SetSource() source = %v, want %v", man.source, tt.want)
+			}
+		})
+	}
+}
+
+func TestManager_Subscribe(t *testing.T) {
+	type args struct {
+		observer Observer
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "observer",
+			args: args{
+				observer: func(Event) {},
+			},
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name: "nil",
+			args: args{
+				observer: nil,
+			},
+			want:    0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			man := &Manager{}
+			if err := man.Subscribe(tt.args.observer); (err != nil) != tt.wantErr {
+				t.Errorf("Manager.Subscribe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(man.observers) != tt.want {
+				t.Errorf("Manager.Subscribe() observers = %v, want %v", len(man.observers), tt.want)
 			}
 		})
 	}
@@ -429,6 +980,8 @@ func TestManager_String(t *testing.T) {
 		storageType StorageType
 		prefix      *string
 		regions     []string
+		tagKeys     []string
+		enrich      bool
 		sem         *semaphore.Weighted
 	}
 	tests := []struct {
@@ -444,7 +997,7 @@ func TestManager_String(t *testing.T) {
 				storageType: StorageTypeStandardStorage,
 				prefix:      nil,
 			},
-			want: `{"metricName":"BucketSizeBytes","storageType":"StandardStorage","prefix":null,"regions":null}`,
+			want: `{"metricName":"BucketSizeBytes","storageType":"StandardStorage","prefix":null,"regions":null,"scanMode":"none","noDataMode":"none"}`,
 		},
 		{
 			name: "prefixed",
@@ -454,12 +1007,32 @@ func TestManager_String(t *testing.T) {
 				storageType: StorageTypeStandardStorage,
 				prefix:      aws.String("test"),
 			},
-			want: `{"metricName":"BucketSizeBytes","storageType":"StandardStorage","prefix":"test","regions":null}`,
+			want: `{"metricName":"BucketSizeBytes","storageType":"StandardStorage","prefix":"test","regions":null,"scanMode":"none","noDataMode":"none"}`,
+		},
+		{
+			name: "tagged",
+			fields: fields{
+				client:      newMockClient(&mockS3{}, &mockCloudWatch{}),
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+				tagKeys:     []string{"team", "env"},
+			},
+			want: `{"metricName":"BucketSizeBytes","storageType":"StandardStorage","prefix":null,"regions":null,"scanMode":"none","noDataMode":"none","tags":["team","env"]}`,
+		},
+		{
+			name: "enriched",
+			fields: fields{
+				client:      newMockClient(&mockS3{}, &mockCloudWatch{}),
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+				enrich:      true,
+			},
+			want: `{"metricName":"BucketSizeBytes","storageType":"StandardStorage","prefix":null,"regions":null,"scanMode":"none","noDataMode":"none","enrich":true}`,
 		},
 		{
 			name:   "empty",
 			fields: fields{},
-			want:   `{"metricName":"none","storageType":"none","prefix":null,"regions":null}`,
+			want:   `{"metricName":"none","storageType":"none","prefix":null,"regions":null,"scanMode":"none","noDataMode":"none"}`,
 		},
 	}
 	for _, tt := range tests {
@@ -470,6 +1043,8 @@ func TestManager_String(t *testing.T) {
 				storageType: tt.fields.storageType,
 				prefix:      tt.fields.prefix,
 				regions:     tt.fields.regions,
+				tagKeys:     tt.fields.tagKeys,
+				enrich:      tt.fields.enrich,
 				sem:         tt.fields.sem,
 			}
 			if diff := cmp.Diff(man.String(), tt.want); diff != "" {
@@ -0,0 +1,73 @@
+package s3bytes
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// metadataHeader is the columns appended to the header when the metrics are enriched.
+var metadataHeader = []string{
+	"CreationDate",
+	"Versioning",
+	"Lifecycle",
+	"Encryption",
+	"ObjectLock",
+}
+
+// withMetadataHeader returns the header followed by the columns of the bucket metadata if enriched.
+func (man *Manager) withMetadataHeader(header []string) []string {
+	if !man.enrich {
+		return header
+	}
+	return append(slices.Clone(header), metadataHeader...)
+}
+
+// enrichMetadata sets the bucket metadata to the metrics of the buckets in the region.
+// The creation dates are taken from the dates returned by ListBuckets when the buckets were discovered,
+// and the other configurations are fetched once per bucket, concurrently up to the concurrency of the
+// manager at a time. The configurations of the directory buckets are left empty, since they are not
+// supported by the bucket APIs.
+func (man *Manager) enrichMetadata(ctx context.Context, metrics []*Metric, region string, created map[string]time.Time) error {
+	if !man.enrich || len(metrics) == 0 {
+		return nil
+	}
+	var (
+		buckets = make([]string, 0, len(metrics))
+		index   = make(map[string]int, len(metrics))
+	)
+	for _, metric := range metrics {
+		if _, ok := index[metric.BucketName]; !ok {
+			index[metric.BucketName] = len(buckets)
+			buckets = append(buckets, metric.BucketName)
+		}
+	}
+	metadata := make([]BucketMetadata, len(buckets))
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(man.workers())
+	for i, bucket := range buckets {
+		if isDirectoryBucket(bucket) {
+			continue
+		}
+		g.Go(func() error {
+			m, err := getBucketMetadata(ctx, man.client, bucket, region)
+			if err != nil {
+				return err
+			}
+			metadata[i] = m
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	for bucket, i := range index {
+		metadata[i].CreationDate = created[bucket]
+	}
+	for _, metric := range metrics {
+		metric.BucketMetadata = metadata[index[metric.BucketName]]
+	}
+	return nil
+}
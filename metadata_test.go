@@ -0,0 +1,396 @@
+package s3bytes
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// newMockMetadataS3 returns a mock in which bucket0 has all the features enabled
+// and the other buckets have none of them configured.
+func newMockMetadataS3(creationDate time.Time) *mockS3 {
+	return &mockS3{
+		ListDirectoryBucketsFunc: listNoDirectoryBuckets,
+		ListBucketsFunc: func(_ context.Context, _ *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+			return &s3.ListBucketsOutput{
+				Buckets: []s3types.Bucket{
+					{Name: aws.String("bucket0"), CreationDate: aws.Time(creationDate)},
+					{Name: aws.String("bucket1"), CreationDate: aws.Time(creationDate.AddDate(1, 0, 0))},
+				},
+			}, nil
+		},
+		GetBucketVersioningFunc: func(_ context.Context, params *s3.GetBucketVersioningInput, _ ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
+			if aws.ToString(params.Bucket) == "bucket0" {
+				return &s3.GetBucketVersioningOutput{Status: s3types.BucketVersioningStatusEnabled}, nil
+			}
+			return &s3.GetBucketVersioningOutput{}, nil
+		},
+		GetBucketLifecycleConfigurationFunc: func(_ context.Context, params *s3.GetBucketLifecycleConfigurationInput, _ ...func(*s3.Options)) (*s3.GetBucketLifecycleConfigurationOutput, error) {
+			if aws.ToString(params.Bucket) == "bucket0" {
+				return &s3.GetBucketLifecycleConfigurationOutput{
+					Rules: []s3types.LifecycleRule{
+						{Status: s3types.ExpirationStatusDisabled},
+						{Status: s3types.ExpirationStatusEnabled},
+					},
+				}, nil
+			}
+			return nil, &smithy.GenericAPIError{Code: "NoSuchLifecycleConfiguration"}
+		},
+		GetBucketEncryptionFunc: func(_ context.Context, params *s3.GetBucketEncryptionInput, _ ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error) {
+			if aws.ToString(params.Bucket) == "bucket0" {
+				return &s3.GetBucketEncryptionOutput{
+					ServerSideEncryptionConfiguration: &s3types.ServerSideEncryptionConfiguration{
+						Rules: []s3types.ServerSideEncryptionRule{
+							{
+								ApplyServerSideEncryptionByDefault: &s3types.ServerSideEncryptionByDefault{
+									SSEAlgorithm: s3types.ServerSideEncryptionAwsKms,
+								},
+							},
+						},
+					},
+				}, nil
+			}
+			return nil, &smithy.GenericAPIError{Code: "ServerSideEncryptionConfigurationNotFoundError"}
+		},
+		GetObjectLockConfigurationFunc: func(_ context.Context, params *s3.GetObjectLockConfigurationInput, _ ...func(*s3.Options)) (*s3.GetObjectLockConfigurationOutput, error) {
+			if aws.ToString(params.Bucket) == "bucket0" {
+				return &s3.GetObjectLockConfigurationOutput{
+					ObjectLockConfiguration: &s3types.ObjectLockConfiguration{
+						ObjectLockEnabled: s3types.ObjectLockEnabledEnabled,
+					},
+				}, nil
+			}
+			return nil, &smithy.GenericAPIError{Code: "ObjectLockConfigurationNotFoundError"}
+		},
+	}
+}
+
+func TestManager_withMetadataHeader(t *testing.T) {
+	type fields struct {
+		enrich bool
+	}
+	type args struct {
+		header []string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   []string
+	}{
+		{
+			name: "not enriched",
+			args: args{
+				header: []string{"BucketName", "Value"},
+			},
+			want: []string{"BucketName", "Value"},
+		},
+		{
+			name: "enriched",
+			fields: fields{
+				enrich: true,
+			},
+			args: args{
+				header: []string{"BucketName", "Value"},
+			},
+			want: []string{"BucketName", "Value", "CreationDate", "Versioning", "Lifecycle", "Encryption", "ObjectLock"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			man := &Manager{
+				enrich: tt.fields.enrich,
+			}
+			if got := man.withMetadataHeader(tt.args.header); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Manager.withMetadataHeader() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManager_enrichMetadata(t *testing.T) {
+	creationDate := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	type fields struct {
+		client *Client
+		enrich bool
+	}
+	type args struct {
+		ctx     context.Context
+		metrics []*Metric
+		region  string
+		created map[string]time.Time
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    []*Metric
+		wantErr bool
+	}{
+		{
+			name: "enriched",
+			fields: fields{
+				client: newMockClient(newMockMetadataS3(creationDate), nil),
+				enrich: true,
+			},
+			args: args{
+				ctx: context.Background(),
+				metrics: []*Metric{
+					{BucketName: "bucket0", Prefix: "a/"},
+					{BucketName: "bucket0", Prefix: "b/"},
+					{BucketName: "bucket1"},
+				},
+				region: "ap-northeast-1",
+				created: map[string]time.Time{
+					"bucket0": creationDate,
+					"bucket1": creationDate.AddDate(1, 0, 0),
+				},
+			},
+			want: []*Metric{
+				{
+					BucketName: "bucket0",
+					Prefix:     "a/",
+					BucketMetadata: BucketMetadata{
+						CreationDate: creationDate,
+						Versioning:   "Enabled",
+						Lifecycle:    "Enabled",
+						Encryption:   "aws:kms",
+						ObjectLock:   "Enabled",
+					},
+				},
+				{
+					BucketName: "bucket0",
+					Prefix:     "b/",
+					BucketMetadata: BucketMetadata{
+						CreationDate: creationDate,
+						Versioning:   "Enabled",
+						Lifecycle:    "Enabled",
+						Encryption:   "aws:kms",
+						ObjectLock:   "Enabled",
+					},
+				},
+				{
+					BucketName: "bucket1",
+					BucketMetadata: BucketMetadata{
+						CreationDate: creationDate.AddDate(1, 0, 0),
+						Versioning:   "Disabled",
+						Lifecycle:    "Disabled",
+						Encryption:   "None",
+						ObjectLock:   "Disabled",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "directory bucket",
+			fields: fields{
+				client: newMockClient(&mockS3{}, nil),
+				enrich: true,
+			},
+			args: args{
+				ctx:     context.Background(),
+				metrics: []*Metric{{BucketName: "bucket0--apne1-az4--x-s3"}},
+				region:  "ap-northeast-1",
+				created: map[string]time.Time{"bucket0--apne1-az4--x-s3": creationDate},
+			},
+			want: []*Metric{
+				{
+					BucketName: "bucket0--apne1-az4--x-s3",
+					BucketMetadata: BucketMetadata{
+						CreationDate: creationDate,
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "not enriched",
+			fields: fields{
+				client: newMockClient(&mockS3{}, nil),
+			},
+			args: args{
+				ctx:     context.Background(),
+				metrics: []*Metric{{BucketName: "bucket0"}},
+				region:  "ap-northeast-1",
+			},
+			want:    []*Metric{{BucketName: "bucket0"}},
+			wantErr: false,
+		},
+		{
+			name: "buckets not listed again",
+			fields: fields{
+				client: newMockClient(func() *mockS3 {
+					m := newMockMetadataS3(creationDate)
+					m.ListBucketsFunc = func(_ context.Context, _ *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+						return nil, errors.New("failed to list buckets")
+					}
+					return m
+				}(), nil),
+				enrich: true,
+			},
+			args: args{
+				ctx:     context.Background(),
+				metrics: []*Metric{{BucketName: "bucket0"}},
+				region:  "ap-northeast-1",
+				created: map[string]time.Time{"bucket0": creationDate},
+			},
+			want: []*Metric{
+				{
+					BucketName: "bucket0",
+					BucketMetadata: BucketMetadata{
+						CreationDate: creationDate,
+						Versioning:   "Enabled",
+						Lifecycle:    "Enabled",
+						Encryption:   "aws:kms",
+						ObjectLock:   "Enabled",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "access denied",
+			fields: fields{
+				client: newMockClient(func() *mockS3 {
+					m := newMockMetadataS3(creationDate)
+					m.GetBucketEncryptionFunc = func(_ context.Context, _ *s3.GetBucketEncryptionInput, _ ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error) {
+						return nil, &smithy.GenericAPIError{Code: "AccessDenied"}
+					}
+					return m
+				}(), nil),
+				enrich: true,
+			},
+			args: args{
+				ctx:     context.Background(),
+				metrics: []*Metric{{BucketName: "bucket0"}},
+				region:  "ap-northeast-1",
+				created: map[string]time.Time{"bucket0": creationDate},
+			},
+			want: []*Metric{
+				{
+					BucketName: "bucket0",
+					BucketMetadata: BucketMetadata{
+						CreationDate: creationDate,
+						Versioning:   "Enabled",
+						Lifecycle:    "Enabled",
+						Encryption:   "Unknown",
+						ObjectLock:   "Enabled",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "canceled",
+			fields: fields{
+				client: newMockClient(&mockS3{
+					GetBucketVersioningFunc: func(ctx context.Context, _ *s3.GetBucketVersioningInput, _ ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
+						return nil, ctx.Err()
+					},
+				}, nil),
+				enrich: true,
+			},
+			args: args{
+				ctx:     func() context.Context { ctx, cancel := context.WithCancel(context.Background()); cancel(); return ctx }(),
+				metrics: []*Metric{{BucketName: "bucket0"}},
+				region:  "ap-northeast-1",
+			},
+			want:    []*Metric{{BucketName: "bucket0"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			man := &Manager{
+				client: tt.fields.client,
+				enrich: tt.fields.enrich,
+			}
+			err := man.enrichMetadata(tt.args.ctx, tt.args.metrics, tt.args.region, tt.args.created)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Manager.enrichMetadata() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(tt.args.metrics, tt.want) {
+				t.Errorf("Manager.enrichMetadata() = %v, want %v", tt.args.metrics, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetric_metadata(t *testing.T) {
+	metric := &Metric{
+		BucketName: "bucket0",
+		BucketMetadata: BucketMetadata{
+			CreationDate: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			Versioning:   "Enabled",
+			Lifecycle:    "Disabled",
+			Encryption:   "AES256",
+			ObjectLock:   "Disabled",
+		},
+	}
+	tests := []struct {
+		name   string
+		key    string
+		column string
+		want   any
+		want1  any
+	}{
+		{
+			name:   "creation date",
+			key:    "creationDate",
+			column: "CreationDate",
+			want:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			want1:  "2024-01-02T03:04:05Z",
+		},
+		{
+			name:   "versioning",
+			key:    "versioning",
+			column: "Versioning",
+			want:   "Enabled",
+			want1:  "Enabled",
+		},
+		{
+			name:   "lifecycle",
+			key:    "lifecycle",
+			column: "Lifecycle",
+			want:   "Disabled",
+			want1:  "Disabled",
+		},
+		{
+			name:   "encryption",
+			key:    "encryption",
+			column: "Encryption",
+			want:   "AES256",
+			want1:  "AES256",
+		},
+		{
+			name:   "object lock",
+			key:    "objectLock",
+			column: "ObjectLock",
+			want:   "Disabled",
+			want1:  "Disabled",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := metric.GetField(tt.key)
+			if err != nil {
+				t.Fatalf("Metric.GetField() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Metric.GetField() = %v, want %v", got, tt.want)
+			}
+			if got1 := metric.column(tt.column); got1 != tt.want1 {
+				t.Errorf("Metric.column() = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package s3bytes
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 )
 
 var header = []string{
@@ -11,6 +13,23 @@ var header = []string{
 	"MetricName",
 	"StorageType",
 	"Value",
+	"Status",
+	"Timestamp",
+	"Source",
+	"BucketType",
+}
+
+var prefixHeader = []string{
+	"BucketName",
+	"Region",
+	"Prefix",
+	"MetricName",
+	"StorageType",
+	"Value",
+	"Status",
+	"Timestamp",
+	"Source",
+	"BucketType",
 }
 
 var _ filterTarget = (*Metric)(nil)
@@ -24,40 +43,222 @@ type MetricData struct {
 }
 
 // Metric represents the metrics data for a single bucket.
+// Status tells whether the value is backed by datapoints,
+// and Timestamp is the time of the datapoint the value was taken from.
+// Prefix is set only when the value is aggregated for a prefix in the bucket.
+// Compliance and Rule are set only when the metrics are evaluated against a policy.
+// Tags holds the selected tags of the bucket that are present, and Group is set only
+// when the value is aggregated for a group by GroupMetrics.
+// BucketType is either a general purpose bucket or a directory bucket.
+// BucketMetadata is set only when the metrics are enriched, Forecast is set only by ListForecast,
+// Anomaly is set only by ListAnomalies, and Delta is set only by CompareMetrics against previous data.
 type Metric struct {
 	BucketName  string
 	Region      string
+	Prefix      string `json:",omitempty"`
 	MetricName  MetricName
 	StorageType StorageType
 	Value       float64
+	Status      DataStatus
+	Timestamp   time.Time `json:",omitzero"`
+	Source      SourceType
+	BucketType  BucketType        `json:",omitzero"`
+	Compliance  ComplianceStatus  `json:",omitzero"`
+	Rule        string            `json:",omitempty"`
+	Tags        map[string]string `json:",omitempty"`
+	Group       string            `json:",omitempty"`
+	Delta       *float64          `json:",omitempty"`
+	BucketMetadata
+	Forecast
+	Anomaly
+}
+
+// BucketMetadata represents the configurations of a bucket added by the enrichment.
+// Versioning is one of "Enabled", "Suspended" and "Disabled", Lifecycle and ObjectLock
+// are either "Enabled" or "Disabled", and Encryption is the algorithm of the default encryption
+// such as "AES256" and "aws:kms", or "None".
+type BucketMetadata struct {
+	CreationDate time.Time `json:",omitzero"`
+	Versioning   string    `json:",omitempty"`
+	Lifecycle    string    `json:",omitempty"`
+	Encryption   string    `json:",omitempty"`
+	ObjectLock   string    `json:",omitempty"`
 }
 
 // GetField returns the value of the specified field in the Metric struct.
+// The tags are referred to as "tag_<key>" or the column name "Tag:<key>",
+// and a missing tag is an empty string.
 func (t *Metric) GetField(key string) (any, error) {
+	if k, ok := tagKey(key); ok {
+		return t.Tags[k], nil
+	}
 	switch key {
+	case "bucket", "BucketName":
+		return t.BucketName, nil
+	case "region", "Region":
+		return t.Region, nil
+	case "storageType", "StorageType":
+		return t.StorageType.String(), nil
 	case "bytes", "Bytes", "value", "Value":
 		return t.Value, nil
+	case "prefix", "Prefix":
+		return t.Prefix, nil
+	case "status", "Status":
+		return t.Status.String(), nil
+	case "timestamp", "Timestamp":
+		return t.Timestamp, nil
+	case "source", "Source":
+		return t.Source.String(), nil
+	case "bucketType", "BucketType":
+		return t.BucketType.String(), nil
+	case "group", "Group":
+		return t.Group, nil
+	case "creationDate", "CreationDate":
+		return t.CreationDate, nil
+	case "versioning", "Versioning":
+		return t.Versioning, nil
+	case "lifecycle", "Lifecycle":
+		return t.Lifecycle, nil
+	case "encryption", "Encryption":
+		return t.Encryption, nil
+	case "objectLock", "ObjectLock":
+		return t.ObjectLock, nil
+	case "growthPerDay", "GrowthPerDay":
+		return t.GrowthPerDay, nil
+	case "forecast30d", "Forecast30d":
+		return t.Forecast30d, nil
+	case "forecast90d", "Forecast90d":
+		return t.Forecast90d, nil
+	case "forecast365d", "Forecast365d":
+		return t.Forecast365d, nil
+	case "quotaDate", "QuotaDate":
+		return t.QuotaDate, nil
+	case "anomalyDate", "AnomalyDate":
+		return t.AnomalyDate, nil
+	case "change", "Change":
+		return t.Change, nil
+	case "changePercent", "ChangePercent":
+		return t.ChangePercent, nil
+	case "zScore", "ZScore":
+		return t.ZScore, nil
 	default:
 		return 0, fmt.Errorf("field not found: %q", key)
 	}
 }
 
-func (t *Metric) toInput() []any {
-	return []any{
-		t.BucketName,
-		t.Region,
-		t.MetricName,
-		t.StorageType,
-		t.Value,
+func (t *Metric) toInput(header []string) []any {
+	row := make([]any, len(header))
+	for i, key := range header {
+		row[i] = t.column(key)
+	}
+	return row
+}
+
+func (t *Metric) toTSV(header []string) []string {
+	row := make([]string, len(header))
+	for i, key := range header {
+		switch v := t.column(key).(type) {
+		case string:
+			row[i] = v
+		case float64:
+			row[i] = strconv.FormatFloat(v, 'f', t.precision(key), 64)
+		case fmt.Stringer:
+			row[i] = v.String()
+		default:
+			row[i] = fmt.Sprint(v)
+		}
+	}
+	return row
+}
+
+// precision returns the number of decimals of the float column with the specified header name.
+// The ratios and the latencies averaged in milliseconds keep 2 decimals, and the others are integral.
+func (t *Metric) precision(key string) int {
+	switch key {
+	case "ChangePercent", "ZScore":
+		return 2
+	case "Value", "Delta":
+		if t.MetricName.isLatency() {
+			return 2
+		}
+	}
+	return 0
+}
+
+// column returns the value of the column with the specified header name.
+func (t *Metric) column(key string) any {
+	switch key {
+	case "BucketName":
+		return t.BucketName
+	case "Region":
+		return t.Region
+	case "Prefix":
+		return t.Prefix
+	case "MetricName":
+		return t.MetricName
+	case "StorageType":
+		return t.StorageType
+	case "Value":
+		return t.Value
+	case "Status":
+		return t.Status
+	case "Timestamp":
+		return formatTime(t.Timestamp)
+	case "Source":
+		return t.Source
+	case "BucketType":
+		return t.BucketType
+	case "Compliance":
+		return t.Compliance
+	case "Rule":
+		return t.Rule
+	case "Group":
+		return t.Group
+	case "Delta":
+		if t.Delta == nil {
+			return ""
+		}
+		return *t.Delta
+	case "CreationDate":
+		return formatTime(t.CreationDate)
+	case "Versioning":
+		return t.Versioning
+	case "Lifecycle":
+		return t.Lifecycle
+	case "Encryption":
+		return t.Encryption
+	case "ObjectLock":
+		return t.ObjectLock
+	case "GrowthPerDay":
+		return t.GrowthPerDay
+	case "Forecast30d":
+		return t.Forecast30d
+	case "Forecast90d":
+		return t.Forecast90d
+	case "Forecast365d":
+		return t.Forecast365d
+	case "QuotaDate":
+		return formatTime(t.QuotaDate)
+	case "AnomalyDate":
+		return formatTime(t.AnomalyDate)
+	case "Change":
+		return t.Change
+	case "ChangePercent":
+		return t.ChangePercent
+	case "ZScore":
+		return t.ZScore
+	default:
+		if k, ok := strings.CutPrefix(key, tagColumnPrefix); ok {
+			return t.Tags[k]
+		}
+		return ""
 	}
 }
 
-func (t *Metric) toTSV() []string {
-	return []string{
-		t.BucketName,
-		t.Region,
-		t.MetricName.String(),
-		t.StorageType.String(),
-		strconv.FormatFloat(t.Value, 'f', 0, 64),
+// formatTime formats the time in RFC3339 in UTC, or returns an empty string for the zero time.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
 	}
+	return t.UTC().Format(time.RFC3339)
 }
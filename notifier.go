@@ -0,0 +1,208 @@
+package s3bytes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultNotificationTemplate is the payload accepted by both Slack and Microsoft Teams incoming webhooks.
+const defaultNotificationTemplate = `{"text": {{ json .Text }}}`
+
+const (
+	// defaultNotifierRetries is the number of retries after the first attempt by default.
+	defaultNotifierRetries = 3
+
+	// defaultNotifierBackoff is the wait before the first retry by default, which doubles on each retry.
+	defaultNotifierBackoff = time.Second
+)
+
+// Notification represents a message about the offending rows, such as the violations of the check
+// or the rows exceeding the limits of a policy. The notification is passed to the payload template,
+// in which the fields and the Text and Rows methods are available along with the json function
+// that encodes a value as JSON.
+type Notification struct {
+	Title    string
+	Messages []string
+	Data     *MetricData
+}
+
+// Text returns the plain text of the notification: the title, the messages and the offending rows.
+func (n *Notification) Text() string {
+	var b strings.Builder
+	b.WriteString(n.Title)
+	for _, message := range n.Messages {
+		b.WriteString("\n- ")
+		b.WriteString(message)
+	}
+	if rows := n.Rows(); len(rows) > 0 {
+		b.WriteString("\n```\n")
+		b.WriteString(strings.Join(n.Data.Header, "\t"))
+		for _, row := range rows {
+			b.WriteString("\n")
+			b.WriteString(strings.Join(row, "\t"))
+		}
+		b.WriteString("\n```")
+	}
+	return b.String()
+}
+
+// Rows returns the offending rows as strings in the order of the header.
+func (n *Notification) Rows() [][]string {
+	if n.Data == nil {
+		return nil
+	}
+	rows := make([][]string, len(n.Data.Metrics))
+	for i, metric := range n.Data.Metrics {
+		rows[i] = metric.toTSV(n.Data.Header)
+	}
+	return rows
+}
+
+// Notifier posts notifications to a generic webhook as JSON payloads rendered from a template.
+// Requests failing with network errors, 429 or 5xx are retried with exponential backoff.
+type Notifier struct {
+	url      string
+	client   *http.Client
+	template string
+	retries  int
+	backoff  time.Duration
+	dryRun   io.Writer
+
+	tmpl *template.Template
+}
+
+// NotifierOption represents an option for the notifier.
+type NotifierOption func(*Notifier)
+
+// WithTemplate sets the text/template of the payload. The result must be valid JSON.
+func WithTemplate(text string) NotifierOption {
+	return func(n *Notifier) {
+		n.template = text
+	}
+}
+
+// WithRetry sets the number of retries after the first attempt and the wait before the first retry.
+func WithRetry(retries int, backoff time.Duration) NotifierOption {
+	return func(n *Notifier) {
+		n.retries = retries
+		n.backoff = backoff
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to post the payloads.
+func WithHTTPClient(client *http.Client) NotifierOption {
+	return func(n *Notifier) {
+		n.client = client
+	}
+}
+
+// WithDryRun makes the notifier write the payloads to the writer instead of posting them.
+func WithDryRun(w io.Writer) NotifierOption {
+	return func(n *Notifier) {
+		n.dryRun = w
+	}
+}
+
+// NewNotifier creates a new notifier for the webhook URL. The URL may be empty in the dry-run mode.
+func NewNotifier(url string, opts ...NotifierOption) (*Notifier, error) {
+	n := &Notifier{
+		url:      url,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		template: defaultNotificationTemplate,
+		retries:  defaultNotifierRetries,
+		backoff:  defaultNotifierBackoff,
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	if n.url == "" && n.dryRun == nil {
+		return nil, errors.New("webhook url is required unless dry-run")
+	}
+	if n.retries < 0 {
+		return nil, fmt.Errorf("invalid number of retries: %d", n.retries)
+	}
+	tmpl, err := template.New("notification").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			if err := enc.Encode(v); err != nil {
+				return "", err
+			}
+			return strings.TrimSuffix(buf.String(), "\n"), nil
+		},
+	}).Parse(n.template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse notification template: %w", err)
+	}
+	n.tmpl = tmpl
+	return n, nil
+}
+
+// Notify renders the payload of the notification and posts it to the webhook.
+func (n *Notifier) Notify(ctx context.Context, notification *Notification) error {
+	payload, err := n.payload(notification)
+	if err != nil {
+		return err
+	}
+	if n.dryRun != nil {
+		_, err := fmt.Fprintf(n.dryRun, "%s\n", payload)
+		return err
+	}
+	wait := n.backoff
+	for i := 0; ; i++ {
+		retryable, err := n.post(ctx, payload)
+		if err == nil {
+			return nil
+		}
+		if !retryable || i >= n.retries {
+			return fmt.Errorf("failed to notify: %w", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// payload renders the template and checks that the result is valid JSON.
+func (n *Notifier) payload(notification *Notification) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := n.tmpl.Execute(&buf, notification); err != nil {
+		return nil, fmt.Errorf("failed to render notification template: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("notification template rendered invalid json: %q", buf.String())
+	}
+	return buf.Bytes(), nil
+}
+
+// post sends the payload once, and reports whether the failure is worth retrying.
+func (n *Notifier) post(ctx context.Context, payload []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retryable, fmt.Errorf("unexpected status: %s", resp.Status)
+}
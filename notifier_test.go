@@ -0,0 +1,225 @@
+package s3bytes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testNotification = &Notification{
+	Title:    "s3bytes: 1 violation found",
+	Messages: []string{`bucket0: bytes > 1KiB (4.1 kB)`},
+	Data: &MetricData{
+		Header: []string{"BucketName", "Value"},
+		Metrics: []*Metric{
+			{
+				BucketName: "bucket0",
+				Value:      4096,
+			},
+		},
+	},
+}
+
+func TestNotification_Text(t *testing.T) {
+	tests := []struct {
+		name         string
+		notification *Notification
+		want         string
+	}{
+		{
+			name:         "with rows",
+			notification: testNotification,
+			want:         "s3bytes: 1 violation found\n- bucket0: bytes > 1KiB (4.1 kB)\n```\nBucketName\tValue\nbucket0\t4096\n```",
+		},
+		{
+			name: "title only",
+			notification: &Notification{
+				Title: "title",
+			},
+			want: "title",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.notification.Text(); got != tt.want {
+				t.Errorf("Notification.Text() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewNotifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		opts    []NotifierOption
+		wantErr bool
+	}{
+		{
+			name:    "default",
+			url:     "http://localhost",
+			wantErr: false,
+		},
+		{
+			name:    "dry-run without url",
+			url:     "",
+			opts:    []NotifierOption{WithDryRun(io.Discard)},
+			wantErr: false,
+		},
+		{
+			name:    "no url",
+			url:     "",
+			wantErr: true,
+		},
+		{
+			name:    "invalid template",
+			url:     "http://localhost",
+			opts:    []NotifierOption{WithTemplate("{{ .Title ")},
+			wantErr: true,
+		},
+		{
+			name:    "negative retries",
+			url:     "http://localhost",
+			opts:    []NotifierOption{WithRetry(-1, 0)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewNotifier(tt.url, tt.opts...); (err != nil) != tt.wantErr {
+				t.Errorf("NewNotifier() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		opts      []NotifierOption
+		wantCalls int32
+		wantBody  string
+		wantErr   bool
+	}{
+		{
+			name:      "ok",
+			statuses:  []int{http.StatusOK},
+			wantCalls: 1,
+			wantBody:  `{"text": "s3bytes: 1 violation found\n- bucket0: bytes > 1KiB (4.1 kB)\n` + "```" + `\nBucketName\tValue\nbucket0\t4096\n` + "```" + `"}`,
+			wantErr:   false,
+		},
+		{
+			name:      "retry on server error",
+			statuses:  []int{http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusNoContent},
+			wantCalls: 3,
+			wantErr:   false,
+		},
+		{
+			name:      "no retry on client error",
+			statuses:  []int{http.StatusBadRequest, http.StatusOK},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "retries exhausted",
+			statuses:  []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK},
+			wantCalls: 4,
+			wantErr:   true,
+		},
+		{
+			name:      "custom template",
+			statuses:  []int{http.StatusOK},
+			opts:      []NotifierOption{WithTemplate(`{"title": {{ json .Title }}, "rows": {{ json .Rows }}}`)},
+			wantCalls: 1,
+			wantBody:  `{"title": "s3bytes: 1 violation found", "rows": [["bucket0","4096"]]}`,
+			wantErr:   false,
+		},
+		{
+			name:      "template rendering invalid json",
+			statuses:  []int{http.StatusOK},
+			opts:      []NotifierOption{WithTemplate(`{"text": {{ .Title }}}`)},
+			wantCalls: 0,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				calls atomic.Int32
+				body  []byte
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				i := calls.Add(1) - 1
+				if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+					t.Errorf("Content-Type = %q", ct)
+				}
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.statuses[min(int(i), len(tt.statuses)-1)])
+			}))
+			defer srv.Close()
+			opts := append([]NotifierOption{WithRetry(3, time.Millisecond)}, tt.opts...)
+			n, err := NewNotifier(srv.URL, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := n.Notify(context.Background(), testNotification); (err != nil) != tt.wantErr {
+				t.Errorf("Notifier.Notify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("Notifier.Notify() calls = %v, want %v", got, tt.wantCalls)
+			}
+			if tt.wantBody != "" && string(body) != tt.wantBody {
+				t.Errorf("Notifier.Notify() body = %s, want %s", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestNotifier_Notify_dryRun(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+	w := &bytes.Buffer{}
+	n, err := NewNotifier(srv.URL, WithDryRun(w))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Notify(context.Background(), testNotification); err != nil {
+		t.Fatalf("Notifier.Notify() error = %v", err)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("Notifier.Notify() posted in dry-run")
+	}
+	var payload map[string]string
+	if err := json.Unmarshal(w.Bytes(), &payload); err != nil {
+		t.Fatalf("Notifier.Notify() wrote invalid json: %v", err)
+	}
+	if payload["text"] != testNotification.Text() {
+		t.Errorf("Notifier.Notify() text = %q, want %q", payload["text"], testNotification.Text())
+	}
+}
+
+func TestNotifier_Notify_canceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	n, err := NewNotifier(srv.URL, WithRetry(3, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := n.Notify(ctx, testNotification); err == nil {
+		t.Errorf("Notifier.Notify() error = nil, want context error")
+	}
+}
@@ -0,0 +1,875 @@
+package s3bytes
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// orcMagic is the magic number at the start of an ORC file and in its postscript.
+const orcMagic = "ORC"
+
+// The compression kinds of ORC.
+const (
+	orcCompressionNone   = 0
+	orcCompressionZlib   = 1
+	orcCompressionSnappy = 2
+	orcCompressionZstd   = 5
+)
+
+// The type kinds of ORC used by the inventory reports.
+const (
+	orcKindBoolean = 0
+	orcKindShort   = 2
+	orcKindInt     = 3
+	orcKindLong    = 4
+	orcKindString  = 7
+	orcKindStruct  = 12
+	orcKindVarchar = 16
+	orcKindChar    = 17
+)
+
+// The stream kinds of ORC.
+const (
+	orcStreamPresent        = 0
+	orcStreamData           = 1
+	orcStreamLength         = 2
+	orcStreamDictionaryData = 3
+)
+
+// The column encodings of ORC.
+const (
+	orcEncodingDirect       = 0
+	orcEncodingDictionary   = 1
+	orcEncodingDirectV2     = 2
+	orcEncodingDictionaryV2 = 3
+)
+
+// orcType is the type of a column in the footer.
+type orcType struct {
+	kind       uint64
+	subtypes   []uint64
+	fieldNames []string
+}
+
+// orcStripe is the information of a stripe in the footer.
+type orcStripe struct {
+	offset       uint64
+	indexLength  uint64
+	dataLength   uint64
+	footerLength uint64
+	numRows      uint64
+}
+
+// orcFile is the metadata of an ORC file read from its postscript and footer.
+type orcFile struct {
+	compression uint64
+	blockSize   uint64
+	stripes     []orcStripe
+	types       []orcType
+}
+
+// orcStream is a stream of a column in a stripe.
+type orcStream struct {
+	kind   uint64
+	column uint64
+	length uint64
+}
+
+// orcColumnEncoding is the encoding of a column in a stripe.
+type orcColumnEncoding struct {
+	kind           uint64
+	dictionarySize uint64
+}
+
+// orcInventoryObjects returns the objects in the ORC data file. The columns are read one stripe
+// at a time, and the root struct of the inventory reports is assumed to hold the fields.
+func orcInventoryObjects(r io.ReaderAt, size int64) iter.Seq2[inventoryObject, error] {
+	return func(yield func(inventoryObject, error) bool) {
+		file, err := readORCFile(r, size)
+		if err != nil {
+			yield(inventoryObject{}, err)
+			return
+		}
+		if len(file.types) == 0 || file.types[0].kind != orcKindStruct || len(file.types[0].subtypes) != len(file.types[0].fieldNames) {
+			yield(inventoryObject{}, errors.New("orc schema must be a struct"))
+			return
+		}
+		fields := make(map[uint64]string)
+		for i, name := range file.types[0].fieldNames {
+			field := inventoryFieldName(name)
+			if _, ok := inventoryFieldKinds[field]; ok {
+				fields[file.types[0].subtypes[i]] = field
+			}
+		}
+		for _, required := range []string{inventoryFieldKey, inventoryFieldSize} {
+			found := false
+			for _, field := range fields {
+				found = found || field == required
+			}
+			if !found {
+				yield(inventoryObject{}, fmt.Errorf("orc schema must contain %s", required))
+				return
+			}
+		}
+		for _, stripe := range file.stripes {
+			set, err := readORCStripe(r, file, stripe, fields)
+			if err != nil {
+				yield(inventoryObject{}, err)
+				return
+			}
+			for obj := range set.objects() {
+				if !yield(obj, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// readORCFile reads the postscript at the end of the file and the footer before it.
+func readORCFile(r io.ReaderAt, size int64) (*orcFile, error) {
+	if size < int64(len(orcMagic)+1) {
+		return nil, errors.New("not an orc file")
+	}
+	last := make([]byte, 1)
+	if _, err := r.ReadAt(last, size-1); err != nil {
+		return nil, err
+	}
+	psLength := int64(last[0])
+	if psLength+1 > size {
+		return nil, errors.New("invalid orc postscript length")
+	}
+	ps := make([]byte, psLength)
+	if _, err := r.ReadAt(ps, size-1-psLength); err != nil {
+		return nil, err
+	}
+	var (
+		file         = &orcFile{}
+		footerLength uint64
+		magic        string
+	)
+	p := &protoReader{b: ps}
+	err := p.readMessage(func(field, wire int) error {
+		var err error
+		switch {
+		case field == 1 && wire == protoVarint:
+			footerLength, err = p.uvarint()
+		case field == 2 && wire == protoVarint:
+			file.compression, err = p.uvarint()
+		case field == 3 && wire == protoVarint:
+			file.blockSize, err = p.uvarint()
+		case field == 8000 && wire == protoBytes:
+			var b []byte
+			b, err = p.bytes()
+			magic = string(b)
+		default:
+			err = p.skip(wire)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid orc postscript: %w", err)
+	}
+	if magic != orcMagic {
+		return nil, errors.New("not an orc file")
+	}
+	if int64(footerLength) > size-1-psLength {
+		return nil, errors.New("invalid orc footer length")
+	}
+	b := make([]byte, footerLength)
+	if _, err := r.ReadAt(b, size-1-psLength-int64(footerLength)); err != nil {
+		return nil, err
+	}
+	if b, err = file.decompress(b); err != nil {
+		return nil, err
+	}
+	p = &protoReader{b: b}
+	err = p.readMessage(func(field, wire int) error {
+		switch {
+		case field == 3 && wire == protoBytes:
+			b, err := p.bytes()
+			if err != nil {
+				return err
+			}
+			stripe, err := readORCStripeInformation(b)
+			file.stripes = append(file.stripes, stripe)
+			return err
+		case field == 4 && wire == protoBytes:
+			b, err := p.bytes()
+			if err != nil {
+				return err
+			}
+			typ, err := readORCType(b)
+			file.types = append(file.types, typ)
+			return err
+		default:
+			return p.skip(wire)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid orc footer: %w", err)
+	}
+	return file, nil
+}
+
+// readORCStripeInformation reads a StripeInformation message.
+func readORCStripeInformation(b []byte) (orcStripe, error) {
+	var stripe orcStripe
+	p := &protoReader{b: b}
+	err := p.readMessage(func(field, wire int) error {
+		if wire != protoVarint {
+			return p.skip(wire)
+		}
+		var err error
+		switch field {
+		case 1:
+			stripe.offset, err = p.uvarint()
+		case 2:
+			stripe.indexLength, err = p.uvarint()
+		case 3:
+			stripe.dataLength, err = p.uvarint()
+		case 4:
+			stripe.footerLength, err = p.uvarint()
+		case 5:
+			stripe.numRows, err = p.uvarint()
+		default:
+			err = p.skip(wire)
+		}
+		return err
+	})
+	return stripe, err
+}
+
+// readORCType reads a Type message.
+func readORCType(b []byte) (orcType, error) {
+	var typ orcType
+	p := &protoReader{b: b}
+	err := p.readMessage(func(field, wire int) error {
+		var err error
+		switch {
+		case field == 1 && wire == protoVarint:
+			typ.kind, err = p.uvarint()
+		case field == 2:
+			var subtypes []uint64
+			subtypes, err = p.uvarints(wire)
+			typ.subtypes = append(typ.subtypes, subtypes...)
+		case field == 3 && wire == protoBytes:
+			var b []byte
+			b, err = p.bytes()
+			typ.fieldNames = append(typ.fieldNames, string(b))
+		default:
+			err = p.skip(wire)
+		}
+		return err
+	})
+	return typ, err
+}
+
+// readORCStripeFooter reads a StripeFooter message.
+func readORCStripeFooter(b []byte) ([]orcStream, []orcColumnEncoding, error) {
+	var (
+		streams   []orcStream
+		encodings []orcColumnEncoding
+	)
+	p := &protoReader{b: b}
+	err := p.readMessage(func(field, wire int) error {
+		if wire != protoBytes || (field != 1 && field != 2) {
+			return p.skip(wire)
+		}
+		b, err := p.bytes()
+		if err != nil {
+			return err
+		}
+		m := &protoReader{b: b}
+		if field == 1 {
+			var stream orcStream
+			err = m.readMessage(func(field, wire int) error {
+				var err error
+				switch {
+				case field == 1 && wire == protoVarint:
+					stream.kind, err = m.uvarint()
+				case field == 2 && wire == protoVarint:
+					stream.column, err = m.uvarint()
+				case field == 3 && wire == protoVarint:
+					stream.length, err = m.uvarint()
+				default:
+					err = m.skip(wire)
+				}
+				return err
+			})
+			streams = append(streams, stream)
+			return err
+		}
+		var encoding orcColumnEncoding
+		err = m.readMessage(func(field, wire int) error {
+			var err error
+			switch {
+			case field == 1 && wire == protoVarint:
+				encoding.kind, err = m.uvarint()
+			case field == 2 && wire == protoVarint:
+				encoding.dictionarySize, err = m.uvarint()
+			default:
+				err = m.skip(wire)
+			}
+			return err
+		})
+		encodings = append(encodings, encoding)
+		return err
+	})
+	return streams, encodings, err
+}
+
+// readORCStripe reads the columns of the fields in the stripe.
+func readORCStripe(r io.ReaderAt, file *orcFile, stripe orcStripe, fields map[uint64]string) (*inventoryColumnSet, error) {
+	length := stripe.indexLength + stripe.dataLength + stripe.footerLength
+	if length > 1<<40 || stripe.numRows > 1<<40 {
+		return nil, errors.New("invalid orc stripe")
+	}
+	b := make([]byte, length)
+	if _, err := r.ReadAt(b, int64(stripe.offset)); err != nil {
+		return nil, err
+	}
+	footer, err := file.decompress(b[stripe.indexLength+stripe.dataLength:])
+	if err != nil {
+		return nil, err
+	}
+	streams, encodings, err := readORCStripeFooter(footer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid orc stripe footer: %w", err)
+	}
+	data := make(map[uint64]map[uint64][]byte)
+	var off uint64
+	for _, stream := range streams {
+		if stream.length > length-stripe.footerLength-off {
+			return nil, errors.New("orc stream exceeds the stripe")
+		}
+		if _, ok := fields[stream.column]; ok {
+			if data[stream.column] == nil {
+				data[stream.column] = make(map[uint64][]byte)
+			}
+			data[stream.column][stream.kind] = b[off : off+stream.length]
+		}
+		off += stream.length
+	}
+	set := &inventoryColumnSet{n: int(stripe.numRows)}
+	for column, field := range fields {
+		if column >= uint64(len(file.types)) || column >= uint64(len(encodings)) {
+			return nil, fmt.Errorf("orc column %d not found", column)
+		}
+		streams := make(map[uint64][]byte, len(data[column]))
+		for kind, b := range data[column] {
+			if streams[kind], err = file.decompress(b); err != nil {
+				return nil, err
+			}
+		}
+		col, err := decodeORCColumn(streams, file.types[column].kind, encodings[column], inventoryFieldKinds[field], set.n)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read orc column %q: %w", field, err)
+		}
+		set.set(field, col)
+	}
+	return set, nil
+}
+
+// decompress decompresses the chunks of the stream with the compression of the file.
+// Each chunk has a 3-byte header holding its length and whether it is stored uncompressed.
+func (f *orcFile) decompress(b []byte) ([]byte, error) {
+	if f.compression == orcCompressionNone {
+		return b, nil
+	}
+	var out []byte
+	for len(b) > 0 {
+		if len(b) < 3 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		h := int(b[0]) | int(b[1])<<8 | int(b[2])<<16
+		size := h >> 1
+		if size > len(b)-3 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		chunk := b[3 : 3+size]
+		b = b[3+size:]
+		if h&1 == 1 {
+			out = append(out, chunk...)
+			continue
+		}
+		var (
+			d   []byte
+			err error
+		)
+		switch f.compression {
+		case orcCompressionZlib:
+			d, err = decompressDeflate(chunk, int(f.blockSize))
+		case orcCompressionSnappy:
+			d, err = decompressSnappy(chunk)
+		case orcCompressionZstd:
+			d, err = decompressZstd(chunk, int(f.blockSize))
+		default:
+			return nil, fmt.Errorf("unsupported orc compression: %d", f.compression)
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d...)
+	}
+	return out, nil
+}
+
+// decodeORCColumn decodes the streams of the column of the type into a column of the kind.
+func decodeORCColumn(streams map[uint64][]byte, typ uint64, encoding orcColumnEncoding, kind inventoryKind, n int) (*inventoryColumn, error) {
+	valid := make([]bool, n)
+	nonNull := n
+	if present, ok := streams[orcStreamPresent]; ok {
+		bits, err := decodeORCBooleans(present, n)
+		if err != nil {
+			return nil, err
+		}
+		copy(valid, bits)
+		nonNull = 0
+		for _, ok := range valid {
+			if ok {
+				nonNull++
+			}
+		}
+	} else {
+		for i := range valid {
+			valid[i] = true
+		}
+	}
+	values := &inventoryColumn{kind: kind}
+	switch {
+	case kind == inventoryKindBool && typ == orcKindBoolean:
+		bits, err := decodeORCBooleans(streams[orcStreamData], nonNull)
+		if err != nil {
+			return nil, err
+		}
+		values.bools = bits
+	case kind == inventoryKindInt && (typ == orcKindShort || typ == orcKindInt || typ == orcKindLong):
+		ints, err := decodeORCInts(streams[orcStreamData], nonNull, true, encoding.kind)
+		if err != nil {
+			return nil, err
+		}
+		values.ints = ints
+	case kind == inventoryKindString && (typ == orcKindString || typ == orcKindVarchar || typ == orcKindChar):
+		strs, err := decodeORCStrings(streams, nonNull, encoding)
+		if err != nil {
+			return nil, err
+		}
+		values.strings = strs
+	default:
+		return nil, fmt.Errorf("unexpected type kind: %d", typ)
+	}
+	col := &inventoryColumn{kind: kind}
+	if err := col.appendRows(values, valid); err != nil {
+		return nil, err
+	}
+	return col, nil
+}
+
+// decodeORCStrings decodes the n strings in the direct or the dictionary encoding.
+func decodeORCStrings(streams map[uint64][]byte, n int, encoding orcColumnEncoding) ([]string, error) {
+	switch encoding.kind {
+	case orcEncodingDirect, orcEncodingDirectV2:
+		lengths, err := decodeORCInts(streams[orcStreamLength], n, false, encoding.kind)
+		if err != nil {
+			return nil, err
+		}
+		return splitORCStrings(streams[orcStreamData], lengths)
+	case orcEncodingDictionary, orcEncodingDictionaryV2:
+		// the dictionary holds the distinct values of the stripe
+		if encoding.dictionarySize > uint64(n) {
+			return nil, fmt.Errorf("invalid dictionary size: %d", encoding.dictionarySize)
+		}
+		lengths, err := decodeORCInts(streams[orcStreamLength], int(encoding.dictionarySize), false, encoding.kind)
+		if err != nil {
+			return nil, err
+		}
+		dict, err := splitORCStrings(streams[orcStreamDictionaryData], lengths)
+		if err != nil {
+			return nil, err
+		}
+		indices, err := decodeORCInts(streams[orcStreamData], n, false, encoding.kind)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]string, n)
+		for i, index := range indices {
+			if index < 0 || index >= int64(len(dict)) {
+				return nil, fmt.Errorf("dictionary index out of range: %d", index)
+			}
+			values[i] = dict[index]
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unsupported orc column encoding: %d", encoding.kind)
+	}
+}
+
+// splitORCStrings splits the concatenated bytes into strings of the lengths.
+func splitORCStrings(b []byte, lengths []int64) ([]string, error) {
+	values := make([]string, len(lengths))
+	off := 0
+	for i, n := range lengths {
+		if n < 0 || n > int64(len(b)-off) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		values[i] = string(b[off : off+int(n)])
+		off += int(n)
+	}
+	return values, nil
+}
+
+// decodeORCInts decodes the n integers in the run length encoding of the version used by the column encoding.
+func decodeORCInts(b []byte, n int, signed bool, encoding uint64) ([]int64, error) {
+	if encoding == orcEncodingDirect || encoding == orcEncodingDictionary {
+		return decodeORCIntRLEv1(b, n, signed)
+	}
+	return decodeORCIntRLEv2(b, n, signed)
+}
+
+// decodeORCByteRLE decodes the n bytes in the byte run length encoding.
+func decodeORCByteRLE(b []byte, n int) ([]byte, error) {
+	values := make([]byte, 0, n)
+	off := 0
+	for len(values) < n {
+		if off >= len(b) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		c := b[off]
+		off++
+		if c < 0x80 {
+			if off >= len(b) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			for range min(int(c)+3, n-len(values)) {
+				values = append(values, b[off])
+			}
+			off++
+			continue
+		}
+		count := 0x100 - int(c)
+		if count > len(b)-off {
+			return nil, io.ErrUnexpectedEOF
+		}
+		values = append(values, b[off:off+min(count, n-len(values))]...)
+		off += count
+	}
+	return values, nil
+}
+
+// decodeORCBooleans decodes the n booleans packed from the most significant bit into bytes in the byte run length encoding.
+func decodeORCBooleans(b []byte, n int) ([]bool, error) {
+	bytes, err := decodeORCByteRLE(b, (n+7)/8)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]bool, n)
+	for i := range values {
+		values[i] = bytes[i/8]>>(7-i%8)&1 == 1
+	}
+	return values, nil
+}
+
+// decodeORCIntRLEv1 decodes the n integers in the integer run length encoding version 1.
+func decodeORCIntRLEv1(b []byte, n int, signed bool) ([]int64, error) {
+	values := make([]int64, 0, n)
+	off := 0
+	varint := func() (int64, error) {
+		var (
+			v int64
+			k int
+		)
+		if signed {
+			v, k = binary.Varint(b[off:])
+		} else {
+			var u uint64
+			u, k = binary.Uvarint(b[off:])
+			v = int64(u)
+		}
+		if k <= 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		off += k
+		return v, nil
+	}
+	for len(values) < n {
+		if off >= len(b) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		c := int8(b[off])
+		off++
+		if c >= 0 {
+			if off >= len(b) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			delta := int64(int8(b[off]))
+			off++
+			base, err := varint()
+			if err != nil {
+				return nil, err
+			}
+			for i := range min(int(c)+3, n-len(values)) {
+				values = append(values, base+int64(i)*delta)
+			}
+			continue
+		}
+		for range -int(c) {
+			v, err := varint()
+			if err != nil {
+				return nil, err
+			}
+			if len(values) < n {
+				values = append(values, v)
+			}
+		}
+	}
+	return values, nil
+}
+
+// decodeORCIntRLEv2 decodes the n integers in the integer run length encoding version 2,
+// which consists of the short repeat, the direct, the patched base and the delta sub-encodings.
+func decodeORCIntRLEv2(b []byte, n int, signed bool) ([]int64, error) {
+	values := make([]int64, 0, n)
+	off := 0
+	appendValue := func(v int64) {
+		if len(values) < n {
+			values = append(values, v)
+		}
+	}
+	for len(values) < n {
+		if off >= len(b) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		h := b[off]
+		switch h >> 6 {
+		case 0: // short repeat
+			width := int(h>>3&0x07) + 1
+			count := int(h&0x07) + 3
+			off++
+			if width > len(b)-off {
+				return nil, io.ErrUnexpectedEOF
+			}
+			var v uint64
+			for _, c := range b[off : off+width] {
+				v = v<<8 | uint64(c)
+			}
+			off += width
+			for range count {
+				appendValue(orcInt(v, signed))
+			}
+		case 1: // direct
+			if off+2 > len(b) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			width := orcBitWidth(int(h >> 1 & 0x1f))
+			count := int(h&0x01)<<8 | int(b[off+1]) + 1
+			off += 2
+			unpacked, k, err := unpackBitsBE(b[off:], width, count)
+			if err != nil {
+				return nil, err
+			}
+			off += k
+			for _, v := range unpacked {
+				appendValue(orcInt(v, signed))
+			}
+		case 2: // patched base
+			if off+4 > len(b) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			var (
+				width       = orcBitWidth(int(h >> 1 & 0x1f))
+				count       = int(h&0x01)<<8 | int(b[off+1]) + 1
+				baseWidth   = int(b[off+2]>>5) + 1
+				patchWidth  = orcBitWidth(int(b[off+2] & 0x1f))
+				gapWidth    = int(b[off+3]>>5) + 1
+				patchLength = int(b[off+3] & 0x1f)
+			)
+			off += 4
+			if baseWidth > len(b)-off {
+				return nil, io.ErrUnexpectedEOF
+			}
+			var base uint64
+			for _, c := range b[off : off+baseWidth] {
+				base = base<<8 | uint64(c)
+			}
+			off += baseWidth
+			// the base is in sign-magnitude form with the most significant bit as the sign
+			signedBase := int64(base)
+			if mask := uint64(1) << (8*baseWidth - 1); base&mask != 0 {
+				signedBase = -int64(base &^ mask)
+			}
+			unpacked, k, err := unpackBitsBE(b[off:], width, count)
+			if err != nil {
+				return nil, err
+			}
+			off += k
+			entryWidth := orcClosestFixedBits(gapWidth + patchWidth)
+			if gapWidth+patchWidth > 64 {
+				return nil, fmt.Errorf("invalid patch width: %d", gapWidth+patchWidth)
+			}
+			patches, k, err := unpackBitsBE(b[off:], entryWidth, patchLength)
+			if err != nil {
+				return nil, err
+			}
+			off += k
+			index := 0
+			for _, patch := range patches {
+				gap := int(patch >> patchWidth)
+				value := patch & (1<<patchWidth - 1)
+				index += gap
+				if gap == 255 && value == 0 {
+					continue
+				}
+				if index >= count {
+					return nil, fmt.Errorf("patch index out of range: %d", index)
+				}
+				unpacked[index] |= value << width
+			}
+			for _, v := range unpacked {
+				appendValue(signedBase + int64(v))
+			}
+		case 3: // delta
+			if off+2 > len(b) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			width := 0
+			if code := int(h >> 1 & 0x1f); code != 0 {
+				width = orcBitWidth(code)
+			}
+			count := int(h&0x01)<<8 | int(b[off+1]) + 1
+			off += 2
+			var (
+				base int64
+				k    int
+			)
+			if signed {
+				base, k = binary.Varint(b[off:])
+			} else {
+				var u uint64
+				u, k = binary.Uvarint(b[off:])
+				base = int64(u)
+			}
+			if k <= 0 {
+				return nil, io.ErrUnexpectedEOF
+			}
+			off += k
+			deltaBase, k := binary.Varint(b[off:])
+			if k <= 0 {
+				return nil, io.ErrUnexpectedEOF
+			}
+			off += k
+			prev := base
+			appendValue(prev)
+			if count == 1 {
+				continue
+			}
+			prev += deltaBase
+			appendValue(prev)
+			if width == 0 {
+				for range count - 2 {
+					prev += deltaBase
+					appendValue(prev)
+				}
+				continue
+			}
+			deltas, k, err := unpackBitsBE(b[off:], width, count-2)
+			if err != nil {
+				return nil, err
+			}
+			off += k
+			for _, d := range deltas {
+				if deltaBase < 0 {
+					prev -= int64(d)
+				} else {
+					prev += int64(d)
+				}
+				appendValue(prev)
+			}
+		}
+	}
+	return values, nil
+}
+
+// orcInt converts the unsigned value into an integer, decoding the zigzag encoding of the signed integers.
+func orcInt(v uint64, signed bool) int64 {
+	if !signed {
+		return int64(v)
+	}
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// orcBitWidth decodes the 5-bit code of the bit width used by the integer run length encoding version 2.
+func orcBitWidth(code int) int {
+	switch {
+	case code < 24:
+		return code + 1
+	case code == 24:
+		return 26
+	case code == 25:
+		return 28
+	case code == 26:
+		return 30
+	case code == 27:
+		return 32
+	case code == 28:
+		return 40
+	case code == 29:
+		return 48
+	case code == 30:
+		return 56
+	default:
+		return 64
+	}
+}
+
+// orcClosestFixedBits rounds the bit width up to the closest width that has a 5-bit code.
+func orcClosestFixedBits(n int) int {
+	switch {
+	case n == 0:
+		return 1
+	case n <= 24:
+		return n
+	case n <= 26:
+		return 26
+	case n <= 28:
+		return 28
+	case n <= 30:
+		return 30
+	case n <= 32:
+		return 32
+	case n <= 40:
+		return 40
+	case n <= 48:
+		return 48
+	case n <= 56:
+		return 56
+	default:
+		return 64
+	}
+}
+
+// unpackBitsBE unpacks the n values of the bit width packed from the most significant bit,
+// and returns the number of bytes read, which ends at a byte boundary.
+func unpackBitsBE(b []byte, width, n int) ([]uint64, int, error) {
+	size := (width*n + 7) / 8
+	if size > len(b) {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	values := make([]uint64, n)
+	pos := 0
+	for i := range values {
+		var v uint64
+		for left := width; left > 0; {
+			shift := pos % 8
+			take := min(left, 8-shift)
+			v = v<<take | uint64(b[pos/8]>>(8-shift-take))&(1<<take-1)
+			left -= take
+			pos += take
+		}
+		values[i] = v
+	}
+	return values, size, nil
+}
@@ -0,0 +1,616 @@
+package s3bytes
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// testORCOptions is the layout of a test ORC file.
+type testORCOptions struct {
+	compression uint64
+	v1          bool
+	dictionary  bool
+	stripeSize  int
+}
+
+// writeTestORC encodes the columns into an ORC file with the struct of the columns as the root.
+// The integers are written in the literal runs of the encoding version, and the bytes in the literal runs.
+func writeTestORC(t *testing.T, cols []testInventoryColumn, opts testORCOptions) []byte {
+	t.Helper()
+	var (
+		buf     = bytes.NewBufferString(orcMagic)
+		n       = len(cols[0].values)
+		stripes [][]byte
+	)
+	direct, dictionary := uint64(orcEncodingDirectV2), uint64(orcEncodingDictionaryV2)
+	if opts.v1 {
+		direct, dictionary = orcEncodingDirect, orcEncodingDictionary
+	}
+	for start := 0; start < n; start += opts.stripeSize {
+		end := min(start+opts.stripeSize, n)
+		var (
+			offset = uint64(buf.Len())
+			footer = &protoWriter{}
+			data   uint64
+		)
+		addStream := func(column int, kind uint64, b []byte) {
+			b = compressTestORC(t, opts.compression, b)
+			s := &protoWriter{}
+			s.uvarint(1, kind)
+			s.uvarint(2, uint64(column))
+			s.uvarint(3, uint64(len(b)))
+			footer.bytes(1, s.Bytes())
+			buf.Write(b)
+			data += uint64(len(b))
+		}
+		encodings := []orcColumnEncoding{{kind: orcEncodingDirect}}
+		for i, col := range cols {
+			column := i + 1
+			values := col.values[start:end]
+			var (
+				present []bool
+				nonNull []any
+				hasNull bool
+			)
+			for _, v := range values {
+				present = append(present, v != nil)
+				if v == nil {
+					hasNull = true
+				} else {
+					nonNull = append(nonNull, v)
+				}
+			}
+			if hasNull {
+				addStream(column, orcStreamPresent, encodeTestORCBooleans(present))
+			}
+			switch col.kind {
+			case inventoryKindBool:
+				var bools []bool
+				for _, v := range nonNull {
+					bools = append(bools, v.(bool))
+				}
+				addStream(column, orcStreamData, encodeTestORCBooleans(bools))
+				encodings = append(encodings, orcColumnEncoding{kind: orcEncodingDirect})
+			case inventoryKindInt:
+				var ints []int64
+				for _, v := range nonNull {
+					ints = append(ints, v.(int64))
+				}
+				addStream(column, orcStreamData, encodeTestORCInts(ints, true, opts.v1))
+				encodings = append(encodings, orcColumnEncoding{kind: direct})
+			case inventoryKindString:
+				if opts.dictionary {
+					var (
+						index   = make(map[string]int64)
+						entries []string
+						indices []int64
+					)
+					for _, v := range nonNull {
+						s := v.(string)
+						if _, ok := index[s]; !ok {
+							index[s] = int64(len(entries))
+							entries = append(entries, s)
+						}
+						indices = append(indices, index[s])
+					}
+					var (
+						dict    []byte
+						lengths []int64
+					)
+					for _, s := range entries {
+						dict = append(dict, s...)
+						lengths = append(lengths, int64(len(s)))
+					}
+					addStream(column, orcStreamData, encodeTestORCInts(indices, false, opts.v1))
+					addStream(column, orcStreamDictionaryData, dict)
+					addStream(column, orcStreamLength, encodeTestORCInts(lengths, false, opts.v1))
+					encodings = append(encodings, orcColumnEncoding{kind: dictionary, dictionarySize: uint64(len(entries))})
+					continue
+				}
+				var (
+					b       []byte
+					lengths []int64
+				)
+				for _, v := range nonNull {
+					b = append(b, v.(string)...)
+					lengths = append(lengths, int64(len(v.(string))))
+				}
+				addStream(column, orcStreamData, b)
+				addStream(column, orcStreamLength, encodeTestORCInts(lengths, false, opts.v1))
+				encodings = append(encodings, orcColumnEncoding{kind: direct})
+			}
+		}
+		for _, encoding := range encodings {
+			e := &protoWriter{}
+			e.uvarint(1, encoding.kind)
+			if encoding.dictionarySize > 0 {
+				e.uvarint(2, encoding.dictionarySize)
+			}
+			footer.bytes(2, e.Bytes())
+		}
+		b := compressTestORC(t, opts.compression, footer.Bytes())
+		buf.Write(b)
+		info := &protoWriter{}
+		info.uvarint(1, offset)
+		info.uvarint(2, 0)
+		info.uvarint(3, data)
+		info.uvarint(4, uint64(len(b)))
+		info.uvarint(5, uint64(end-start))
+		stripes = append(stripes, info.Bytes())
+	}
+	footer := &protoWriter{}
+	footer.uvarint(1, uint64(len(orcMagic)))
+	footer.uvarint(2, uint64(buf.Len()-len(orcMagic)))
+	for _, stripe := range stripes {
+		footer.bytes(3, stripe)
+	}
+	root := &protoWriter{}
+	root.uvarint(1, orcKindStruct)
+	var subtypes []uint64
+	for i, col := range cols {
+		subtypes = append(subtypes, uint64(i+1))
+		root.bytes(3, []byte(col.name))
+	}
+	root.packed(2, subtypes)
+	footer.bytes(4, root.Bytes())
+	for _, col := range cols {
+		typ := &protoWriter{}
+		switch col.kind {
+		case inventoryKindString:
+			typ.uvarint(1, orcKindString)
+		case inventoryKindInt:
+			typ.uvarint(1, orcKindLong)
+		case inventoryKindBool:
+			typ.uvarint(1, orcKindBoolean)
+		}
+		footer.bytes(4, typ.Bytes())
+	}
+	footer.uvarint(6, uint64(n))
+	b := compressTestORC(t, opts.compression, footer.Bytes())
+	buf.Write(b)
+	ps := &protoWriter{}
+	ps.uvarint(1, uint64(len(b)))
+	ps.uvarint(2, opts.compression)
+	ps.uvarint(3, 64)
+	ps.packed(4, []uint64{0, 12})
+	ps.bytes(8000, []byte(orcMagic))
+	buf.Write(ps.Bytes())
+	buf.WriteByte(byte(ps.Len()))
+	return buf.Bytes()
+}
+
+// compressTestORC splits the stream into chunks of 64 bytes and compresses them with the compression.
+// The chunks that do not shrink are stored as the original.
+func compressTestORC(t *testing.T, compression uint64, b []byte) []byte {
+	t.Helper()
+	if compression == orcCompressionNone {
+		return b
+	}
+	var out []byte
+	for start := 0; start < len(b); start += 64 {
+		chunk := b[start:min(start+64, len(b))]
+		var compressed []byte
+		switch compression {
+		case orcCompressionZlib:
+			var buf bytes.Buffer
+			fw, err := flate.NewWriter(&buf, flate.BestCompression)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := fw.Write(chunk); err != nil {
+				t.Fatal(err)
+			}
+			if err := fw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			compressed = buf.Bytes()
+		case orcCompressionSnappy:
+			compressed = snappy.Encode(nil, chunk)
+		case orcCompressionZstd:
+			enc, err := zstd.NewWriter(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			compressed = enc.EncodeAll(chunk, nil)
+			enc.Close()
+		default:
+			// an unknown compression keeps the chunk marked as compressed
+			out = append(out, byte(len(chunk)<<1), byte(len(chunk)>>7), byte(len(chunk)>>15))
+			out = append(out, chunk...)
+			continue
+		}
+		h := len(compressed) << 1
+		if len(compressed) >= len(chunk) {
+			compressed, h = chunk, len(chunk)<<1|1
+		}
+		out = append(out, byte(h), byte(h>>8), byte(h>>16))
+		out = append(out, compressed...)
+	}
+	return out
+}
+
+// encodeTestORCBooleans encodes the booleans into bytes from the most significant bit in the literal runs.
+func encodeTestORCBooleans(values []bool) []byte {
+	var b []byte
+	for i := 0; i < len(values); i += 8 {
+		var c byte
+		for j := i; j < min(i+8, len(values)); j++ {
+			if values[j] {
+				c |= 0x80 >> (j - i)
+			}
+		}
+		b = append(b, c)
+	}
+	var out []byte
+	for start := 0; start < len(b); start += 128 {
+		literal := b[start:min(start+128, len(b))]
+		out = append(out, byte(0x100-len(literal)))
+		out = append(out, literal...)
+	}
+	return out
+}
+
+// encodeTestORCInts encodes the integers in the literal runs of the version 1,
+// or in the direct sub-encoding of the version 2 with 64-bit width.
+func encodeTestORCInts(values []int64, signed, v1 bool) []byte {
+	var out []byte
+	size := 512
+	if v1 {
+		size = 128
+	}
+	for start := 0; start < len(values); start += size {
+		run := values[start:min(start+size, len(values))]
+		if v1 {
+			out = append(out, byte(0x100-len(run)))
+			for _, v := range run {
+				if signed {
+					out = binary.AppendVarint(out, v)
+				} else {
+					out = binary.AppendUvarint(out, uint64(v))
+				}
+			}
+			continue
+		}
+		out = append(out, 0x40|31<<1|byte((len(run)-1)>>8), byte(len(run)-1))
+		for _, v := range run {
+			u := uint64(v)
+			if signed {
+				u = uint64(v<<1) ^ uint64(v>>63)
+			}
+			out = binary.BigEndian.AppendUint64(out, u)
+		}
+	}
+	return out
+}
+
+func Test_orcInventoryObjects(t *testing.T) {
+	cols := testInventoryColumns(
+		`"bucket0","a/1.txt","v1","true","false","10","STANDARD",""`,
+		`"bucket0","a/1.txt","v0","false","false","20","STANDARD",""`,
+		`"bucket0","a/1.txt","v2","false","true","","",""`,
+		`"bucket0","a/b/2.txt","","true","false","100","INTELLIGENT_TIERING","INFREQUENT"`,
+		`"bucket0","3.txt","","true","false","1000","GLACIER",""`,
+		`"bucket0","a/b/4.txt","","true","false","10000","INTELLIGENT_TIERING","ARCHIVE"`,
+		`"bucket0","a/2.txt","","true","false","0","STANDARD",""`,
+		`"bucket0","a+b.txt","","true","false","5","STANDARD",""`,
+		`"bucket0","a/1.txt","v3","false","false","30","STANDARD_IA",""`,
+		`"bucket0","c/5.txt","","true","false","1","STANDARD",""`,
+	)
+	want := []inventoryObject{
+		{key: "a/1.txt", size: 10, hasSize: true, storageClass: "STANDARD"},
+		{key: "a/1.txt", size: 20, hasSize: true, storageClass: "STANDARD"},
+		{key: "a/1.txt", deleteMarker: true},
+		{key: "a/b/2.txt", size: 100, hasSize: true, storageClass: "INTELLIGENT_TIERING", accessTier: "INFREQUENT"},
+		{key: "3.txt", size: 1000, hasSize: true, storageClass: "GLACIER"},
+		{key: "a/b/4.txt", size: 10000, hasSize: true, storageClass: "INTELLIGENT_TIERING", accessTier: "ARCHIVE"},
+		{key: "a/2.txt", size: 0, hasSize: true, storageClass: "STANDARD"},
+		{key: "a+b.txt", size: 5, hasSize: true, storageClass: "STANDARD"},
+		{key: "a/1.txt", size: 30, hasSize: true, storageClass: "STANDARD_IA"},
+		{key: "c/5.txt", size: 1, hasSize: true, storageClass: "STANDARD"},
+	}
+	tests := []struct {
+		name    string
+		b       []byte
+		want    []inventoryObject
+		wantErr bool
+	}{
+		{
+			name:    "direct v2 uncompressed",
+			b:       writeTestORC(t, cols, testORCOptions{compression: orcCompressionNone, stripeSize: 10}),
+			want:    want,
+			wantErr: false,
+		},
+		{
+			name:    "dictionary v2 zlib with stripes",
+			b:       writeTestORC(t, cols, testORCOptions{compression: orcCompressionZlib, dictionary: true, stripeSize: 4}),
+			want:    want,
+			wantErr: false,
+		},
+		{
+			name:    "direct v1 snappy",
+			b:       writeTestORC(t, cols, testORCOptions{compression: orcCompressionSnappy, v1: true, stripeSize: 3}),
+			want:    want,
+			wantErr: false,
+		},
+		{
+			name:    "dictionary v1 zstd",
+			b:       writeTestORC(t, cols, testORCOptions{compression: orcCompressionZstd, v1: true, dictionary: true, stripeSize: 10}),
+			want:    want,
+			wantErr: false,
+		},
+		{
+			name:    "no size",
+			b:       writeTestORC(t, cols[:2], testORCOptions{compression: orcCompressionNone, stripeSize: 10}),
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported compression",
+			b:       writeTestORC(t, cols, testORCOptions{compression: 3, stripeSize: 10}),
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "not orc",
+			b:       []byte("bucket,key\n"),
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []inventoryObject
+			var err error
+			for obj, e := range orcInventoryObjects(bytes.NewReader(tt.b), int64(len(tt.b))) {
+				if e != nil {
+					err = e
+					break
+				}
+				got = append(got, obj)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("orcInventoryObjects() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orcInventoryObjects() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_decodeORCIntRLEv2(t *testing.T) {
+	type args struct {
+		b      []byte
+		n      int
+		signed bool
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []int64
+		wantErr bool
+	}{
+		{
+			name: "short repeat",
+			args: args{
+				b: []byte{0x0a, 0x27, 0x10},
+				n: 5,
+			},
+			want:    []int64{10000, 10000, 10000, 10000, 10000},
+			wantErr: false,
+		},
+		{
+			name: "direct",
+			args: args{
+				b: []byte{0x5e, 0x03, 0x5c, 0xa1, 0xab, 0x1e, 0xde, 0xad, 0xbe, 0xef},
+				n: 4,
+			},
+			want:    []int64{23713, 43806, 57005, 48879},
+			wantErr: false,
+		},
+		{
+			name: "patched base",
+			args: args{
+				b: []byte{
+					0x8e, 0x13, 0x2b, 0x21, 0x07, 0xd0, 0x1e, 0x00, 0x14, 0x70, 0x28, 0x32, 0x3c, 0x46, 0x50, 0x5a,
+					0x64, 0x6e, 0x78, 0x82, 0x8c, 0x96, 0xa0, 0xaa, 0xb4, 0xbe, 0xfc, 0xe8,
+				},
+				n: 20,
+			},
+			want: []int64{
+				2030, 2000, 2020, 1000000, 2040, 2050, 2060, 2070, 2080, 2090,
+				2100, 2110, 2120, 2130, 2140, 2150, 2160, 2170, 2180, 2190,
+			},
+			wantErr: false,
+		},
+		{
+			name: "delta",
+			args: args{
+				b: []byte{0xc6, 0x09, 0x02, 0x02, 0x22, 0x42, 0x42, 0x46},
+				n: 10,
+			},
+			want:    []int64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29},
+			wantErr: false,
+		},
+		{
+			name: "fixed delta signed",
+			args: args{
+				b:      []byte{0xc0, 0x04, 0x14, 0x03},
+				n:      5,
+				signed: true,
+			},
+			want:    []int64{10, 8, 6, 4, 2},
+			wantErr: false,
+		},
+		{
+			name: "signed direct",
+			args: args{
+				b:      []byte{0x42, 0x01, 0x70},
+				n:      2,
+				signed: true,
+			},
+			want:    []int64{-1, -2},
+			wantErr: false,
+		},
+		{
+			name: "truncated",
+			args: args{
+				b: []byte{0x5e, 0x03, 0x5c, 0xa1},
+				n: 4,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeORCIntRLEv2(tt.args.b, tt.args.n, tt.args.signed)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decodeORCIntRLEv2() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeORCIntRLEv2() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_decodeORCIntRLEv1(t *testing.T) {
+	type args struct {
+		b      []byte
+		n      int
+		signed bool
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []int64
+		wantErr bool
+	}{
+		{
+			name: "run",
+			args: args{
+				b: []byte{0x61, 0x00, 0x07},
+				n: 100,
+			},
+			want: func() []int64 {
+				values := make([]int64, 100)
+				for i := range values {
+					values[i] = 7
+				}
+				return values
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "run with delta",
+			args: args{
+				b:      []byte{0x00, 0xff, 0x14},
+				n:      3,
+				signed: true,
+			},
+			want:    []int64{10, 9, 8},
+			wantErr: false,
+		},
+		{
+			name: "literals",
+			args: args{
+				b: []byte{0xfb, 0x02, 0x03, 0x06, 0x07, 0x0b},
+				n: 5,
+			},
+			want:    []int64{2, 3, 6, 7, 11},
+			wantErr: false,
+		},
+		{
+			name: "truncated",
+			args: args{
+				b: []byte{0xfb, 0x02, 0x03},
+				n: 5,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeORCIntRLEv1(tt.args.b, tt.args.n, tt.args.signed)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decodeORCIntRLEv1() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeORCIntRLEv1() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_decodeORCBooleans(t *testing.T) {
+	type args struct {
+		b []byte
+		n int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []bool
+		wantErr bool
+	}{
+		{
+			name: "run",
+			args: args{
+				b: []byte{0x00, 0xff},
+				n: 20,
+			},
+			want: func() []bool {
+				values := make([]bool, 20)
+				for i := range values {
+					values[i] = true
+				}
+				return values
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "literals",
+			args: args{
+				b: []byte{0xfe, 0x80, 0x01},
+				n: 16,
+			},
+			want:    []bool{true, false, false, false, false, false, false, false, false, false, false, false, false, false, false, true},
+			wantErr: false,
+		},
+		{
+			name: "truncated",
+			args: args{
+				b: []byte{0xfe, 0x80},
+				n: 16,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeORCBooleans(tt.args.b, tt.args.n)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decodeORCBooleans() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeORCBooleans() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
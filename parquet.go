@@ -0,0 +1,789 @@
+package s3bytes
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"math/bits"
+	"strings"
+)
+
+// parquetMagic is the magic number at the start and the end of a Parquet file.
+const parquetMagic = "PAR1"
+
+// The physical types of Parquet used by the inventory reports.
+const (
+	parquetTypeBoolean   = 0
+	parquetTypeInt32     = 1
+	parquetTypeInt64     = 2
+	parquetTypeByteArray = 6
+)
+
+// The repetition types of Parquet.
+const (
+	parquetRequired = 0
+	parquetOptional = 1
+)
+
+// The encodings of Parquet.
+const (
+	parquetEncodingPlain                = 0
+	parquetEncodingPlainDictionary      = 2
+	parquetEncodingRLE                  = 3
+	parquetEncodingDeltaBinaryPacked    = 5
+	parquetEncodingDeltaLengthByteArray = 6
+	parquetEncodingDeltaByteArray       = 7
+	parquetEncodingRLEDictionary        = 8
+)
+
+// The page types of Parquet.
+const (
+	parquetPageData       = 0
+	parquetPageDictionary = 2
+	parquetPageDataV2     = 3
+)
+
+// The compression codecs of Parquet.
+const (
+	parquetCodecUncompressed = 0
+	parquetCodecSnappy       = 1
+	parquetCodecGzip         = 2
+	parquetCodecZstd         = 6
+)
+
+// parquetSchemaElement is the element of the schema in the file metadata.
+type parquetSchemaElement struct {
+	typ        int32
+	repetition int32
+	name       string
+}
+
+// parquetColumnChunk is the metadata of a column chunk in a row group.
+type parquetColumnChunk struct {
+	typ                  int32
+	path                 []string
+	codec                int32
+	numValues            int64
+	totalCompressedSize  int64
+	dataPageOffset       int64
+	dictionaryPageOffset int64
+}
+
+// parquetRowGroup is the metadata of a row group.
+type parquetRowGroup struct {
+	columns []parquetColumnChunk
+	numRows int64
+}
+
+// parquetMetadata is the file metadata in the footer of a Parquet file.
+type parquetMetadata struct {
+	schema    []parquetSchemaElement
+	rowGroups []parquetRowGroup
+}
+
+// parquetPageHeader is the header of a page in a column chunk.
+type parquetPageHeader struct {
+	typ              int32
+	uncompressedSize int32
+	compressedSize   int32
+	numValues        int32
+	encoding         int32
+	defEncoding      int32
+	defLength        int32
+	repLength        int32
+	isCompressed     bool
+}
+
+// parquetInventoryObjects returns the objects in the Parquet data file. The columns are read
+// one row group at a time, and the flat schema of the inventory reports is assumed.
+func parquetInventoryObjects(r io.ReaderAt, size int64) iter.Seq2[inventoryObject, error] {
+	return func(yield func(inventoryObject, error) bool) {
+		meta, err := readParquetMetadata(r, size)
+		if err != nil {
+			yield(inventoryObject{}, err)
+			return
+		}
+		fields := make(map[string]parquetSchemaElement)
+		for _, elem := range meta.schema[1:] {
+			fields[inventoryFieldName(elem.name)] = elem
+		}
+		if _, ok := fields[inventoryFieldKey]; !ok {
+			yield(inventoryObject{}, errors.New("parquet schema must contain key"))
+			return
+		}
+		if _, ok := fields[inventoryFieldSize]; !ok {
+			yield(inventoryObject{}, errors.New("parquet schema must contain size"))
+			return
+		}
+		for _, rg := range meta.rowGroups {
+			set := &inventoryColumnSet{n: int(rg.numRows)}
+			for _, chunk := range rg.columns {
+				if len(chunk.path) != 1 {
+					continue
+				}
+				field := inventoryFieldName(chunk.path[0])
+				kind, ok := inventoryFieldKinds[field]
+				if !ok {
+					continue
+				}
+				col, err := readParquetColumn(r, chunk, fields[field], kind)
+				if err != nil {
+					yield(inventoryObject{}, fmt.Errorf("failed to read parquet column %q: %w", chunk.path[0], err))
+					return
+				}
+				if col.len() != set.n {
+					yield(inventoryObject{}, fmt.Errorf("parquet column %q has %d values for %d rows", chunk.path[0], col.len(), set.n))
+					return
+				}
+				set.set(field, col)
+			}
+			for obj := range set.objects() {
+				if !yield(obj, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// readParquetMetadata reads the file metadata from the footer.
+func readParquetMetadata(r io.ReaderAt, size int64) (*parquetMetadata, error) {
+	if size < int64(2*len(parquetMagic)+4) {
+		return nil, errors.New("not a parquet file")
+	}
+	tail := make([]byte, 4+len(parquetMagic))
+	if _, err := r.ReadAt(tail, size-int64(len(tail))); err != nil {
+		return nil, err
+	}
+	if string(tail[4:]) != parquetMagic {
+		return nil, errors.New("not a parquet file")
+	}
+	n := int64(binary.LittleEndian.Uint32(tail))
+	if n > size-int64(len(tail)+len(parquetMagic)) {
+		return nil, errors.New("invalid parquet footer length")
+	}
+	footer := make([]byte, n)
+	if _, err := r.ReadAt(footer, size-int64(len(tail))-n); err != nil {
+		return nil, err
+	}
+	meta := &parquetMetadata{}
+	t := &thriftReader{b: footer}
+	err := t.readStruct(func(id int16, typ byte) error {
+		switch {
+		case id == 2 && typ == thriftList:
+			return t.readList(func(byte) error {
+				elem, err := readParquetSchemaElement(t)
+				meta.schema = append(meta.schema, elem)
+				return err
+			})
+		case id == 4 && typ == thriftList:
+			return t.readList(func(byte) error {
+				rg, err := readParquetRowGroup(t)
+				meta.rowGroups = append(meta.rowGroups, rg)
+				return err
+			})
+		default:
+			return t.skip(typ)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid parquet metadata: %w", err)
+	}
+	if len(meta.schema) == 0 {
+		return nil, errors.New("parquet metadata has no schema")
+	}
+	return meta, nil
+}
+
+// readParquetSchemaElement reads a SchemaElement struct.
+func readParquetSchemaElement(t *thriftReader) (parquetSchemaElement, error) {
+	var elem parquetSchemaElement
+	err := t.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == thriftI32:
+			elem.typ, err = t.i32()
+		case id == 3 && typ == thriftI32:
+			elem.repetition, err = t.i32()
+		case id == 4 && typ == thriftBinary:
+			var b []byte
+			b, err = t.binary()
+			elem.name = string(b)
+		default:
+			err = t.skip(typ)
+		}
+		return err
+	})
+	return elem, err
+}
+
+// readParquetRowGroup reads a RowGroup struct.
+func readParquetRowGroup(t *thriftReader) (parquetRowGroup, error) {
+	var rg parquetRowGroup
+	err := t.readStruct(func(id int16, typ byte) error {
+		switch {
+		case id == 1 && typ == thriftList:
+			return t.readList(func(byte) error {
+				chunk, err := readParquetColumnChunk(t)
+				rg.columns = append(rg.columns, chunk)
+				return err
+			})
+		case id == 3 && typ == thriftI64:
+			var err error
+			rg.numRows, err = t.i64()
+			return err
+		default:
+			return t.skip(typ)
+		}
+	})
+	return rg, err
+}
+
+// readParquetColumnChunk reads a ColumnChunk struct with its ColumnMetaData.
+func readParquetColumnChunk(t *thriftReader) (parquetColumnChunk, error) {
+	var chunk parquetColumnChunk
+	err := t.readStruct(func(id int16, typ byte) error {
+		if id != 3 || typ != thriftStruct {
+			return t.skip(typ)
+		}
+		return t.readStruct(func(id int16, typ byte) error {
+			var err error
+			switch {
+			case id == 1 && typ == thriftI32:
+				chunk.typ, err = t.i32()
+			case id == 3 && typ == thriftList:
+				err = t.readList(func(byte) error {
+					b, err := t.binary()
+					chunk.path = append(chunk.path, string(b))
+					return err
+				})
+			case id == 4 && typ == thriftI32:
+				chunk.codec, err = t.i32()
+			case id == 5 && typ == thriftI64:
+				chunk.numValues, err = t.i64()
+			case id == 7 && typ == thriftI64:
+				chunk.totalCompressedSize, err = t.i64()
+			case id == 9 && typ == thriftI64:
+				chunk.dataPageOffset, err = t.i64()
+			case id == 11 && typ == thriftI64:
+				chunk.dictionaryPageOffset, err = t.i64()
+			default:
+				err = t.skip(typ)
+			}
+			return err
+		})
+	})
+	return chunk, err
+}
+
+// readParquetPageHeader reads a PageHeader struct with the header of the data or dictionary page.
+func readParquetPageHeader(t *thriftReader) (parquetPageHeader, error) {
+	h := parquetPageHeader{isCompressed: true}
+	err := t.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == thriftI32:
+			h.typ, err = t.i32()
+		case id == 2 && typ == thriftI32:
+			h.uncompressedSize, err = t.i32()
+		case id == 3 && typ == thriftI32:
+			h.compressedSize, err = t.i32()
+		case (id == 5 || id == 7) && typ == thriftStruct:
+			// DataPageHeader and DictionaryPageHeader share the number of values and the encoding
+			err = t.readStruct(func(id int16, typ byte) error {
+				var err error
+				switch {
+				case id == 1 && typ == thriftI32:
+					h.numValues, err = t.i32()
+				case id == 2 && typ == thriftI32:
+					h.encoding, err = t.i32()
+				case id == 3 && typ == thriftI32:
+					h.defEncoding, err = t.i32()
+				default:
+					err = t.skip(typ)
+				}
+				return err
+			})
+		case id == 8 && typ == thriftStruct:
+			err = t.readStruct(func(id int16, typ byte) error {
+				var err error
+				switch {
+				case id == 1 && typ == thriftI32:
+					h.numValues, err = t.i32()
+				case id == 4 && typ == thriftI32:
+					h.encoding, err = t.i32()
+				case id == 5 && typ == thriftI32:
+					h.defLength, err = t.i32()
+				case id == 6 && typ == thriftI32:
+					h.repLength, err = t.i32()
+				case id == 7 && (typ == thriftTrue || typ == thriftFalse):
+					h.isCompressed = typ == thriftTrue
+				default:
+					err = t.skip(typ)
+				}
+				return err
+			})
+		default:
+			err = t.skip(typ)
+		}
+		return err
+	})
+	return h, err
+}
+
+// readParquetColumn reads all pages of the column chunk into a column of the kind.
+// Null values are found by the definition levels of the optional columns.
+func readParquetColumn(r io.ReaderAt, chunk parquetColumnChunk, elem parquetSchemaElement, kind inventoryKind) (*inventoryColumn, error) {
+	if err := checkParquetType(chunk.typ, kind); err != nil {
+		return nil, err
+	}
+	var maxDef int
+	switch elem.repetition {
+	case parquetRequired:
+	case parquetOptional:
+		maxDef = 1
+	default:
+		return nil, errors.New("repeated columns are not supported")
+	}
+	start := chunk.dataPageOffset
+	if chunk.dictionaryPageOffset > 0 && chunk.dictionaryPageOffset < start {
+		start = chunk.dictionaryPageOffset
+	}
+	b := make([]byte, chunk.totalCompressedSize)
+	if _, err := r.ReadAt(b, start); err != nil {
+		return nil, err
+	}
+	var (
+		col  = &inventoryColumn{kind: kind}
+		dict *inventoryColumn
+	)
+	for int64(col.len()) < chunk.numValues {
+		if len(b) == 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		t := &thriftReader{b: b}
+		h, err := readParquetPageHeader(t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid page header: %w", err)
+		}
+		if h.compressedSize < 0 || int(h.compressedSize) > len(b)-t.off {
+			return nil, io.ErrUnexpectedEOF
+		}
+		page := b[t.off : t.off+int(h.compressedSize)]
+		b = b[t.off+int(h.compressedSize):]
+		switch h.typ {
+		case parquetPageDictionary:
+			data, err := parquetDecompress(chunk.codec, page, int(h.uncompressedSize))
+			if err != nil {
+				return nil, err
+			}
+			if dict, _, err = decodeParquetPlain(data, chunk.typ, kind, int(h.numValues)); err != nil {
+				return nil, err
+			}
+		case parquetPageData, parquetPageDataV2:
+			var defs []uint32
+			var data []byte
+			if h.typ == parquetPageData {
+				if data, err = parquetDecompress(chunk.codec, page, int(h.uncompressedSize)); err != nil {
+					return nil, err
+				}
+				if maxDef > 0 {
+					if h.defEncoding != parquetEncodingRLE {
+						return nil, fmt.Errorf("unsupported definition level encoding: %d", h.defEncoding)
+					}
+					if len(data) < 4 {
+						return nil, io.ErrUnexpectedEOF
+					}
+					n := int(binary.LittleEndian.Uint32(data))
+					if n > len(data)-4 {
+						return nil, io.ErrUnexpectedEOF
+					}
+					if defs, _, err = decodeParquetHybrid(data[4:4+n], bits.Len(uint(maxDef)), int(h.numValues)); err != nil {
+						return nil, err
+					}
+					data = data[4+n:]
+				}
+			} else {
+				levels := int(h.repLength) + int(h.defLength)
+				if levels > len(page) || h.repLength < 0 || h.defLength < 0 {
+					return nil, io.ErrUnexpectedEOF
+				}
+				if maxDef > 0 {
+					if defs, _, err = decodeParquetHybrid(page[h.repLength:levels], bits.Len(uint(maxDef)), int(h.numValues)); err != nil {
+						return nil, err
+					}
+				}
+				data = page[levels:]
+				if h.isCompressed {
+					if data, err = parquetDecompress(chunk.codec, data, int(h.uncompressedSize)-levels); err != nil {
+						return nil, err
+					}
+				}
+			}
+			valid := make([]bool, h.numValues)
+			n := 0
+			for i := range valid {
+				valid[i] = defs == nil || int(defs[i]) == maxDef
+				if valid[i] {
+					n++
+				}
+			}
+			values, err := decodeParquetValues(data, chunk.typ, kind, h.encoding, n, dict)
+			if err != nil {
+				return nil, err
+			}
+			if err := col.appendRows(values, valid); err != nil {
+				return nil, err
+			}
+		default:
+			// index pages carry no values
+		}
+	}
+	return col, nil
+}
+
+// checkParquetType checks that the physical type can be read as the kind.
+func checkParquetType(typ int32, kind inventoryKind) error {
+	switch {
+	case kind == inventoryKindString && typ == parquetTypeByteArray,
+		kind == inventoryKindInt && (typ == parquetTypeInt64 || typ == parquetTypeInt32),
+		kind == inventoryKindBool && typ == parquetTypeBoolean:
+		return nil
+	default:
+		return fmt.Errorf("unexpected physical type: %d", typ)
+	}
+}
+
+// parquetDecompress decompresses the page with the codec of the column chunk.
+func parquetDecompress(codec int32, b []byte, size int) ([]byte, error) {
+	switch codec {
+	case parquetCodecUncompressed:
+		return b, nil
+	case parquetCodecSnappy:
+		return decompressSnappy(b)
+	case parquetCodecGzip:
+		return decompressGzip(b, size)
+	case parquetCodecZstd:
+		return decompressZstd(b, size)
+	default:
+		return nil, fmt.Errorf("unsupported parquet compression codec: %d", codec)
+	}
+}
+
+// decodeParquetValues decodes the n non-null values of the page in the encoding.
+func decodeParquetValues(b []byte, typ int32, kind inventoryKind, encoding int32, n int, dict *inventoryColumn) (*inventoryColumn, error) {
+	switch encoding {
+	case parquetEncodingPlain:
+		col, _, err := decodeParquetPlain(b, typ, kind, n)
+		return col, err
+	case parquetEncodingPlainDictionary, parquetEncodingRLEDictionary:
+		if dict == nil {
+			return nil, errors.New("dictionary page not found")
+		}
+		if n == 0 {
+			return &inventoryColumn{kind: kind}, nil
+		}
+		if len(b) == 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		indices, _, err := decodeParquetHybrid(b[1:], int(b[0]), n)
+		if err != nil {
+			return nil, err
+		}
+		col := &inventoryColumn{kind: kind}
+		for _, i := range indices {
+			if int(i) >= dict.len() {
+				return nil, fmt.Errorf("dictionary index out of range: %d", i)
+			}
+			col.appendValue(dict, int(i))
+		}
+		return col, nil
+	case parquetEncodingRLE:
+		if kind != inventoryKindBool {
+			return nil, fmt.Errorf("unsupported encoding for physical type %d: %d", typ, encoding)
+		}
+		if len(b) < 4 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		values, _, err := decodeParquetHybrid(b[4:], 1, n)
+		if err != nil {
+			return nil, err
+		}
+		col := &inventoryColumn{kind: kind}
+		for _, v := range values {
+			col.bools = append(col.bools, v != 0)
+		}
+		return col, nil
+	case parquetEncodingDeltaBinaryPacked:
+		if kind != inventoryKindInt {
+			return nil, fmt.Errorf("unsupported encoding for physical type %d: %d", typ, encoding)
+		}
+		values, _, err := decodeParquetDelta(b)
+		if err != nil {
+			return nil, err
+		}
+		return &inventoryColumn{kind: kind, ints: values}, nil
+	case parquetEncodingDeltaLengthByteArray, parquetEncodingDeltaByteArray:
+		if kind != inventoryKindString {
+			return nil, fmt.Errorf("unsupported encoding for physical type %d: %d", typ, encoding)
+		}
+		var (
+			values []string
+			err    error
+		)
+		if encoding == parquetEncodingDeltaLengthByteArray {
+			values, _, err = decodeParquetDeltaLength(b)
+		} else {
+			values, err = decodeParquetDeltaByteArray(b)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &inventoryColumn{kind: kind, strings: values}, nil
+	default:
+		return nil, fmt.Errorf("unsupported parquet encoding: %d", encoding)
+	}
+}
+
+// decodeParquetPlain decodes the n values in the plain encoding, and returns the number of bytes read.
+func decodeParquetPlain(b []byte, typ int32, kind inventoryKind, n int) (*inventoryColumn, int, error) {
+	if err := checkParquetType(typ, kind); err != nil {
+		return nil, 0, err
+	}
+	col := &inventoryColumn{kind: kind}
+	off := 0
+	switch typ {
+	case parquetTypeBoolean:
+		if (n+7)/8 > len(b) {
+			return nil, 0, io.ErrUnexpectedEOF
+		}
+		for i := range n {
+			col.bools = append(col.bools, b[i/8]>>(i%8)&1 == 1)
+		}
+		off = (n + 7) / 8
+	case parquetTypeInt32:
+		if 4*n > len(b) {
+			return nil, 0, io.ErrUnexpectedEOF
+		}
+		for i := range n {
+			col.ints = append(col.ints, int64(int32(binary.LittleEndian.Uint32(b[4*i:]))))
+		}
+		off = 4 * n
+	case parquetTypeInt64:
+		if 8*n > len(b) {
+			return nil, 0, io.ErrUnexpectedEOF
+		}
+		for i := range n {
+			col.ints = append(col.ints, int64(binary.LittleEndian.Uint64(b[8*i:])))
+		}
+		off = 8 * n
+	case parquetTypeByteArray:
+		for range n {
+			if off+4 > len(b) {
+				return nil, 0, io.ErrUnexpectedEOF
+			}
+			size := int(binary.LittleEndian.Uint32(b[off:]))
+			off += 4
+			if size > len(b)-off {
+				return nil, 0, io.ErrUnexpectedEOF
+			}
+			col.strings = append(col.strings, string(b[off:off+size]))
+			off += size
+		}
+	}
+	return col, off, nil
+}
+
+// decodeParquetHybrid decodes the n values in the RLE/bit-packing hybrid encoding of the bit width,
+// and returns the number of bytes read. The values of the last bit-packed run beyond n are dropped.
+func decodeParquetHybrid(b []byte, width, n int) ([]uint32, int, error) {
+	if width > 32 {
+		return nil, 0, fmt.Errorf("invalid bit width: %d", width)
+	}
+	values := make([]uint32, 0, n)
+	off := 0
+	for len(values) < n {
+		h, k := binary.Uvarint(b[off:])
+		if k <= 0 {
+			return nil, 0, io.ErrUnexpectedEOF
+		}
+		off += k
+		if h&1 == 0 {
+			size := (width + 7) / 8
+			if size > len(b)-off {
+				return nil, 0, io.ErrUnexpectedEOF
+			}
+			var v uint32
+			for i := range size {
+				v |= uint32(b[off+i]) << (8 * i)
+			}
+			off += size
+			for range min(int(h>>1), n-len(values)) {
+				values = append(values, v)
+			}
+			continue
+		}
+		count := int(h>>1) * 8
+		size := count * width / 8
+		// the last run may be cut short by the end of the levels
+		size = min(size, len(b)-off)
+		for _, v := range unpackBits(b[off:off+size], width, min(count, n-len(values))) {
+			values = append(values, uint32(v))
+		}
+		off += size
+	}
+	return values, off, nil
+}
+
+// decodeParquetDelta decodes the integers in the DELTA_BINARY_PACKED encoding,
+// and returns the number of bytes read.
+func decodeParquetDelta(b []byte) ([]int64, int, error) {
+	off := 0
+	uvarint := func() (uint64, error) {
+		v, k := binary.Uvarint(b[off:])
+		if k <= 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		off += k
+		return v, nil
+	}
+	varint := func() (int64, error) {
+		v, k := binary.Varint(b[off:])
+		if k <= 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		off += k
+		return v, nil
+	}
+	blockSize, err := uvarint()
+	if err != nil {
+		return nil, 0, err
+	}
+	miniblocks, err := uvarint()
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := uvarint()
+	if err != nil {
+		return nil, 0, err
+	}
+	first, err := varint()
+	if err != nil {
+		return nil, 0, err
+	}
+	if miniblocks == 0 || blockSize%miniblocks != 0 || blockSize/miniblocks%8 != 0 {
+		return nil, 0, fmt.Errorf("invalid delta block: %d values in %d miniblocks", blockSize, miniblocks)
+	}
+	// the count is not trusted for the allocation, since the blocks run out with the buffer anyway
+	values := make([]int64, 0, min(total, uint64(len(b))))
+	if total == 0 {
+		return values, off, nil
+	}
+	values = append(values, first)
+	per := int(blockSize / miniblocks)
+	for uint64(len(values)) < total {
+		minDelta, err := varint()
+		if err != nil {
+			return nil, 0, err
+		}
+		if int(miniblocks) > len(b)-off {
+			return nil, 0, io.ErrUnexpectedEOF
+		}
+		widths := b[off : off+int(miniblocks)]
+		off += int(miniblocks)
+		for _, width := range widths {
+			if uint64(len(values)) >= total {
+				break
+			}
+			if width > 64 {
+				return nil, 0, fmt.Errorf("invalid bit width: %d", width)
+			}
+			size := per * int(width) / 8
+			if size > len(b)-off {
+				return nil, 0, io.ErrUnexpectedEOF
+			}
+			for _, v := range unpackBits(b[off:off+size], int(width), per) {
+				if uint64(len(values)) >= total {
+					break
+				}
+				values = append(values, values[len(values)-1]+minDelta+int64(v))
+			}
+			off += size
+		}
+	}
+	return values, off, nil
+}
+
+// decodeParquetDeltaLength decodes the byte arrays in the DELTA_LENGTH_BYTE_ARRAY encoding,
+// and returns the number of bytes read.
+func decodeParquetDeltaLength(b []byte) ([]string, int, error) {
+	lengths, off, err := decodeParquetDelta(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	values := make([]string, 0, len(lengths))
+	for _, n := range lengths {
+		if n < 0 || n > int64(len(b)-off) {
+			return nil, 0, io.ErrUnexpectedEOF
+		}
+		values = append(values, string(b[off:off+int(n)]))
+		off += int(n)
+	}
+	return values, off, nil
+}
+
+// decodeParquetDeltaByteArray decodes the byte arrays in the DELTA_BYTE_ARRAY encoding,
+// in which each value is stored as the length of the prefix shared with the previous value and the suffix.
+func decodeParquetDeltaByteArray(b []byte) ([]string, error) {
+	prefixes, off, err := decodeParquetDelta(b)
+	if err != nil {
+		return nil, err
+	}
+	suffixes, _, err := decodeParquetDeltaLength(b[off:])
+	if err != nil {
+		return nil, err
+	}
+	if len(prefixes) != len(suffixes) {
+		return nil, errors.New("mismatched numbers of prefixes and suffixes")
+	}
+	values := make([]string, len(suffixes))
+	prev := ""
+	for i, suffix := range suffixes {
+		if prefixes[i] < 0 || prefixes[i] > int64(len(prev)) {
+			return nil, fmt.Errorf("invalid prefix length: %d", prefixes[i])
+		}
+		var sb strings.Builder
+		sb.WriteString(prev[:prefixes[i]])
+		sb.WriteString(suffix)
+		values[i] = sb.String()
+		prev = values[i]
+	}
+	return values, nil
+}
+
+// unpackBits unpacks the n values of the bit width packed from the least significant bit.
+func unpackBits(b []byte, width, n int) []uint64 {
+	values := make([]uint64, n)
+	if width == 0 {
+		return values
+	}
+	mask := uint64(1)<<width - 1
+	for i := range values {
+		pos := i * width
+		if width <= 56 && pos/8+8 <= len(b) {
+			values[i] = binary.LittleEndian.Uint64(b[pos/8:]) >> (pos % 8) & mask
+			continue
+		}
+		var v uint64
+		for j := range width {
+			pos := i*width + j
+			if pos/8 < len(b) && b[pos/8]>>(pos%8)&1 == 1 {
+				v |= 1 << j
+			}
+		}
+		values[i] = v
+	}
+	return values
+}
@@ -0,0 +1,631 @@
+package s3bytes
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"math/bits"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// testParquetOptions is the layout of a test Parquet file.
+type testParquetOptions struct {
+	codec      int32
+	v2         bool
+	dictionary bool
+	groupSize  int
+	pageSize   int
+}
+
+// writeTestParquet encodes the optional columns into a Parquet file. The strings are encoded
+// with a dictionary if specified, and the definition levels are bit-packed.
+func writeTestParquet(t *testing.T, cols []testInventoryColumn, opts testParquetOptions) []byte {
+	t.Helper()
+	var (
+		buf       = bytes.NewBufferString(parquetMagic)
+		n         = len(cols[0].values)
+		rowGroups = &thriftWriter{}
+		groups    = 0
+	)
+	for start := 0; start < n; start += opts.groupSize {
+		end := min(start+opts.groupSize, n)
+		groups++
+		rowGroups.begin()
+		rowGroups.list(1, thriftStruct, len(cols))
+		for _, col := range cols {
+			typ := testParquetType(col.kind)
+			values := col.values[start:end]
+			var (
+				dictOffset int64
+				dict       map[string]int
+			)
+			if opts.dictionary && col.kind == inventoryKindString {
+				dict = make(map[string]int)
+				var entries []any
+				for _, v := range values {
+					if s, ok := v.(string); ok {
+						if _, ok := dict[s]; !ok {
+							dict[s] = len(dict)
+							entries = append(entries, s)
+						}
+					}
+				}
+				dictOffset = int64(buf.Len())
+				page := encodeTestParquetPlain(entries)
+				h := &thriftWriter{}
+				h.begin()
+				h.i32(1, parquetPageDictionary)
+				h.i32(2, int32(len(page)))
+				compressed := compressTestParquet(t, opts.codec, page)
+				h.i32(3, int32(len(compressed)))
+				h.structField(7)
+				h.i32(1, int32(len(entries)))
+				h.i32(2, parquetEncodingPlain)
+				h.end()
+				h.end()
+				buf.Write(h.Bytes())
+				buf.Write(compressed)
+			}
+			dataOffset := int64(buf.Len())
+			for pageStart := 0; pageStart < len(values); pageStart += opts.pageSize {
+				page := values[pageStart:min(pageStart+opts.pageSize, len(values))]
+				var (
+					defs    []byte
+					present []any
+				)
+				for _, v := range page {
+					if v != nil {
+						present = append(present, v)
+					}
+				}
+				for i := 0; i < len(page); i += 8 {
+					var b byte
+					for j := i; j < min(i+8, len(page)); j++ {
+						if page[j] != nil {
+							b |= 1 << (j - i)
+						}
+					}
+					defs = append(defs, b)
+				}
+				defs = append(binary.AppendUvarint(nil, uint64(len(defs))<<1|1), defs...)
+				encoding := int32(parquetEncodingPlain)
+				var data []byte
+				if dict != nil {
+					encoding = parquetEncodingRLEDictionary
+					data = []byte{8}
+					for _, v := range present {
+						data = append(data, 2, byte(dict[v.(string)]))
+					}
+				} else {
+					data = encodeTestParquetPlain(present)
+				}
+				h := &thriftWriter{}
+				h.begin()
+				if opts.v2 {
+					compressed := compressTestParquet(t, opts.codec, data)
+					h.i32(1, parquetPageDataV2)
+					h.i32(2, int32(len(defs)+len(data)))
+					h.i32(3, int32(len(defs)+len(compressed)))
+					h.structField(8)
+					h.i32(1, int32(len(page)))
+					h.i32(2, int32(len(page)-len(present)))
+					h.i32(3, int32(len(page)))
+					h.i32(4, encoding)
+					h.i32(5, int32(len(defs)))
+					h.i32(6, 0)
+					h.end()
+					h.end()
+					buf.Write(h.Bytes())
+					buf.Write(defs)
+					buf.Write(compressed)
+					continue
+				}
+				body := binary.LittleEndian.AppendUint32(nil, uint32(len(defs)))
+				body = append(append(body, defs...), data...)
+				compressed := compressTestParquet(t, opts.codec, body)
+				h.i32(1, parquetPageData)
+				h.i32(2, int32(len(body)))
+				h.i32(3, int32(len(compressed)))
+				h.structField(5)
+				h.i32(1, int32(len(page)))
+				h.i32(2, encoding)
+				h.i32(3, parquetEncodingRLE)
+				h.i32(4, parquetEncodingRLE)
+				h.end()
+				h.end()
+				buf.Write(h.Bytes())
+				buf.Write(compressed)
+			}
+			start := dataOffset
+			if dict != nil {
+				start = dictOffset
+			}
+			rowGroups.begin()
+			rowGroups.i64(2, start)
+			rowGroups.structField(3)
+			rowGroups.i32(1, typ)
+			rowGroups.list(2, thriftI32, 1)
+			rowGroups.varint(parquetEncodingPlain)
+			rowGroups.list(3, thriftBinary, 1)
+			rowGroups.raw([]byte(col.name))
+			rowGroups.i32(4, opts.codec)
+			rowGroups.i64(5, int64(len(values)))
+			rowGroups.i64(6, int64(buf.Len())-start)
+			rowGroups.i64(7, int64(buf.Len())-start)
+			rowGroups.i64(9, dataOffset)
+			if dict != nil {
+				rowGroups.i64(11, dictOffset)
+			}
+			rowGroups.end()
+			rowGroups.end()
+		}
+		rowGroups.i64(2, int64(buf.Len()))
+		rowGroups.i64(3, int64(end-start))
+		rowGroups.end()
+	}
+	meta := &thriftWriter{}
+	meta.begin()
+	meta.i32(1, 1)
+	meta.list(2, thriftStruct, len(cols)+1)
+	meta.begin()
+	meta.binary(4, []byte("schema"))
+	meta.i32(5, int32(len(cols)))
+	meta.end()
+	for _, col := range cols {
+		meta.begin()
+		meta.i32(1, testParquetType(col.kind))
+		meta.i32(3, parquetOptional)
+		meta.binary(4, []byte(col.name))
+		meta.end()
+	}
+	meta.i64(3, int64(n))
+	meta.list(4, thriftStruct, groups)
+	meta.Write(rowGroups.Bytes())
+	meta.binary(6, []byte("s3bytes test"))
+	meta.end()
+	buf.Write(meta.Bytes())
+	buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(meta.Len())))
+	buf.WriteString(parquetMagic)
+	return buf.Bytes()
+}
+
+// testParquetType returns the physical type of the kind.
+func testParquetType(kind inventoryKind) int32 {
+	switch kind {
+	case inventoryKindString:
+		return parquetTypeByteArray
+	case inventoryKindInt:
+		return parquetTypeInt64
+	default:
+		return parquetTypeBoolean
+	}
+}
+
+// encodeTestParquetPlain encodes the non-null values in the plain encoding.
+func encodeTestParquetPlain(values []any) []byte {
+	var b []byte
+	var bits []bool
+	for _, v := range values {
+		switch v := v.(type) {
+		case string:
+			b = binary.LittleEndian.AppendUint32(b, uint32(len(v)))
+			b = append(b, v...)
+		case int64:
+			b = binary.LittleEndian.AppendUint64(b, uint64(v))
+		case bool:
+			bits = append(bits, v)
+		}
+	}
+	for i := 0; i < len(bits); i += 8 {
+		var c byte
+		for j := i; j < min(i+8, len(bits)); j++ {
+			if bits[j] {
+				c |= 1 << (j - i)
+			}
+		}
+		b = append(b, c)
+	}
+	return b
+}
+
+// compressTestParquet compresses the page with the codec.
+func compressTestParquet(t *testing.T, codec int32, b []byte) []byte {
+	t.Helper()
+	switch codec {
+	case parquetCodecSnappy:
+		return snappy.Encode(nil, b)
+	case parquetCodecGzip:
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(b); err != nil {
+			t.Fatal(err)
+		}
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	case parquetCodecZstd:
+		enc, err := zstd.NewWriter(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer enc.Close()
+		return enc.EncodeAll(b, nil)
+	default:
+		return b
+	}
+}
+
+func Test_parquetInventoryObjects(t *testing.T) {
+	cols := testInventoryColumns(
+		`"bucket0","a/1.txt","v1","true","false","10","STANDARD",""`,
+		`"bucket0","a/1.txt","v0","false","false","20","STANDARD",""`,
+		`"bucket0","a/1.txt","v2","false","true","","",""`,
+		`"bucket0","a/b/2.txt","","true","false","100","INTELLIGENT_TIERING","INFREQUENT"`,
+		`"bucket0","3.txt","","true","false","1000","GLACIER",""`,
+		`"bucket0","a/b/4.txt","","true","false","10000","INTELLIGENT_TIERING","ARCHIVE"`,
+		`"bucket0","a/2.txt","","true","false","0","STANDARD",""`,
+		`"bucket0","a+b.txt","","true","false","5","STANDARD",""`,
+		`"bucket0","a/1.txt","v3","false","false","30","STANDARD_IA",""`,
+		`"bucket0","c/5.txt","","true","false","1","STANDARD",""`,
+	)
+	want := []inventoryObject{
+		{key: "a/1.txt", size: 10, hasSize: true, storageClass: "STANDARD"},
+		{key: "a/1.txt", size: 20, hasSize: true, storageClass: "STANDARD"},
+		{key: "a/1.txt", deleteMarker: true},
+		{key: "a/b/2.txt", size: 100, hasSize: true, storageClass: "INTELLIGENT_TIERING", accessTier: "INFREQUENT"},
+		{key: "3.txt", size: 1000, hasSize: true, storageClass: "GLACIER"},
+		{key: "a/b/4.txt", size: 10000, hasSize: true, storageClass: "INTELLIGENT_TIERING", accessTier: "ARCHIVE"},
+		{key: "a/2.txt", size: 0, hasSize: true, storageClass: "STANDARD"},
+		{key: "a+b.txt", size: 5, hasSize: true, storageClass: "STANDARD"},
+		{key: "a/1.txt", size: 30, hasSize: true, storageClass: "STANDARD_IA"},
+		{key: "c/5.txt", size: 1, hasSize: true, storageClass: "STANDARD"},
+	}
+	tests := []struct {
+		name    string
+		b       []byte
+		want    []inventoryObject
+		wantErr bool
+	}{
+		{
+			name:    "plain uncompressed",
+			b:       writeTestParquet(t, cols, testParquetOptions{codec: parquetCodecUncompressed, groupSize: 10, pageSize: 10}),
+			want:    want,
+			wantErr: false,
+		},
+		{
+			name:    "dictionary snappy with row groups",
+			b:       writeTestParquet(t, cols, testParquetOptions{codec: parquetCodecSnappy, dictionary: true, groupSize: 4, pageSize: 4}),
+			want:    want,
+			wantErr: false,
+		},
+		{
+			name:    "plain gzip with pages",
+			b:       writeTestParquet(t, cols, testParquetOptions{codec: parquetCodecGzip, groupSize: 10, pageSize: 3}),
+			want:    want,
+			wantErr: false,
+		},
+		{
+			name:    "data page v2 dictionary zstd",
+			b:       writeTestParquet(t, cols, testParquetOptions{codec: parquetCodecZstd, v2: true, dictionary: true, groupSize: 6, pageSize: 5}),
+			want:    want,
+			wantErr: false,
+		},
+		{
+			name:    "data page v2 plain uncompressed",
+			b:       writeTestParquet(t, cols, testParquetOptions{codec: parquetCodecUncompressed, v2: true, groupSize: 10, pageSize: 10}),
+			want:    want,
+			wantErr: false,
+		},
+		{
+			name:    "no size",
+			b:       writeTestParquet(t, cols[:2], testParquetOptions{codec: parquetCodecUncompressed, groupSize: 10, pageSize: 10}),
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported codec",
+			b:       writeTestParquet(t, cols, testParquetOptions{codec: 3, groupSize: 10, pageSize: 10}),
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "not parquet",
+			b:       []byte("bucket,key\n"),
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []inventoryObject
+			var err error
+			for obj, e := range parquetInventoryObjects(bytes.NewReader(tt.b), int64(len(tt.b))) {
+				if e != nil {
+					err = e
+					break
+				}
+				got = append(got, obj)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parquetInventoryObjects() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parquetInventoryObjects() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_decodeParquetHybrid(t *testing.T) {
+	type args struct {
+		b     []byte
+		width int
+		n     int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []uint32
+		wantErr bool
+	}{
+		{
+			name: "bit-packed",
+			args: args{
+				b:     []byte{0x03, 0x88, 0xc6, 0xfa},
+				width: 3,
+				n:     8,
+			},
+			want:    []uint32{0, 1, 2, 3, 4, 5, 6, 7},
+			wantErr: false,
+		},
+		{
+			name: "rle",
+			args: args{
+				b:     []byte{0x0a, 0x05, 0x06, 0x01},
+				width: 3,
+				n:     8,
+			},
+			want:    []uint32{5, 5, 5, 5, 5, 1, 1, 1},
+			wantErr: false,
+		},
+		{
+			name: "rle of two bytes",
+			args: args{
+				b:     []byte{0x04, 0x2c, 0x01},
+				width: 9,
+				n:     2,
+			},
+			want:    []uint32{300, 300},
+			wantErr: false,
+		},
+		{
+			name: "padded bit-packed run",
+			args: args{
+				b:     []byte{0x03, 0x88, 0xc6, 0xfa},
+				width: 3,
+				n:     5,
+			},
+			want:    []uint32{0, 1, 2, 3, 4},
+			wantErr: false,
+		},
+		{
+			name: "truncated",
+			args: args{
+				b:     []byte{0x0a},
+				width: 3,
+				n:     5,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := decodeParquetHybrid(tt.args.b, tt.args.width, tt.args.n)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decodeParquetHybrid() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeParquetHybrid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_decodeParquetDelta(t *testing.T) {
+	tests := []struct {
+		name    string
+		b       []byte
+		want    []int64
+		wantN   int
+		wantErr bool
+	}{
+		{
+			name:    "fixed delta",
+			b:       []byte{0x80, 0x01, 0x04, 0x05, 0x02, 0x02, 0x00, 0x00, 0x00, 0x00},
+			want:    []int64{1, 2, 3, 4, 5},
+			wantN:   10,
+			wantErr: false,
+		},
+		{
+			name:    "packed deltas",
+			b:       []byte{0x80, 0x01, 0x04, 0x08, 0x0e, 0x03, 0x02, 0x00, 0x00, 0x00, 0xc0, 0x3f, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			want:    []int64{7, 5, 3, 1, 2, 3, 4, 5},
+			wantN:   18,
+			wantErr: false,
+		},
+		{
+			name:    "single value",
+			b:       []byte{0x80, 0x01, 0x04, 0x01, 0x01},
+			want:    []int64{-1},
+			wantN:   5,
+			wantErr: false,
+		},
+		{
+			name:    "invalid block",
+			b:       []byte{0x80, 0x01, 0x03, 0x01, 0x01},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "truncated",
+			b:       []byte{0x80, 0x01, 0x04, 0x08, 0x0e, 0x03, 0x02, 0x00, 0x00, 0x00, 0xc0},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, n, err := decodeParquetDelta(tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decodeParquetDelta() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeParquetDelta() = %v, want %v", got, tt.want)
+			}
+			if n != tt.wantN {
+				t.Errorf("decodeParquetDelta() n = %v, want %v", n, tt.wantN)
+			}
+		})
+	}
+}
+
+// encodeTestParquetDelta encodes the integers in the DELTA_BINARY_PACKED encoding
+// with blocks of 128 values in 4 miniblocks.
+func encodeTestParquetDelta(values []int64) []byte {
+	b := binary.AppendUvarint(nil, 128)
+	b = binary.AppendUvarint(b, 4)
+	b = binary.AppendUvarint(b, uint64(len(values)))
+	if len(values) == 0 {
+		return binary.AppendVarint(b, 0)
+	}
+	b = binary.AppendVarint(b, values[0])
+	deltas := make([]int64, 0, len(values))
+	for i := 1; i < len(values); i++ {
+		deltas = append(deltas, values[i]-values[i-1])
+	}
+	for start := 0; start < len(deltas); start += 128 {
+		block := deltas[start:min(start+128, len(deltas))]
+		minDelta := slices.Min(block)
+		b = binary.AppendVarint(b, minDelta)
+		var (
+			widths = make([]byte, 4)
+			body   []byte
+		)
+		for m := range 4 {
+			if m*32 >= len(block) {
+				break
+			}
+			mini := make([]uint64, 32)
+			for i, d := range block[m*32 : min(m*32+32, len(block))] {
+				mini[i] = uint64(d - minDelta)
+				widths[m] = max(widths[m], byte(bits.Len64(mini[i])))
+			}
+			packed := make([]byte, 32*int(widths[m])/8)
+			for i, v := range mini {
+				for j := range int(widths[m]) {
+					pos := i*int(widths[m]) + j
+					packed[pos/8] |= byte(v>>j&1) << (pos % 8)
+				}
+			}
+			body = append(body, packed...)
+		}
+		b = append(append(b, widths...), body...)
+	}
+	return b
+}
+
+func Test_decodeParquetDeltaLength(t *testing.T) {
+	b := encodeTestParquetDelta([]int64{5, 0, 5})
+	b = append(b, "helloworld"...)
+	tests := []struct {
+		name    string
+		b       []byte
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "lengths",
+			b:       b,
+			want:    []string{"hello", "", "world"},
+			wantErr: false,
+		},
+		{
+			name:    "truncated",
+			b:       b[:len(b)-1],
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := decodeParquetDeltaLength(tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decodeParquetDeltaLength() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeParquetDeltaLength() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_decodeParquetDeltaByteArray(t *testing.T) {
+	// the example in the specification, with the prefix lengths 0, 4, 4, 4 and the suffixes "axis", "le", "ly", "s"
+	b := encodeTestParquetDelta([]int64{0, 4, 4, 4})
+	b = append(b, encodeTestParquetDelta([]int64{4, 2, 2, 1})...)
+	b = append(b, "axislelys"...)
+	invalid := encodeTestParquetDelta([]int64{0, 5})
+	invalid = append(invalid, encodeTestParquetDelta([]int64{4, 2})...)
+	invalid = append(invalid, "axisle"...)
+	tests := []struct {
+		name    string
+		b       []byte
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "prefixes",
+			b:       b,
+			want:    []string{"axis", "axisle", "axisly", "axiss"},
+			wantErr: false,
+		},
+		{
+			name:    "prefix longer than the previous value",
+			b:       invalid,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "truncated",
+			b:       b[:len(b)-1],
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeParquetDeltaByteArray(tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decodeParquetDeltaByteArray() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeParquetDeltaByteArray() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package s3bytes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/nekrassov01/filter"
+	"gopkg.in/yaml.v3"
+)
+
+// policyHeader is the columns appended to the header when the metrics are evaluated against a policy.
+var policyHeader = []string{
+	"Compliance",
+	"Rule",
+}
+
+// Policy represents the quotas declared in a policy file. Each metric is evaluated against
+// all the rules that match it, and annotated with the worst status among them.
+type Policy struct {
+	Rules []*PolicyRule `json:"rules" yaml:"rules"`
+}
+
+// PolicyRule represents a quota for the metrics that match all of its conditions.
+// Bucket is a regular expression for the bucket names, Region is the exact region,
+// Tags is the exact values of the bucket tags, and Condition is a filter expression
+// in the same grammar as the filter flag. Empty conditions match any metric.
+// Bytes applies to BucketSizeBytes and Objects applies to NumberOfObjects.
+type PolicyRule struct {
+	Name      string            `json:"name" yaml:"name"`
+	Bucket    string            `json:"bucket,omitempty" yaml:"bucket,omitempty"`
+	Region    string            `json:"region,omitempty" yaml:"region,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Condition string            `json:"condition,omitempty" yaml:"condition,omitempty"`
+	Bytes     *PolicyLimits     `json:"bytes,omitempty" yaml:"bytes,omitempty"`
+	Objects   *PolicyLimits     `json:"objects,omitempty" yaml:"objects,omitempty"`
+
+	bucketPattern *regexp.Regexp
+	conditionExpr filterExpr
+}
+
+// PolicyLimits represents the warning and critical levels of a quota. The levels accept
+// the units of sizes such as "4TiB" and "500GB" as well as plain numbers, and either may be empty.
+type PolicyLimits struct {
+	Warning  string `json:"warning,omitempty" yaml:"warning,omitempty"`
+	Critical string `json:"critical,omitempty" yaml:"critical,omitempty"`
+
+	warning  *float64
+	critical *float64
+}
+
+// PolicyReport represents the number of metrics for each compliance status.
+type PolicyReport struct {
+	OK       int
+	Warning  int
+	Critical int
+	None     int
+}
+
+// LoadPolicy reads the policy from the file, which is parsed as YAML if the extension
+// is ".yaml" or ".yml", and as JSON otherwise.
+func LoadPolicy(path string) (*Policy, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return ParsePolicyYAML(b)
+	default:
+		return ParsePolicy(b)
+	}
+}
+
+// ParsePolicy parses the policy in JSON. Unknown keys are rejected so that typos do not
+// silently disable a quota.
+func ParsePolicy(b []byte) (*Policy, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	var policy Policy
+	if err := dec.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	if err := policy.compile(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// ParsePolicyYAML parses the policy in YAML with the same keys as JSON. Unknown keys are rejected
+// as well as in JSON.
+func ParsePolicyYAML(b []byte) (*Policy, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	var policy Policy
+	if err := dec.Decode(&policy); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	if err := policy.compile(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// compile names the unnamed rules and compiles all the rules of the policy.
+func (p *Policy) compile() error {
+	if len(p.Rules) == 0 {
+		return errors.New("policy has no rules")
+	}
+	for i, rule := range p.Rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule%d", i)
+		}
+		if err := rule.compile(); err != nil {
+			return fmt.Errorf("invalid policy rule %q: %w", rule.Name, err)
+		}
+	}
+	return nil
+}
+
+// TagKeys returns the sorted keys of the bucket tags that the rules match,
+// which must be fetched for the rules to apply.
+func (p *Policy) TagKeys() []string {
+	var keys []string
+	for _, rule := range p.Rules {
+		for key := range rule.Tags {
+			if !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// compile parses the conditions and the limits of the rule.
+func (r *PolicyRule) compile() error {
+	if r.Bucket != "" {
+		pattern, err := regexp.Compile(r.Bucket)
+		if err != nil {
+			return fmt.Errorf("invalid bucket pattern: %w", err)
+		}
+		r.bucketPattern = pattern
+	}
+	for key := range r.Tags {
+		if key == "" {
+			return errors.New("empty tag key")
+		}
+	}
+	if r.Condition != "" {
+		expr, err := filter.Parse(r.Condition)
+		if err != nil {
+			return fmt.Errorf("failed to parse condition: %w", err)
+		}
+		r.conditionExpr = expr
+	}
+	if r.Bytes == nil && r.Objects == nil {
+		return errors.New("no limits: specify bytes or objects")
+	}
+	for _, limits := range []*PolicyLimits{r.Bytes, r.Objects} {
+		if limits == nil {
+			continue
+		}
+		if err := limits.compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compile parses the levels of the limits.
+func (l *PolicyLimits) compile() error {
+	if l.Warning == "" && l.Critical == "" {
+		return errors.New("no levels: specify warning or critical")
+	}
+	parse := func(s string) (*float64, error) {
+		if s == "" {
+			return nil, nil
+		}
+		n, err := humanize.ParseBytes(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid level: %q", s)
+		}
+		v := float64(n)
+		return &v, nil
+	}
+	var err error
+	if l.warning, err = parse(l.Warning); err != nil {
+		return err
+	}
+	if l.critical, err = parse(l.Critical); err != nil {
+		return err
+	}
+	return nil
+}
+
+// match reports whether the rule applies to the metric.
+func (r *PolicyRule) match(metric *Metric) (bool, error) {
+	if r.limits(metric.MetricName) == nil {
+		return false, nil
+	}
+	if r.bucketPattern != nil && !r.bucketPattern.MatchString(metric.BucketName) {
+		return false, nil
+	}
+	if r.Region != "" && r.Region != metric.Region {
+		return false, nil
+	}
+	for key, value := range r.Tags {
+		if v, ok := metric.Tags[key]; !ok || v != value {
+			return false, nil
+		}
+	}
+	if r.conditionExpr == nil {
+		return true, nil
+	}
+	return r.conditionExpr.Eval(metric)
+}
+
+// limits returns the limits of the rule for the metric name, or nil if the rule has none.
+func (r *PolicyRule) limits(metricName MetricName) *PolicyLimits {
+	switch metricName {
+	case MetricNameBucketSizeBytes:
+		return r.Bytes
+	case MetricNameNumberOfObjects:
+		return r.Objects
+	default:
+		return nil
+	}
+}
+
+// status returns the compliance status of the value against the limits.
+// A value that reaches the level is regarded as exceeding it.
+func (l *PolicyLimits) status(value float64) ComplianceStatus {
+	switch {
+	case l.critical != nil && value >= *l.critical:
+		return ComplianceStatusCritical
+	case l.warning != nil && value >= *l.warning:
+		return ComplianceStatusWarning
+	default:
+		return ComplianceStatusOK
+	}
+}
+
+// Evaluate annotates each metric with the worst compliance status among the matching rules
+// and the name of the rule that determined it, and appends the Compliance and Rule columns
+// to the header. If no rules match, the status is none and the rule is empty.
+func (p *Policy) Evaluate(data *MetricData) (*PolicyReport, error) {
+	report := &PolicyReport{}
+	for _, metric := range data.Metrics {
+		metric.Compliance = ComplianceStatusNone
+		metric.Rule = ""
+		for _, rule := range p.Rules {
+			ok, err := rule.match(metric)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			if status := rule.limits(metric.MetricName).status(metric.Value); status > metric.Compliance {
+				metric.Compliance = status
+				metric.Rule = rule.Name
+			}
+		}
+		switch metric.Compliance {
+		case ComplianceStatusOK:
+			report.OK++
+		case ComplianceStatusWarning:
+			report.Warning++
+		case ComplianceStatusCritical:
+			report.Critical++
+		default:
+			report.None++
+		}
+	}
+	if !slices.Contains(data.Header, policyHeader[0]) {
+		data.Header = append(slices.Clone(data.Header), policyHeader...)
+	}
+	return report, nil
+}
@@ -0,0 +1,382 @@
+package s3bytes
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testPolicy = `{
+  "rules": [
+    {
+      "name": "logs",
+      "bucket": "^logs-",
+      "bytes": {"warning": "2KiB", "critical": "4KiB"}
+    },
+    {
+      "name": "tokyo",
+      "region": "ap-northeast-1",
+      "condition": "status == \"ok\"",
+      "bytes": {"critical": "1KiB"},
+      "objects": {"warning": "100"}
+    },
+    {
+      "name": "team",
+      "tags": {"team": "a"},
+      "bytes": {"warning": "1KiB"}
+    }
+  ]
+}`
+
+const testPolicyYAML = `rules:
+  - name: logs
+    bucket: ^logs-
+    bytes:
+      warning: 2KiB
+      critical: 4KiB
+  - name: team
+    tags:
+      team: a
+    objects:
+      warning: 100
+`
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		b       string
+		want    int
+		wantErr bool
+	}{
+		{
+			name:    "normal",
+			b:       testPolicy,
+			want:    3,
+			wantErr: false,
+		},
+		{
+			name:    "default name",
+			b:       `{"rules": [{"bytes": {"warning": "1GB"}}]}`,
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name:    "no rules",
+			b:       `{"rules": []}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown key",
+			b:       `{"rules": [{"name": "a", "buckets": "^a", "bytes": {"warning": "1GB"}}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid bucket pattern",
+			b:       `{"rules": [{"name": "a", "bucket": "(", "bytes": {"warning": "1GB"}}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid condition",
+			b:       `{"rules": [{"name": "a", "condition": "bytes >", "bytes": {"warning": "1GB"}}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "no limits",
+			b:       `{"rules": [{"name": "a"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "no levels",
+			b:       `{"rules": [{"name": "a", "bytes": {}}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid level",
+			b:       `{"rules": [{"name": "a", "objects": {"critical": "many"}}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "empty tag key",
+			b:       `{"rules": [{"name": "a", "tags": {"": "a"}, "bytes": {"warning": "1GB"}}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			b:       `{`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePolicy([]byte(tt.b))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePolicy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if len(got.Rules) != tt.want {
+				t.Errorf("ParsePolicy() rules = %v, want %v", len(got.Rules), tt.want)
+			}
+			for _, rule := range got.Rules {
+				if rule.Name == "" {
+					t.Errorf("ParsePolicy() rule name is empty")
+				}
+			}
+		})
+	}
+}
+
+func TestParsePolicyYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		b       string
+		want    int
+		wantErr bool
+	}{
+		{
+			name:    "normal",
+			b:       testPolicyYAML,
+			want:    2,
+			wantErr: false,
+		},
+		{
+			name:    "json",
+			b:       testPolicy,
+			want:    3,
+			wantErr: false,
+		},
+		{
+			name:    "empty",
+			b:       ``,
+			wantErr: true,
+		},
+		{
+			name:    "unknown key",
+			b:       "rules:\n  - name: a\n    buckets: ^a\n    bytes:\n      warning: 1GB\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid level",
+			b:       "rules:\n  - name: a\n    objects:\n      critical: many\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid yaml",
+			b:       "rules: [",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePolicyYAML([]byte(tt.b))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePolicyYAML() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if len(got.Rules) != tt.want {
+				t.Errorf("ParsePolicyYAML() rules = %v, want %v", len(got.Rules), tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.json")
+	if err := os.WriteFile(path, []byte(testPolicy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	yamlPath := filepath.Join(dir, "policy.yml")
+	if err := os.WriteFile(yamlPath, []byte(testPolicyYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		path    string
+		want    int
+		wantErr bool
+	}{
+		{
+			name:    "normal",
+			path:    path,
+			want:    3,
+			wantErr: false,
+		},
+		{
+			name:    "yaml",
+			path:    yamlPath,
+			want:    2,
+			wantErr: false,
+		},
+		{
+			name:    "not found",
+			path:    filepath.Join(dir, "unknown.json"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadPolicy(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadPolicy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if len(got.Rules) != tt.want {
+				t.Errorf("LoadPolicy() rules = %v, want %v", len(got.Rules), tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicy_Evaluate(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		data       *MetricData
+		want       []*Metric
+		wantReport *PolicyReport
+		wantHeader []string
+	}{
+		{
+			name: "size",
+			data: &MetricData{
+				Header: header,
+				Metrics: []*Metric{
+					{BucketName: "logs-0", Region: "us-east-1", MetricName: MetricNameBucketSizeBytes, Value: 4096, Status: DataStatusOK},
+					{BucketName: "logs-1", Region: "us-east-1", MetricName: MetricNameBucketSizeBytes, Value: 2048, Status: DataStatusOK},
+					{BucketName: "logs-2", Region: "ap-northeast-1", MetricName: MetricNameBucketSizeBytes, Value: 2048, Status: DataStatusOK},
+					{BucketName: "data-0", Region: "ap-northeast-1", MetricName: MetricNameBucketSizeBytes, Value: 0, Status: DataStatusNoData},
+					{BucketName: "data-1", Region: "us-east-1", MetricName: MetricNameBucketSizeBytes, Value: 8192, Status: DataStatusOK},
+				},
+			},
+			want: []*Metric{
+				{BucketName: "logs-0", Region: "us-east-1", MetricName: MetricNameBucketSizeBytes, Value: 4096, Status: DataStatusOK, Compliance: ComplianceStatusCritical, Rule: "logs"},
+				{BucketName: "logs-1", Region: "us-east-1", MetricName: MetricNameBucketSizeBytes, Value: 2048, Status: DataStatusOK, Compliance: ComplianceStatusWarning, Rule: "logs"},
+				{BucketName: "logs-2", Region: "ap-northeast-1", MetricName: MetricNameBucketSizeBytes, Value: 2048, Status: DataStatusOK, Compliance: ComplianceStatusCritical, Rule: "tokyo"},
+				{BucketName: "data-0", Region: "ap-northeast-1", MetricName: MetricNameBucketSizeBytes, Value: 0, Status: DataStatusNoData},
+				{BucketName: "data-1", Region: "us-east-1", MetricName: MetricNameBucketSizeBytes, Value: 8192, Status: DataStatusOK},
+			},
+			wantReport: &PolicyReport{
+				OK:       0,
+				Warning:  1,
+				Critical: 2,
+				None:     2,
+			},
+			wantHeader: append(append([]string{}, header...), "Compliance", "Rule"),
+		},
+		{
+			name: "objects",
+			data: &MetricData{
+				Header: header,
+				Metrics: []*Metric{
+					{BucketName: "logs-0", Region: "ap-northeast-1", MetricName: MetricNameNumberOfObjects, Value: 10, Status: DataStatusOK},
+					{BucketName: "data-0", Region: "ap-northeast-1", MetricName: MetricNameNumberOfObjects, Value: 100, Status: DataStatusOK},
+				},
+			},
+			want: []*Metric{
+				{BucketName: "logs-0", Region: "ap-northeast-1", MetricName: MetricNameNumberOfObjects, Value: 10, Status: DataStatusOK, Compliance: ComplianceStatusOK, Rule: "tokyo"},
+				{BucketName: "data-0", Region: "ap-northeast-1", MetricName: MetricNameNumberOfObjects, Value: 100, Status: DataStatusOK, Compliance: ComplianceStatusWarning, Rule: "tokyo"},
+			},
+			wantReport: &PolicyReport{
+				OK:       1,
+				Warning:  1,
+				Critical: 0,
+				None:     0,
+			},
+			wantHeader: append(append([]string{}, header...), "Compliance", "Rule"),
+		},
+		{
+			name: "tags",
+			data: &MetricData{
+				Header: header,
+				Metrics: []*Metric{
+					{BucketName: "data-0", Region: "us-east-1", MetricName: MetricNameBucketSizeBytes, Value: 2048, Status: DataStatusOK, Tags: map[string]string{"team": "a"}},
+					{BucketName: "data-1", Region: "us-east-1", MetricName: MetricNameBucketSizeBytes, Value: 2048, Status: DataStatusOK, Tags: map[string]string{"team": "b"}},
+					{BucketName: "data-2", Region: "us-east-1", MetricName: MetricNameBucketSizeBytes, Value: 512, Status: DataStatusOK, Tags: map[string]string{"team": "a"}},
+				},
+			},
+			want: []*Metric{
+				{BucketName: "data-0", Region: "us-east-1", MetricName: MetricNameBucketSizeBytes, Value: 2048, Status: DataStatusOK, Tags: map[string]string{"team": "a"}, Compliance: ComplianceStatusWarning, Rule: "team"},
+				{BucketName: "data-1", Region: "us-east-1", MetricName: MetricNameBucketSizeBytes, Value: 2048, Status: DataStatusOK, Tags: map[string]string{"team": "b"}},
+				{BucketName: "data-2", Region: "us-east-1", MetricName: MetricNameBucketSizeBytes, Value: 512, Status: DataStatusOK, Tags: map[string]string{"team": "a"}, Compliance: ComplianceStatusOK, Rule: "team"},
+			},
+			wantReport: &PolicyReport{
+				OK:       1,
+				Warning:  1,
+				Critical: 0,
+				None:     1,
+			},
+			wantHeader: append(append([]string{}, header...), "Compliance", "Rule"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := policy.Evaluate(tt.data)
+			if err != nil {
+				t.Fatalf("Policy.Evaluate() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.wantReport) {
+				t.Errorf("Policy.Evaluate() = %v, want %v", got, tt.wantReport)
+			}
+			if !reflect.DeepEqual(tt.data.Metrics, tt.want) {
+				t.Errorf("Policy.Evaluate() metrics = %v, want %v", tt.data.Metrics, tt.want)
+			}
+			if !reflect.DeepEqual(tt.data.Header, tt.wantHeader) {
+				t.Errorf("Policy.Evaluate() header = %v, want %v", tt.data.Header, tt.wantHeader)
+			}
+			if _, err := policy.Evaluate(tt.data); err != nil || len(tt.data.Header) != len(tt.wantHeader) {
+				t.Errorf("Policy.Evaluate() is not idempotent: header = %v, err = %v", tt.data.Header, err)
+			}
+		})
+	}
+	if len(header) != 9 {
+		t.Errorf("Policy.Evaluate() modified the shared header: %v", header)
+	}
+}
+
+func TestPolicy_TagKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *Policy
+		want   []string
+	}{
+		{
+			name: "normal",
+			policy: &Policy{
+				Rules: []*PolicyRule{
+					{Name: "a", Tags: map[string]string{"team": "a", "env": "prod"}},
+					{Name: "b", Tags: map[string]string{"team": "b"}},
+				},
+			},
+			want: []string{"env", "team"},
+		},
+		{
+			name: "no tags",
+			policy: &Policy{
+				Rules: []*PolicyRule{
+					{Name: "a"},
+				},
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.TagKeys(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Policy.TagKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package s3bytes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// prefixDelimiter is the delimiter that separates the levels of prefixes.
+	prefixDelimiter = "/"
+
+	// rootPrefix is the prefix shown for the objects at the root of the bucket, outside any prefix.
+	rootPrefix = "/"
+)
+
+// prefixNode represents a prefix in a bucket and the objects directly under it.
+// For the prefixes at the deepest level, result holds all objects under the prefix.
+type prefixNode struct {
+	prefix   string
+	depth    int
+	result   scanResult
+	children []*prefixNode
+}
+
+// total returns the result aggregated over the prefix and all its descendants.
+func (n *prefixNode) total() scanResult {
+	result := scanResult{}
+	result.merge(n.result)
+	for _, child := range n.children {
+		result.merge(child.total())
+	}
+	return result
+}
+
+// ListPrefixes aggregates the values by prefix in the specified buckets up to the specified depth.
+// Prefixes are walked level by level with ListObjectsV2 and the "/" delimiter,
+// and those at the deepest level are measured by listing all objects under them.
+// Each row holds the value of the prefix including all its descendants, the objects at the root
+// of the bucket are shown as the "/" prefix, and Total is the sum of the root and the top-level prefixes.
+func (man *Manager) ListPrefixes(ctx context.Context, buckets []string, depth int) (*MetricData, error) {
+	if depth < 1 {
+		return nil, fmt.Errorf("invalid depth: %d", depth)
+	}
+	if man.metricName.isRequest() {
+		return nil, fmt.Errorf("%s metric is not supported for prefixes", man.metricName)
+	}
+	var (
+		end  = now()
+		data = &MetricData{
+			Header:  man.withTagHeader(man.withMetadataHeader(prefixHeader)),
+			Metrics: make([]*Metric, 0),
+		}
+	)
+	for _, bucket := range buckets {
+		b, err := man.getBucket(ctx, bucket)
+		if err != nil {
+			return nil, err
+		}
+		region := aws.ToString(b.BucketRegion)
+		root, err := man.walkPrefixes(ctx, bucket, region, depth)
+		if err != nil {
+			return nil, err
+		}
+		base := &Metric{
+			BucketName: bucket,
+			Region:     region,
+			BucketType: getBucketType(bucket),
+		}
+		if err := man.enrichMetadata(ctx, []*Metric{base}, region, creationDates([]s3types.Bucket{b})); err != nil {
+			return nil, err
+		}
+		if err := man.enrichTags(ctx, []*Metric{base}, region); err != nil {
+			return nil, err
+		}
+		m, n, err := man.getPrefixMetrics(root, base, end, SourceTypeScan)
+		if err != nil {
+			return nil, err
+		}
+		data.Metrics = append(data.Metrics, m...)
+		data.Total += n
+	}
+	return data, nil
+}
+
+// getBucket returns the bucket with its region, and its creation date for the enrichment.
+// The region of a general purpose bucket is taken from GetBucketLocation, and the creation date
+// from ListBuckets only if enabled, since it needs s3:ListAllMyBuckets. If the listing fails
+// for other than the context, the creation date is left empty in the same way as the other metadata.
+// The directory buckets, which ListBuckets does not return, are looked up with ListDirectoryBuckets
+// in the region of the zone ID in the name. If the listing is denied, the region is still taken
+// from the name, without the creation date. If the zone ID is of an unknown region, the bucket
+// is looked up in all target regions of the manager instead.
+func (man *Manager) getBucket(ctx context.Context, bucket string) (s3types.Bucket, error) {
+	if isDirectoryBucket(bucket) {
+		region, known := directoryBucketRegion(bucket)
+		regions := man.regions
+		if known {
+			regions = []string{region}
+		}
+		for _, region := range regions {
+			list, err := listDirectoryBuckets(ctx, man.client, region, bucket)
+			if err != nil {
+				return s3types.Bucket{}, err
+			}
+			for _, b := range list {
+				if aws.ToString(b.Name) == bucket {
+					b.BucketRegion = aws.String(region)
+					return b, nil
+				}
+			}
+		}
+		if !known {
+			return s3types.Bucket{}, fmt.Errorf("directory bucket not found: %q", bucket)
+		}
+		return s3types.Bucket{Name: aws.String(bucket), BucketRegion: aws.String(region)}, nil
+	}
+	region, err := getBucketRegion(ctx, man.client, bucket)
+	if err != nil {
+		if isAPIError(err, "NoSuchBucket") {
+			return s3types.Bucket{}, fmt.Errorf("bucket not found: %q", bucket)
+		}
+		return s3types.Bucket{}, err
+	}
+	ret := s3types.Bucket{Name: aws.String(bucket), BucketRegion: aws.String(region)}
+	if !man.enrich {
+		return ret, nil
+	}
+	in := &s3.ListBucketsInput{
+		Prefix:       aws.String(bucket),
+		BucketRegion: aws.String(region),
+	}
+	out, err := man.client.ListBuckets(ctx, in)
+	switch {
+	case err == nil:
+		for _, b := range out.Buckets {
+			if aws.ToString(b.Name) == bucket {
+				ret.CreationDate = b.CreationDate
+			}
+		}
+	case ctx.Err() != nil:
+		return s3types.Bucket{}, err
+	}
+	return ret, nil
+}
+
+// walkPrefixes walks the prefixes in the bucket breadth-first up to the specified depth.
+// The prefixes at the same level are listed concurrently up to the concurrency of the manager at a time.
+func (man *Manager) walkPrefixes(ctx context.Context, bucket, region string, depth int) (*prefixNode, error) {
+	root := &prefixNode{}
+	level := []*prefixNode{root}
+	for len(level) > 0 {
+		g, ctx := errgroup.WithContext(ctx)
+		g.SetLimit(man.workers())
+		for _, node := range level {
+			g.Go(func() error {
+				if node.depth == depth {
+					result, _, err := listObjects(ctx, man.client, bucket, region, node.prefix, "")
+					if err != nil {
+						return err
+					}
+					node.result = result
+					return nil
+				}
+				result, prefixes, err := listObjects(ctx, man.client, bucket, region, node.prefix, prefixDelimiter)
+				if err != nil {
+					return err
+				}
+				node.result = result
+				node.children = make([]*prefixNode, len(prefixes))
+				for i, prefix := range prefixes {
+					node.children[i] = &prefixNode{
+						prefix: prefix,
+						depth:  node.depth + 1,
+					}
+				}
+				return nil
+			})
+		}
+		if err := g.Wait(); err != nil {
+			return nil, err
+		}
+		next := make([]*prefixNode, 0)
+		for _, node := range level {
+			next = append(next, node.children...)
+		}
+		level = next
+	}
+	return root, nil
+}
+
+// getPrefixMetrics converts the prefixes under the root into metrics in depth-first order,
+// preceded by the objects at the root of the bucket if any.
+// The bucket name, the region, the bucket type, the tags and the metadata of the base are shared by all the prefixes.
+func (man *Manager) getPrefixMetrics(root *prefixNode, base *Metric, timestamp time.Time, source SourceType) ([]*Metric, int64, error) {
+	var (
+		total       int64
+		metrics     = make([]*Metric, 0)
+		storageType = man.storageType.forBucket(base.BucketName)
+		visit       func(node *prefixNode) error
+	)
+	add := func(prefix string, result scanResult, top bool) error {
+		metric := &Metric{
+			BucketName:     base.BucketName,
+			Region:         base.Region,
+			Prefix:         prefix,
+			MetricName:     man.metricName,
+			StorageType:    storageType,
+			Value:          result.value(man.metricName, storageType),
+			Status:         DataStatusOK,
+			Timestamp:      timestamp,
+			Source:         source,
+			BucketType:     base.BucketType,
+			Tags:           base.Tags,
+			BucketMetadata: base.BucketMetadata,
+		}
+		ok, err := man.accept(metric)
+		if err != nil {
+			return err
+		}
+		if ok {
+			metrics = append(metrics, metric)
+			if top {
+				total += int64(metric.Value)
+			}
+		}
+		return nil
+	}
+	visit = func(node *prefixNode) error {
+		for _, child := range node.children {
+			if err := add(child.prefix, child.total(), child.depth == 1); err != nil {
+				return err
+			}
+			if err := visit(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(root.result) > 0 {
+		if err := add(rootPrefix, root.result, true); err != nil {
+			return nil, 0, err
+		}
+	}
+	if err := visit(root); err != nil {
+		return nil, 0, err
+	}
+	return metrics, total, nil
+}
+
+// ParentPrefix returns the prefix one level above, or an empty string for the top level.
+func ParentPrefix(prefix string) string {
+	s := strings.TrimSuffix(prefix, prefixDelimiter)
+	i := strings.LastIndex(s, prefixDelimiter)
+	if i < 0 {
+		return ""
+	}
+	return s[:i+1]
+}
@@ -0,0 +1,529 @@
+package s3bytes
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/nekrassov01/filter"
+)
+
+// newMockListObjectsV2 returns a ListObjectsV2 function that emulates the prefix and delimiter
+// behavior of S3 over the specified keys and sizes.
+func newMockListObjectsV2(objects map[string]int64) func(context.Context, *s3.ListObjectsV2Input, ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return func(_ context.Context, params *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+		var (
+			out       = &s3.ListObjectsV2Output{}
+			prefix    = aws.ToString(params.Prefix)
+			delimiter = aws.ToString(params.Delimiter)
+			seen      = map[string]struct{}{}
+		)
+		for _, key := range keys {
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			if delimiter != "" {
+				if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+					p := key[:len(prefix)+i+len(delimiter)]
+					if _, ok := seen[p]; !ok {
+						seen[p] = struct{}{}
+						out.CommonPrefixes = append(out.CommonPrefixes, s3types.CommonPrefix{Prefix: aws.String(p)})
+					}
+					continue
+				}
+			}
+			out.Contents = append(out.Contents, s3types.Object{
+				Key:  aws.String(key),
+				Size: aws.Int64(objects[key]),
+			})
+		}
+		return out, nil
+	}
+}
+
+var testPrefixObjects = map[string]int64{
+	"root.txt":             1,
+	"logs/a.log":           10,
+	"logs/2025/b.log":      100,
+	"logs/2026/c.log":      1000,
+	"logs/2026/01/d.log":   10000,
+	"data/e.csv":           100000,
+	"data/parquet/f.parq":  1000000,
+	"data/parquet/g.parq":  1000000,
+	"data/parquet/x/h.bin": 1,
+}
+
+func TestManager_ListPrefixes(t *testing.T) {
+	type fields struct {
+		client      *Client
+		metricName  MetricName
+		storageType StorageType
+		filterExpr  filterExpr
+		regions     []string
+		enrich      bool
+	}
+	type args struct {
+		ctx     context.Context
+		buckets []string
+		depth   int
+	}
+	getBucketLocation := func(_ context.Context, params *s3.GetBucketLocationInput, _ ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error) {
+		if aws.ToString(params.Bucket) != "bucket0" {
+			return nil, &smithy.GenericAPIError{Code: "NoSuchBucket"}
+		}
+		return &s3.GetBucketLocationOutput{LocationConstraint: s3types.BucketLocationConstraintApNortheast1}, nil
+	}
+	client := newMockClient(
+		&mockS3{
+			ListDirectoryBucketsFunc: listNoDirectoryBuckets,
+			ListBucketsFunc: func(_ context.Context, _ *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+				return nil, errors.New("unexpected call")
+			},
+			GetBucketLocationFunc: getBucketLocation,
+			ListObjectsV2Func:     newMockListObjectsV2(testPrefixObjects),
+		},
+		nil,
+	)
+	denied := func() error {
+		return &smithy.GenericAPIError{Code: "AccessDenied", Message: "Access Denied"}
+	}
+	newEnrichClient := func(listBuckets func(context.Context, *s3.ListBucketsInput, ...func(*s3.Options)) (*s3.ListBucketsOutput, error)) *Client {
+		return newMockClient(
+			&mockS3{
+				ListDirectoryBucketsFunc: listNoDirectoryBuckets,
+				ListBucketsFunc:          listBuckets,
+				GetBucketLocationFunc:    getBucketLocation,
+				ListObjectsV2Func:        newMockListObjectsV2(testPrefixObjects),
+				GetBucketVersioningFunc: func(_ context.Context, _ *s3.GetBucketVersioningInput, _ ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
+					return nil, denied()
+				},
+				GetBucketLifecycleConfigurationFunc: func(_ context.Context, _ *s3.GetBucketLifecycleConfigurationInput, _ ...func(*s3.Options)) (*s3.GetBucketLifecycleConfigurationOutput, error) {
+					return nil, denied()
+				},
+				GetBucketEncryptionFunc: func(_ context.Context, _ *s3.GetBucketEncryptionInput, _ ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error) {
+					return nil, denied()
+				},
+				GetObjectLockConfigurationFunc: func(_ context.Context, _ *s3.GetObjectLockConfigurationInput, _ ...func(*s3.Options)) (*s3.GetObjectLockConfigurationOutput, error) {
+					return nil, denied()
+				},
+			},
+			nil,
+		)
+	}
+	newMetric := func(prefix string, value float64) *Metric {
+		return &Metric{
+			BucketName:  "bucket0",
+			Region:      "ap-northeast-1",
+			Prefix:      prefix,
+			MetricName:  MetricNameBucketSizeBytes,
+			StorageType: StorageTypeStandardStorage,
+			Value:       value,
+			Status:      DataStatusOK,
+			Timestamp:   testNow,
+			Source:      SourceTypeScan,
+			BucketType:  BucketTypeGeneralPurpose,
+		}
+	}
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	unknown := BucketMetadata{
+		Versioning: statusUnknown,
+		Lifecycle:  statusUnknown,
+		Encryption: statusUnknown,
+		ObjectLock: statusUnknown,
+	}
+	withCreated := unknown
+	withCreated.CreationDate = created
+	newEnrichMetric := func(prefix string, value float64, metadata BucketMetadata) *Metric {
+		metric := newMetric(prefix, value)
+		metric.BucketMetadata = metadata
+		return metric
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *MetricData
+		wantErr bool
+	}{
+		{
+			name: "depth 1",
+			fields: fields{
+				client:      client,
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+			},
+			args: args{
+				ctx:     context.Background(),
+				buckets: []string{"bucket0"},
+				depth:   1,
+			},
+			want: &MetricData{
+				Header: prefixHeader,
+				Metrics: []*Metric{
+					newMetric("/", 1),
+					newMetric("data/", 2100001),
+					newMetric("logs/", 11110),
+				},
+				Total: 2111112,
+			},
+			wantErr: false,
+		},
+		{
+			name: "depth 2",
+			fields: fields{
+				client:      client,
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+			},
+			args: args{
+				ctx:     context.Background(),
+				buckets: []string{"bucket0"},
+				depth:   2,
+			},
+			want: &MetricData{
+				Header: prefixHeader,
+				Metrics: []*Metric{
+					newMetric("/", 1),
+					newMetric("data/", 2100001),
+					newMetric("data/parquet/", 2000001),
+					newMetric("logs/", 11110),
+					newMetric("logs/2025/", 100),
+					newMetric("logs/2026/", 11000),
+				},
+				Total: 2111112,
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter",
+			fields: fields{
+				client:      client,
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+				filterExpr:  func() filterExpr { expr, _ := filter.Parse(`prefix =~ "^logs/"`); return expr }(),
+			},
+			args: args{
+				ctx:     context.Background(),
+				buckets: []string{"bucket0"},
+				depth:   2,
+			},
+			want: &MetricData{
+				Header: prefixHeader,
+				Metrics: []*Metric{
+					newMetric("logs/", 11110),
+					newMetric("logs/2025/", 100),
+					newMetric("logs/2026/", 11000),
+				},
+				Total: 11110,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid depth",
+			fields: fields{
+				client: client,
+			},
+			args: args{
+				ctx:     context.Background(),
+				buckets: []string{"bucket0"},
+				depth:   0,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "request metric",
+			fields: fields{
+				client:     client,
+				metricName: MetricNameAllRequests,
+			},
+			args: args{
+				ctx:     context.Background(),
+				buckets: []string{"bucket0"},
+				depth:   1,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "directory bucket",
+			fields: fields{
+				client: newMockClient(
+					&mockS3{
+						ListDirectoryBucketsFunc: func(_ context.Context, _ *s3.ListDirectoryBucketsInput, _ ...func(*s3.Options)) (*s3.ListDirectoryBucketsOutput, error) {
+							return &s3.ListDirectoryBucketsOutput{
+								Buckets: []s3types.Bucket{{Name: aws.String("bucket0--apne1-az4--x-s3")}},
+							}, nil
+						},
+						ListBucketsFunc: func(_ context.Context, _ *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+							return nil, errors.New("unexpected call")
+						},
+						ListObjectsV2Func: newMockListObjectsV2(testPrefixObjects),
+					},
+					nil,
+				),
+				metricName:  MetricNameNumberOfObjects,
+				storageType: StorageTypeAllStorageTypes,
+			},
+			args: args{
+				ctx:     context.Background(),
+				buckets: []string{"bucket0--apne1-az4--x-s3"},
+				depth:   1,
+			},
+			want: &MetricData{
+				Header: prefixHeader,
+				Metrics: func() []*Metric {
+					ret := make([]*Metric, 0, 3)
+					for i, prefix := range []string{"/", "data/", "logs/"} {
+						ret = append(ret, &Metric{
+							BucketName:  "bucket0--apne1-az4--x-s3",
+							Region:      "ap-northeast-1",
+							Prefix:      prefix,
+							MetricName:  MetricNameNumberOfObjects,
+							StorageType: StorageTypeAllStorageTypes,
+							Value:       []float64{1, 4, 4}[i],
+							Status:      DataStatusOK,
+							Timestamp:   testNow,
+							Source:      SourceTypeScan,
+							BucketType:  BucketTypeDirectory,
+						})
+					}
+					return ret
+				}(),
+				Total: 9,
+			},
+			wantErr: false,
+		},
+		{
+			name: "directory bucket of unknown zone",
+			fields: fields{
+				client: newMockClient(
+					&mockS3{
+						ListDirectoryBucketsFunc: func(_ context.Context, _ *s3.ListDirectoryBucketsInput, optFns ...func(*s3.Options)) (*s3.ListDirectoryBucketsOutput, error) {
+							o := &s3.Options{}
+							for _, fn := range optFns {
+								fn(o)
+							}
+							if o.Region != "xx-new-1" {
+								return &s3.ListDirectoryBucketsOutput{}, nil
+							}
+							return &s3.ListDirectoryBucketsOutput{
+								Buckets: []s3types.Bucket{{Name: aws.String("bucket0--xxn1-az1--x-s3")}},
+							}, nil
+						},
+						ListObjectsV2Func: newMockListObjectsV2(map[string]int64{"a.txt": 1}),
+					},
+					nil,
+				),
+				metricName:  MetricNameNumberOfObjects,
+				storageType: StorageTypeAllStorageTypes,
+				regions:     []string{"ap-northeast-1", "xx-new-1"},
+			},
+			args: args{
+				ctx:     context.Background(),
+				buckets: []string{"bucket0--xxn1-az1--x-s3"},
+				depth:   1,
+			},
+			want: &MetricData{
+				Header: prefixHeader,
+				Metrics: []*Metric{
+					{
+						BucketName:  "bucket0--xxn1-az1--x-s3",
+						Region:      "xx-new-1",
+						Prefix:      "/",
+						MetricName:  MetricNameNumberOfObjects,
+						StorageType: StorageTypeAllStorageTypes,
+						Value:       1,
+						Status:      DataStatusOK,
+						Timestamp:   testNow,
+						Source:      SourceTypeScan,
+						BucketType:  BucketTypeDirectory,
+					},
+				},
+				Total: 1,
+			},
+			wantErr: false,
+		},
+		{
+			name: "directory bucket not found",
+			fields: fields{
+				client:  client,
+				regions: []string{"ap-northeast-1"},
+			},
+			args: args{
+				ctx:     context.Background(),
+				buckets: []string{"bucket0--xxx1-az1--x-s3"},
+				depth:   1,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "bucket not found",
+			fields: fields{
+				client: client,
+			},
+			args: args{
+				ctx:     context.Background(),
+				buckets: []string{"unknown"},
+				depth:   1,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "enrich",
+			fields: fields{
+				client: newEnrichClient(func(_ context.Context, params *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+					return &s3.ListBucketsOutput{
+						Buckets: []s3types.Bucket{
+							{
+								Name:         aws.String("bucket0"),
+								BucketRegion: aws.String("ap-northeast-1"),
+								CreationDate: aws.Time(created),
+							},
+							{
+								Name:         aws.String("bucket00"),
+								BucketRegion: aws.String("ap-northeast-1"),
+							},
+						},
+						Prefix: params.Prefix,
+					}, nil
+				}),
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+				enrich:      true,
+			},
+			args: args{
+				ctx:     context.Background(),
+				buckets: []string{"bucket0"},
+				depth:   1,
+			},
+			want: &MetricData{
+				Header: append(slices.Clone(prefixHeader), metadataHeader...),
+				Metrics: []*Metric{
+					newEnrichMetric("/", 1, withCreated),
+					newEnrichMetric("data/", 2100001, withCreated),
+					newEnrichMetric("logs/", 11110, withCreated),
+				},
+				Total: 2111112,
+			},
+			wantErr: false,
+		},
+		{
+			name: "enrich without listing",
+			fields: fields{
+				client: newEnrichClient(func(_ context.Context, _ *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+					return nil, denied()
+				}),
+				metricName:  MetricNameBucketSizeBytes,
+				storageType: StorageTypeStandardStorage,
+				enrich:      true,
+			},
+			args: args{
+				ctx:     context.Background(),
+				buckets: []string{"bucket0"},
+				depth:   1,
+			},
+			want: &MetricData{
+				Header: append(slices.Clone(prefixHeader), metadataHeader...),
+				Metrics: []*Metric{
+					newEnrichMetric("/", 1, unknown),
+					newEnrichMetric("data/", 2100001, unknown),
+					newEnrichMetric("logs/", 11110, unknown),
+				},
+				Total: 2111112,
+			},
+			wantErr: false,
+		},
+		{
+			name: "list error",
+			fields: fields{
+				client: newMockClient(
+					&mockS3{
+						ListDirectoryBucketsFunc: listNoDirectoryBuckets,
+						GetBucketLocationFunc:    getBucketLocation,
+						ListObjectsV2Func: func(_ context.Context, _ *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+							return nil, errors.New("error")
+						},
+					},
+					nil,
+				),
+			},
+			args: args{
+				ctx:     context.Background(),
+				buckets: []string{"bucket0"},
+				depth:   1,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			man := &Manager{
+				client:      tt.fields.client,
+				metricName:  tt.fields.metricName,
+				storageType: tt.fields.storageType,
+				filterExpr:  tt.fields.filterExpr,
+				regions:     tt.fields.regions,
+				enrich:      tt.fields.enrich,
+			}
+			got, err := man.ListPrefixes(tt.args.ctx, tt.args.buckets, tt.args.depth)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Manager.ListPrefixes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != nil {
+				slices.SortFunc(got.Metrics, func(a, b *Metric) int { return strings.Compare(a.Prefix, b.Prefix) })
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Manager.ListPrefixes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParentPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		want   string
+	}{
+		{
+			name:   "top level",
+			prefix: "logs/",
+			want:   "",
+		},
+		{
+			name:   "second level",
+			prefix: "logs/2026/",
+			want:   "logs/",
+		},
+		{
+			name:   "third level",
+			prefix: "logs/2026/01/",
+			want:   "logs/2026/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParentPrefix(tt.prefix); got != tt.want {
+				t.Errorf("ParentPrefix() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
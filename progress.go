@@ -0,0 +1,41 @@
+package s3bytes
+
+import (
+	"context"
+	"errors"
+)
+
+// Event represents the progress of a region reported to the observers of the manager.
+// Buckets is the number of the buckets listed in the region for EventTypeBucketsListed,
+// and the number of the buckets in the batch for EventTypeBatchSent.
+// Err is the cause of the failure for EventTypeRegionFailed.
+type Event struct {
+	Type    EventType
+	Region  string
+	Buckets int
+	Err     error
+}
+
+// Observer is a function that receives the progress events of the manager.
+// It is called from the goroutines of the regions concurrently, so it must be safe
+// for concurrent use and should return quickly not to slow down the listing.
+type Observer func(Event)
+
+// notify sends the event to all observers of the manager.
+func (man *Manager) notify(event Event) {
+	for _, observer := range man.observers {
+		observer(event)
+	}
+}
+
+// notifyResult sends the event of the finish or the failure of the region depending on the error,
+// and returns the error as it is. The region stopped because the consumer of the metrics stopped
+// is regarded as finished, since nothing failed.
+func (man *Manager) notifyResult(ctx context.Context, region string, err error) error {
+	if err != nil && !errors.Is(err, errStopped) && !errors.Is(context.Cause(ctx), errStopped) {
+		man.notify(Event{Type: EventTypeRegionFailed, Region: region, Err: err})
+		return err
+	}
+	man.notify(Event{Type: EventTypeRegionFinished, Region: region})
+	return err
+}
@@ -0,0 +1,76 @@
+package s3bytes
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestManager_notifyResult(t *testing.T) {
+	errRegion := errors.New("error")
+	stopped, stop := context.WithCancelCause(context.Background())
+	stop(errStopped)
+	type args struct {
+		ctx context.Context
+		err error
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantEvent Event
+		wantErr   error
+	}{
+		{
+			name: "finished",
+			args: args{
+				ctx: context.Background(),
+				err: nil,
+			},
+			wantEvent: Event{Type: EventTypeRegionFinished, Region: "ap-northeast-1"},
+			wantErr:   nil,
+		},
+		{
+			name: "failed",
+			args: args{
+				ctx: context.Background(),
+				err: errRegion,
+			},
+			wantEvent: Event{Type: EventTypeRegionFailed, Region: "ap-northeast-1", Err: errRegion},
+			wantErr:   errRegion,
+		},
+		{
+			name: "stopped",
+			args: args{
+				ctx: context.Background(),
+				err: errStopped,
+			},
+			wantEvent: Event{Type: EventTypeRegionFinished, Region: "ap-northeast-1"},
+			wantErr:   errStopped,
+		},
+		{
+			name: "canceled by consumer",
+			args: args{
+				ctx: stopped,
+				err: context.Canceled,
+			},
+			wantEvent: Event{Type: EventTypeRegionFinished, Region: "ap-northeast-1"},
+			wantErr:   context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []Event
+			observer := func(e Event) { events = append(events, e) }
+			man := &Manager{
+				observers: []Observer{observer, observer},
+			}
+			if err := man.notifyResult(tt.args.ctx, "ap-northeast-1", tt.args.err); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Manager.notifyResult() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if want := []Event{tt.wantEvent, tt.wantEvent}; !reflect.DeepEqual(events, want) {
+				t.Errorf("Manager.notifyResult() events = %v, want %v", events, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package s3bytes
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// The wire types of Protocol Buffers.
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+	protoFixed32 = 5
+)
+
+// protoReader is a decoder of the wire format of Protocol Buffers used by the metadata of ORC.
+// Only the parts needed to read the metadata are implemented, and unknown fields are skipped.
+type protoReader struct {
+	b   []byte
+	off int
+}
+
+// readMessage reads the fields of the message to the end of the buffer, calling fn with the number
+// and the wire type of each field. fn must read or skip the value of the field.
+func (p *protoReader) readMessage(fn func(field int, wire int) error) error {
+	for p.off < len(p.b) {
+		key, err := p.uvarint()
+		if err != nil {
+			return err
+		}
+		if err := fn(int(key>>3), int(key&7)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uvarint reads an unsigned varint.
+func (p *protoReader) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(p.b[p.off:])
+	if n <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	p.off += n
+	return v, nil
+}
+
+// bytes reads a length-delimited value, which is also used for strings and embedded messages.
+// The returned slice refers to the buffer of the reader.
+func (p *protoReader) bytes() ([]byte, error) {
+	n, err := p.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(p.b)-p.off) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := p.b[p.off : p.off+int(n)]
+	p.off += int(n)
+	return b, nil
+}
+
+// uvarints reads a repeated varint field either packed or not.
+func (p *protoReader) uvarints(wire int) ([]uint64, error) {
+	if wire == protoVarint {
+		v, err := p.uvarint()
+		return []uint64{v}, err
+	}
+	if wire != protoBytes {
+		return nil, fmt.Errorf("unexpected wire type for varints: %d", wire)
+	}
+	b, err := p.bytes()
+	if err != nil {
+		return nil, err
+	}
+	var values []uint64
+	packed := &protoReader{b: b}
+	for packed.off < len(packed.b) {
+		v, err := packed.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// skip skips the value of the wire type.
+func (p *protoReader) skip(wire int) error {
+	switch wire {
+	case protoVarint:
+		_, err := p.uvarint()
+		return err
+	case protoFixed64, protoFixed32:
+		n := 8
+		if wire == protoFixed32 {
+			n = 4
+		}
+		if n > len(p.b)-p.off {
+			return io.ErrUnexpectedEOF
+		}
+		p.off += n
+		return nil
+	case protoBytes:
+		_, err := p.bytes()
+		return err
+	default:
+		return fmt.Errorf("unsupported protobuf wire type: %d", wire)
+	}
+}
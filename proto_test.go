@@ -0,0 +1,109 @@
+package s3bytes
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// protoWriter is an encoder of the wire format of Protocol Buffers to build the metadata of test files.
+type protoWriter struct {
+	bytes.Buffer
+}
+
+// uvarint writes a varint field.
+func (w *protoWriter) uvarint(field int, v uint64) {
+	w.Write(binary.AppendUvarint(nil, uint64(field)<<3|protoVarint))
+	w.Write(binary.AppendUvarint(nil, v))
+}
+
+// bytes writes a length-delimited field.
+func (w *protoWriter) bytes(field int, b []byte) {
+	w.Write(binary.AppendUvarint(nil, uint64(field)<<3|protoBytes))
+	w.Write(binary.AppendUvarint(nil, uint64(len(b))))
+	w.Write(b)
+}
+
+// packed writes a packed repeated varint field.
+func (w *protoWriter) packed(field int, values []uint64) {
+	var b []byte
+	for _, v := range values {
+		b = binary.AppendUvarint(b, v)
+	}
+	w.bytes(field, b)
+}
+
+func Test_protoReader(t *testing.T) {
+	w := &protoWriter{}
+	w.uvarint(1, 300)
+	w.Write(binary.AppendUvarint(nil, 2<<3|protoFixed64))
+	w.Write(make([]byte, 8))
+	w.Write(binary.AppendUvarint(nil, 3<<3|protoFixed32))
+	w.Write(make([]byte, 4))
+	w.packed(4, []uint64{1, 2, 300})
+	w.uvarint(4, 4)
+	w.bytes(5, []byte("skipped"))
+	w.bytes(8000, []byte("ORC"))
+	type want struct {
+		v      uint64
+		values []uint64
+		magic  string
+	}
+	tests := []struct {
+		name    string
+		b       []byte
+		want    want
+		wantErr bool
+	}{
+		{
+			name:    "all wire types",
+			b:       w.Bytes(),
+			want:    want{v: 300, values: []uint64{1, 2, 300, 4}, magic: "ORC"},
+			wantErr: false,
+		},
+		{
+			name:    "truncated",
+			b:       w.Bytes()[:w.Len()-1],
+			want:    want{v: 300, values: []uint64{1, 2, 300, 4}},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported wire type",
+			b:       []byte{1<<3 | 3},
+			want:    want{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got want
+			p := &protoReader{b: tt.b}
+			err := p.readMessage(func(field, wire int) error {
+				var err error
+				switch {
+				case field == 1 && wire == protoVarint:
+					got.v, err = p.uvarint()
+				case field == 4:
+					var values []uint64
+					values, err = p.uvarints(wire)
+					got.values = append(got.values, values...)
+				case field == 8000 && wire == protoBytes:
+					var b []byte
+					b, err = p.bytes()
+					got.magic = string(b)
+				default:
+					err = p.skip(wire)
+				}
+				return err
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("protoReader.readMessage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("protoReader.readMessage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
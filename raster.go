@@ -0,0 +1,86 @@
+package s3bytes
+
+import (
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// writePNG rasterizes the canvas and writes it as a PNG image.
+func (c *staticCanvas) writePNG(w io.Writer) error {
+	img := image.NewRGBA(image.Rect(0, 0, c.width, c.height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(staticBackground), image.Point{}, draw.Src)
+	for _, r := range c.rects {
+		fillRect(img, r)
+	}
+	for _, wd := range c.wedges {
+		fillWedge(img, wd)
+	}
+	for _, t := range c.texts {
+		drawText(img, t)
+	}
+	return png.Encode(w, img)
+}
+
+// fillRect fills the rectangle.
+func fillRect(img *image.RGBA, r staticRect) {
+	rect := image.Rect(int(math.Round(r.x)), int(math.Round(r.y)), int(math.Round(r.x+r.w)), int(math.Round(r.y+r.h)))
+	draw.Draw(img, rect.Intersect(img.Bounds()), image.NewUniform(r.fill), image.Point{}, draw.Src)
+}
+
+// fillWedge fills the pixels whose centers are inside the wedge.
+func fillWedge(img *image.RGBA, wd staticWedge) {
+	bounds := image.Rect(int(wd.cx-wd.r), int(wd.cy-wd.r), int(wd.cx+wd.r)+1, int(wd.cy+wd.r)+1).Intersect(img.Bounds())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			dx, dy := float64(x)+0.5-wd.cx, float64(y)+0.5-wd.cy
+			if dx*dx+dy*dy > wd.r*wd.r {
+				continue
+			}
+			angle := math.Atan2(dy, dx) + math.Pi/2
+			if angle < 0 {
+				angle += 2 * math.Pi
+			}
+			if angle >= wd.start && angle < wd.end {
+				img.SetRGBA(x, y, wd.fill)
+			}
+		}
+	}
+}
+
+// drawText draws the text with the fixed-size basic font, scaled by a whole number to approximate the font size.
+// The text is drawn to a mask at the original size first, so that the glyphs stay sharp when scaled.
+func drawText(img *image.RGBA, t staticText) {
+	face := basicfont.Face7x13
+	var (
+		scale  = max(1, int(math.Round(t.size/float64(face.Height))))
+		width  = font.MeasureString(face, t.text).Ceil()
+		mask   = image.NewAlpha(image.Rect(0, 0, width, face.Height))
+		scaled = image.NewAlpha(image.Rect(0, 0, width*scale, face.Height*scale))
+		x      = int(math.Round(t.x))
+		y      = int(math.Round(t.y)) - face.Ascent*scale
+	)
+	d := &font.Drawer{
+		Dst:  mask,
+		Src:  image.Opaque,
+		Face: face,
+		Dot:  fixed.P(0, face.Ascent),
+	}
+	d.DrawString(t.text)
+	xdraw.NearestNeighbor.Scale(scaled, scaled.Bounds(), mask, mask.Bounds(), draw.Src, nil)
+	switch t.anchor {
+	case "middle":
+		x -= scaled.Rect.Dx() / 2
+	case "end":
+		x -= scaled.Rect.Dx()
+	}
+	rect := scaled.Bounds().Add(image.Pt(x, y))
+	draw.DrawMask(img, rect, image.NewUniform(t.fill), image.Point{}, scaled, image.Point{}, draw.Over)
+}
@@ -0,0 +1,114 @@
+package s3bytes
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func Test_fillWedge(t *testing.T) {
+	fill := color.RGBA{0xff, 0, 0, 0xff}
+	tests := []struct {
+		name  string
+		wedge staticWedge
+		point image.Point
+		want  bool
+	}{
+		{
+			name:  "inside of right half",
+			wedge: staticWedge{cx: 50, cy: 50, r: 40, start: 0, end: math.Pi, fill: fill},
+			point: image.Pt(70, 50),
+			want:  true,
+		},
+		{
+			name:  "outside of angle",
+			wedge: staticWedge{cx: 50, cy: 50, r: 40, start: 0, end: math.Pi, fill: fill},
+			point: image.Pt(30, 50),
+			want:  false,
+		},
+		{
+			name:  "outside of radius",
+			wedge: staticWedge{cx: 50, cy: 50, r: 40, start: 0, end: 2 * math.Pi, fill: fill},
+			point: image.Pt(95, 50),
+			want:  false,
+		},
+		{
+			name:  "top left of full circle",
+			wedge: staticWedge{cx: 50, cy: 50, r: 40, start: 0, end: 2 * math.Pi, fill: fill},
+			point: image.Pt(40, 40),
+			want:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+			fillWedge(img, tt.wedge)
+			if got := img.RGBAAt(tt.point.X, tt.point.Y) == fill; got != tt.want {
+				t.Errorf("fillWedge() filled %v = %v, want %v", tt.point, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_drawText(t *testing.T) {
+	fill := color.RGBA{0, 0, 0xff, 0xff}
+	tests := []struct {
+		name string
+		text staticText
+		want image.Rectangle
+	}{
+		{
+			name: "start",
+			text: staticText{x: 20, y: 30, text: "I", anchor: "start", size: 12, fill: fill},
+			want: image.Rect(21, 21, 26, 30),
+		},
+		{
+			name: "end",
+			text: staticText{x: 20, y: 30, text: "I", anchor: "end", size: 12, fill: fill},
+			want: image.Rect(14, 21, 19, 30),
+		},
+		{
+			name: "middle",
+			text: staticText{x: 20, y: 30, text: "I", anchor: "middle", size: 12, fill: fill},
+			want: image.Rect(18, 21, 23, 30),
+		},
+		{
+			name: "scaled",
+			text: staticText{x: 20, y: 30, text: "I", anchor: "start", size: 26, fill: fill},
+			want: image.Rect(22, 12, 32, 30),
+		},
+		{
+			name: "lowercase",
+			text: staticText{x: 20, y: 30, text: "ab", anchor: "start", size: 12, fill: fill},
+			want: image.Rect(20, 21, 33, 30),
+		},
+		{
+			name: "latin-1",
+			text: staticText{x: 20, y: 30, text: "é", anchor: "start", size: 12, fill: fill},
+			want: image.Rect(21, 21, 26, 30),
+		},
+		{
+			name: "space",
+			text: staticText{x: 20, y: 30, text: " ", anchor: "start", size: 12, fill: fill},
+			want: image.Rectangle{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, 60, 60))
+			drawText(img, tt.text)
+			var got image.Rectangle
+			for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+				for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+					if img.RGBAAt(x, y) == fill {
+						got = got.Union(image.Rect(x, y, x+1, y+1))
+					}
+				}
+			}
+			if got != tt.want {
+				t.Errorf("drawText() filled %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
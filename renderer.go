@@ -1,11 +1,27 @@
 package s3bytes
 
 import (
+	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"fmt"
 	"io"
+	"slices"
+	"strings"
 
+	"github.com/go-echarts/go-echarts/v2/components"
 	"github.com/nekrassov01/mintab"
+	"github.com/pkg/browser"
+)
+
+const (
+	highlightColor = "\x1b[33m"
+	resetColor     = "\x1b[0m"
+
+	// highlightStart and highlightEnd mark the cells to highlight in the table. They are control
+	// characters of zero width, so that the table is aligned as if they were not there.
+	highlightStart = "\x1e"
+	highlightEnd   = "\x1f"
 )
 
 // Renderer is a renderer struct for the s3bytes package.
@@ -14,15 +30,75 @@ type Renderer struct {
 	Data       *MetricData
 	OutputType OutputType
 	w          io.Writer
+	highlight  bool
+	chartType  ChartType
+	chartOut   string
+	noOpen     bool
+	topN       int
+	minPercent float64
+}
+
+// RendererOption is a functional option for the renderer.
+type RendererOption func(*Renderer)
+
+// WithHighlight sets whether to highlight the cells of buckets without datapoints.
+// Highlighting is applied to the text and compressed text formats with ANSI colors.
+func WithHighlight(highlight bool) RendererOption {
+	return func(ren *Renderer) {
+		ren.highlight = highlight
+	}
+}
+
+// WithChartType sets the kind of chart rendered by the chart output type.
+// If it is not set, a treemap is rendered for prefixes and a pie otherwise.
+func WithChartType(chartType ChartType) RendererOption {
+	return func(ren *Renderer) {
+		ren.chartType = chartType
+	}
+}
+
+// WithChartOutput sets the path of the file to write the chart to.
+// If the path is "-", the chart is written to the writer of the renderer.
+// If the path is empty, the chart is written to a new s3bytesN.html in the current directory.
+func WithChartOutput(path string) RendererOption {
+	return func(ren *Renderer) {
+		ren.chartOut = path
+	}
+}
+
+// WithNoOpen sets whether to skip opening the chart file in a browser.
+func WithNoOpen(noOpen bool) RendererOption {
+	return func(ren *Renderer) {
+		ren.noOpen = noOpen
+	}
+}
+
+// WithTopN sets the maximum number of items in a chart including "others", or MaxChartItems if n is not positive.
+func WithTopN(n int) RendererOption {
+	return func(ren *Renderer) {
+		ren.topN = n
+	}
+}
+
+// WithMinPercent sets the minimum share in percent of the buckets named in the pie chart.
+// Buckets below it are grouped into "others". If percent is not positive, no buckets are grouped by share.
+func WithMinPercent(percent float64) RendererOption {
+	return func(ren *Renderer) {
+		ren.minPercent = percent
+	}
 }
 
 // NewRenderer creates a new renderer with the specified parameters.
-func NewRenderer(w io.Writer, data *MetricData, outputType OutputType) *Renderer {
-	return &Renderer{
+func NewRenderer(w io.Writer, data *MetricData, outputType OutputType, opts ...RendererOption) *Renderer {
+	ren := &Renderer{
 		Data:       data,
 		OutputType: outputType,
 		w:          w,
 	}
+	for _, opt := range opts {
+		opt(ren)
+	}
+	return ren
 }
 
 // String returns the string representation of the renderer.
@@ -42,6 +118,10 @@ func (ren *Renderer) Render() error {
 		return ren.toTSV()
 	case OutputTypeChart:
 		return ren.toChart()
+	case OutputTypeHTML:
+		return ren.toHTML()
+	case OutputTypeSVG, OutputTypePNG:
+		return ren.toStatic()
 	default:
 		return nil
 	}
@@ -67,6 +147,9 @@ func (ren *Renderer) toTable() error {
 	case OutputTypeBacklog:
 		opt = mintab.WithFormat(mintab.BacklogFormat)
 	}
+	if ren.highlight && (ren.OutputType == OutputTypeText || ren.OutputType == OutputTypeCompressedText) {
+		return ren.toHighlightedTable(opt)
+	}
 	table := mintab.New(ren.w, opt)
 	if err := table.Load(ren.toInput()); err != nil {
 		return err
@@ -75,10 +158,53 @@ func (ren *Renderer) toTable() error {
 	return nil
 }
 
+// toHighlightedTable renders the table and colors the cells of buckets without datapoints.
+// The text of each cell is marked before rendering and the marks are replaced with the colors
+// after rendering, so that the cells are colored wherever they are placed. The numbers and
+// the empty cells are left as they are, so that they are aligned and shown in the same way.
+func (ren *Renderer) toHighlightedTable(opt mintab.Option) error {
+	input := ren.toInput()
+	for i, metric := range ren.Data.Metrics {
+		if metric.Status != DataStatusNoData {
+			continue
+		}
+		for j, v := range input.Data[i] {
+			input.Data[i][j] = highlightCell(v)
+		}
+	}
+	var buf bytes.Buffer
+	table := mintab.New(&buf, opt)
+	if err := table.Load(input); err != nil {
+		return err
+	}
+	table.Render()
+	r := strings.NewReplacer(highlightStart, highlightColor, highlightEnd, resetColor)
+	_, err := r.WriteString(ren.w, buf.String())
+	return err
+}
+
+// highlightCell marks the text of the cell to highlight. Each line is marked separately,
+// since the lines of a cell are placed on separate lines of the table.
+func highlightCell(v any) any {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		return v
+	}
+	if s == "" {
+		return v
+	}
+	return highlightStart + strings.ReplaceAll(s, "\n", highlightEnd+"\n"+highlightStart) + highlightEnd
+}
+
 func (ren *Renderer) toInput() mintab.Input {
 	data := make([][]any, len(ren.Data.Metrics))
 	for i, row := range ren.Data.Metrics {
-		data[i] = row.toInput()
+		data[i] = row.toInput(ren.Data.Header)
 	}
 	return mintab.Input{
 		Header: ren.Data.Header,
@@ -93,7 +219,7 @@ func (ren *Renderer) toTSV() error {
 		return err
 	}
 	for _, metric := range ren.Data.Metrics {
-		if err := w.Write(metric.toTSV()); err != nil {
+		if err := w.Write(metric.toTSV(ren.Data.Header)); err != nil {
 			return err
 		}
 	}
@@ -102,7 +228,84 @@ func (ren *Renderer) toTSV() error {
 }
 
 func (ren *Renderer) toChart() error {
-	title, items := getPieItems(ren.Data)
-	pie := newPie(title, items)
-	return render(pie)
+	var (
+		chart    components.Charter
+		prefixed = slices.Contains(ren.Data.Header, "Prefix")
+	)
+	switch ren.chartType {
+	case ChartTypePie:
+		chart = ren.toPie()
+	case ChartTypeBar:
+		title, names, items := getBarItems(ren.Data, ren.getTopN())
+		if bar := newBar(title, names, items); bar != nil {
+			chart = bar
+		}
+	case ChartTypeTreeMap:
+		title, items := getRegionTreeMapNodes(ren.Data)
+		if prefixed {
+			title, items = getTreeMapNodes(ren.Data)
+		}
+		if treemap := newTreeMap(title, items); treemap != nil {
+			chart = treemap
+		}
+	case ChartTypeSunburst:
+		title, items := getSunburstItems(ren.Data)
+		if sunburst := newSunburst(title, items); sunburst != nil {
+			chart = sunburst
+		}
+	default:
+		if !prefixed {
+			chart = ren.toPie()
+			break
+		}
+		title, items := getTreeMapNodes(ren.Data)
+		if treemap := newTreeMap(title, items); treemap != nil {
+			chart = treemap
+		}
+	}
+	if chart == nil {
+		return nil
+	}
+	return ren.render(chart)
+}
+
+// toPie returns the pie chart, or nil if there are no items.
+func (ren *Renderer) toPie() components.Charter {
+	title, items := getPieItems(ren.Data, ren.getTopN(), ren.minPercent)
+	pie := newPie(title, withPieValues(ren.Data, items))
+	if pie == nil {
+		return nil
+	}
+	return pie
+}
+
+// render writes the chart to the destination specified by the chart output,
+// and opens the file in a browser unless disabled.
+func (ren *Renderer) render(chart components.Charter) error {
+	if ren.chartOut == "-" {
+		return renderPage(ren.w, chart)
+	}
+	path := ren.chartOut
+	if path == "" {
+		p, err := nextChartFile()
+		if err != nil {
+			return err
+		}
+		path = p
+	}
+	if err := writeChart(path, chart); err != nil {
+		return err
+	}
+	if !ren.noOpen {
+		_ = browser.OpenFile(path)
+	}
+	return nil
+}
+
+// getTopN returns the maximum number of items in a chart, falling back to MaxChartItems.
+func (ren *Renderer) getTopN() int {
+	if ren.topN > 0 {
+		return ren.topN
+	}
+	return MaxChartItems
 }
@@ -3,12 +3,19 @@ package s3bytes
 import (
 	"bytes"
 	"io"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/google/go-cmp/cmp"
 )
 
+var testTimestamp = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
 var testSizeMetricData = &MetricData{
 	Header: header,
 	Metrics: []*Metric{
@@ -18,6 +25,10 @@ var testSizeMetricData = &MetricData{
 			MetricName:  MetricNameBucketSizeBytes,
 			StorageType: StorageTypeStandardStorage,
 			Value:       1024,
+			Status:      DataStatusOK,
+			Timestamp:   testTimestamp,
+			Source:      SourceTypeCloudWatch,
+			BucketType:  BucketTypeGeneralPurpose,
 		},
 		{
 			BucketName:  "bucket1",
@@ -25,6 +36,10 @@ var testSizeMetricData = &MetricData{
 			MetricName:  MetricNameBucketSizeBytes,
 			StorageType: StorageTypeGlacierStorage,
 			Value:       4096,
+			Status:      DataStatusOK,
+			Timestamp:   testTimestamp,
+			Source:      SourceTypeCloudWatch,
+			BucketType:  BucketTypeGeneralPurpose,
 		},
 	},
 }
@@ -38,6 +53,10 @@ var testObjectMetricData = &MetricData{
 			MetricName:  MetricNameNumberOfObjects,
 			StorageType: StorageTypeAllStorageTypes,
 			Value:       20,
+			Status:      DataStatusOK,
+			Timestamp:   testTimestamp,
+			Source:      SourceTypeCloudWatch,
+			BucketType:  BucketTypeGeneralPurpose,
 		},
 		{
 			BucketName:  "bucket1",
@@ -45,6 +64,9 @@ var testObjectMetricData = &MetricData{
 			MetricName:  MetricNameNumberOfObjects,
 			StorageType: StorageTypeAllStorageTypes,
 			Value:       0,
+			Status:      DataStatusNoData,
+			Source:      SourceTypeCloudWatch,
+			BucketType:  BucketTypeGeneralPurpose,
 		},
 	},
 }
@@ -53,6 +75,7 @@ func TestNewRenderer(t *testing.T) {
 	type args struct {
 		data       *MetricData
 		outputType OutputType
+		opts       []RendererOption
 	}
 	tests := []struct {
 		name  string
@@ -72,6 +95,50 @@ func TestNewRenderer(t *testing.T) {
 				w:          &bytes.Buffer{},
 			},
 		},
+		{
+			name: "with highlight",
+			args: args{
+				data:       testObjectMetricData,
+				outputType: OutputTypeText,
+				opts:       []RendererOption{WithHighlight(true)},
+			},
+			want: &Renderer{
+				Data:       testObjectMetricData,
+				OutputType: OutputTypeText,
+				w:          &bytes.Buffer{},
+				highlight:  true,
+			},
+		},
+		{
+			name: "with chart output",
+			args: args{
+				data:       testSizeMetricData,
+				outputType: OutputTypeChart,
+				opts:       []RendererOption{WithChartOutput("chart.html"), WithNoOpen(true)},
+			},
+			want: &Renderer{
+				Data:       testSizeMetricData,
+				OutputType: OutputTypeChart,
+				w:          &bytes.Buffer{},
+				chartOut:   "chart.html",
+				noOpen:     true,
+			},
+		},
+		{
+			name: "with top n",
+			args: args{
+				data:       testSizeMetricData,
+				outputType: OutputTypeChart,
+				opts:       []RendererOption{WithTopN(5), WithMinPercent(1.5)},
+			},
+			want: &Renderer{
+				Data:       testSizeMetricData,
+				OutputType: OutputTypeChart,
+				w:          &bytes.Buffer{},
+				topN:       5,
+				minPercent: 1.5,
+			},
+		},
 		{
 			name: "empty",
 			args: args{
@@ -88,7 +155,7 @@ func TestNewRenderer(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			w := &bytes.Buffer{}
-			if got := NewRenderer(w, tt.args.data, tt.args.outputType); !reflect.DeepEqual(got, tt.want) {
+			if got := NewRenderer(w, tt.args.data, tt.args.outputType, tt.args.opts...); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("NewRenderer() = %v, want %v", got, tt.want)
 			}
 			if gotW := w.String(); gotW != tt.wantW {
@@ -123,7 +190,11 @@ func TestRenderer_String(t *testing.T) {
       "Region",
       "MetricName",
       "StorageType",
-      "Value"
+      "Value",
+      "Status",
+      "Timestamp",
+      "Source",
+      "BucketType"
     ],
     "Metrics": [
       {
@@ -131,14 +202,22 @@ func TestRenderer_String(t *testing.T) {
         "Region": "ap-northeast-1",
         "MetricName": "BucketSizeBytes",
         "StorageType": "StandardStorage",
-        "Value": 1024
+        "Value": 1024,
+        "Status": "ok",
+        "Timestamp": "2026-01-01T00:00:00Z",
+        "Source": "cloudwatch",
+        "BucketType": "general-purpose"
       },
       {
         "BucketName": "bucket1",
         "Region": "ap-northeast-2",
         "MetricName": "BucketSizeBytes",
         "StorageType": "GlacierStorage",
-        "Value": 4096
+        "Value": 4096,
+        "Status": "ok",
+        "Timestamp": "2026-01-01T00:00:00Z",
+        "Source": "cloudwatch",
+        "BucketType": "general-purpose"
       }
     ],
     "Total": 0
@@ -177,6 +256,7 @@ func TestRenderer_Render(t *testing.T) {
 	type fields struct {
 		Data       *MetricData
 		OutputType OutputType
+		highlight  bool
 	}
 	tests := []struct {
 		name    string
@@ -190,7 +270,7 @@ func TestRenderer_Render(t *testing.T) {
 				Data:       testSizeMetricData,
 				OutputType: OutputTypeJSON,
 			},
-			want: `[{"BucketName":"bucket0","Region":"ap-northeast-1","MetricName":"BucketSizeBytes","StorageType":"StandardStorage","Value":1024},{"BucketName":"bucket1","Region":"ap-northeast-2","MetricName":"BucketSizeBytes","StorageType":"GlacierStorage","Value":4096}]
+			want: `[{"BucketName":"bucket0","Region":"ap-northeast-1","MetricName":"BucketSizeBytes","StorageType":"StandardStorage","Value":1024,"Status":"ok","Timestamp":"2026-01-01T00:00:00Z","Source":"cloudwatch","BucketType":"general-purpose"},{"BucketName":"bucket1","Region":"ap-northeast-2","MetricName":"BucketSizeBytes","StorageType":"GlacierStorage","Value":4096,"Status":"ok","Timestamp":"2026-01-01T00:00:00Z","Source":"cloudwatch","BucketType":"general-purpose"}]
 `,
 			wantErr: false,
 		},
@@ -206,14 +286,22 @@ func TestRenderer_Render(t *testing.T) {
     "Region": "ap-northeast-1",
     "MetricName": "BucketSizeBytes",
     "StorageType": "StandardStorage",
-    "Value": 1024
+    "Value": 1024,
+    "Status": "ok",
+    "Timestamp": "2026-01-01T00:00:00Z",
+    "Source": "cloudwatch",
+    "BucketType": "general-purpose"
   },
   {
     "BucketName": "bucket1",
     "Region": "ap-northeast-2",
     "MetricName": "BucketSizeBytes",
     "StorageType": "GlacierStorage",
-    "Value": 4096
+    "Value": 4096,
+    "Status": "ok",
+    "Timestamp": "2026-01-01T00:00:00Z",
+    "Source": "cloudwatch",
+    "BucketType": "general-purpose"
   }
 ]
 `,
@@ -225,13 +313,13 @@ func TestRenderer_Render(t *testing.T) {
 				Data:       testSizeMetricData,
 				OutputType: OutputTypeText,
 			},
-			want: `+------------+----------------+-----------------+-----------------+-------+
-| BucketName | Region         | MetricName      | StorageType     | Value |
-+------------+----------------+-----------------+-----------------+-------+
-| bucket0    | ap-northeast-1 | BucketSizeBytes | StandardStorage |  1024 |
-+------------+----------------+-----------------+-----------------+-------+
-| bucket1    | ap-northeast-2 | BucketSizeBytes | GlacierStorage  |  4096 |
-+------------+----------------+-----------------+-----------------+-------+
+			want: `+------------+----------------+-----------------+-----------------+-------+--------+----------------------+------------+-----------------+
+| BucketName | Region         | MetricName      | StorageType     | Value | Status | Timestamp            | Source     | BucketType      |
++------------+----------------+-----------------+-----------------+-------+--------+----------------------+------------+-----------------+
+| bucket0    | ap-northeast-1 | BucketSizeBytes | StandardStorage |  1024 | ok     | 2026-01-01T00:00:00Z | cloudwatch | general-purpose |
++------------+----------------+-----------------+-----------------+-------+--------+----------------------+------------+-----------------+
+| bucket1    | ap-northeast-2 | BucketSizeBytes | GlacierStorage  |  4096 | ok     | 2026-01-01T00:00:00Z | cloudwatch | general-purpose |
++------------+----------------+-----------------+-----------------+-------+--------+----------------------+------------+-----------------+
 `,
 			wantErr: false,
 		},
@@ -241,13 +329,13 @@ func TestRenderer_Render(t *testing.T) {
 				Data:       testObjectMetricData,
 				OutputType: OutputTypeText,
 			},
-			want: `+------------+----------------+-----------------+-----------------+-------+
-| BucketName | Region         | MetricName      | StorageType     | Value |
-+------------+----------------+-----------------+-----------------+-------+
-| bucket0    | ap-northeast-1 | NumberOfObjects | AllStorageTypes |    20 |
-+------------+----------------+-----------------+-----------------+-------+
-| bucket1    | ap-northeast-2 | NumberOfObjects | AllStorageTypes |     0 |
-+------------+----------------+-----------------+-----------------+-------+
+			want: `+------------+----------------+-----------------+-----------------+-------+---------+----------------------+------------+-----------------+
+| BucketName | Region         | MetricName      | StorageType     | Value | Status  | Timestamp            | Source     | BucketType      |
++------------+----------------+-----------------+-----------------+-------+---------+----------------------+------------+-----------------+
+| bucket0    | ap-northeast-1 | NumberOfObjects | AllStorageTypes |    20 | ok      | 2026-01-01T00:00:00Z | cloudwatch | general-purpose |
++------------+----------------+-----------------+-----------------+-------+---------+----------------------+------------+-----------------+
+| bucket1    | ap-northeast-2 | NumberOfObjects | AllStorageTypes |     0 | no-data | -                    | cloudwatch | general-purpose |
++------------+----------------+-----------------+-----------------+-------+---------+----------------------+------------+-----------------+
 `,
 			wantErr: false,
 		},
@@ -257,12 +345,12 @@ func TestRenderer_Render(t *testing.T) {
 				Data:       testSizeMetricData,
 				OutputType: OutputTypeCompressedText,
 			},
-			want: `+------------+----------------+-----------------+-----------------+-------+
-| BucketName | Region         | MetricName      | StorageType     | Value |
-+------------+----------------+-----------------+-----------------+-------+
-| bucket0    | ap-northeast-1 | BucketSizeBytes | StandardStorage |  1024 |
-| bucket1    | ap-northeast-2 | BucketSizeBytes | GlacierStorage  |  4096 |
-+------------+----------------+-----------------+-----------------+-------+
+			want: `+------------+----------------+-----------------+-----------------+-------+--------+----------------------+------------+-----------------+
+| BucketName | Region         | MetricName      | StorageType     | Value | Status | Timestamp            | Source     | BucketType      |
++------------+----------------+-----------------+-----------------+-------+--------+----------------------+------------+-----------------+
+| bucket0    | ap-northeast-1 | BucketSizeBytes | StandardStorage |  1024 | ok     | 2026-01-01T00:00:00Z | cloudwatch | general-purpose |
+| bucket1    | ap-northeast-2 | BucketSizeBytes | GlacierStorage  |  4096 | ok     | 2026-01-01T00:00:00Z | cloudwatch | general-purpose |
++------------+----------------+-----------------+-----------------+-------+--------+----------------------+------------+-----------------+
 `,
 			wantErr: false,
 		},
@@ -272,10 +360,10 @@ func TestRenderer_Render(t *testing.T) {
 				Data:       testSizeMetricData,
 				OutputType: OutputTypeMarkdown,
 			},
-			want: `| BucketName | Region         | MetricName      | StorageType     | Value |
-|------------|----------------|-----------------|-----------------|-------|
-| bucket0    | ap-northeast-1 | BucketSizeBytes | StandardStorage |  1024 |
-| bucket1    | ap-northeast-2 | BucketSizeBytes | GlacierStorage  |  4096 |
+			want: `| BucketName | Region         | MetricName      | StorageType     | Value | Status | Timestamp            | Source     | BucketType      |
+|------------|----------------|-----------------|-----------------|-------|--------|----------------------|------------|-----------------|
+| bucket0    | ap-northeast-1 | BucketSizeBytes | StandardStorage |  1024 | ok     | 2026-01-01T00:00:00Z | cloudwatch | general-purpose |
+| bucket1    | ap-northeast-2 | BucketSizeBytes | GlacierStorage  |  4096 | ok     | 2026-01-01T00:00:00Z | cloudwatch | general-purpose |
 `,
 			wantErr: false,
 		},
@@ -285,9 +373,9 @@ func TestRenderer_Render(t *testing.T) {
 				Data:       testSizeMetricData,
 				OutputType: OutputTypeBacklog,
 			},
-			want: `| BucketName | Region         | MetricName      | StorageType     | Value |h
-| bucket0    | ap-northeast-1 | BucketSizeBytes | StandardStorage |  1024 |
-| bucket1    | ap-northeast-2 | BucketSizeBytes | GlacierStorage  |  4096 |
+			want: `| BucketName | Region         | MetricName      | StorageType     | Value | Status | Timestamp            | Source     | BucketType      |h
+| bucket0    | ap-northeast-1 | BucketSizeBytes | StandardStorage |  1024 | ok     | 2026-01-01T00:00:00Z | cloudwatch | general-purpose |
+| bucket1    | ap-northeast-2 | BucketSizeBytes | GlacierStorage  |  4096 | ok     | 2026-01-01T00:00:00Z | cloudwatch | general-purpose |
 `,
 			wantErr: false,
 		},
@@ -297,9 +385,9 @@ func TestRenderer_Render(t *testing.T) {
 				Data:       testSizeMetricData,
 				OutputType: OutputTypeTSV,
 			},
-			want: `BucketName	Region	MetricName	StorageType	Value
-bucket0	ap-northeast-1	BucketSizeBytes	StandardStorage	1024
-bucket1	ap-northeast-2	BucketSizeBytes	GlacierStorage	4096
+			want: `BucketName	Region	MetricName	StorageType	Value	Status	Timestamp	Source	BucketType
+bucket0	ap-northeast-1	BucketSizeBytes	StandardStorage	1024	ok	2026-01-01T00:00:00Z	cloudwatch	general-purpose
+bucket1	ap-northeast-2	BucketSizeBytes	GlacierStorage	4096	ok	2026-01-01T00:00:00Z	cloudwatch	general-purpose
 `,
 			wantErr: false,
 		},
@@ -309,9 +397,105 @@ bucket1	ap-northeast-2	BucketSizeBytes	GlacierStorage	4096
 				Data:       testObjectMetricData,
 				OutputType: OutputTypeTSV,
 			},
-			want: `BucketName	Region	MetricName	StorageType	Value
-bucket0	ap-northeast-1	NumberOfObjects	AllStorageTypes	20
-bucket1	ap-northeast-2	NumberOfObjects	AllStorageTypes	0
+			want: `BucketName	Region	MetricName	StorageType	Value	Status	Timestamp	Source	BucketType
+bucket0	ap-northeast-1	NumberOfObjects	AllStorageTypes	20	ok	2026-01-01T00:00:00Z	cloudwatch	general-purpose
+bucket1	ap-northeast-2	NumberOfObjects	AllStorageTypes	0	no-data		cloudwatch	general-purpose
+`,
+			wantErr: false,
+		},
+		{
+			name: "tsv for latency metric",
+			fields: fields{
+				Data: &MetricData{
+					Header: []string{"BucketName", "MetricName", "Value", "Delta"},
+					Metrics: []*Metric{
+						{BucketName: "bucket0", MetricName: MetricNameFirstByteLatency, Value: 12.345, Delta: aws.Float64(-0.5)},
+						{BucketName: "bucket1", MetricName: MetricNameFirstByteLatency, Value: 8},
+					},
+				},
+				OutputType: OutputTypeTSV,
+			},
+			want: `BucketName	MetricName	Value	Delta
+bucket0	FirstByteLatency	12.35	-0.50
+bucket1	FirstByteLatency	8.00	
+`,
+			wantErr: false,
+		},
+		{
+			name: "tsv for anomaly",
+			fields: fields{
+				Data: &MetricData{
+					Header: append([]string{"BucketName", "Value"}, anomalyHeader...),
+					Metrics: []*Metric{
+						{
+							BucketName: "bucket0",
+							MetricName: MetricNameBucketSizeBytes,
+							Value:      2048.4,
+							Anomaly: Anomaly{
+								AnomalyDate:   testTimestamp,
+								Change:        1024.4,
+								ChangePercent: 100.04,
+								ZScore:        3.456,
+							},
+						},
+					},
+				},
+				OutputType: OutputTypeTSV,
+			},
+			want: `BucketName	Value	AnomalyDate	Change	ChangePercent	ZScore
+bucket0	2048	2026-01-01T00:00:00Z	1024	100.04	3.46
+`,
+			wantErr: false,
+		},
+		{
+			name: "highlight compressed text",
+			fields: fields{
+				Data:       testObjectMetricData,
+				OutputType: OutputTypeCompressedText,
+				highlight:  true,
+			},
+			want: "+------------+----------------+-----------------+-----------------+-------+---------+----------------------+------------+-----------------+\n" +
+				"| BucketName | Region         | MetricName      | StorageType     | Value | Status  | Timestamp            | Source     | BucketType      |\n" +
+				"+------------+----------------+-----------------+-----------------+-------+---------+----------------------+------------+-----------------+\n" +
+				"| bucket0    | ap-northeast-1 | NumberOfObjects | AllStorageTypes |    20 | ok      | 2026-01-01T00:00:00Z | cloudwatch | general-purpose |\n" +
+				"| \x1b[33mbucket1\x1b[0m    | \x1b[33map-northeast-2\x1b[0m | \x1b[33mNumberOfObjects\x1b[0m | \x1b[33mAllStorageTypes\x1b[0m |     0 | \x1b[33mno-data\x1b[0m | -                    | \x1b[33mcloudwatch\x1b[0m | \x1b[33mgeneral-purpose\x1b[0m |\n" +
+				"+------------+----------------+-----------------+-----------------+-------+---------+----------------------+------------+-----------------+\n",
+			wantErr: false,
+		},
+		{
+			name: "highlight text with multiline cells",
+			fields: fields{
+				Data: &MetricData{
+					Header: []string{"BucketName", "Status", "Tag:note"},
+					Metrics: []*Metric{
+						{BucketName: "bucket0", Status: DataStatusOK, Tags: map[string]string{"note": "a\nb"}},
+						{BucketName: "bucket1", Status: DataStatusNoData, Tags: map[string]string{"note": "c\nd"}},
+					},
+				},
+				OutputType: OutputTypeText,
+				highlight:  true,
+			},
+			want: "+------------+---------+----------+\n" +
+				"| BucketName | Status  | Tag:note |\n" +
+				"+------------+---------+----------+\n" +
+				"| bucket0    | ok      | a        |\n" +
+				"|            |         | b        |\n" +
+				"+------------+---------+----------+\n" +
+				"| \x1b[33mbucket1\x1b[0m    | \x1b[33mno-data\x1b[0m | \x1b[33mc\x1b[0m        |\n" +
+				"|            |         | \x1b[33md\x1b[0m        |\n" +
+				"+------------+---------+----------+\n",
+			wantErr: false,
+		},
+		{
+			name: "highlight is ignored for tsv",
+			fields: fields{
+				Data:       testObjectMetricData,
+				OutputType: OutputTypeTSV,
+				highlight:  true,
+			},
+			want: `BucketName	Region	MetricName	StorageType	Value	Status	Timestamp	Source	BucketType
+bucket0	ap-northeast-1	NumberOfObjects	AllStorageTypes	20	ok	2026-01-01T00:00:00Z	cloudwatch	general-purpose
+bucket1	ap-northeast-2	NumberOfObjects	AllStorageTypes	0	no-data		cloudwatch	general-purpose
 `,
 			wantErr: false,
 		},
@@ -323,6 +507,7 @@ bucket1	ap-northeast-2	NumberOfObjects	AllStorageTypes	0
 				Data:       tt.fields.Data,
 				OutputType: tt.fields.OutputType,
 				w:          w,
+				highlight:  tt.fields.highlight,
 			}
 			if err := ren.Render(); (err != nil) != tt.wantErr {
 				t.Errorf("Renderer.Render() error = %v, wantErr %v", err, tt.wantErr)
@@ -333,3 +518,156 @@ bucket1	ap-northeast-2	NumberOfObjects	AllStorageTypes	0
 		})
 	}
 }
+
+func TestRenderer_render(t *testing.T) {
+	dir := t.TempDir()
+	type fields struct {
+		Data      *MetricData
+		chartType ChartType
+		chartOut  string
+	}
+	tests := []struct {
+		name     string
+		fields   fields
+		wantFile string
+		wantW    bool
+		wantErr  bool
+	}{
+		{
+			name: "file",
+			fields: fields{
+				Data:     testSizeMetricData,
+				chartOut: filepath.Join(dir, "chart.html"),
+			},
+			wantFile: filepath.Join(dir, "chart.html"),
+			wantW:    false,
+			wantErr:  false,
+		},
+		{
+			name: "stdout",
+			fields: fields{
+				Data:     testSizeMetricData,
+				chartOut: "-",
+			},
+			wantW:   true,
+			wantErr: false,
+		},
+		{
+			name: "default",
+			fields: fields{
+				Data: testObjectMetricData,
+			},
+			wantFile: filepath.Join(dir, "s3bytes.html"),
+			wantW:    false,
+			wantErr:  false,
+		},
+		{
+			name: "bar",
+			fields: fields{
+				Data:      testSizeMetricData,
+				chartType: ChartTypeBar,
+				chartOut:  "-",
+			},
+			wantW:   true,
+			wantErr: false,
+		},
+		{
+			name: "treemap",
+			fields: fields{
+				Data:      testSizeMetricData,
+				chartType: ChartTypeTreeMap,
+				chartOut:  "-",
+			},
+			wantW:   true,
+			wantErr: false,
+		},
+		{
+			name: "sunburst",
+			fields: fields{
+				Data:      testSizeMetricData,
+				chartType: ChartTypeSunburst,
+				chartOut:  "-",
+			},
+			wantW:   true,
+			wantErr: false,
+		},
+		{
+			name: "pie without items",
+			fields: fields{
+				Data:      &MetricData{Header: header},
+				chartType: ChartTypePie,
+				chartOut:  "-",
+			},
+			wantW:   false,
+			wantErr: false,
+		},
+		{
+			name: "directory not found",
+			fields: fields{
+				Data:     testSizeMetricData,
+				chartOut: filepath.Join(dir, "unknown", "chart.html"),
+			},
+			wantErr: true,
+		},
+	}
+	t.Chdir(dir)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			ren := &Renderer{
+				Data:       tt.fields.Data,
+				OutputType: OutputTypeChart,
+				w:          w,
+				chartType:  tt.fields.chartType,
+				chartOut:   tt.fields.chartOut,
+				noOpen:     true,
+			}
+			if err := ren.Render(); (err != nil) != tt.wantErr {
+				t.Errorf("Renderer.Render() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotW := strings.Contains(w.String(), "<html>"); gotW != tt.wantW {
+				t.Errorf("Renderer.Render() wrote to writer = %v, want %v", gotW, tt.wantW)
+			}
+			if tt.wantFile != "" {
+				if _, err := os.Stat(tt.wantFile); err != nil {
+					t.Errorf("Renderer.Render() file = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderer_getTopN(t *testing.T) {
+	tests := []struct {
+		name string
+		topN int
+		want int
+	}{
+		{
+			name: "set",
+			topN: 5,
+			want: 5,
+		},
+		{
+			name: "default",
+			topN: 0,
+			want: MaxChartItems,
+		},
+		{
+			name: "negative",
+			topN: -1,
+			want: MaxChartItems,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ren := &Renderer{
+				topN: tt.topN,
+			}
+			if got := ren.getTopN(); got != tt.want {
+				t.Errorf("Renderer.getTopN() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,26 +2,315 @@ package s3bytes
 
 import (
 	"context"
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 )
 
-// getBuckets returns the buckets in the specified region.
-func (man *Manager) getBuckets(ctx context.Context, region string) ([]types.Bucket, error) {
+const (
+	// statusEnabled is the status of the bucket metadata that means the feature is enabled.
+	statusEnabled = "Enabled"
+
+	// statusDisabled is the status of the bucket metadata that means the feature is not configured.
+	statusDisabled = "Disabled"
+
+	// statusUnknown is the status of the bucket metadata that could not be fetched, such as for lack of permission.
+	statusUnknown = "Unknown"
+
+	// encryptionNone is the default encryption of the buckets without any encryption configuration.
+	encryptionNone = "None"
+
+	// directoryBucketSuffix is the suffix of the names of the directory buckets, such as "bucket--apne1-az4--x-s3".
+	directoryBucketSuffix = "--x-s3"
+)
+
+// listBuckets returns the general purpose buckets and the directory buckets in the specified region.
+func listBuckets(ctx context.Context, client S3API, region, prefix string) ([]s3types.Bucket, error) {
+	buckets, err := listGeneralPurposeBuckets(ctx, client, region, prefix)
+	if err != nil {
+		return nil, err
+	}
+	directories, err := listDirectoryBuckets(ctx, client, region, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return append(buckets, directories...), nil
+}
+
+// listGeneralPurposeBuckets returns the general purpose buckets in the specified region.
+func listGeneralPurposeBuckets(ctx context.Context, client S3API, region, prefix string) ([]s3types.Bucket, error) {
 	in := &s3.ListBucketsInput{
 		BucketRegion: aws.String(region),
 	}
-	if man.prefix != nil {
-		in.Prefix = man.prefix
+	if prefix != "" {
+		in.Prefix = aws.String(prefix)
 	}
 	opt := func(o *s3.Options) {
 		o.Region = region
 	}
-	out, err := man.client.ListBuckets(ctx, in, opt)
+	out, err := client.ListBuckets(ctx, in, opt)
 	if err != nil {
 		return nil, err
 	}
 	return out.Buckets, nil
 }
+
+// listDirectoryBuckets returns the directory buckets in the specified region.
+// Since ListDirectoryBuckets does not accept a prefix, the buckets are filtered by the prefix here.
+// AccessDenied and NotImplemented are regarded as no directory buckets, so that the users
+// without s3express:ListAllMyDirectoryBuckets can still list the general purpose buckets,
+// and so is the endpoint that does not exist in the regions where S3 Express One Zone is not available.
+func listDirectoryBuckets(ctx context.Context, client S3API, region, prefix string) ([]s3types.Bucket, error) {
+	var (
+		buckets = make([]s3types.Bucket, 0)
+		opt     = func(o *s3.Options) { o.Region = region }
+	)
+	paginator := s3.NewListDirectoryBucketsPaginator(client, &s3.ListDirectoryBucketsInput{})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx, opt)
+		if err != nil {
+			if isAPIError(err, "AccessDenied", "NotImplemented") || isEndpointNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		for _, bucket := range out.Buckets {
+			if strings.HasPrefix(aws.ToString(bucket.Name), prefix) {
+				buckets = append(buckets, bucket)
+			}
+		}
+	}
+	return buckets, nil
+}
+
+// isDirectoryBucket reports whether the bucket is a directory bucket by the suffix of the name.
+func isDirectoryBucket(bucket string) bool {
+	return strings.HasSuffix(bucket, directoryBucketSuffix)
+}
+
+// isEndpointNotFound reports whether the error is the failure to resolve the host of the endpoint,
+// which is returned for the services not available in the region.
+func isEndpointNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// directoryBucketRegion returns the region of the directory bucket from the zone ID in the name,
+// such as "ap-northeast-1" for "bucket--apne1-az4--x-s3". It reports false if the name has no zone ID
+// of the known regions.
+func directoryBucketRegion(bucket string) (string, bool) {
+	name, ok := strings.CutSuffix(bucket, directoryBucketSuffix)
+	if !ok {
+		return "", false
+	}
+	i := strings.LastIndex(name, "--")
+	if i < 0 {
+		return "", false
+	}
+	zone, _, ok := strings.Cut(name[i+2:], "-")
+	if !ok {
+		return "", false
+	}
+	for region := range allowedRegions {
+		if zoneRegionPrefix(region) == zone {
+			return region, true
+		}
+	}
+	return "", false
+}
+
+// zoneRegionPrefix returns the prefix of the zone IDs in the region, which abbreviates the direction,
+// such as "apne1" for "ap-northeast-1" and "euc1" for "eu-central-1".
+func zoneRegionPrefix(region string) string {
+	parts := strings.Split(region, "-")
+	if len(parts) != 3 {
+		return ""
+	}
+	direction, ok := zoneDirections[parts[1]]
+	if !ok {
+		return ""
+	}
+	return parts[0] + direction + parts[2]
+}
+
+// getBucketType returns the type of the bucket.
+func getBucketType(bucket string) BucketType {
+	if isDirectoryBucket(bucket) {
+		return BucketTypeDirectory
+	}
+	return BucketTypeGeneralPurpose
+}
+
+// getBuckets returns the names of the buckets in the specified region.
+func getBuckets(ctx context.Context, client S3API, region, prefix string) ([]string, error) {
+	out, err := listBuckets(ctx, client, region, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return bucketNames(out), nil
+}
+
+// bucketNames returns the names of the buckets.
+func bucketNames(buckets []s3types.Bucket) []string {
+	names := make([]string, len(buckets))
+	for i, bucket := range buckets {
+		names[i] = aws.ToString(bucket.Name)
+	}
+	return names
+}
+
+// creationDates returns the creation dates of the buckets by name.
+// The buckets without a creation date are omitted.
+func creationDates(buckets []s3types.Bucket) map[string]time.Time {
+	dates := make(map[string]time.Time, len(buckets))
+	for _, bucket := range buckets {
+		if bucket.CreationDate != nil {
+			dates[aws.ToString(bucket.Name)] = aws.ToTime(bucket.CreationDate)
+		}
+	}
+	return dates
+}
+
+// isAPIError reports whether the error is an API error with any of the codes.
+func isAPIError(err error, codes ...string) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && slices.Contains(codes, apiErr.ErrorCode())
+}
+
+// getBucketRegion returns the region of the general purpose bucket with GetBucketLocation,
+// which answers for the buckets in any region. The buckets in us-east-1 have no location constraint,
+// and the legacy "EU" constraint means eu-west-1.
+func getBucketRegion(ctx context.Context, client S3API, bucket string) (string, error) {
+	in := &s3.GetBucketLocationInput{
+		Bucket: aws.String(bucket),
+	}
+	out, err := client.GetBucketLocation(ctx, in)
+	if err != nil {
+		return "", err
+	}
+	switch out.LocationConstraint {
+	case "":
+		return "us-east-1", nil
+	case s3types.BucketLocationConstraintEu:
+		return "eu-west-1", nil
+	default:
+		return string(out.LocationConstraint), nil
+	}
+}
+
+// getBucketTags returns the tags of the bucket in the specified region.
+// A bucket without tags is returned as an empty map instead of the NoSuchTagSet error.
+// The other errors of the bucket such as AccessDenied return nil in the same way as getBucketMetadata,
+// so that a partial permission does not fail the whole run. Only the error of the context is returned.
+func getBucketTags(ctx context.Context, client S3API, bucket, region string) (map[string]string, error) {
+	in := &s3.GetBucketTaggingInput{
+		Bucket: aws.String(bucket),
+	}
+	opt := func(o *s3.Options) {
+		o.Region = region
+	}
+	out, err := client.GetBucketTagging(ctx, in, opt)
+	switch {
+	case err == nil:
+	case isAPIError(err, "NoSuchTagSet"):
+		return map[string]string{}, nil
+	case ctx.Err() != nil:
+		return nil, err
+	default:
+		return nil, nil
+	}
+	tags := make(map[string]string, len(out.TagSet))
+	for _, tag := range out.TagSet {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags, nil
+}
+
+// getBucketMetadata returns the versioning status, the presence of enabled lifecycle rules,
+// the default encryption and the object lock status of the bucket in the specified region.
+// The errors meaning that the feature is not configured are regarded as disabled, and the other
+// errors of the bucket such as AccessDenied set the field to unknown, so that a partial permission
+// does not fail the whole run. Only the error of the context is returned.
+// The creation date is not set since it is returned by ListBuckets.
+func getBucketMetadata(ctx context.Context, client S3API, bucket, region string) (BucketMetadata, error) {
+	metadata := BucketMetadata{
+		Versioning: statusDisabled,
+		Lifecycle:  statusDisabled,
+		Encryption: encryptionNone,
+		ObjectLock: statusDisabled,
+	}
+	opt := func(o *s3.Options) {
+		o.Region = region
+	}
+	versioning, err := client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{
+		Bucket: aws.String(bucket),
+	}, opt)
+	switch {
+	case err == nil:
+		if versioning.Status != "" {
+			metadata.Versioning = string(versioning.Status)
+		}
+	case ctx.Err() != nil:
+		return BucketMetadata{}, err
+	default:
+		metadata.Versioning = statusUnknown
+	}
+	lifecycle, err := client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(bucket),
+	}, opt)
+	switch {
+	case err == nil:
+		if slices.ContainsFunc(lifecycle.Rules, func(rule s3types.LifecycleRule) bool {
+			return rule.Status == s3types.ExpirationStatusEnabled
+		}) {
+			metadata.Lifecycle = statusEnabled
+		}
+	case isAPIError(err, "NoSuchLifecycleConfiguration"):
+	case ctx.Err() != nil:
+		return BucketMetadata{}, err
+	default:
+		metadata.Lifecycle = statusUnknown
+	}
+	encryption, err := client.GetBucketEncryption(ctx, &s3.GetBucketEncryptionInput{
+		Bucket: aws.String(bucket),
+	}, opt)
+	switch {
+	case err == nil:
+		if encryption.ServerSideEncryptionConfiguration == nil {
+			break
+		}
+		for _, rule := range encryption.ServerSideEncryptionConfiguration.Rules {
+			if rule.ApplyServerSideEncryptionByDefault != nil {
+				metadata.Encryption = string(rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm)
+				break
+			}
+		}
+	case isAPIError(err, "ServerSideEncryptionConfigurationNotFoundError"):
+	case ctx.Err() != nil:
+		return BucketMetadata{}, err
+	default:
+		metadata.Encryption = statusUnknown
+	}
+	objectLock, err := client.GetObjectLockConfiguration(ctx, &s3.GetObjectLockConfigurationInput{
+		Bucket: aws.String(bucket),
+	}, opt)
+	switch {
+	case err == nil:
+		if objectLock.ObjectLockConfiguration != nil && objectLock.ObjectLockConfiguration.ObjectLockEnabled == s3types.ObjectLockEnabledEnabled {
+			metadata.ObjectLock = statusEnabled
+		}
+	case isAPIError(err, "ObjectLockConfigurationNotFoundError"):
+	case ctx.Err() != nil:
+		return BucketMetadata{}, err
+	default:
+		metadata.ObjectLock = statusUnknown
+	}
+	return metadata, nil
+}
@@ -3,40 +3,42 @@ package s3bytes
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net"
 	"reflect"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
-	"golang.org/x/sync/semaphore"
+	"github.com/aws/smithy-go"
 )
 
-func TestManager_getBuckets(t *testing.T) {
-	type fields struct {
-		client      *Client
-		metricName  MetricName
-		storageType StorageType
-		prefix      *string
-		regions     []string
-		sem         *semaphore.Weighted
-	}
+// listNoDirectoryBuckets is the mock of ListDirectoryBuckets that returns no directory buckets.
+func listNoDirectoryBuckets(_ context.Context, _ *s3.ListDirectoryBucketsInput, _ ...func(*s3.Options)) (*s3.ListDirectoryBucketsOutput, error) {
+	return &s3.ListDirectoryBucketsOutput{}, nil
+}
+
+func Test_getBuckets(t *testing.T) {
 	type args struct {
 		ctx    context.Context
+		client S3API
 		region string
+		prefix string
 	}
 	tests := []struct {
 		name    string
-		fields  fields
 		args    args
-		want    []types.Bucket
+		want    []string
 		wantErr bool
 	}{
 		{
 			name: "single bucket",
-			fields: fields{
+			args: args{
+				ctx: context.Background(),
 				client: newMockClient(
 					&mockS3{
+						ListDirectoryBucketsFunc: listNoDirectoryBuckets,
 						ListBucketsFunc: func(_ context.Context, _ *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
 							out := &s3.ListBucketsOutput{
 								Buckets: []types.Bucket{
@@ -52,25 +54,18 @@ func TestManager_getBuckets(t *testing.T) {
 					},
 					nil,
 				),
-				prefix: nil,
-			},
-			args: args{
-				ctx:    context.Background(),
 				region: "ap-northeast-1",
 			},
-			want: []types.Bucket{
-				{
-					Name:         aws.String("bucket0"),
-					BucketRegion: aws.String("ap-northeast-1"),
-				},
-			},
+			want:    []string{"bucket0"},
 			wantErr: false,
 		},
 		{
 			name: "multiple bucket",
-			fields: fields{
+			args: args{
+				ctx: context.Background(),
 				client: newMockClient(
 					&mockS3{
+						ListDirectoryBucketsFunc: listNoDirectoryBuckets,
 						ListBucketsFunc: func(_ context.Context, _ *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
 							out := &s3.ListBucketsOutput{
 								Buckets: []types.Bucket{
@@ -90,38 +85,178 @@ func TestManager_getBuckets(t *testing.T) {
 					},
 					nil,
 				),
-				prefix: nil,
+				region: "ap-northeast-1",
 			},
+			want:    []string{"bucket0", "bucket1"},
+			wantErr: false,
+		},
+		{
+			name: "prefix",
 			args: args{
-				ctx:    context.Background(),
+				ctx: context.Background(),
+				client: newMockClient(
+					&mockS3{
+						ListDirectoryBucketsFunc: listNoDirectoryBuckets,
+						ListBucketsFunc: func(_ context.Context, params *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+							if aws.ToString(params.Prefix) != "bucket" {
+								return nil, errors.New("unexpected prefix")
+							}
+							out := &s3.ListBucketsOutput{
+								Buckets: []types.Bucket{
+									{
+										Name:         aws.String("bucket0"),
+										BucketRegion: aws.String("ap-northeast-1"),
+									},
+								},
+							}
+							return out, nil
+						},
+					},
+					nil,
+				),
 				region: "ap-northeast-1",
+				prefix: "bucket",
 			},
-			want: []types.Bucket{
-				{
-					Name:         aws.String("bucket0"),
-					BucketRegion: aws.String("ap-northeast-1"),
-				},
-				{
-					Name:         aws.String("bucket1"),
-					BucketRegion: aws.String("ap-northeast-2"),
-				},
+			want:    []string{"bucket0"},
+			wantErr: false,
+		},
+		{
+			name: "directory buckets",
+			args: args{
+				ctx: context.Background(),
+				client: newMockClient(
+					&mockS3{
+						ListDirectoryBucketsFunc: func(_ context.Context, params *s3.ListDirectoryBucketsInput, _ ...func(*s3.Options)) (*s3.ListDirectoryBucketsOutput, error) {
+							if params.ContinuationToken == nil {
+								return &s3.ListDirectoryBucketsOutput{
+									Buckets: []types.Bucket{
+										{
+											Name: aws.String("bucket1--apne1-az4--x-s3"),
+										},
+									},
+									ContinuationToken: aws.String("token"),
+								}, nil
+							}
+							return &s3.ListDirectoryBucketsOutput{
+								Buckets: []types.Bucket{
+									{
+										Name: aws.String("bucket2--apne1-az4--x-s3"),
+									},
+									{
+										Name: aws.String("other--apne1-az4--x-s3"),
+									},
+								},
+							}, nil
+						},
+						ListBucketsFunc: func(_ context.Context, _ *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+							out := &s3.ListBucketsOutput{
+								Buckets: []types.Bucket{
+									{
+										Name:         aws.String("bucket0"),
+										BucketRegion: aws.String("ap-northeast-1"),
+									},
+								},
+							}
+							return out, nil
+						},
+					},
+					nil,
+				),
+				region: "ap-northeast-1",
+				prefix: "bucket",
 			},
+			want:    []string{"bucket0", "bucket1--apne1-az4--x-s3", "bucket2--apne1-az4--x-s3"},
 			wantErr: false,
 		},
 		{
-			name: "error",
-			fields: fields{
+			name: "directory buckets not supported in region",
+			args: args{
+				ctx: context.Background(),
 				client: newMockClient(
 					&mockS3{
+						ListDirectoryBucketsFunc: func(_ context.Context, _ *s3.ListDirectoryBucketsInput, _ ...func(*s3.Options)) (*s3.ListDirectoryBucketsOutput, error) {
+							return nil, &net.DNSError{Err: "no such host", Name: "s3express-control.ap-northeast-3.amazonaws.com", IsNotFound: true}
+						},
 						ListBucketsFunc: func(_ context.Context, _ *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
-							return nil, errors.New("failed to list buckets")
+							out := &s3.ListBucketsOutput{
+								Buckets: []types.Bucket{
+									{
+										Name:         aws.String("bucket0"),
+										BucketRegion: aws.String("ap-northeast-3"),
+									},
+								},
+							}
+							return out, nil
 						},
 					},
 					nil,
 				),
+				region: "ap-northeast-3",
 			},
+			want:    []string{"bucket0"},
+			wantErr: false,
+		},
+		{
+			name: "directory buckets access denied",
 			args: args{
-				ctx:    context.Background(),
+				ctx: context.Background(),
+				client: newMockClient(
+					&mockS3{
+						ListDirectoryBucketsFunc: func(_ context.Context, _ *s3.ListDirectoryBucketsInput, _ ...func(*s3.Options)) (*s3.ListDirectoryBucketsOutput, error) {
+							return nil, &smithy.GenericAPIError{Code: "AccessDenied"}
+						},
+						ListBucketsFunc: func(_ context.Context, _ *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+							out := &s3.ListBucketsOutput{
+								Buckets: []types.Bucket{
+									{
+										Name:         aws.String("bucket0"),
+										BucketRegion: aws.String("ap-northeast-1"),
+									},
+								},
+							}
+							return out, nil
+						},
+					},
+					nil,
+				),
+				region: "ap-northeast-1",
+			},
+			want:    []string{"bucket0"},
+			wantErr: false,
+		},
+		{
+			name: "directory buckets error",
+			args: args{
+				ctx: context.Background(),
+				client: newMockClient(
+					&mockS3{
+						ListDirectoryBucketsFunc: func(_ context.Context, _ *s3.ListDirectoryBucketsInput, _ ...func(*s3.Options)) (*s3.ListDirectoryBucketsOutput, error) {
+							return nil, errors.New("failed to list directory buckets")
+						},
+						ListBucketsFunc: func(_ context.Context, _ *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+							return &s3.ListBucketsOutput{}, nil
+						},
+					},
+					nil,
+				),
+				region: "ap-northeast-1",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "error",
+			args: args{
+				ctx: context.Background(),
+				client: newMockClient(
+					&mockS3{
+						ListDirectoryBucketsFunc: listNoDirectoryBuckets,
+						ListBucketsFunc: func(_ context.Context, _ *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+							return nil, errors.New("failed to list buckets")
+						},
+					},
+					nil,
+				),
 				region: "ap-northeast-1",
 			},
 			want:    nil,
@@ -129,9 +264,11 @@ func TestManager_getBuckets(t *testing.T) {
 		},
 		{
 			name: "no buckets",
-			fields: fields{
+			args: args{
+				ctx: context.Background(),
 				client: newMockClient(
 					&mockS3{
+						ListDirectoryBucketsFunc: listNoDirectoryBuckets,
 						ListBucketsFunc: func(_ context.Context, _ *s3.ListBucketsInput, _ ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
 							out := &s3.ListBucketsOutput{
 								Buckets:           []types.Bucket{},
@@ -142,32 +279,392 @@ func TestManager_getBuckets(t *testing.T) {
 					},
 					nil,
 				),
+				region: "ap-northeast-1",
 			},
+			want:    []string{},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getBuckets(tt.args.ctx, tt.args.client, tt.args.region, tt.args.prefix)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getBuckets() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getBuckets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_directoryBucketRegion(t *testing.T) {
+	tests := []struct {
+		name   string
+		bucket string
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "ap-northeast-1",
+			bucket: "bucket0--apne1-az4--x-s3",
+			want:   "ap-northeast-1",
+			wantOK: true,
+		},
+		{
+			name:   "us-east-1 with hyphens in name",
+			bucket: "my-bucket--data--use1-az5--x-s3",
+			want:   "us-east-1",
+			wantOK: true,
+		},
+		{
+			name:   "eu-central-1",
+			bucket: "bucket0--euc1-az2--x-s3",
+			want:   "eu-central-1",
+			wantOK: true,
+		},
+		{
+			name:   "ap-southeast-2",
+			bucket: "bucket0--apse2-az1--x-s3",
+			want:   "ap-southeast-2",
+			wantOK: true,
+		},
+		{
+			name:   "unknown zone",
+			bucket: "bucket0--xxx1-az1--x-s3",
+			want:   "",
+			wantOK: false,
+		},
+		{
+			name:   "no zone",
+			bucket: "bucket0--x-s3",
+			want:   "",
+			wantOK: false,
+		},
+		{
+			name:   "general purpose bucket",
+			bucket: "bucket0",
+			want:   "",
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := directoryBucketRegion(tt.bucket)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("directoryBucketRegion() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func Test_getBucketType(t *testing.T) {
+	type args struct {
+		bucket string
+	}
+	tests := []struct {
+		name string
+		args args
+		want BucketType
+	}{
+		{
+			name: "general purpose bucket",
 			args: args{
-				ctx:    context.Background(),
-				region: "ap-northeast-1",
+				bucket: "bucket0",
+			},
+			want: BucketTypeGeneralPurpose,
+		},
+		{
+			name: "directory bucket",
+			args: args{
+				bucket: "bucket0--apne1-az4--x-s3",
+			},
+			want: BucketTypeDirectory,
+		},
+		{
+			name: "suffix in the middle",
+			args: args{
+				bucket: "bucket0--x-s3-logs",
 			},
-			want:    []types.Bucket{},
+			want: BucketTypeGeneralPurpose,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getBucketType(tt.args.bucket); got != tt.want {
+				t.Errorf("getBucketType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getBucketRegion(t *testing.T) {
+	type args struct {
+		ctx    context.Context
+		client S3API
+		bucket string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "location constraint",
+			args: args{
+				ctx: context.Background(),
+				client: newMockClient(
+					&mockS3{
+						GetBucketLocationFunc: func(_ context.Context, _ *s3.GetBucketLocationInput, _ ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error) {
+							return &s3.GetBucketLocationOutput{LocationConstraint: types.BucketLocationConstraintApNortheast1}, nil
+						},
+					},
+					nil,
+				),
+				bucket: "bucket0",
+			},
+			want:    "ap-northeast-1",
 			wantErr: false,
 		},
+		{
+			name: "us-east-1",
+			args: args{
+				ctx: context.Background(),
+				client: newMockClient(
+					&mockS3{
+						GetBucketLocationFunc: func(_ context.Context, _ *s3.GetBucketLocationInput, _ ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error) {
+							return &s3.GetBucketLocationOutput{}, nil
+						},
+					},
+					nil,
+				),
+				bucket: "bucket0",
+			},
+			want:    "us-east-1",
+			wantErr: false,
+		},
+		{
+			name: "legacy eu",
+			args: args{
+				ctx: context.Background(),
+				client: newMockClient(
+					&mockS3{
+						GetBucketLocationFunc: func(_ context.Context, _ *s3.GetBucketLocationInput, _ ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error) {
+							return &s3.GetBucketLocationOutput{LocationConstraint: types.BucketLocationConstraintEu}, nil
+						},
+					},
+					nil,
+				),
+				bucket: "bucket0",
+			},
+			want:    "eu-west-1",
+			wantErr: false,
+		},
+		{
+			name: "error",
+			args: args{
+				ctx: context.Background(),
+				client: newMockClient(
+					&mockS3{
+						GetBucketLocationFunc: func(_ context.Context, _ *s3.GetBucketLocationInput, _ ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error) {
+							return nil, errors.New("failed to get bucket location")
+						},
+					},
+					nil,
+				),
+				bucket: "bucket0",
+			},
+			want:    "",
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			man := &Manager{
-				client:      tt.fields.client,
-				metricName:  tt.fields.metricName,
-				storageType: tt.fields.storageType,
-				prefix:      tt.fields.prefix,
-				regions:     tt.fields.regions,
-				sem:         tt.fields.sem,
+			got, err := getBucketRegion(tt.args.ctx, tt.args.client, tt.args.bucket)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getBucketRegion() error = %v, wantErr %v", err, tt.wantErr)
+				return
 			}
-			got, err := man.getBuckets(tt.args.ctx, tt.args.region)
+			if got != tt.want {
+				t.Errorf("getBucketRegion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getBucketTags(t *testing.T) {
+	type args struct {
+		ctx    context.Context
+		client S3API
+		bucket string
+		region string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "tagged",
+			args: args{
+				ctx: context.Background(),
+				client: newMockClient(
+					&mockS3{
+						GetBucketTaggingFunc: func(_ context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+							o := &s3.Options{}
+							for _, fn := range optFns {
+								fn(o)
+							}
+							if aws.ToString(params.Bucket) != "bucket0" || o.Region != "ap-northeast-1" {
+								return nil, errors.New("unexpected bucket or region")
+							}
+							return &s3.GetBucketTaggingOutput{
+								TagSet: []types.Tag{
+									{Key: aws.String("team"), Value: aws.String("platform")},
+									{Key: aws.String("env"), Value: aws.String("prod")},
+								},
+							}, nil
+						},
+					},
+					nil,
+				),
+				bucket: "bucket0",
+				region: "ap-northeast-1",
+			},
+			want:    map[string]string{"team": "platform", "env": "prod"},
+			wantErr: false,
+		},
+		{
+			name: "no such tag set",
+			args: args{
+				ctx: context.Background(),
+				client: newMockClient(
+					&mockS3{
+						GetBucketTaggingFunc: func(_ context.Context, _ *s3.GetBucketTaggingInput, _ ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+							return nil, &smithy.GenericAPIError{Code: "NoSuchTagSet", Message: "The TagSet does not exist"}
+						},
+					},
+					nil,
+				),
+				bucket: "bucket0",
+				region: "ap-northeast-1",
+			},
+			want:    map[string]string{},
+			wantErr: false,
+		},
+		{
+			name: "access denied",
+			args: args{
+				ctx: context.Background(),
+				client: newMockClient(
+					&mockS3{
+						GetBucketTaggingFunc: func(_ context.Context, _ *s3.GetBucketTaggingInput, _ ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+							return nil, &smithy.GenericAPIError{Code: "AccessDenied", Message: "Access Denied"}
+						},
+					},
+					nil,
+				),
+				bucket: "bucket0",
+				region: "ap-northeast-1",
+			},
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name: "context canceled",
+			args: args{
+				ctx: func() context.Context {
+					ctx, cancel := context.WithCancel(context.Background())
+					cancel()
+					return ctx
+				}(),
+				client: newMockClient(
+					&mockS3{
+						GetBucketTaggingFunc: func(ctx context.Context, _ *s3.GetBucketTaggingInput, _ ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+							return nil, ctx.Err()
+						},
+					},
+					nil,
+				),
+				bucket: "bucket0",
+				region: "ap-northeast-1",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getBucketTags(tt.args.ctx, tt.args.client, tt.args.bucket, tt.args.region)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("Manager.getBuckets() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("getBucketTags() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Manager.getBuckets() = %v, want %v", got, tt.want)
+				t.Errorf("getBucketTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isAPIError(t *testing.T) {
+	type args struct {
+		err   error
+		codes []string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{
+			name: "matched",
+			args: args{
+				err:   &smithy.GenericAPIError{Code: "NoSuchTagSet"},
+				codes: []string{"NoSuchTagSet"},
+			},
+			want: true,
+		},
+		{
+			name: "wrapped",
+			args: args{
+				err:   fmt.Errorf("wrapped: %w", &smithy.GenericAPIError{Code: "NoSuchLifecycleConfiguration"}),
+				codes: []string{"NoSuchTagSet", "NoSuchLifecycleConfiguration"},
+			},
+			want: true,
+		},
+		{
+			name: "other code",
+			args: args{
+				err:   &smithy.GenericAPIError{Code: "AccessDenied"},
+				codes: []string{"NoSuchTagSet"},
+			},
+			want: false,
+		},
+		{
+			name: "not api error",
+			args: args{
+				err:   errors.New("error"),
+				codes: []string{"NoSuchTagSet"},
+			},
+			want: false,
+		},
+		{
+			name: "nil",
+			args: args{
+				err:   nil,
+				codes: []string{"NoSuchTagSet"},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isAPIError(tt.args.err, tt.args.codes...); got != tt.want {
+				t.Errorf("isAPIError() = %v, want %v", got, tt.want)
 			}
 		})
 	}
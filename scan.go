@@ -0,0 +1,179 @@
+package s3bytes
+
+import (
+	"cmp"
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"golang.org/x/sync/errgroup"
+)
+
+// scanStat represents the exact size and number of objects for a single storage class.
+type scanStat struct {
+	bytes   int64
+	objects int64
+}
+
+// scanResult represents the result of listing all objects in a bucket, keyed by storage type.
+type scanResult map[StorageType]*scanStat
+
+// add adds the size and number of objects to the storage type.
+func (r scanResult) add(storageType StorageType, bytes, objects int64) {
+	stat, ok := r[storageType]
+	if !ok {
+		stat = &scanStat{}
+		r[storageType] = stat
+	}
+	stat.bytes += bytes
+	stat.objects += objects
+}
+
+// merge adds all the stats of the other result.
+func (r scanResult) merge(other scanResult) {
+	for storageType, stat := range other {
+		r.add(storageType, stat.bytes, stat.objects)
+	}
+}
+
+// value returns the value corresponding to the specified metric name and storage type.
+func (r scanResult) value(metricName MetricName, storageType StorageType) float64 {
+	switch metricName {
+	case MetricNameBucketSizeBytes:
+		if stat, ok := r[storageType]; ok {
+			return float64(stat.bytes)
+		}
+		return 0
+	case MetricNameNumberOfObjects:
+		var n int64
+		for _, stat := range r {
+			n += stat.objects
+		}
+		return float64(n)
+	default:
+		return 0
+	}
+}
+
+// ScanSource is the source that computes exact values by listing all objects in each bucket.
+// It is slow for large buckets but does not depend on the daily CloudWatch storage metrics.
+type ScanSource struct {
+	client      *Client
+	concurrency int
+}
+
+// NewScanSource creates a new source that lists objects with the client.
+func NewScanSource(client *Client) *ScanSource {
+	return &ScanSource{
+		client:      client,
+		concurrency: DefaultConcurrency,
+	}
+}
+
+// Buckets returns the names of the buckets in the region.
+func (s *ScanSource) Buckets(ctx context.Context, region, prefix string) ([]string, error) {
+	return getBuckets(ctx, s.client, region, prefix)
+}
+
+// listBuckets returns the buckets in the region along with their creation dates.
+func (s *ScanSource) listBuckets(ctx context.Context, region, prefix string) ([]s3types.Bucket, error) {
+	return listBuckets(ctx, s.client, region, prefix)
+}
+
+// Metrics lists all objects in each bucket of the query and returns the aggregated values.
+// Buckets are scanned concurrently up to DefaultConcurrency at a time, or the concurrency of the manager
+// when the manager scans in the fallback or force scan mode, and the scan stops
+// as soon as the context is canceled or any error occurs.
+func (s *ScanSource) Metrics(ctx context.Context, query *SourceQuery) ([]*Metric, error) {
+	metrics := make([]*Metric, len(query.Buckets))
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(cmp.Or(s.concurrency, DefaultConcurrency))
+	for i, bucket := range query.Buckets {
+		g.Go(func() error {
+			result, _, err := listObjects(ctx, s.client, bucket, query.Region, "", "")
+			if err != nil {
+				return err
+			}
+			storageType := query.StorageType.forBucket(bucket)
+			metrics[i] = &Metric{
+				BucketName:  bucket,
+				Region:      query.Region,
+				MetricName:  query.MetricName,
+				StorageType: storageType,
+				Value:       result.value(query.MetricName, storageType),
+				Status:      DataStatusOK,
+				Timestamp:   query.end(),
+				Source:      SourceTypeScan,
+				BucketType:  getBucketType(bucket),
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// listObjects lists the objects under the prefix and aggregates them by storage type.
+// If the delimiter is not empty, only the objects directly under the prefix are aggregated,
+// and the common prefixes one level below are returned as well.
+func listObjects(ctx context.Context, client S3API, bucket, region, prefix, delimiter string) (scanResult, []string, error) {
+	var (
+		result   = scanResult{}
+		prefixes = make([]string, 0)
+		opt      = func(o *s3.Options) { o.Region = region }
+		in       = &s3.ListObjectsV2Input{
+			Bucket: aws.String(bucket),
+		}
+	)
+	if prefix != "" {
+		in.Prefix = aws.String(prefix)
+	}
+	if delimiter != "" {
+		in.Delimiter = aws.String(delimiter)
+	}
+	paginator := s3.NewListObjectsV2Paginator(client, in)
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx, opt)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, obj := range out.Contents {
+			result.add(storageTypeFromClass(obj.StorageClass), aws.ToInt64(obj.Size), 1)
+		}
+		for _, p := range out.CommonPrefixes {
+			prefixes = append(prefixes, aws.ToString(p.Prefix))
+		}
+	}
+	return result, prefixes, nil
+}
+
+// storageTypeFromClass converts the storage class of an object into the storage type of CloudWatch.
+// Intelligent-Tiering objects are counted as the frequent access tier,
+// since the access tier is not exposed by ListObjectsV2.
+func storageTypeFromClass(class s3types.ObjectStorageClass) StorageType {
+	switch class {
+	case s3types.ObjectStorageClassStandard, "":
+		return StorageTypeStandardStorage
+	case s3types.ObjectStorageClassIntelligentTiering:
+		return StorageTypeIntelligentTieringFAStorage
+	case s3types.ObjectStorageClassStandardIa:
+		return StorageTypeStandardIAStorage
+	case s3types.ObjectStorageClassOnezoneIa:
+		return StorageTypeOneZoneIAStorage
+	case s3types.ObjectStorageClassReducedRedundancy:
+		return StorageTypeReducedRedundancyStorage
+	case s3types.ObjectStorageClassGlacierIr:
+		return StorageTypeGlacierInstantRetrievalStorage
+	case s3types.ObjectStorageClassGlacier:
+		return StorageTypeGlacierStorage
+	case s3types.ObjectStorageClassDeepArchive:
+		return StorageTypeDeepArchiveStorage
+	case s3types.ObjectStorageClassExpressOnezone:
+		return StorageTypeExpressOneZoneStorage
+	default:
+		return StorageTypeNone
+	}
+}
@@ -0,0 +1,261 @@
+package s3bytes
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func Test_listObjects(t *testing.T) {
+	type fields struct {
+		client *Client
+	}
+	type args struct {
+		ctx    context.Context
+		bucket string
+		region string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    scanResult
+		wantErr bool
+	}{
+		{
+			name: "pagination",
+			fields: fields{
+				client: newMockClient(
+					&mockS3{
+						ListObjectsV2Func: func(_ context.Context, params *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+							if params.ContinuationToken == nil {
+								return &s3.ListObjectsV2Output{
+									Contents: []s3types.Object{
+										{
+											Key:          aws.String("key0"),
+											Size:         aws.Int64(1024),
+											StorageClass: s3types.ObjectStorageClassStandard,
+										},
+										{
+											Key:          aws.String("key1"),
+											Size:         aws.Int64(2048),
+											StorageClass: s3types.ObjectStorageClassGlacier,
+										},
+									},
+									IsTruncated:           aws.Bool(true),
+									NextContinuationToken: aws.String("token0"),
+								}, nil
+							}
+							return &s3.ListObjectsV2Output{
+								Contents: []s3types.Object{
+									{
+										Key:  aws.String("key2"),
+										Size: aws.Int64(512),
+									},
+								},
+								IsTruncated: aws.Bool(false),
+							}, nil
+						},
+					},
+					nil,
+				),
+			},
+			args: args{
+				ctx:    context.Background(),
+				bucket: "bucket0",
+				region: "ap-northeast-1",
+			},
+			want: scanResult{
+				StorageTypeStandardStorage: {bytes: 1536, objects: 2},
+				StorageTypeGlacierStorage:  {bytes: 2048, objects: 1},
+			},
+			wantErr: false,
+		},
+		{
+			name: "error",
+			fields: fields{
+				client: newMockClient(
+					&mockS3{
+						ListObjectsV2Func: func(_ context.Context, _ *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+							return nil, errors.New("error")
+						},
+					},
+					nil,
+				),
+			},
+			args: args{
+				ctx:    context.Background(),
+				bucket: "bucket0",
+				region: "ap-northeast-1",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := listObjects(tt.args.ctx, tt.fields.client, tt.args.bucket, tt.args.region, "", "")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("listObjects() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("listObjects() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanSource_Metrics(t *testing.T) {
+	type fields struct {
+		client *Client
+	}
+	type args struct {
+		ctx   context.Context
+		query *SourceQuery
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    []*Metric
+		wantErr bool
+	}{
+		{
+			name: "objects",
+			fields: fields{
+				client: newMockClient(
+					&mockS3{
+						ListObjectsV2Func: func(_ context.Context, _ *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+							return &s3.ListObjectsV2Output{
+								Contents: []s3types.Object{
+									{
+										Key:          aws.String("key0"),
+										Size:         aws.Int64(1024),
+										StorageClass: s3types.ObjectStorageClassStandardIa,
+									},
+									{
+										Key:          aws.String("key1"),
+										Size:         aws.Int64(2048),
+										StorageClass: s3types.ObjectStorageClassDeepArchive,
+									},
+								},
+							}, nil
+						},
+					},
+					nil,
+				),
+			},
+			args: args{
+				ctx: context.Background(),
+				query: &SourceQuery{
+					Region:      "ap-northeast-1",
+					Buckets:     []string{"bucket0"},
+					MetricName:  MetricNameNumberOfObjects,
+					StorageType: StorageTypeAllStorageTypes,
+				},
+			},
+			want: []*Metric{
+				{
+					BucketName:  "bucket0",
+					Region:      "ap-northeast-1",
+					MetricName:  MetricNameNumberOfObjects,
+					StorageType: StorageTypeAllStorageTypes,
+					Value:       2,
+					Status:      DataStatusOK,
+					Timestamp:   testNow,
+					Source:      SourceTypeScan,
+					BucketType:  BucketTypeGeneralPurpose,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "canceled",
+			fields: fields{
+				client: newMockClient(
+					&mockS3{
+						ListObjectsV2Func: func(ctx context.Context, _ *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+							return nil, ctx.Err()
+						},
+					},
+					nil,
+				),
+			},
+			args: args{
+				ctx: func() context.Context {
+					ctx, cancel := context.WithCancel(context.Background())
+					cancel()
+					return ctx
+				}(),
+				query: &SourceQuery{
+					Region:      "ap-northeast-1",
+					Buckets:     []string{"bucket0"},
+					MetricName:  MetricNameBucketSizeBytes,
+					StorageType: StorageTypeStandardStorage,
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScanSource(tt.fields.client)
+			got, err := s.Metrics(tt.args.ctx, tt.args.query)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ScanSource.Metrics() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ScanSource.Metrics() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_storageTypeFromClass(t *testing.T) {
+	tests := []struct {
+		name  string
+		class s3types.ObjectStorageClass
+		want  StorageType
+	}{
+		{
+			name:  "empty",
+			class: "",
+			want:  StorageTypeStandardStorage,
+		},
+		{
+			name:  "intelligent tiering",
+			class: s3types.ObjectStorageClassIntelligentTiering,
+			want:  StorageTypeIntelligentTieringFAStorage,
+		},
+		{
+			name:  "glacier ir",
+			class: s3types.ObjectStorageClassGlacierIr,
+			want:  StorageTypeGlacierInstantRetrievalStorage,
+		},
+		{
+			name:  "express one zone",
+			class: s3types.ObjectStorageClassExpressOnezone,
+			want:  StorageTypeExpressOneZoneStorage,
+		},
+		{
+			name:  "unknown",
+			class: s3types.ObjectStorageClassOutposts,
+			want:  StorageTypeNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := storageTypeFromClass(tt.class); got != tt.want {
+				t.Errorf("storageTypeFromClass() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package s3bytes
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// day is the interval of the daily storage metrics, which is the unit of the series.
+	day = 24 * time.Hour
+
+	// MaxLookbackDays is the maximum number of days of the datapoints to fetch,
+	// since CloudWatch keeps the datapoints of one-day periods for 455 days.
+	MaxLookbackDays = 455
+
+	// minLookbackDays is the minimum number of days of the datapoints to fetch.
+	minLookbackDays = 2
+)
+
+// point represents a datapoint of a series.
+type point struct {
+	timestamp time.Time
+	value     float64
+}
+
+// series represents the datapoints of a bucket sorted oldest first.
+type series struct {
+	bucket string
+	points []point
+}
+
+// last returns the latest datapoint of the series.
+func (s *series) last() point {
+	return s.points[len(s.points)-1]
+}
+
+// listSeries fetches the daily series of the buckets in all regions over the last lookback days
+// and converts each of them into a metric holding the latest datapoint, which is then passed to
+// the analyze function along with the series to add the columns of the analysis. The columns are
+// appended to the header. The series without datapoints are not analyzed and marked as no data.
+// The metrics are enriched and filtered in the same way as List.
+func (man *Manager) listSeries(ctx context.Context, lookback int, columns []string, analyze func(metric *Metric, s *series)) (*MetricData, error) {
+	if lookback < minLookbackDays || lookback > MaxLookbackDays {
+		return nil, fmt.Errorf("invalid lookback: %d days, must be between %d and %d", lookback, minLookbackDays, MaxLookbackDays)
+	}
+	var (
+		source  = NewCloudWatchSource(man.client)
+		end     = now()
+		start   = end.Add(-time.Duration(lookback) * day)
+		results = make([][]*Metric, len(man.regions))
+		data    = &MetricData{
+			Header:  man.withTagHeader(man.withMetadataHeader(append(slices.Clone(header), columns...))),
+			Metrics: make([]*Metric, 0),
+		}
+	)
+	g, ctx := errgroup.WithContext(ctx)
+	for i, region := range man.regions {
+		g.Go(func() error {
+			if err := man.sem.Acquire(ctx, 1); err != nil {
+				return man.notifyResult(ctx, region, err)
+			}
+			defer man.sem.Release(1)
+			man.notify(Event{Type: EventTypeRegionStarted, Region: region})
+			buckets, err := discoverBuckets(ctx, source, region, aws.ToString(man.prefix))
+			if err != nil {
+				return man.notifyResult(ctx, region, err)
+			}
+			man.notify(Event{Type: EventTypeBucketsListed, Region: region, Buckets: len(buckets)})
+			metrics := make([]*Metric, 0, len(buckets))
+			for batch := range slices.Chunk(bucketNames(buckets), man.batch()) {
+				man.notify(Event{Type: EventTypeBatchSent, Region: region, Buckets: len(batch)})
+				query := man.newSourceQuery(region, batch, end)
+				list, err := source.getSeries(ctx, query, start)
+				if err != nil {
+					return man.notifyResult(ctx, region, err)
+				}
+				for _, s := range list {
+					metric := newSeriesMetric(query, s)
+					if len(s.points) > 0 {
+						analyze(metric, s)
+					}
+					metrics = append(metrics, metric)
+				}
+			}
+			if err := man.enrichMetadata(ctx, metrics, region, creationDates(buckets)); err != nil {
+				return man.notifyResult(ctx, region, err)
+			}
+			if err := man.enrichTags(ctx, metrics, region); err != nil {
+				return man.notifyResult(ctx, region, err)
+			}
+			results[i] = metrics
+			return man.notifyResult(ctx, region, nil)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	for _, metrics := range results {
+		for _, metric := range metrics {
+			ok, err := man.accept(metric)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			data.Metrics = append(data.Metrics, metric)
+			data.Total += int64(metric.Value)
+		}
+	}
+	return data, nil
+}
+
+// newSeriesMetric converts the series into a metric holding the latest datapoint.
+func newSeriesMetric(query *SourceQuery, s *series) *Metric {
+	metric := &Metric{
+		BucketName:  s.bucket,
+		Region:      query.Region,
+		MetricName:  query.MetricName,
+		StorageType: query.StorageType.forBucket(s.bucket),
+		Status:      DataStatusNoData,
+		Source:      SourceTypeCloudWatch,
+		BucketType:  getBucketType(s.bucket),
+	}
+	if len(s.points) > 0 {
+		last := s.last()
+		metric.Value = last.value
+		metric.Timestamp = last.timestamp
+		metric.Status = DataStatusOK
+	}
+	return metric
+}
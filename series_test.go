@@ -0,0 +1,84 @@
+package s3bytes
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// testSeriesOrigin is a Monday, from which the synthetic series start.
+var testSeriesOrigin = time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+
+// newTestPoints returns a synthetic daily series of n datapoints from testSeriesOrigin.
+func newTestPoints(n int, f func(i int, t time.Time) float64) []point {
+	points := make([]point, n)
+	for i := range points {
+		t := testSeriesOrigin.Add(time.Duration(i) * day)
+		points[i] = point{timestamp: t, value: f(i, t)}
+	}
+	return points
+}
+
+// newMockSeriesCloudWatch returns a mock that answers the series of bucket0 in two pages,
+// newest first as CloudWatch does, and no datapoints for the other buckets.
+func newMockSeriesCloudWatch(points []point) *mockCloudWatch {
+	return &mockCloudWatch{
+		GetMetricDataFunc: func(_ context.Context, params *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+			var (
+				half    = len(points) / 2
+				page    = points[half:]
+				out     = &cloudwatch.GetMetricDataOutput{NextToken: aws.String("token")}
+				results = make([]cwtypes.MetricDataResult, 0, len(params.MetricDataQueries))
+			)
+			if params.NextToken != nil {
+				page = points[:half]
+				out.NextToken = nil
+			}
+			for _, q := range params.MetricDataQueries {
+				result := cwtypes.MetricDataResult{Id: q.Id, Label: q.Label}
+				if aws.ToString(q.Label) == "bucket0" {
+					for _, p := range slices.Backward(page) {
+						result.Timestamps = append(result.Timestamps, p.timestamp)
+						result.Values = append(result.Values, p.value)
+					}
+				}
+				results = append(results, result)
+			}
+			out.MetricDataResults = results
+			return out, nil
+		},
+	}
+}
+
+func Test_series_last(t *testing.T) {
+	points := newTestPoints(3, linearValue)
+	tests := []struct {
+		name string
+		s    *series
+		want point
+	}{
+		{
+			name: "last",
+			s:    &series{bucket: "bucket0", points: points},
+			want: points[2],
+		},
+		{
+			name: "single",
+			s:    &series{bucket: "bucket0", points: points[:1]},
+			want: points[0],
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.last(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("series.last() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
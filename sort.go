@@ -5,12 +5,18 @@ import (
 	"slices"
 )
 
-// SortMetrics sorts the metrics by value and bucket name.
+// SortMetrics sorts the metrics by value, group and bucket name.
 func SortMetrics(data *MetricData) {
 	slices.SortFunc(data.Metrics, func(a, b *Metric) int {
 		if n := cmp.Compare(b.Value, a.Value); n != 0 {
 			return n
 		}
-		return cmp.Compare(a.BucketName, b.BucketName)
+		if n := cmp.Compare(a.Group, b.Group); n != 0 {
+			return n
+		}
+		if n := cmp.Compare(a.BucketName, b.BucketName); n != 0 {
+			return n
+		}
+		return cmp.Compare(a.Prefix, b.Prefix)
 	})
 }
@@ -0,0 +1,98 @@
+package s3bytes
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var (
+	_ Source       = (*CloudWatchSource)(nil)
+	_ Source       = (*ScanSource)(nil)
+	_ PagedSource  = (*CloudWatchSource)(nil)
+	_ bucketLister = (*CloudWatchSource)(nil)
+	_ bucketLister = (*ScanSource)(nil)
+)
+
+// Source is an interface for the source from which buckets are discovered and their values are fetched.
+// CloudWatchSource is used by default, and another implementation can be plugged in with Manager.SetSource.
+type Source interface {
+	// Buckets returns the names of the buckets in the region.
+	// If prefix is not empty, only the buckets whose names start with it are returned.
+	Buckets(ctx context.Context, region, prefix string) ([]string, error)
+
+	// Metrics returns the metrics for the batch of buckets in the query.
+	// Buckets without values should be returned with DataStatusNoData rather than omitted,
+	// so that the manager can handle them according to the scan and no-data modes.
+	Metrics(ctx context.Context, query *SourceQuery) ([]*Metric, error)
+}
+
+// PagedSource is a Source that fetches the metrics of a batch in pages, so that the manager can
+// stream the metrics as each page returns rather than after the whole batch. The sources that do not
+// implement it are regarded as returning the whole batch in a single page.
+type PagedSource interface {
+	Source
+
+	// MetricPages yields the metrics for the batch of buckets in the query page by page,
+	// and stops fetching the next page when the consumer stops.
+	MetricPages(ctx context.Context, query *SourceQuery) iter.Seq2[[]*Metric, error]
+}
+
+// bucketLister is implemented by the sources that discover the buckets with ListBuckets, which returns
+// the creation dates along with the names, so that the enrichment does not need to list the buckets again.
+type bucketLister interface {
+	listBuckets(ctx context.Context, region, prefix string) ([]s3types.Bucket, error)
+}
+
+// discoverBuckets returns the buckets in the region from the source.
+// The buckets of a source that does not implement bucketLister have no creation dates.
+func discoverBuckets(ctx context.Context, source Source, region, prefix string) ([]s3types.Bucket, error) {
+	if lister, ok := source.(bucketLister); ok {
+		return lister.listBuckets(ctx, region, prefix)
+	}
+	names, err := source.Buckets(ctx, region, prefix)
+	if err != nil {
+		return nil, err
+	}
+	buckets := make([]s3types.Bucket, len(names))
+	for i, name := range names {
+		buckets[i] = s3types.Bucket{Name: aws.String(name)}
+	}
+	return buckets, nil
+}
+
+// SourceQuery represents a batch of buckets in a region whose values are fetched from a source.
+// The number of buckets in a batch does not exceed the batch size of the manager, which is at most
+// MaxQueries. FilterID is the filter of the request metrics configuration, which is set only for
+// the request metrics. EndTime is the end of the time window, which is the start time of the run
+// so that all the batches of a run share the same window. If it is zero, the current time is used.
+type SourceQuery struct {
+	Region      string
+	Buckets     []string
+	MetricName  MetricName
+	StorageType StorageType
+	FilterID    string
+	EndTime     time.Time
+}
+
+// end returns the end of the time window of the query.
+func (q *SourceQuery) end() time.Time {
+	if q.EndTime.IsZero() {
+		return now()
+	}
+	return q.EndTime
+}
+
+// metricPages returns the pages of the metrics for the query from the source.
+// The whole batch is a single page unless the source is a PagedSource.
+func metricPages(ctx context.Context, source Source, query *SourceQuery) iter.Seq2[[]*Metric, error] {
+	if s, ok := source.(PagedSource); ok {
+		return s.MetricPages(ctx, query)
+	}
+	return func(yield func([]*Metric, error) bool) {
+		yield(source.Metrics(ctx, query))
+	}
+}
@@ -0,0 +1,146 @@
+package s3bytes
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func Test_metricPages(t *testing.T) {
+	metrics := func(_ context.Context, query *SourceQuery) ([]*Metric, error) {
+		ret := make([]*Metric, 0, len(query.Buckets))
+		for _, bucket := range query.Buckets {
+			ret = append(ret, &Metric{BucketName: bucket})
+		}
+		return ret, nil
+	}
+	type args struct {
+		source Source
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    [][]string
+		wantErr bool
+	}{
+		{
+			name: "single page",
+			args: args{
+				source: &mockSource{MetricsFunc: metrics},
+			},
+			want:    [][]string{{"bucket0", "bucket1"}},
+			wantErr: false,
+		},
+		{
+			name: "paged source",
+			args: args{
+				source: &mockPagedSource{mockSource: mockSource{MetricsFunc: metrics}},
+			},
+			want:    [][]string{{"bucket0"}, {"bucket1"}},
+			wantErr: false,
+		},
+		{
+			name: "error",
+			args: args{
+				source: &mockSource{
+					MetricsFunc: func(_ context.Context, _ *SourceQuery) ([]*Metric, error) {
+						return nil, errors.New("error")
+					},
+				},
+			},
+			want:    [][]string{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := &SourceQuery{Region: "ap-northeast-1", Buckets: []string{"bucket0", "bucket1"}}
+			got := make([][]string, 0)
+			var gotErr error
+			for page, err := range metricPages(context.Background(), tt.args.source, query) {
+				if err != nil {
+					gotErr = err
+					continue
+				}
+				names := make([]string, 0, len(page))
+				for _, metric := range page {
+					names = append(names, metric.BucketName)
+				}
+				got = append(got, names)
+			}
+			if (gotErr != nil) != tt.wantErr {
+				t.Errorf("metricPages() error = %v, wantErr %v", gotErr, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("metricPages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_discoverBuckets(t *testing.T) {
+	creationDate := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	type args struct {
+		source Source
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []s3types.Bucket
+		wantErr bool
+	}{
+		{
+			name: "bucket lister",
+			args: args{
+				source: NewCloudWatchSource(newMockClient(newMockMetadataS3(creationDate), nil)),
+			},
+			want: []s3types.Bucket{
+				{Name: aws.String("bucket0"), CreationDate: aws.Time(creationDate)},
+				{Name: aws.String("bucket1"), CreationDate: aws.Time(creationDate.AddDate(1, 0, 0))},
+			},
+			wantErr: false,
+		},
+		{
+			name: "names only",
+			args: args{
+				source: &mockSource{
+					BucketsFunc: func(_ context.Context, _, _ string) ([]string, error) {
+						return []string{"bucket0", "bucket1"}, nil
+					},
+				},
+			},
+			want:    newTestBuckets("bucket0", "bucket1"),
+			wantErr: false,
+		},
+		{
+			name: "error",
+			args: args{
+				source: &mockSource{
+					BucketsFunc: func(_ context.Context, _, _ string) ([]string, error) {
+						return nil, errors.New("error")
+					},
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := discoverBuckets(context.Background(), tt.args.source, "ap-northeast-1", "")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("discoverBuckets() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("discoverBuckets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
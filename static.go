@@ -0,0 +1,313 @@
+package s3bytes
+
+import (
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const (
+	// staticWidth is the width of the static charts in pixels.
+	staticWidth = 960
+
+	// staticHeight is the height of the static charts in pixels.
+	staticHeight = 540
+
+	// staticLabelLength is the maximum number of characters of the labels in the static charts.
+	staticLabelLength = 32
+)
+
+// staticPalette is the colors of the items in the static charts, which follows the default theme of ECharts.
+var staticPalette = []color.RGBA{
+	{0x54, 0x70, 0xc6, 0xff},
+	{0x91, 0xcc, 0x75, 0xff},
+	{0xfa, 0xc8, 0x58, 0xff},
+	{0xee, 0x66, 0x66, 0xff},
+	{0x73, 0xc0, 0xde, 0xff},
+	{0x3b, 0xa2, 0x72, 0xff},
+	{0xfc, 0x84, 0x52, 0xff},
+	{0x9a, 0x60, 0xb4, 0xff},
+	{0xea, 0x7c, 0xcc, 0xff},
+}
+
+var (
+	staticBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	staticForeground = color.RGBA{0x33, 0x33, 0x33, 0xff}
+)
+
+// staticItem represents a named value drawn in the static charts.
+type staticItem struct {
+	label string
+	value float64
+}
+
+// staticRect represents a filled rectangle.
+type staticRect struct {
+	x, y, w, h float64
+	fill       color.RGBA
+}
+
+// staticWedge represents a filled sector of a circle. The angles are in radians,
+// measured clockwise from 12 o'clock.
+type staticWedge struct {
+	cx, cy, r  float64
+	start, end float64
+	fill       color.RGBA
+}
+
+// staticText represents a line of text. y is the baseline, and anchor is
+// one of "start", "middle" and "end" as in SVG.
+type staticText struct {
+	x, y   float64
+	text   string
+	anchor string
+	size   float64
+	fill   color.RGBA
+}
+
+// staticCanvas holds the shapes of a static chart, which are drawn in the order
+// of rectangles, wedges and texts by both the SVG and the PNG writers.
+type staticCanvas struct {
+	width  int
+	height int
+	rects  []staticRect
+	wedges []staticWedge
+	texts  []staticText
+}
+
+// newStaticCanvas returns a canvas with the title.
+func newStaticCanvas(title string) *staticCanvas {
+	c := &staticCanvas{
+		width:  staticWidth,
+		height: staticHeight,
+	}
+	c.texts = append(c.texts, staticText{
+		x:      staticWidth / 2,
+		y:      40,
+		text:   title,
+		anchor: "middle",
+		size:   20,
+		fill:   staticForeground,
+	})
+	return c
+}
+
+// noData writes a placeholder in the middle of the canvas for the data without items.
+func (c *staticCanvas) noData() {
+	c.texts = append(c.texts, staticText{
+		x:      float64(c.width) / 2,
+		y:      float64(c.height) / 2,
+		text:   "no data",
+		anchor: "middle",
+		size:   16,
+		fill:   staticForeground,
+	})
+}
+
+// toStatic renders the pie or the bar chart as SVG or PNG to the writer of the renderer,
+// so that the chart can be embedded without a browser.
+func (ren *Renderer) toStatic() error {
+	canvas, err := ren.toCanvas()
+	if err != nil {
+		return err
+	}
+	if ren.OutputType == OutputTypePNG {
+		return canvas.writePNG(ren.w)
+	}
+	return canvas.writeSVG(ren.w)
+}
+
+// toCanvas lays out the chart of the chart type. The pie chart is the default.
+func (ren *Renderer) toCanvas() (*staticCanvas, error) {
+	switch ren.chartType {
+	case ChartTypeNone, ChartTypePie:
+		title, items := getPieItems(ren.Data, ren.getTopN(), ren.minPercent)
+		return newPieCanvas(title, toStaticItems(items, func(item opts.PieData) (string, any) { return item.Name, item.Value }), ren.Data), nil
+	case ChartTypeBar:
+		title, _, items := getBarItems(ren.Data, ren.getTopN())
+		items = slices.Clone(items)
+		slices.Reverse(items)
+		return newBarCanvas(title, toStaticItems(items, func(item opts.BarData) (string, any) { return item.Name, item.Value }), ren.Data), nil
+	default:
+		return nil, fmt.Errorf("unsupported chart type for %s output: %q", ren.OutputType, ren.chartType)
+	}
+}
+
+// toStaticItems converts the items of the charts into the static items.
+func toStaticItems[T any](items []T, fn func(T) (string, any)) []staticItem {
+	ret := make([]staticItem, 0, len(items))
+	for _, item := range items {
+		label, value := fn(item)
+		v, ok := value.(float64)
+		if !ok {
+			continue
+		}
+		ret = append(ret, staticItem{
+			label: truncateLabel(label, staticLabelLength),
+			value: v,
+		})
+	}
+	return ret
+}
+
+// truncateLabel shortens the label to n characters with a trailing ellipsis.
+func truncateLabel(label string, n int) string {
+	r := []rune(label)
+	if len(r) <= n {
+		return label
+	}
+	return string(r[:n-3]) + "..."
+}
+
+// newPieCanvas lays out the pie on the left and the legend with the values and the shares on the right.
+// The legend is wrapped into up to three columns with narrower rows and shorter labels as the items
+// grow, and the items beyond the columns are summarized in the last row, so that it stays in the canvas.
+func newPieCanvas(title string, items []staticItem, data *MetricData) *staticCanvas {
+	c := newStaticCanvas(title)
+	total := 0.0
+	for _, item := range items {
+		total += item.value
+	}
+	if total == 0 {
+		c.noData()
+		return c
+	}
+	const (
+		cx, cy, r  = 280.0, 295.0, 200.0
+		legendX    = 540.0
+		legendY    = 100.0
+		legendW    = staticWidth - legendX - 20
+		legendH    = staticHeight - legendY - 20
+		minLineH   = 18.0
+		maxColumns = 3
+		charW      = 7.0
+	)
+	var (
+		perColumn = int(math.Floor(legendH / minLineH))
+		columns   = min((len(items)+perColumn-1)/perColumn, maxColumns)
+		rows      = min((len(items)+columns-1)/columns, perColumn)
+		lineH     = min(28.0, legendH/float64(rows))
+		columnW   = legendW / float64(columns)
+		shown     = min(len(items), rows*columns)
+	)
+	start := 0.0
+	for i, item := range items {
+		fill := staticPalette[i%len(staticPalette)]
+		end := start + item.value/total*2*math.Pi
+		if i == len(items)-1 {
+			end = 2 * math.Pi
+		}
+		c.wedges = append(c.wedges, staticWedge{cx: cx, cy: cy, r: r, start: start, end: end, fill: fill})
+		start = end
+		if i >= shown {
+			continue
+		}
+		var (
+			x    = legendX + float64(i/rows)*columnW
+			y    = legendY + float64(i%rows)*lineH
+			text string
+		)
+		if i == shown-1 && shown < len(items) {
+			text = fmt.Sprintf("+%d more", len(items)-i)
+		} else {
+			suffix := fmt.Sprintf("  %s (%.1f%%)", formatValue(data, item.value), item.value/total*100)
+			n := max(int((columnW-24)/charW)-len(suffix), 4)
+			text = truncateLabel(item.label, n) + suffix
+			c.rects = append(c.rects, staticRect{x: x, y: y - 12, w: 14, h: 14, fill: fill})
+		}
+		c.texts = append(c.texts, staticText{
+			x:      x + 24,
+			y:      y,
+			text:   text,
+			anchor: "start",
+			size:   12,
+			fill:   staticForeground,
+		})
+	}
+	return c
+}
+
+// newBarCanvas lays out the horizontal bars in descending order with the labels on the left
+// and the values at the end of the bars.
+func newBarCanvas(title string, items []staticItem, data *MetricData) *staticCanvas {
+	c := newStaticCanvas(title)
+	if len(items) == 0 {
+		c.noData()
+		return c
+	}
+	const (
+		labelX = 288.0
+		barX   = 300.0
+		barW   = 520.0
+		top    = 80.0
+	)
+	var (
+		rowH = min(48.0, (staticHeight-top-20)/float64(len(items)))
+		max  = items[0].value
+	)
+	for _, item := range items {
+		max = math.Max(max, item.value)
+	}
+	for i, item := range items {
+		var (
+			y = top + float64(i)*rowH
+			w = item.value / max * barW
+		)
+		c.rects = append(c.rects, staticRect{x: barX, y: y + rowH*0.2, w: w, h: rowH * 0.6, fill: staticPalette[0]})
+		c.texts = append(c.texts,
+			staticText{x: labelX, y: y + rowH*0.5 + 4, text: item.label, anchor: "end", size: 12, fill: staticForeground},
+			staticText{x: barX + w + 8, y: y + rowH*0.5 + 4, text: formatValue(data, item.value), anchor: "start", size: 12, fill: staticForeground},
+		)
+	}
+	return c
+}
+
+// writeSVG writes the canvas as an SVG document.
+func (c *staticCanvas) writeSVG(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif">`+"\n",
+		c.width, c.height, c.width, c.height)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hexColor(staticBackground))
+	for _, r := range c.rects {
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`+"\n", r.x, r.y, r.w, r.h, hexColor(r.fill))
+	}
+	for _, wd := range c.wedges {
+		if wd.end-wd.start >= 2*math.Pi-1e-9 {
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="%s"/>`+"\n", wd.cx, wd.cy, wd.r, hexColor(wd.fill))
+			continue
+		}
+		var (
+			x1, y1 = wd.point(wd.start)
+			x2, y2 = wd.point(wd.end)
+			large  = 0
+		)
+		if wd.end-wd.start > math.Pi {
+			large = 1
+		}
+		fmt.Fprintf(&b, `<path d="M%.1f %.1f L%.1f %.1f A%.1f %.1f 0 %d 1 %.1f %.1f Z" fill="%s"/>`+"\n",
+			wd.cx, wd.cy, x1, y1, wd.r, wd.r, large, x2, y2, hexColor(wd.fill))
+	}
+	for _, t := range c.texts {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-size="%.0f" text-anchor="%s" fill="%s">%s</text>`+"\n",
+			t.x, t.y, t.size, t.anchor, hexColor(t.fill), html.EscapeString(t.text))
+	}
+	b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// point returns the point on the arc of the wedge at the angle.
+func (wd staticWedge) point(angle float64) (float64, float64) {
+	return wd.cx + wd.r*math.Sin(angle), wd.cy - wd.r*math.Cos(angle)
+}
+
+// hexColor returns the color in the hexadecimal notation.
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
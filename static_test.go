@@ -0,0 +1,296 @@
+package s3bytes
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestRenderer_toStatic(t *testing.T) {
+	type fields struct {
+		Data       *MetricData
+		OutputType OutputType
+		chartType  ChartType
+		topN       int
+		minPercent float64
+	}
+	tests := []struct {
+		name     string
+		fields   fields
+		contains []string
+		wantErr  bool
+	}{
+		{
+			name: "svg pie",
+			fields: fields{
+				Data:       testDashboardMetricData,
+				OutputType: OutputTypeSVG,
+				chartType:  ChartTypeNone,
+			},
+			contains: []string{
+				`<svg xmlns="http://www.w3.org/2000/svg" width="960" height="540"`,
+				">Bucket Size Bytes</text>",
+				">bucket0  1.0 kB (14.3%)</text>",
+				">&lt;bucket1&gt;  4.1 kB (57.1%)</text>",
+				`<path d="M280.0 295.0 L280.0 95.0 A200.0 200.0 0 0 1 436.4 170.3 Z" fill="#5470c6"/>`,
+			},
+			wantErr: false,
+		},
+		{
+			name: "svg pie with top n",
+			fields: fields{
+				Data:       testDashboardMetricData,
+				OutputType: OutputTypeSVG,
+				chartType:  ChartTypePie,
+				topN:       2,
+			},
+			contains: []string{
+				">bucket0  1.0 kB (14.3%)</text>",
+				">others  6.1 kB (85.7%)</text>",
+			},
+			wantErr: false,
+		},
+		{
+			name: "svg pie with min percent",
+			fields: fields{
+				Data:       testDashboardMetricData,
+				OutputType: OutputTypeSVG,
+				chartType:  ChartTypePie,
+				minPercent: 20,
+			},
+			contains: []string{
+				">bucket0  2.0 kB (28.6%)</text>",
+				">others  1.0 kB (14.3%)</text>",
+			},
+			wantErr: false,
+		},
+		{
+			name: "svg bar",
+			fields: fields{
+				Data:       testDashboardMetricData,
+				OutputType: OutputTypeSVG,
+				chartType:  ChartTypeBar,
+			},
+			contains: []string{
+				`text-anchor="end" fill="#333333">&lt;bucket1&gt;</text>`,
+				">4.1 kB</text>",
+				`<rect x="300.0" y="`,
+			},
+			wantErr: false,
+		},
+		{
+			name: "svg without items",
+			fields: fields{
+				Data:       &MetricData{Header: header},
+				OutputType: OutputTypeSVG,
+				chartType:  ChartTypePie,
+			},
+			contains: []string{
+				">no data</text>",
+			},
+			wantErr: false,
+		},
+		{
+			name: "unsupported chart type",
+			fields: fields{
+				Data:       testDashboardMetricData,
+				OutputType: OutputTypePNG,
+				chartType:  ChartTypeTreeMap,
+			},
+			contains: nil,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			ren := &Renderer{
+				Data:       tt.fields.Data,
+				OutputType: tt.fields.OutputType,
+				chartType:  tt.fields.chartType,
+				topN:       tt.fields.topN,
+				minPercent: tt.fields.minPercent,
+				w:          w,
+			}
+			if err := ren.Render(); (err != nil) != tt.wantErr {
+				t.Errorf("Renderer.Render() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			got := w.String()
+			for _, s := range tt.contains {
+				if !strings.Contains(got, s) {
+					t.Errorf("Renderer.Render() does not contain %q", s)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderer_toStatic_png(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypePie, ChartTypeBar} {
+		t.Run(chartType.String(), func(t *testing.T) {
+			w := &bytes.Buffer{}
+			ren := &Renderer{
+				Data:       testDashboardMetricData,
+				OutputType: OutputTypePNG,
+				chartType:  chartType,
+				w:          w,
+			}
+			if err := ren.Render(); err != nil {
+				t.Fatalf("Renderer.Render() error = %v", err)
+			}
+			img, err := png.Decode(w)
+			if err != nil {
+				t.Fatalf("png.Decode() error = %v", err)
+			}
+			if got := img.Bounds().Size(); got.X != staticWidth || got.Y != staticHeight {
+				t.Errorf("Renderer.Render() size = %v, want %dx%d", got, staticWidth, staticHeight)
+			}
+		})
+	}
+}
+
+func Test_newPieCanvas(t *testing.T) {
+	newItems := func(n int) []staticItem {
+		items := make([]staticItem, n)
+		for i := range items {
+			items[i] = staticItem{label: fmt.Sprintf("bucket-with-a-long-name-%04d", i), value: 1024}
+		}
+		return items
+	}
+	type want struct {
+		legends int
+		columns int
+		last    string
+	}
+	tests := []struct {
+		name  string
+		items []staticItem
+		want  want
+	}{
+		{
+			name:  "one column",
+			items: newItems(3),
+			want:  want{legends: 3, columns: 1, last: "bucket-with-a-long-name-0002  1.0 kB (33.3%)"},
+		},
+		{
+			name:  "two columns",
+			items: newItems(40),
+			want:  want{legends: 40, columns: 2, last: "bucket-...  1.0 kB (2.5%)"},
+		},
+		{
+			name:  "more than columns",
+			items: newItems(100),
+			want:  want{legends: 69, columns: 3, last: "+32 more"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newPieCanvas("title", tt.items, testSizeMetricData)
+			if len(c.wedges) != len(tt.items) {
+				t.Errorf("newPieCanvas() wedges = %d, want %d", len(c.wedges), len(tt.items))
+			}
+			legends := c.texts[1:]
+			if len(legends) != tt.want.legends {
+				t.Fatalf("newPieCanvas() legends = %d, want %d", len(legends), tt.want.legends)
+			}
+			columns := map[float64]struct{}{}
+			for _, text := range legends {
+				columns[text.x] = struct{}{}
+				if text.y > staticHeight-20 {
+					t.Errorf("newPieCanvas() legend %q at %v is out of the canvas", text.text, text.y)
+				}
+			}
+			if len(columns) != tt.want.columns {
+				t.Errorf("newPieCanvas() columns = %d, want %d", len(columns), tt.want.columns)
+			}
+			if got := legends[len(legends)-1].text; got != tt.want.last {
+				t.Errorf("newPieCanvas() last legend = %q, want %q", got, tt.want.last)
+			}
+		})
+	}
+}
+
+func Test_truncateLabel(t *testing.T) {
+	type args struct {
+		label string
+		n     int
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "short",
+			args: args{
+				label: "bucket0",
+				n:     10,
+			},
+			want: "bucket0",
+		},
+		{
+			name: "just",
+			args: args{
+				label: "bucket0123",
+				n:     10,
+			},
+			want: "bucket0123",
+		},
+		{
+			name: "long",
+			args: args{
+				label: "bucket01234",
+				n:     10,
+			},
+			want: "bucket0...",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateLabel(tt.args.label, tt.args.n); got != tt.want {
+				t.Errorf("truncateLabel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_staticWedge_point(t *testing.T) {
+	wd := staticWedge{cx: 100, cy: 100, r: 50}
+	tests := []struct {
+		name  string
+		angle float64
+		wantX float64
+		wantY float64
+	}{
+		{
+			name:  "top",
+			angle: 0,
+			wantX: 100,
+			wantY: 50,
+		},
+		{
+			name:  "right",
+			angle: math.Pi / 2,
+			wantX: 150,
+			wantY: 100,
+		},
+		{
+			name:  "bottom",
+			angle: math.Pi,
+			wantX: 100,
+			wantY: 150,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y := wd.point(tt.angle)
+			if math.Abs(x-tt.wantX) > 1e-9 || math.Abs(y-tt.wantY) > 1e-9 {
+				t.Errorf("staticWedge.point() = (%v, %v), want (%v, %v)", x, y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+}
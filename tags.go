@@ -0,0 +1,102 @@
+package s3bytes
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// maxTagKeyLength is the maximum length of the keys of the bucket tags.
+	maxTagKeyLength = 128
+
+	// tagColumnPrefix is the prefix of the columns of the selected tags, such as "Tag:team".
+	tagColumnPrefix = "Tag:"
+
+	// tagFieldPrefix is the prefix of the tags in filter expressions, such as "tag_team",
+	// since the identifiers of the filter grammar do not accept colons.
+	tagFieldPrefix = "tag_"
+)
+
+// tagKey returns the tag key of the field referring to a tag, and reports whether it does.
+func tagKey(field string) (string, bool) {
+	for _, prefix := range []string{tagFieldPrefix, tagColumnPrefix} {
+		if k, ok := strings.CutPrefix(field, prefix); ok && k != "" {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// withTagHeader returns the header followed by the columns of the selected tags.
+func (man *Manager) withTagHeader(header []string) []string {
+	if len(man.tagKeys) == 0 {
+		return header
+	}
+	ret := slices.Clone(header)
+	for _, key := range man.tagKeys {
+		ret = append(ret, tagColumnPrefix+key)
+	}
+	return ret
+}
+
+// selectTags returns the selected tags that are present in the tags of the bucket.
+// If the tags could not be read, all the selected tags are set to unknown.
+func (man *Manager) selectTags(tags map[string]string) map[string]string {
+	ret := make(map[string]string, len(man.tagKeys))
+	for _, key := range man.tagKeys {
+		if tags == nil {
+			ret[key] = statusUnknown
+			continue
+		}
+		if v, ok := tags[key]; ok {
+			ret[key] = v
+		}
+	}
+	return ret
+}
+
+// enrichTags sets the selected tags to the metrics of the buckets in the region.
+// The tags are fetched once per bucket, concurrently up to the concurrency of the manager at a time,
+// except for the directory buckets that do not support GetBucketTagging. The tags of the buckets
+// that cannot be read are shown as unknown, and only the error of the context is returned.
+func (man *Manager) enrichTags(ctx context.Context, metrics []*Metric, region string) error {
+	if len(man.tagKeys) == 0 || len(metrics) == 0 {
+		return nil
+	}
+	var (
+		buckets = make([]string, 0, len(metrics))
+		index   = make(map[string]int, len(metrics))
+	)
+	for _, metric := range metrics {
+		if _, ok := index[metric.BucketName]; !ok {
+			index[metric.BucketName] = len(buckets)
+			buckets = append(buckets, metric.BucketName)
+		}
+	}
+	tags := make([]map[string]string, len(buckets))
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(man.workers())
+	for i, bucket := range buckets {
+		if isDirectoryBucket(bucket) {
+			continue
+		}
+		g.Go(func() error {
+			t, err := getBucketTags(ctx, man.client, bucket, region)
+			if err != nil {
+				return err
+			}
+			tags[i] = man.selectTags(t)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	for _, metric := range metrics {
+		metric.Tags = tags[index[metric.BucketName]]
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package s3bytes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_tagKey(t *testing.T) {
+	type args struct {
+		field string
+	}
+	tests := []struct {
+		name  string
+		args  args
+		want  string
+		want1 bool
+	}{
+		{
+			name: "field",
+			args: args{
+				field: "tag_team",
+			},
+			want:  "team",
+			want1: true,
+		},
+		{
+			name: "column",
+			args: args{
+				field: "Tag:cost-center",
+			},
+			want:  "cost-center",
+			want1: true,
+		},
+		{
+			name: "empty key",
+			args: args{
+				field: "tag_",
+			},
+			want:  "",
+			want1: false,
+		},
+		{
+			name: "not tag",
+			args: args{
+				field: "bucket",
+			},
+			want:  "",
+			want1: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1 := tagKey(tt.args.field)
+			if got != tt.want {
+				t.Errorf("tagKey() got = %v, want %v", got, tt.want)
+			}
+			if got1 != tt.want1 {
+				t.Errorf("tagKey() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestManager_withTagHeader(t *testing.T) {
+	type fields struct {
+		tagKeys []string
+	}
+	type args struct {
+		header []string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   []string
+	}{
+		{
+			name: "no tags",
+			args: args{
+				header: []string{"BucketName", "Value"},
+			},
+			want: []string{"BucketName", "Value"},
+		},
+		{
+			name: "tags",
+			fields: fields{
+				tagKeys: []string{"team", "env"},
+			},
+			args: args{
+				header: []string{"BucketName", "Value"},
+			},
+			want: []string{"BucketName", "Value", "Tag:team", "Tag:env"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			man := &Manager{
+				tagKeys: tt.fields.tagKeys,
+			}
+			got := man.withTagHeader(tt.args.header)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Manager.withTagHeader() = %v, want %v", got, tt.want)
+			}
+			if len(tt.fields.tagKeys) > 0 && len(tt.args.header) == len(got) {
+				t.Errorf("Manager.withTagHeader() modified the header in place")
+			}
+		})
+	}
+}
+
+func TestManager_selectTags(t *testing.T) {
+	type fields struct {
+		tagKeys []string
+	}
+	type args struct {
+		tags map[string]string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   map[string]string
+	}{
+		{
+			name: "selected",
+			fields: fields{
+				tagKeys: []string{"team", "env"},
+			},
+			args: args{
+				tags: map[string]string{"team": "platform", "owner": "alice"},
+			},
+			want: map[string]string{"team": "platform"},
+		},
+		{
+			name: "no tags",
+			fields: fields{
+				tagKeys: []string{"team"},
+			},
+			args: args{
+				tags: map[string]string{},
+			},
+			want: map[string]string{},
+		},
+		{
+			name: "unknown",
+			fields: fields{
+				tagKeys: []string{"team", "env"},
+			},
+			args: args{
+				tags: nil,
+			},
+			want: map[string]string{"team": statusUnknown, "env": statusUnknown},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			man := &Manager{
+				tagKeys: tt.fields.tagKeys,
+			}
+			if got := man.selectTags(tt.args.tags); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Manager.selectTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetric_GetField_tag(t *testing.T) {
+	metric := &Metric{
+		BucketName: "bucket0",
+		Tags:       map[string]string{"team": "platform"},
+	}
+	tests := []struct {
+		name string
+		key  string
+		want any
+	}{
+		{
+			name: "field",
+			key:  "tag_team",
+			want: "platform",
+		},
+		{
+			name: "column",
+			key:  "Tag:team",
+			want: "platform",
+		},
+		{
+			name: "missing",
+			key:  "tag_env",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := metric.GetField(tt.key)
+			if err != nil {
+				t.Fatalf("Metric.GetField() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Metric.GetField() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package s3bytes
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The types of the Thrift compact protocol. The booleans in fields carry their values in the type.
+const (
+	thriftStop   = 0
+	thriftTrue   = 1
+	thriftFalse  = 2
+	thriftByte   = 3
+	thriftI16    = 4
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftDouble = 7
+	thriftBinary = 8
+	thriftList   = 9
+	thriftSet    = 10
+	thriftMap    = 11
+	thriftStruct = 12
+)
+
+// thriftMaxDepth is the maximum depth of the nested structs and collections to skip,
+// which protects the decoder from corrupt metadata.
+const thriftMaxDepth = 64
+
+// thriftReader is a decoder of the Thrift compact protocol used by the metadata of Parquet.
+// Only the parts needed to read the metadata are implemented, and unknown fields are skipped.
+type thriftReader struct {
+	b     []byte
+	off   int
+	depth int
+}
+
+// enter enters a nested struct or collection.
+func (t *thriftReader) enter() error {
+	if t.depth++; t.depth > thriftMaxDepth {
+		return errors.New("thrift value nested too deeply")
+	}
+	return nil
+}
+
+// leave leaves a nested struct or collection.
+func (t *thriftReader) leave() {
+	t.depth--
+}
+
+// byte reads a byte.
+func (t *thriftReader) byte() (byte, error) {
+	if t.off >= len(t.b) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	b := t.b[t.off]
+	t.off++
+	return b, nil
+}
+
+// uvarint reads an unsigned varint.
+func (t *thriftReader) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(t.b[t.off:])
+	if n <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	t.off += n
+	return v, nil
+}
+
+// varint reads a zigzag-encoded varint.
+func (t *thriftReader) varint() (int64, error) {
+	v, n := binary.Varint(t.b[t.off:])
+	if n <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	t.off += n
+	return v, nil
+}
+
+// i32 reads a 32-bit integer.
+func (t *thriftReader) i32() (int32, error) {
+	v, err := t.varint()
+	return int32(v), err
+}
+
+// i64 reads a 64-bit integer.
+func (t *thriftReader) i64() (int64, error) {
+	return t.varint()
+}
+
+// binary reads a length-prefixed byte array, which is also used for strings.
+// The returned slice refers to the buffer of the reader.
+func (t *thriftReader) binary() ([]byte, error) {
+	n, err := t.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(t.b)-t.off) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := t.b[t.off : t.off+int(n)]
+	t.off += int(n)
+	return b, nil
+}
+
+// readStruct reads the fields of a struct up to the stop field, calling fn with the ID and the type
+// of each field. fn must read or skip the value of the field.
+func (t *thriftReader) readStruct(fn func(id int16, typ byte) error) error {
+	if err := t.enter(); err != nil {
+		return err
+	}
+	defer t.leave()
+	var id int16
+	for {
+		b, err := t.byte()
+		if err != nil {
+			return err
+		}
+		typ := b & 0x0f
+		if typ == thriftStop {
+			return nil
+		}
+		if delta := int16(b >> 4); delta != 0 {
+			id += delta
+		} else {
+			v, err := t.varint()
+			if err != nil {
+				return err
+			}
+			id = int16(v)
+		}
+		if err := fn(id, typ); err != nil {
+			return err
+		}
+	}
+}
+
+// readList reads the header of a list or a set, and calls fn with the type of the elements once per element.
+func (t *thriftReader) readList(fn func(typ byte) error) error {
+	if err := t.enter(); err != nil {
+		return err
+	}
+	defer t.leave()
+	b, err := t.byte()
+	if err != nil {
+		return err
+	}
+	typ := b & 0x0f
+	n := uint64(b >> 4)
+	if n == 15 {
+		if n, err = t.uvarint(); err != nil {
+			return err
+		}
+	}
+	if n > uint64(len(t.b)-t.off) {
+		return io.ErrUnexpectedEOF
+	}
+	for range n {
+		if err := fn(typ); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skip skips the value of the type.
+func (t *thriftReader) skip(typ byte) error {
+	switch typ {
+	case thriftTrue, thriftFalse:
+		return nil
+	case thriftByte:
+		_, err := t.byte()
+		return err
+	case thriftI16, thriftI32, thriftI64:
+		_, err := t.varint()
+		return err
+	case thriftDouble:
+		if len(t.b)-t.off < 8 {
+			return io.ErrUnexpectedEOF
+		}
+		t.off += 8
+		return nil
+	case thriftBinary:
+		_, err := t.binary()
+		return err
+	case thriftList, thriftSet:
+		return t.readList(t.skipElem)
+	case thriftMap:
+		n, err := t.uvarint()
+		if err != nil || n == 0 {
+			return err
+		}
+		b, err := t.byte()
+		if err != nil {
+			return err
+		}
+		for range n {
+			if err := t.skipElem(b >> 4); err != nil {
+				return err
+			}
+			if err := t.skipElem(b & 0x0f); err != nil {
+				return err
+			}
+		}
+		return nil
+	case thriftStruct:
+		return t.readStruct(func(_ int16, typ byte) error {
+			return t.skip(typ)
+		})
+	default:
+		return fmt.Errorf("unknown thrift type: %d", typ)
+	}
+}
+
+// skipElem skips an element of a collection, in which a boolean takes a byte unlike in a field.
+func (t *thriftReader) skipElem(typ byte) error {
+	if typ == thriftTrue || typ == thriftFalse {
+		_, err := t.byte()
+		return err
+	}
+	return t.skip(typ)
+}
@@ -0,0 +1,196 @@
+package s3bytes
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// thriftWriter is an encoder of the Thrift compact protocol to build the metadata of test files.
+type thriftWriter struct {
+	bytes.Buffer
+	last []int16
+}
+
+// begin begins a struct.
+func (w *thriftWriter) begin() {
+	w.last = append(w.last, 0)
+}
+
+// end ends the struct with the stop field.
+func (w *thriftWriter) end() {
+	w.WriteByte(thriftStop)
+	w.last = w.last[:len(w.last)-1]
+}
+
+// field writes the header of the field, with the delta of the ID if it fits.
+func (w *thriftWriter) field(id int16, typ byte) {
+	last := &w.last[len(w.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		w.WriteByte(typ)
+		w.varint(int64(id))
+	}
+	*last = id
+}
+
+// varint writes a zigzag-encoded varint.
+func (w *thriftWriter) varint(v int64) {
+	w.Write(binary.AppendVarint(nil, v))
+}
+
+// raw writes a length-prefixed byte array without the field header.
+func (w *thriftWriter) raw(b []byte) {
+	w.Write(binary.AppendUvarint(nil, uint64(len(b))))
+	w.Write(b)
+}
+
+// i32 writes a 32-bit integer field.
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(id, thriftI32)
+	w.varint(int64(v))
+}
+
+// i64 writes a 64-bit integer field.
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(id, thriftI64)
+	w.varint(v)
+}
+
+// binary writes a byte array field.
+func (w *thriftWriter) binary(id int16, b []byte) {
+	w.field(id, thriftBinary)
+	w.raw(b)
+}
+
+// bool writes a boolean field.
+func (w *thriftWriter) bool(id int16, v bool) {
+	if v {
+		w.field(id, thriftTrue)
+	} else {
+		w.field(id, thriftFalse)
+	}
+}
+
+// list writes the header of a list field of the n elements of the type.
+func (w *thriftWriter) list(id int16, typ byte, n int) {
+	w.field(id, thriftList)
+	w.listHeader(typ, n)
+}
+
+// listHeader writes the header of a list without the field header.
+func (w *thriftWriter) listHeader(typ byte, n int) {
+	if n < 15 {
+		w.WriteByte(byte(n)<<4 | typ)
+		return
+	}
+	w.WriteByte(0xf0 | typ)
+	w.Write(binary.AppendUvarint(nil, uint64(n)))
+}
+
+// structField begins a struct field.
+func (w *thriftWriter) structField(id int16) {
+	w.field(id, thriftStruct)
+	w.begin()
+}
+
+func Test_thriftReader(t *testing.T) {
+	// a struct with the fields of every type to skip before the field to read
+	w := &thriftWriter{}
+	w.begin()
+	w.bool(1, true)
+	w.field(2, thriftByte)
+	w.WriteByte(0x7f)
+	w.field(3, thriftI16)
+	w.varint(-2)
+	w.field(4, thriftDouble)
+	w.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(1.5)))
+	w.list(5, thriftTrue, 2)
+	w.Write([]byte{1, 2})
+	w.list(6, thriftBinary, 16)
+	for range 16 {
+		w.raw([]byte("value"))
+	}
+	w.field(7, thriftSet)
+	w.listHeader(thriftI32, 1)
+	w.varint(3)
+	w.field(8, thriftMap)
+	w.Write(binary.AppendUvarint(nil, 1))
+	w.WriteByte(thriftBinary<<4 | thriftStruct)
+	w.raw([]byte("key"))
+	w.begin()
+	w.i32(1, 1)
+	w.end()
+	w.field(9, thriftMap)
+	w.WriteByte(0)
+	w.structField(10)
+	w.i64(1, 1)
+	w.end()
+	w.binary(100, []byte("found"))
+	w.i64(101, -1<<40)
+	w.end()
+	type want struct {
+		binary string
+		i64    int64
+	}
+	tests := []struct {
+		name    string
+		b       []byte
+		want    want
+		wantErr bool
+	}{
+		{
+			name:    "all types",
+			b:       w.Bytes(),
+			want:    want{binary: "found", i64: -1 << 40},
+			wantErr: false,
+		},
+		{
+			name:    "truncated",
+			b:       w.Bytes()[:w.Len()-4],
+			want:    want{binary: "found"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			b:       []byte{0x1d},
+			want:    want{},
+			wantErr: true,
+		},
+		{
+			name:    "too deep",
+			b:       bytes.Repeat([]byte{0x1c}, thriftMaxDepth+1),
+			want:    want{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got want
+			r := &thriftReader{b: tt.b}
+			err := r.readStruct(func(id int16, typ byte) error {
+				var err error
+				switch {
+				case id == 100 && typ == thriftBinary:
+					var b []byte
+					b, err = r.binary()
+					got.binary = string(b)
+				case id == 101 && typ == thriftI64:
+					got.i64, err = r.i64()
+				default:
+					err = r.skip(typ)
+				}
+				return err
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("thriftReader.readStruct() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("thriftReader.readStruct() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}